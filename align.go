@@ -0,0 +1,174 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// SizeMismatch tells Diff what to do with images of different bounds.
+type SizeMismatch int
+
+const (
+	// SizeMismatchFail rejects images of different bounds with a
+	// *SizeMismatchError.
+	SizeMismatchFail SizeMismatch = iota
+
+	// SizeMismatchPad extends both images to the union of their bounds,
+	// filling the missing pixels with the pad color (see WithPadColor),
+	// and compares the padded images; output must have the union bounds.
+	SizeMismatchPad
+
+	// SizeMismatchCrop compares only the intersection of the bounds
+	// and counts every pixel covered by just one of the images as different;
+	// output must have the intersection bounds.
+	SizeMismatchCrop
+)
+
+// String returns the name of the mode.
+func (m SizeMismatch) String() string {
+	switch m {
+	case SizeMismatchFail:
+		return "error"
+	case SizeMismatchPad:
+		return "pad"
+	case SizeMismatchCrop:
+		return "crop"
+	default:
+		return "unknown"
+	}
+}
+
+// WithSizeMismatch sets how images of different bounds are compared.
+func WithSizeMismatch(mode SizeMismatch) Option {
+	return func(o *Options) {
+		o.sizeMismatch = mode
+	}
+}
+
+// WithPadColor sets the color of the pixels added by SizeMismatchPad.
+func WithPadColor(c color.Color) Option {
+	return func(o *Options) {
+		if c == nil {
+			o.nilColor("pad")
+			return
+		}
+		o.padColor = toNRGBAColor(c)
+	}
+}
+
+// alignImages converts both images to *image.NRGBA of the same bounds
+// according to options.sizeMismatch and checks that a non-nil output
+// has these bounds too. Pixels dropped by cropping are
+// returned as a partial result to be merged into the final one.
+func alignImages(img1, img2 image.Image, output *image.NRGBA, options *Options) (a, b *image.NRGBA, outside DiffResult, err error) {
+	imgs := []image.Image{img1, img2}
+	if output != nil {
+		imgs = append(imgs, output)
+	}
+
+	if err = checkEmptyImages(imgs...); err != nil {
+		return nil, nil, outside, err
+	}
+
+	r1, r2 := img1.Bounds(), img2.Bounds()
+
+	switch {
+	case r1.Eq(r2) || options.sizeMismatch == SizeMismatchFail:
+		if err = checkImageSizes(imgs...); err != nil {
+			return nil, nil, outside, err
+		}
+		options.debugConverted(img1, img2)
+
+		return convertNRGBA(img1, options.pool), convertNRGBA(img2, options.pool), outside, nil
+
+	case options.sizeMismatch == SizeMismatchPad:
+		union := r1.Union(r2)
+		options.debug("padding images to the union of their bounds", "bounds", union)
+		a, b = padImage(img1, union, options.padColor, options.pool), padImage(img2, union, options.padColor, options.pool)
+
+	default:
+		inter := r1.Intersect(r2)
+		if inter.Empty() {
+			return nil, nil, outside, checkImageSizes(img1, img2)
+		}
+
+		options.debug("cropping images to the intersection of their bounds", "bounds", inter)
+		a, b = cropImage(img1, inter, options.pool), cropImage(img2, inter, options.pool)
+
+		for _, r := range append(subtractRect(r1, inter), subtractRect(r2, inter)...) {
+			r = options.compareRect(r)
+			outside.DiffPixels += uint64(r.Dx() * r.Dy())
+			outside.TotalPixels += uint64(r.Dx() * r.Dy())
+			outside.Bounds = outside.Bounds.Union(r)
+		}
+	}
+
+	if err = checkImageSizes(append([]image.Image{a, b}, imgs[2:]...)...); err != nil {
+		return nil, nil, outside, err
+	}
+
+	return a, b, outside, nil
+}
+
+// toNRGBA returns img itself when it is already *image.NRGBA
+// or its copy converted to NRGBA otherwise.
+func toNRGBA(img image.Image) *image.NRGBA {
+	return convertNRGBA(img, nil)
+}
+
+// convertNRGBA is toNRGBA taking the copy from the pool.
+func convertNRGBA(img image.Image, pool *pixPool) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok {
+		return nrgba
+	}
+
+	dst := pool.newNRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return dst
+}
+
+func padImage(img image.Image, r image.Rectangle, fill color.NRGBA, pool *pixPool) *image.NRGBA {
+	dst := pool.newNRGBA(r)
+	draw.Draw(dst, r, &image.Uniform{C: fill}, image.Point{}, draw.Src)
+	draw.Draw(dst, img.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return dst
+}
+
+func cropImage(img image.Image, r image.Rectangle, pool *pixPool) *image.NRGBA {
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return convertNRGBA(sub.SubImage(r), pool)
+	}
+
+	dst := pool.newNRGBA(r)
+	draw.Draw(dst, r, img, r.Min, draw.Src)
+
+	return dst
+}
+
+// subtractRect returns up to four non-overlapping rectangles
+// covering the part of r outside of cut.
+func subtractRect(r, cut image.Rectangle) []image.Rectangle {
+	cut = cut.Intersect(r)
+	if cut.Empty() {
+		return []image.Rectangle{r}
+	}
+
+	var parts []image.Rectangle
+	for _, part := range []image.Rectangle{
+		image.Rect(r.Min.X, r.Min.Y, r.Max.X, cut.Min.Y),
+		image.Rect(r.Min.X, cut.Max.Y, r.Max.X, r.Max.Y),
+		image.Rect(r.Min.X, cut.Min.Y, cut.Min.X, cut.Max.Y),
+		image.Rect(cut.Max.X, cut.Min.Y, r.Max.X, cut.Max.Y),
+	} {
+		if !part.Empty() {
+			parts = append(parts, part)
+		}
+	}
+
+	return parts
+}
@@ -0,0 +1,70 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDiffSizeMismatch(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	img1 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 10, 12))
+	for _, img := range []*image.NRGBA{img1, img2} {
+		for i := 0; i < len(img.Pix); i += 4 {
+			copy(img.Pix[i:i+4], []uint8{255, 255, 255, 255})
+		}
+	}
+
+	_, err := Diff(img1, img2, image.NewNRGBA(img2.Bounds()))
+	if !errors.Is(err, ErrImageSize) {
+		t.Errorf("Expected ErrImageSize, got - %v", err)
+	}
+
+	result, err := Diff(img1, img2, image.NewNRGBA(img2.Bounds()),
+		WithSizeMismatch(SizeMismatchPad),
+		WithPadColor(white),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 0 || result.TotalPixels != 120 {
+		t.Errorf("pad with matching color: unexpected counts %d/%d", result.DiffPixels, result.TotalPixels)
+	}
+
+	result, err = Diff(img1, img2, image.NewNRGBA(img2.Bounds()),
+		WithSizeMismatch(SizeMismatchPad),
+		WithPadColor(color.Black),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 20 {
+		t.Errorf("pad: expected 20, got - %d", result.DiffPixels)
+	}
+
+	result, err = Diff(img1, img2, image.NewNRGBA(img1.Bounds()), WithSizeMismatch(SizeMismatchCrop))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 20 || result.TotalPixels != 120 {
+		t.Errorf("crop: unexpected counts %d/%d", result.DiffPixels, result.TotalPixels)
+	}
+	if want := image.Rect(0, 10, 10, 12); !result.Bounds.Eq(want) {
+		t.Errorf("crop: expected bounds %v, got - %v", want, result.Bounds)
+	}
+}
+
+func TestSubtractRect(t *testing.T) {
+	r := image.Rect(0, 0, 10, 10)
+	parts := subtractRect(r, image.Rect(2, 2, 5, 5))
+
+	area := 0
+	for _, part := range parts {
+		area += part.Dx() * part.Dy()
+	}
+	if area != 100-9 {
+		t.Errorf("Expected area 91, got - %d", area)
+	}
+}
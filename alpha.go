@@ -0,0 +1,80 @@
+package pixelmatch
+
+import "image"
+
+// AlphaMode tells how the color channels of the pixels of the compared
+// images relate to their alpha.
+type AlphaMode int
+
+const (
+	// AlphaAuto follows the documented semantics of the image types:
+	// *image.RGBA and *image.RGBA64 are premultiplied, the other types and
+	// the bytes of DiffPix straight. Images holding values of the other
+	// kind, e.g. straight values copied into an *image.RGBA, need one of
+	// the explicit modes.
+	AlphaAuto AlphaMode = iota
+
+	// AlphaStraight reads the channels of all images as independent of
+	// alpha, whatever their type.
+	AlphaStraight
+
+	// AlphaPremultiplied reads the channels of all images as multiplied
+	// by alpha, whatever their type, e.g. *image.NRGBA or DiffPix buffers
+	// wrapping the surfaces of Cairo, Skia or Core Graphics.
+	AlphaPremultiplied
+)
+
+// String returns the name of the mode.
+func (m AlphaMode) String() string {
+	switch m {
+	case AlphaAuto:
+		return "auto"
+	case AlphaStraight:
+		return "straight"
+	case AlphaPremultiplied:
+		return "premultiplied"
+	default:
+		return "unknown"
+	}
+}
+
+// WithAlphaMode overrides how the color channels of the inputs relate
+// to their alpha, see AlphaMode.
+func WithAlphaMode(mode AlphaMode) Option {
+	return func(o *Options) {
+		o.alphaMode = mode
+	}
+}
+
+// alphaImages returns both images as images of the type matching their
+// alpha mode, sharing their pixels.
+func (o *Options) alphaImages(img1, img2 image.Image) (image.Image, image.Image) {
+	return o.alphaImage(img1), o.alphaImage(img2)
+}
+
+// alphaImage returns img as an image of the type matching the alpha mode,
+// sharing its pixels.
+func (o *Options) alphaImage(img image.Image) image.Image {
+	switch img := img.(type) {
+	case *image.RGBA:
+		if o.alphaMode == AlphaStraight {
+			o.debug("reading premultiplied image as straight", "mode", o.alphaMode)
+			return &image.NRGBA{Pix: img.Pix, Stride: img.Stride, Rect: img.Rect}
+		}
+	case *image.RGBA64:
+		if o.alphaMode == AlphaStraight {
+			o.debug("reading premultiplied image as straight", "mode", o.alphaMode)
+			return &image.NRGBA64{Pix: img.Pix, Stride: img.Stride, Rect: img.Rect}
+		}
+	case *image.NRGBA:
+		if o.alphaMode == AlphaPremultiplied {
+			return &image.RGBA{Pix: img.Pix, Stride: img.Stride, Rect: img.Rect}
+		}
+	case *image.NRGBA64:
+		if o.alphaMode == AlphaPremultiplied {
+			return &image.RGBA64{Pix: img.Pix, Stride: img.Stride, Rect: img.Rect}
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,72 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWithAlphaMode(t *testing.T) {
+	fill := func(img interface {
+		image.Image
+		Set(x, y int, c color.Color)
+	}, c color.Color) {
+		for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+			for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+				img.Set(x, y, c)
+			}
+		}
+	}
+
+	// pixels of a given type holding the channels as they are
+	rgba := func(c color.NRGBA) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+		fill(img, color.RGBA(c))
+		return img
+	}
+	nrgba := func(c color.NRGBA) *image.NRGBA {
+		img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+		fill(img, c)
+		return img
+	}
+
+	halfRed := color.NRGBA{255, 0, 0, 128}
+	premultipliedRed := color.NRGBA{128, 0, 0, 128}
+	dimRed := color.NRGBA{100, 0, 0, 128}
+
+	// straight values in the margin of a premultiplied sub image do not
+	// matter as the types decide
+	parent := rgba(premultipliedRed)
+	parent.SetRGBA(3, 3, color.RGBA(halfRed))
+	sub := parent.SubImage(image.Rect(0, 0, 3, 3)).(*image.RGBA)
+
+	wide := image.NewRGBA64(image.Rect(0, 0, 4, 4))
+	fill(wide, color.RGBA64{0xffff, 0, 0, 0x8000})
+
+	for _, tc := range []struct {
+		name       string
+		img1, img2 image.Image
+		mode       AlphaMode
+		equal      bool
+	}{
+		{"premultiplied", rgba(premultipliedRed), nrgba(halfRed), AlphaAuto, true},
+		{"straight in RGBA undeclared", rgba(dimRed), nrgba(dimRed), AlphaAuto, false},
+		{"straight in RGBA declared", rgba(dimRed), nrgba(dimRed), AlphaStraight, true},
+		{"premultiplied in NRGBA", nrgba(premultipliedRed), rgba(premultipliedRed), AlphaPremultiplied, true},
+		{"premultiplied in NRGBA undeclared", nrgba(premultipliedRed), nrgba(halfRed), AlphaAuto, false},
+		{"sub image", sub, nrgba(halfRed).SubImage(sub.Rect), AlphaAuto, true},
+		{"straight in RGBA64 declared", wide, nrgba(halfRed), AlphaStraight, true},
+	} {
+		result, err := Diff(tc.img1, tc.img2, nil, WithAlphaMode(tc.mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if equal := result.DiffPixels == 0; equal != tc.equal {
+			t.Errorf("%s: expected equal %v, got - %d different pixels", tc.name, tc.equal, result.DiffPixels)
+		}
+
+		if count, err := Compare(tc.img1, tc.img2, WithAlphaMode(tc.mode)); err != nil || count != result.DiffPixels {
+			t.Errorf("%s: expected Compare to count %d, got - %d %v", tc.name, result.DiffPixels, count, err)
+		}
+	}
+}
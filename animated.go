@@ -0,0 +1,142 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+)
+
+// Animation is a sequence of frames of the same bounds as a viewer shows
+// them, every frame composed over the previous ones according to their
+// disposal methods.
+type Animation struct {
+	Frames []*image.NRGBA
+
+	// display time of every frame
+	Delays []time.Duration
+}
+
+// GIFAnimation composes the frames of a decoded GIF, honoring
+// their disposal methods. The canvas starts transparent.
+func GIFAnimation(g *gif.GIF) *Animation {
+	canvas := image.NewNRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	if canvas.Rect.Empty() {
+		var r image.Rectangle
+		for _, frame := range g.Image {
+			r = r.Union(frame.Rect)
+		}
+		canvas = image.NewNRGBA(image.Rect(0, 0, r.Max.X, r.Max.Y))
+	}
+
+	anim := &Animation{}
+	for i, frame := range g.Image {
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+
+		var previous *image.NRGBA
+		if disposal == gif.DisposalPrevious {
+			previous = cloneNRGBA(canvas)
+		}
+
+		draw.Draw(canvas, frame.Rect, frame, frame.Rect.Min, draw.Over)
+		anim.Frames = append(anim.Frames, cloneNRGBA(canvas))
+
+		var delay time.Duration
+		if i < len(g.Delay) {
+			delay = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+		anim.Delays = append(anim.Delays, delay)
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Rect, image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+
+	return anim
+}
+
+// EncodeGIF writes the animation as an animated GIF, mapping the colors
+// of the frames to the Plan 9 palette.
+func (a *Animation) EncodeGIF(w io.Writer) error {
+	g := &gif.GIF{}
+	for i, frame := range a.Frames {
+		paletted := image.NewPaletted(frame.Rect, palette.Plan9)
+		draw.Draw(paletted, frame.Rect, frame, frame.Rect.Min, draw.Src)
+
+		g.Image = append(g.Image, paletted)
+		g.Delay = append(g.Delay, int(a.Delays[i]/(10*time.Millisecond)))
+	}
+
+	return gif.EncodeAll(w, g)
+}
+
+// AnimatedResult describes the outcome of comparing two animations.
+type AnimatedResult struct {
+	// results of the frames compared pairwise, in order
+	Frames []DiffResult `json:"frames"`
+
+	// number of frames of both animations; the extra frames
+	// of the longer animation are not compared
+	FramesA int `json:"framesA"`
+	FramesB int `json:"framesB"`
+
+	// number of compared frames with different pixels
+	DiffFrames int `json:"diffFrames"`
+
+	// diff images of the compared frames with the delays of the first animation
+	Diff *Animation `json:"-"`
+}
+
+// Equal reports whether both animations have the same number of frames
+// and no frame has different pixels.
+func (r AnimatedResult) Equal() bool {
+	return r.FramesA == r.FramesB && r.DiffFrames == 0
+}
+
+// DiffAnimations compares a and b frame by frame, frame N with frame N,
+// and renders a diff animation. The frame delays are not compared.
+func DiffAnimations(a, b *Animation, opts ...Option) (AnimatedResult, error) {
+	result := AnimatedResult{
+		FramesA: len(a.Frames),
+		FramesB: len(b.Frames),
+		Diff:    &Animation{},
+	}
+
+	for i := 0; i < len(a.Frames) && i < len(b.Frames); i++ {
+		output, frame, err := DiffNew(a.Frames[i], b.Frames[i], opts...)
+		if err != nil {
+			return result, err
+		}
+
+		result.Frames = append(result.Frames, frame)
+		if frame.DiffPixels > 0 {
+			result.DiffFrames++
+		}
+
+		result.Diff.Frames = append(result.Diff.Frames, output)
+		result.Diff.Delays = append(result.Diff.Delays, a.Delays[i])
+	}
+
+	return result, nil
+}
+
+// DiffAnimated compares two decoded GIFs frame by frame,
+// see GIFAnimation and DiffAnimations.
+func DiffAnimated(a, b *gif.GIF, opts ...Option) (AnimatedResult, error) {
+	return DiffAnimations(GIFAnimation(a), GIFAnimation(b), opts...)
+}
+
+func cloneNRGBA(img *image.NRGBA) *image.NRGBA {
+	dst := image.NewNRGBA(img.Rect)
+	copy(dst.Pix, img.Pix)
+
+	return dst
+}
@@ -0,0 +1,118 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"testing"
+	"time"
+)
+
+// testGIF returns a 4x4 GIF of a white first frame followed by a red pixel
+// at (1,1) drawn with the disposal and a blue pixel at (2,2).
+func testGIF(disposal byte) *gif.GIF {
+	white := image.NewPaletted(image.Rect(0, 0, 4, 4), palette.WebSafe)
+	for i := range white.Pix {
+		white.Pix[i] = uint8(white.Palette.Index(color.White))
+	}
+
+	red := image.NewPaletted(image.Rect(1, 1, 2, 2), palette.WebSafe)
+	red.SetColorIndex(1, 1, uint8(red.Palette.Index(color.RGBA{R: 255, A: 255})))
+	blue := image.NewPaletted(image.Rect(2, 2, 3, 3), palette.WebSafe)
+	blue.SetColorIndex(2, 2, uint8(blue.Palette.Index(color.RGBA{B: 255, A: 255})))
+
+	return &gif.GIF{
+		Image:    []*image.Paletted{white, red, blue},
+		Delay:    []int{10, 20, 30},
+		Disposal: []byte{gif.DisposalNone, disposal, gif.DisposalNone},
+		Config:   image.Config{Width: 4, Height: 4},
+	}
+}
+
+func TestGIFAnimation(t *testing.T) {
+	tests := []struct {
+		disposal byte
+		want     color.NRGBA // at (1,1) in the last frame
+	}{
+		{gif.DisposalNone, color.NRGBA{R: 255, A: 255}},
+		{gif.DisposalBackground, color.NRGBA{}},
+		{gif.DisposalPrevious, color.NRGBA{R: 255, G: 255, B: 255, A: 255}},
+	}
+
+	for _, tc := range tests {
+		anim := GIFAnimation(testGIF(tc.disposal))
+		if len(anim.Frames) != 3 {
+			t.Fatalf("disposal %d: expected 3 frames, got - %d", tc.disposal, len(anim.Frames))
+		}
+		if got := anim.Frames[1].NRGBAAt(1, 1); got != (color.NRGBA{R: 255, A: 255}) {
+			t.Errorf("disposal %d: expected a red second frame, got - %v", tc.disposal, got)
+		}
+		if got := anim.Frames[2].NRGBAAt(1, 1); got != tc.want {
+			t.Errorf("disposal %d: expected %v, got - %v", tc.disposal, tc.want, got)
+		}
+		if got := anim.Frames[2].NRGBAAt(2, 2); got != (color.NRGBA{B: 255, A: 255}) {
+			t.Errorf("disposal %d: expected the third frame drawn, got - %v", tc.disposal, got)
+		}
+		if anim.Delays[1] != 200*time.Millisecond {
+			t.Errorf("disposal %d: expected a delay of 200ms, got - %s", tc.disposal, anim.Delays[1])
+		}
+	}
+}
+
+func TestDiffAnimated(t *testing.T) {
+	result, err := DiffAnimated(testGIF(gif.DisposalNone), testGIF(gif.DisposalNone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Equal() || len(result.Frames) != 3 {
+		t.Errorf("Expected 3 equal frames, got - %+v", result)
+	}
+
+	// the same frames differ only after the disposal of the red pixel
+	result, err = DiffAnimated(testGIF(gif.DisposalNone), testGIF(gif.DisposalPrevious), WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffFrames != 1 || result.Frames[2].DiffPixels != 1 || result.Frames[1].DiffPixels != 0 {
+		t.Errorf("Expected one different pixel in the last frame, got - %+v", result.Frames)
+	}
+	if len(result.Diff.Frames) != 3 || result.Diff.Delays[2] != 300*time.Millisecond {
+		t.Errorf("Expected 3 diff frames with the delays of the first animation, got - %v", result.Diff.Delays)
+	}
+
+	shorter := testGIF(gif.DisposalNone)
+	shorter.Image, shorter.Delay, shorter.Disposal = shorter.Image[:2], shorter.Delay[:2], shorter.Disposal[:2]
+	result, err = DiffAnimated(testGIF(gif.DisposalNone), shorter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Equal() || result.FramesA != 3 || result.FramesB != 2 || len(result.Frames) != 2 || result.DiffFrames != 0 {
+		t.Errorf("Expected 2 equal frames out of 3 and 2, got - %+v", result)
+	}
+}
+
+func TestAnimationEncodeGIF(t *testing.T) {
+	result, err := DiffAnimated(testGIF(gif.DisposalNone), testGIF(gif.DisposalBackground), WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := result.Diff.EncodeGIF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 3 || g.Delay[1] != 20 {
+		t.Errorf("Expected 3 frames with the original delays, got - %d frames, %v", len(g.Image), g.Delay)
+	}
+
+	// the removed red pixel is highlighted in the last diff frame
+	if r, _, _, _ := g.Image[2].At(1, 1).RGBA(); r>>8 != 255 {
+		t.Errorf("Expected a highlighted pixel, got - %v", g.Image[2].At(1, 1))
+	}
+}
@@ -0,0 +1,56 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// edgeImage draws a vertical white to black edge at column 5,
+// optionally smoothed by a gray anti-aliasing column.
+func edgeImage(smooth bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			c := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			switch {
+			case x == 5 && smooth:
+				c = color.NRGBA{R: 128, G: 128, B: 128, A: 255}
+			case x > 5:
+				c = color.NRGBA{A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestAntialiasedIsSymmetric(t *testing.T) {
+	sharp, smooth := edgeImage(false), edgeImage(true)
+
+	for _, tc := range []struct {
+		name       string
+		img1, img2 *image.NRGBA
+	}{
+		{"aa in second image", sharp, smooth},
+		{"aa in first image", smooth, sharp},
+	} {
+		result, err := Diff(tc.img1, tc.img2, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.DiffPixels != 0 || result.AAPixels != 10 {
+			t.Errorf("%s: expected 0 different and 10 anti-aliased pixels, got - %d and %d",
+				tc.name, result.DiffPixels, result.AAPixels)
+		}
+
+		count, err := Compare(tc.img1, tc.img2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%s: expected Compare to find 0, got - %d", tc.name, count)
+		}
+	}
+}
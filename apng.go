@@ -0,0 +1,215 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"time"
+)
+
+// ErrAPNG is returned by DecodeAPNG for malformed animated PNGs.
+var ErrAPNG = errors.New("invalid APNG")
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// APNG dispose and blend operations of fcTL chunks.
+const (
+	apngDisposeBackground = 1
+	apngDisposePrevious   = 2
+
+	apngBlendSource = 0
+)
+
+// apngFrame is a frame control (fcTL) chunk with the image data of the frame.
+type apngFrame struct {
+	rect             image.Rectangle
+	delay            time.Duration
+	dispose, blend   byte
+	data             []byte
+	hasData, isFirst bool
+}
+
+// DecodeAPNG decodes an animated PNG into its composed frames, honoring
+// their dispose and blend operations. A PNG without animation control
+// becomes an animation of a single frame.
+func DecodeAPNG(r io.Reader) (*Animation, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return nil, fmt.Errorf("%w: not a PNG", ErrAPNG)
+	}
+
+	var (
+		ihdr     []byte
+		shared   [][]byte // PLTE and tRNS chunks every frame needs
+		frames   []*apngFrame
+		animated bool
+		idat     []byte
+	)
+
+	for rest := data[len(pngSignature):]; len(rest) > 0; {
+		if len(rest) < 12 {
+			return nil, fmt.Errorf("%w: truncated chunk", ErrAPNG)
+		}
+		size := binary.BigEndian.Uint32(rest)
+		if uint64(size)+12 > uint64(len(rest)) {
+			return nil, fmt.Errorf("%w: truncated chunk", ErrAPNG)
+		}
+		typ, body := string(rest[4:8]), rest[8:8+size]
+		chunk := rest[:12+size]
+		rest = rest[12+size:]
+
+		var last *apngFrame
+		if len(frames) > 0 {
+			last = frames[len(frames)-1]
+		}
+
+		switch typ {
+		case "IHDR":
+			if size != 13 {
+				return nil, fmt.Errorf("%w: bad IHDR", ErrAPNG)
+			}
+			ihdr = body
+		case "PLTE", "tRNS":
+			shared = append(shared, chunk)
+		case "acTL":
+			animated = true
+		case "fcTL":
+			frame, err := parseFCTL(body, len(frames) == 0)
+			if err != nil {
+				return nil, err
+			}
+			frames = append(frames, frame)
+		case "IDAT":
+			idat = append(idat, body...)
+			// the default image is the first frame when an fcTL precedes it
+			if last != nil && last.isFirst {
+				last.data, last.hasData = idat, true
+			}
+		case "fdAT":
+			if last == nil || len(body) < 4 {
+				return nil, fmt.Errorf("%w: fdAT without fcTL", ErrAPNG)
+			}
+			last.data, last.hasData = append(last.data, body[4:]...), true
+		}
+	}
+
+	if ihdr == nil {
+		return nil, fmt.Errorf("%w: missing IHDR", ErrAPNG)
+	}
+	canvasRect := image.Rect(0, 0, int(binary.BigEndian.Uint32(ihdr)), int(binary.BigEndian.Uint32(ihdr[4:])))
+
+	if !animated || len(frames) == 0 {
+		frames = []*apngFrame{{rect: canvasRect, data: idat, hasData: true}}
+	}
+
+	anim := &Animation{}
+	canvas := image.NewNRGBA(canvasRect)
+	for i, frame := range frames {
+		if !frame.hasData {
+			return nil, fmt.Errorf("%w: frame %d has no data", ErrAPNG, i)
+		}
+		if !frame.rect.In(canvasRect) {
+			return nil, fmt.Errorf("%w: frame %d is outside of the image", ErrAPNG, i)
+		}
+
+		img, err := decodeAPNGFrame(ihdr, shared, frame)
+		if err != nil {
+			return nil, fmt.Errorf("%w: frame %d: %v", ErrAPNG, i, err)
+		}
+
+		var previous *image.NRGBA
+		if frame.dispose == apngDisposePrevious {
+			previous = cloneNRGBA(canvas)
+		}
+
+		op := draw.Over
+		if frame.blend == apngBlendSource {
+			op = draw.Src
+		}
+		draw.Draw(canvas, frame.rect, img, image.Point{}, op)
+
+		anim.Frames = append(anim.Frames, cloneNRGBA(canvas))
+		anim.Delays = append(anim.Delays, frame.delay)
+
+		switch frame.dispose {
+		case apngDisposeBackground:
+			draw.Draw(canvas, frame.rect, image.Transparent, image.Point{}, draw.Src)
+		case apngDisposePrevious:
+			canvas = previous
+		}
+	}
+
+	return anim, nil
+}
+
+// parseFCTL parses the body of a frame control chunk.
+func parseFCTL(body []byte, first bool) (*apngFrame, error) {
+	if len(body) != 26 {
+		return nil, fmt.Errorf("%w: bad fcTL", ErrAPNG)
+	}
+
+	var (
+		w, h  = int(binary.BigEndian.Uint32(body[4:])), int(binary.BigEndian.Uint32(body[8:]))
+		x, y  = int(binary.BigEndian.Uint32(body[12:])), int(binary.BigEndian.Uint32(body[16:]))
+		num   = time.Duration(binary.BigEndian.Uint16(body[20:]))
+		den   = time.Duration(binary.BigEndian.Uint16(body[22:]))
+		frame = &apngFrame{rect: image.Rect(x, y, x+w, y+h), dispose: body[24], blend: body[25], isFirst: first}
+	)
+
+	// a zero denominator means hundredths of a second
+	if den == 0 {
+		den = 100
+	}
+	frame.delay = num * time.Second / den
+
+	// the first frame has nothing to restore
+	if first && frame.dispose == apngDisposePrevious {
+		frame.dispose = apngDisposeBackground
+	}
+
+	return frame, nil
+}
+
+// decodeAPNGFrame decodes the image data of a frame by wrapping it
+// into a PNG of the size of the frame.
+func decodeAPNGFrame(ihdr []byte, shared [][]byte, frame *apngFrame) (image.Image, error) {
+	var buf bytes.Buffer
+	buf.WriteString(pngSignature)
+
+	header := append([]byte(nil), ihdr...)
+	binary.BigEndian.PutUint32(header, uint32(frame.rect.Dx()))
+	binary.BigEndian.PutUint32(header[4:], uint32(frame.rect.Dy()))
+	writePNGChunk(&buf, "IHDR", header)
+
+	for _, chunk := range shared {
+		buf.Write(chunk)
+	}
+
+	writePNGChunk(&buf, "IDAT", frame.data)
+	writePNGChunk(&buf, "IEND", nil)
+
+	return png.Decode(&buf)
+}
+
+func writePNGChunk(w *bytes.Buffer, typ string, data []byte) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(data)))
+	copy(header[4:], typ)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	w.Write(header[:])
+	w.Write(data)
+	binary.Write(w, binary.BigEndian, crc.Sum32())
+}
@@ -0,0 +1,157 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+)
+
+type testAPNGFrame struct {
+	img            *image.NRGBA
+	offset         image.Point
+	dispose, blend byte
+}
+
+// encodeAPNG builds an APNG of the frames with 100ms delays, using the first
+// frame as the default image. All frames must encode to the same color type.
+func encodeAPNG(tb testing.TB, frames []testAPNGFrame) []byte {
+	tb.Helper()
+
+	var buf bytes.Buffer
+	buf.WriteString(pngSignature)
+
+	var seq uint32
+	for i, frame := range frames {
+		var encoded bytes.Buffer
+		if err := png.Encode(&encoded, frame.img); err != nil {
+			tb.Fatal(err)
+		}
+
+		var data []byte
+		for rest := encoded.Bytes()[len(pngSignature):]; len(rest) > 0; {
+			size := binary.BigEndian.Uint32(rest)
+			typ, body := string(rest[4:8]), rest[8:8+size]
+			rest = rest[12+size:]
+
+			switch {
+			case typ == "IHDR" && i == 0:
+				writePNGChunk(&buf, typ, body)
+				actl := make([]byte, 8)
+				binary.BigEndian.PutUint32(actl, uint32(len(frames)))
+				writePNGChunk(&buf, "acTL", actl)
+			case typ == "IDAT":
+				data = append(data, body...)
+			}
+		}
+
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl, seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(frame.img.Rect.Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(frame.img.Rect.Dy()))
+		binary.BigEndian.PutUint32(fctl[12:], uint32(frame.offset.X))
+		binary.BigEndian.PutUint32(fctl[16:], uint32(frame.offset.Y))
+		binary.BigEndian.PutUint16(fctl[20:], 1)
+		binary.BigEndian.PutUint16(fctl[22:], 10)
+		fctl[24], fctl[25] = frame.dispose, frame.blend
+		writePNGChunk(&buf, "fcTL", fctl)
+		seq++
+
+		if i == 0 {
+			writePNGChunk(&buf, "IDAT", data)
+			continue
+		}
+		fdat := make([]byte, 4, 4+len(data))
+		binary.BigEndian.PutUint32(fdat, seq)
+		writePNGChunk(&buf, "fdAT", append(fdat, data...))
+		seq++
+	}
+
+	writePNGChunk(&buf, "IEND", nil)
+
+	return buf.Bytes()
+}
+
+// solidImage returns a w x h image of c with a transparent last pixel,
+// so that every such image encodes with an alpha channel.
+func solidImage(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix)-4; i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+
+	return img
+}
+
+func TestDecodeAPNG(t *testing.T) {
+	var (
+		white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+		red   = color.NRGBA{R: 255, A: 255}
+		none  = color.NRGBA{B: 255}
+	)
+
+	tests := []struct {
+		name           string
+		dispose, blend byte
+		want           color.NRGBA // at (1,1) in the last frame
+	}{
+		{"none", 0, 1, red},
+		{"background", 1, 1, color.NRGBA{}},
+		{"previous", 2, 1, white},
+		{"source", 0, 0, none},
+	}
+
+	for _, tc := range tests {
+		data := encodeAPNG(t, []testAPNGFrame{
+			{img: solidImage(4, 4, white)},
+			{img: solidImage(2, 2, red), offset: image.Pt(1, 1), dispose: tc.dispose},
+			{img: solidImage(2, 2, none), offset: image.Pt(1, 1), blend: tc.blend},
+		})
+
+		anim, err := DecodeAPNG(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(anim.Frames) != 3 || anim.Delays[2] != 100*time.Millisecond {
+			t.Fatalf("%s: expected 3 frames of 100ms, got - %d frames, %v", tc.name, len(anim.Frames), anim.Delays)
+		}
+		if got := anim.Frames[1].NRGBAAt(1, 1); got != red {
+			t.Errorf("%s: expected a red second frame, got - %v", tc.name, got)
+		}
+		if got := anim.Frames[2].NRGBAAt(1, 1); got != tc.want {
+			t.Errorf("%s: expected %v, got - %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestDecodeAPNGStill(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, solidImage(3, 2, color.NRGBA{G: 255, A: 255})); err != nil {
+		t.Fatal(err)
+	}
+
+	anim, err := DecodeAPNG(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Frames) != 1 || anim.Frames[0].NRGBAAt(0, 0) != (color.NRGBA{G: 255, A: 255}) {
+		t.Errorf("Expected a single green frame, got - %d frames", len(anim.Frames))
+	}
+}
+
+func TestDecodeAPNGInvalid(t *testing.T) {
+	data := encodeAPNG(t, []testAPNGFrame{{img: solidImage(4, 4, color.NRGBA{A: 255})}})
+
+	for name, input := range map[string][]byte{
+		"not a PNG": []byte("GIF89a"),
+		"truncated": data[:len(data)-20],
+	} {
+		if _, err := DecodeAPNG(bytes.NewReader(input)); !errors.Is(err, ErrAPNG) {
+			t.Errorf("%s: expected ErrAPNG, got - %v", name, err)
+		}
+	}
+}
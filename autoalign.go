@@ -0,0 +1,182 @@
+package pixelmatch
+
+import (
+	"image"
+	"math"
+)
+
+// WithAutoAlign detects a global translation of up to maxShift pixels
+// between the images, such as a page moved by a scrollbar, and compares
+// them with the second image moved back; maxShift <= 0 disables it.
+// The detected translation is reported in DiffResult.Offset. Pixels of the
+// first image without a counterpart in the moved second one are left out
+// like pixels outside WithRegion.
+func WithAutoAlign(maxShift int) Option {
+	return func(o *Options) {
+		o.autoAlign = maxShift
+	}
+}
+
+// minLumaSize is the smallest side of the coarsest level
+// of the pyramid searched for the offset.
+const minLumaSize = 16
+
+// lumaPlane holds the brightness of an image blended with white,
+// the part of a pixel the offset estimation looks at.
+type lumaPlane struct {
+	w, h int
+	pix  []float32
+}
+
+func newLumaPlane(img *image.NRGBA) lumaPlane {
+	r := img.Bounds()
+	p := lumaPlane{w: r.Dx(), h: r.Dy(), pix: make([]float32, r.Dx()*r.Dy())}
+
+	for y := 0; y < p.h; y++ {
+		row := img.Pix[img.PixOffset(r.Min.X, r.Min.Y+y):][:p.w*4]
+		for x := 0; x < p.w; x++ {
+			rr, g, b := blendColor([4]uint8{row[x*4], row[x*4+1], row[x*4+2], row[x*4+3]})
+			p.pix[y*p.w+x] = float32(rgb2y(rr, g, b))
+		}
+	}
+
+	return p
+}
+
+// half returns the plane downscaled by two, averaging 2x2 blocks.
+func (p lumaPlane) half() lumaPlane {
+	h := lumaPlane{w: p.w / 2, h: p.h / 2}
+	h.pix = make([]float32, h.w*h.h)
+
+	for y := 0; y < h.h; y++ {
+		for x := 0; x < h.w; x++ {
+			i := 2*y*p.w + 2*x
+			h.pix[y*h.w+x] = (p.pix[i] + p.pix[i+1] + p.pix[i+p.w] + p.pix[i+p.w+1]) / 4
+		}
+	}
+
+	return h
+}
+
+// distance returns the mean absolute brightness difference of the pixels
+// of p and the pixels of b moved by off over their overlap.
+func (p lumaPlane) distance(b lumaPlane, off image.Point) float64 {
+	x0, x1 := maxInt(0, -off.X), p.w-maxInt(0, off.X)
+	y0, y1 := maxInt(0, -off.Y), p.h-maxInt(0, off.Y)
+	if x0 >= x1 || y0 >= y1 {
+		return math.Inf(1)
+	}
+
+	var sum float64
+	for y := y0; y < y1; y++ {
+		rowA := p.pix[y*p.w+x0 : y*p.w+x1]
+		rowB := b.pix[(y+off.Y)*b.w+x0+off.X:][:len(rowA)]
+
+		var rowSum float32
+		for i, v := range rowA {
+			rowSum += float32(math.Abs(float64(v - rowB[i])))
+		}
+		sum += float64(rowSum)
+	}
+
+	return sum / float64((x1-x0)*(y1-y0))
+}
+
+// bestOffset returns the offset within radius pixels of center and within
+// limit pixels of zero that minimizes the distance of the planes,
+// preferring center on ties.
+func (p lumaPlane) bestOffset(b lumaPlane, center image.Point, radius, limit int) image.Point {
+	best, bestDist := center, p.distance(b, center)
+
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			off := center.Add(image.Pt(dx, dy))
+			if off == center || abs(off.X) > limit || abs(off.Y) > limit {
+				continue
+			}
+
+			if d := p.distance(b, off); d < bestDist {
+				best, bestDist = off, d
+			}
+		}
+	}
+
+	return best
+}
+
+// estimateOffset returns the translation of b against a of up to maxShift
+// pixels in both directions. It searches a pyramid of brightness planes,
+// exhaustively on the coarsest level and refining the offset by a pixel on
+// every finer one, so large images and shifts stay cheap.
+func estimateOffset(a, b *image.NRGBA, maxShift int) image.Point {
+	levels := [][2]lumaPlane{{newLumaPlane(a), newLumaPlane(b)}}
+	for scale := 2; scale <= maxShift; scale *= 2 {
+		top := levels[len(levels)-1]
+		if top[0].w/2 < minLumaSize || top[0].h/2 < minLumaSize {
+			break
+		}
+
+		levels = append(levels, [2]lumaPlane{top[0].half(), top[1].half()})
+	}
+
+	coarsest := len(levels) - 1
+	limit := maxShift >> coarsest
+	off := levels[coarsest][0].bestOffset(levels[coarsest][1], image.Point{}, limit+1, limit+1)
+
+	for i := coarsest - 1; i >= 0; i-- {
+		off = off.Mul(2)
+		off = levels[i][0].bestOffset(levels[i][1], off, 1, maxShift>>i)
+	}
+
+	return clampPoint(off, maxShift)
+}
+
+// shiftNRGBA returns a copy of b moved by -off, so that its pixel x, y is
+// the pixel x+off.X, y+off.Y of b, and the overlap of the moved image with
+// the bounds. Pixels outside of the overlap are taken from a.
+func shiftNRGBA(a, b *image.NRGBA, off image.Point, pool *pixPool) (*image.NRGBA, image.Rectangle) {
+	dst := pool.newNRGBA(b.Rect)
+	overlap := b.Rect.Intersect(b.Rect.Sub(off))
+
+	for y := dst.Rect.Min.Y; y < dst.Rect.Max.Y; y++ {
+		row := dst.Pix[dst.PixOffset(dst.Rect.Min.X, y):][:dst.Rect.Dx()*4]
+		copy(row, a.Pix[a.PixOffset(dst.Rect.Min.X, y):])
+
+		if y >= overlap.Min.Y && y < overlap.Max.Y {
+			i := (overlap.Min.X - dst.Rect.Min.X) * 4
+			copy(row[i:i+overlap.Dx()*4], b.Pix[b.PixOffset(overlap.Min.X+off.X, y+off.Y):])
+		}
+	}
+
+	return dst, overlap
+}
+
+// shiftNRGBA64 is shiftNRGBA for 16-bit images.
+func shiftNRGBA64(a, b *image.NRGBA64, off image.Point) *image.NRGBA64 {
+	dst := image.NewNRGBA64(b.Rect)
+	overlap := b.Rect.Intersect(b.Rect.Sub(off))
+
+	for y := dst.Rect.Min.Y; y < dst.Rect.Max.Y; y++ {
+		row := dst.Pix[dst.PixOffset(dst.Rect.Min.X, y):][:dst.Rect.Dx()*8]
+		copy(row, a.Pix[a.PixOffset(dst.Rect.Min.X, y):])
+
+		if y >= overlap.Min.Y && y < overlap.Max.Y {
+			i := (overlap.Min.X - dst.Rect.Min.X) * 8
+			copy(row[i:i+overlap.Dx()*8], b.Pix[b.PixOffset(overlap.Min.X+off.X, y+off.Y):])
+		}
+	}
+
+	return dst
+}
+
+func clampPoint(p image.Point, n int) image.Point {
+	return image.Pt(clampInt(p.X, -n, n), clampInt(p.Y, -n, n))
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
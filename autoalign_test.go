@@ -0,0 +1,108 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+)
+
+// texturedImage returns an image of random 3x3 blocks, which has a single
+// best match under translation.
+func texturedImage(w, h int, seed int64) *image.NRGBA {
+	rnd := rand.New(rand.NewSource(seed))
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y += 3 {
+		for x := 0; x < w; x += 3 {
+			c := color.NRGBA{R: uint8(rnd.Intn(256)), G: uint8(rnd.Intn(256)), B: uint8(rnd.Intn(256)), A: 255}
+			draw.Draw(img, image.Rect(x, y, x+3, y+3), &image.Uniform{C: c}, image.Point{}, draw.Src)
+		}
+	}
+
+	return img
+}
+
+// movedImage returns img with its content moved by d,
+// the uncovered pixels filled with fresh content.
+func movedImage(img *image.NRGBA, d image.Point) *image.NRGBA {
+	moved := texturedImage(img.Rect.Dx(), img.Rect.Dy(), 2)
+	draw.Draw(moved, img.Rect.Add(d), img, image.Point{}, draw.Src)
+
+	return moved
+}
+
+func TestWithAutoAlign(t *testing.T) {
+	img1 := texturedImage(200, 150, 1)
+	img2 := movedImage(img1, image.Pt(-3, 2))
+
+	result, err := Diff(img1, img2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels == 0 {
+		t.Fatal("Expected the moved image to differ without alignment")
+	}
+
+	output := image.NewNRGBA(img1.Rect)
+	result, err = Diff(img1, img2, output, WithAutoAlign(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Offset != image.Pt(-3, 2) {
+		t.Errorf("Expected offset (-3,2), got - %v", result.Offset)
+	}
+	if result.DiffPixels != 0 {
+		t.Errorf("Expected no difference after alignment, got - %d", result.DiffPixels)
+	}
+	if want := uint64(197 * 148); result.TotalPixels != want {
+		t.Errorf("Expected %d compared pixels in the overlap, got - %d", want, result.TotalPixels)
+	}
+
+	count, err := Compare(img1, img2, WithAutoAlign(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("Expected Compare to agree with Diff, got - %d", count)
+	}
+}
+
+func TestWithAutoAlignHighDepth(t *testing.T) {
+	img1 := texturedImage(64, 64, 1)
+	img2 := movedImage(img1, image.Pt(2, 1))
+
+	a, b := image.NewNRGBA64(img1.Rect), image.NewNRGBA64(img2.Rect)
+	draw.Draw(a, a.Rect, img1, image.Point{}, draw.Src)
+	draw.Draw(b, b.Rect, img2, image.Point{}, draw.Src)
+
+	result, err := Diff(a, b, nil, WithAutoAlign(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Offset != image.Pt(2, 1) || result.DiffPixels != 0 {
+		t.Errorf("Expected offset (2,1) and no difference, got - %v and %d", result.Offset, result.DiffPixels)
+	}
+}
+
+func TestEstimateOffset(t *testing.T) {
+	img := texturedImage(400, 300, 1)
+
+	tests := []struct {
+		moved image.Point
+		max   int
+	}{
+		{image.Pt(0, 0), 8},
+		{image.Pt(1, 0), 1},
+		{image.Pt(-20, 13), 24},
+		{image.Pt(0, -31), 32},
+	}
+
+	for _, tc := range tests {
+		// content moved by some offset is compared at that offset
+		if got := estimateOffset(img, movedImage(img, tc.moved), tc.max); got != tc.moved {
+			t.Errorf("Expected offset %v, got - %v", tc.moved, got)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package pixelmatch
+
+import "image/color"
+
+// WithBackgroundBlend blends the grayscale copy of the first image drawn
+// under the diff towards c instead of white, e.g. black for dark themes
+// or color.Transparent to keep only a faint ghost of the image.
+// Like any background it is drawn only with WithDiffMask(false).
+func WithBackgroundBlend(c color.Color) Option {
+	return func(o *Options) {
+		if c == nil {
+			o.nilColor("background blend")
+			return
+		}
+		nrgba := toNRGBAColor(c)
+		o.backgroundBlend = &nrgba
+		o.noBackground = false
+	}
+}
+
+// WithNoBackground draws unchanged pixels exactly as they are in the first
+// image, or the one selected by WithBaseImage, without turning them gray
+// and blending them.
+// Like any background it is drawn only with WithDiffMask(false).
+func WithNoBackground() Option {
+	return func(o *Options) {
+		o.noBackground = true
+		o.backgroundBlend = nil
+	}
+}
+
+// BaseImage selects the image the diff image shows under the differences.
+type BaseImage int
+
+const (
+	// BaseGray is a grayscale copy of the first image faded by the alpha
+	// of WithAlpha, as in the original pixelmatch.
+	BaseGray BaseImage = iota
+
+	// BaseFirst is the first image in full color faded by the alpha.
+	BaseFirst
+
+	// BaseSecond is the second image, the candidate, in full color faded
+	// by the alpha.
+	BaseSecond
+
+	// BaseNone leaves the similar pixels transparent.
+	BaseNone
+)
+
+// String returns the name of the base image.
+func (b BaseImage) String() string {
+	switch b {
+	case BaseGray:
+		return "gray"
+	case BaseFirst:
+		return "first"
+	case BaseSecond:
+		return "second"
+	case BaseNone:
+		return "none"
+	default:
+		return "unknown"
+	}
+}
+
+// WithBaseImage selects the image drawn under the differences, BaseGray by
+// default. WithNoBackground draws the first or second image without fading
+// it and WithBackgroundBlend fades it towards another color than white.
+// Like any background it is drawn only with WithDiffMask(false).
+func WithBaseImage(b BaseImage) Option {
+	return func(o *Options) {
+		o.baseImage = b
+	}
+}
+
+// backgroundColor returns how a pixel similar in both images is drawn,
+// c1 and c2 being its colors in the first and second image.
+func backgroundColor(c1, c2 [4]uint8, options *Options) color.NRGBA {
+	c := c1
+	switch options.baseImage {
+	case BaseNone:
+		return color.NRGBA{}
+	case BaseSecond:
+		c = c2
+	}
+
+	bg := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	if options.backgroundBlend != nil {
+		bg = *options.backgroundBlend
+	}
+
+	switch {
+	case options.noBackground:
+		return color.NRGBA{R: c[0], G: c[1], B: c[2], A: c[3]}
+	case options.baseImage != BaseGray:
+		return blendOver(c, options.alpha, bg, false)
+	case options.backgroundBlend != nil:
+		return blendOver(c, options.alpha, bg, true)
+	default:
+		return grayColor(c, options.alpha)
+	}
+}
+
+// blendOver draws c, or its gray value, with opacity alpha over the
+// background bg.
+func blendOver(c [4]uint8, alpha float64, bg color.NRGBA, gray bool) color.NRGBA {
+	var (
+		fg = [3]float64{float64(c[0]), float64(c[1]), float64(c[2])}
+		w  = alpha * float64(c[3]) / 255
+		// background opacity and premultiplied channels
+		bgA = float64(bg.A) / 255
+		a   = bgA + (1-bgA)*w
+	)
+	if a == 0 {
+		return color.NRGBA{}
+	}
+	if gray {
+		y := rgb2y(fg[0], fg[1], fg[2])
+		fg = [3]float64{y, y, y}
+	}
+
+	channel := func(v uint8, f float64) uint8 {
+		return clampUint8((float64(v)*bgA*(1-w) + f*w) / a)
+	}
+
+	return color.NRGBA{
+		R: channel(bg.R, fg[0]),
+		G: channel(bg.G, fg[1]),
+		B: channel(bg.B, fg[2]),
+		A: clampUint8(a * 255),
+	}
+}
@@ -0,0 +1,64 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestBackground(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{R: 10, G: 200, B: 30, A: 255})
+
+	for _, tc := range []struct {
+		name string
+		opt  Option
+		want [2]color.NRGBA
+	}{
+		{"white", nil, [2]color.NRGBA{{255, 255, 255, 255}, {242, 242, 242, 255}}},
+		{"black", WithBackgroundBlend(color.Black), [2]color.NRGBA{{26, 26, 26, 255}, {12, 12, 12, 255}}},
+		{"transparent", WithBackgroundBlend(color.Transparent), [2]color.NRGBA{{255, 255, 255, 26}, {124, 124, 124, 26}}},
+		{"original", WithNoBackground(), [2]color.NRGBA{{255, 255, 255, 255}, {10, 200, 30, 255}}},
+	} {
+		output, _, err := DiffNew(img, img, WithDiffMask(false), tc.opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for x, want := range tc.want {
+			if got := output.NRGBAAt(x, 0); got != want {
+				t.Errorf("%s: pixel %d: expected %v, got - %v", tc.name, x, want, got)
+			}
+		}
+	}
+}
+
+func TestWithBaseImage(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img1.SetNRGBA(0, 0, color.NRGBA{R: 10, G: 200, B: 30, A: 255})
+	img2 := image.NewNRGBA(img1.Rect)
+	img2.SetNRGBA(0, 0, color.NRGBA{R: 12, G: 200, B: 30, A: 255})
+
+	for _, tc := range []struct {
+		opts []Option
+		want color.NRGBA
+	}{
+		{[]Option{WithBaseImage(BaseGray)}, color.NRGBA{242, 242, 242, 255}},
+		{[]Option{WithBaseImage(BaseFirst)}, color.NRGBA{230, 250, 232, 255}},
+		{[]Option{WithBaseImage(BaseSecond)}, color.NRGBA{231, 250, 232, 255}},
+		{[]Option{WithBaseImage(BaseSecond), WithNoBackground()}, color.NRGBA{12, 200, 30, 255}},
+		{[]Option{WithBaseImage(BaseSecond), WithBackgroundBlend(color.Black)}, color.NRGBA{1, 20, 3, 255}},
+		{[]Option{WithBaseImage(BaseNone)}, color.NRGBA{}},
+	} {
+		output, result, err := DiffNew(img1, img2, append(tc.opts, WithDiffMask(false))...)
+		if err != nil || result.DiffPixels != 0 {
+			t.Fatalf("Expected the pixels to be similar, got - %d %v", result.DiffPixels, err)
+		}
+
+		name := newOptions(tc.opts...).baseImage.String()
+		if got := output.NRGBAAt(0, 0); got != tc.want {
+			t.Errorf("%s %d: expected %v, got - %v", name, len(tc.opts), tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+// Package baseline compares directories of screenshots against their
+// baselines, the core of a visual regression test runner.
+package baseline
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+// Status is the outcome of comparing a single file.
+type Status int
+
+const (
+	// StatusPassed means the candidate matches its baseline, within the
+	// failure threshold if there is one (see pixelmatch.WithFailureThreshold).
+	StatusPassed Status = iota
+
+	// StatusFailed means the candidate differs from its baseline.
+	StatusFailed
+
+	// StatusMissing means the baseline has no candidate.
+	StatusMissing
+
+	// StatusNew means the candidate has no baseline.
+	StatusNew
+
+	// StatusError means the pair could not be compared, see Entry.Err.
+	StatusError
+)
+
+// String returns the name of the status.
+func (s Status) String() string {
+	switch s {
+	case StatusPassed:
+		return "passed"
+	case StatusFailed:
+		return "failed"
+	case StatusMissing:
+		return "missing"
+	case StatusNew:
+		return "new"
+	case StatusError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Entry describes a single file of the compared directories.
+type Entry struct {
+	// path of the file relative to the compared directories
+	Name string
+
+	Status Status
+
+	// result of the comparison; zero unless both files exist
+	Result pixelmatch.DiffResult
+
+	// name of the diff image written to the sink for a failed comparison
+	DiffName string
+
+	// path of the diff image written by CompareDirs or to a pixelmatch.DirSink
+	DiffPath string
+
+	// why the comparison failed with StatusError
+	Err error
+}
+
+// Report is the outcome of CompareDirs, entries are sorted by name.
+type Report struct {
+	Entries []Entry
+}
+
+// Passed reports whether every baseline has a matching candidate
+// and there are no new candidates.
+func (r *Report) Passed() bool {
+	for _, e := range r.Entries {
+		if e.Status != StatusPassed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Count returns the number of entries with the given status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, e := range r.Entries {
+		if e.Status == status {
+			n++
+		}
+	}
+
+	return n
+}
+
+// WriteReport writes the report as JSON to the sink under name, e.g. for
+// a dashboard of the CI runs.
+func (r *Report) WriteReport(sink pixelmatch.Sink, name string) error {
+	type entry struct {
+		Name   string                 `json:"name"`
+		Status string                 `json:"status"`
+		Result *pixelmatch.DiffResult `json:"result,omitempty"`
+		Diff   string                 `json:"diff,omitempty"`
+		Error  string                 `json:"error,omitempty"`
+	}
+
+	report := struct {
+		Passed  bool    `json:"passed"`
+		Entries []entry `json:"entries"`
+	}{Passed: r.Passed(), Entries: make([]entry, 0, len(r.Entries))}
+
+	for i, e := range r.Entries {
+		out := entry{Name: e.Name, Status: e.Status.String(), Diff: e.DiffName}
+		if e.Status == StatusPassed || e.Status == StatusFailed {
+			out.Result = &r.Entries[i].Result
+		}
+		if e.Err != nil {
+			out.Error = e.Err.Error()
+		}
+		report.Entries = append(report.Entries, out)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return sink.WriteReport(name, data)
+}
+
+// CompareDirs matches the images of baselineDir and candidateDir by their
+// relative paths, compares every pair with the options and writes a PNG diff
+// image of every failed pair to reportDir under the same relative path.
+// Per-file problems are reported in the entries; the error is returned only
+// when the directories themselves cannot be read or written.
+func CompareDirs(baselineDir, candidateDir, reportDir string, opts ...pixelmatch.Option) (*Report, error) {
+	return CompareDirsTo(baselineDir, candidateDir, pixelmatch.NewDirSink(reportDir), opts...)
+}
+
+// CompareDirsTo is like CompareDirs but writes the diff images to the sink,
+// e.g. to a bucket; the error is also returned when the sink fails.
+func CompareDirsTo(baselineDir, candidateDir string, sink pixelmatch.Sink, opts ...pixelmatch.Option) (*Report, error) {
+	return CompareDirsWith(baselineDir, candidateDir, sink, func(string) []pixelmatch.Option {
+		return opts
+	})
+}
+
+// CompareDirsWith is like CompareDirsTo but compares every pair with the
+// options returned by opts for its relative path, e.g. to apply different
+// thresholds and ignore regions to different screenshots.
+func CompareDirsWith(baselineDir, candidateDir string, sink pixelmatch.Sink, opts func(name string) []pixelmatch.Option) (*Report, error) {
+	return CompareDirsParallel(baselineDir, candidateDir, sink, 1, opts)
+}
+
+// CompareDirsParallel is like CompareDirsWith but compares up to jobs pairs
+// at once, each on a single goroutine unless its options say otherwise;
+// jobs <= 0 means runtime.NumCPU(). With jobs == 1 the pairs are compared
+// one by one on the calling goroutine. opts and the sink are called
+// concurrently.
+func CompareDirsParallel(baselineDir, candidateDir string, sink pixelmatch.Sink, jobs int, opts func(name string) []pixelmatch.Option) (*Report, error) {
+	baselines, err := listImages(baselineDir)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := listImages(candidateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	var pairs []string
+	for name := range baselines {
+		if candidates[name] {
+			pairs = append(pairs, name)
+		} else {
+			report.Entries = append(report.Entries, Entry{Name: name, Status: StatusMissing})
+		}
+	}
+
+	for name := range candidates {
+		if !baselines[name] {
+			report.Entries = append(report.Entries, Entry{Name: name, Status: StatusNew})
+		}
+	}
+
+	entries, err := comparePairs(pairs, baselineDir, candidateDir, sink, jobs, opts)
+	if err != nil {
+		return nil, err
+	}
+	report.Entries = append(report.Entries, entries...)
+
+	sort.Slice(report.Entries, func(i, j int) bool {
+		return report.Entries[i].Name < report.Entries[j].Name
+	})
+
+	return report, nil
+}
+
+// comparePairs compares the files of the given names on up to jobs
+// goroutines; the error of the first diff image that cannot be written
+// is returned.
+func comparePairs(names []string, baselineDir, candidateDir string, sink pixelmatch.Sink, jobs int, opts func(name string) []pixelmatch.Option) ([]Entry, error) {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	entries := make([]Entry, len(names))
+
+	if jobs == 1 {
+		for i, name := range names {
+			entry, err := compareFile(name, baselineDir, candidateDir, sink, opts(name))
+			if err != nil {
+				return nil, err
+			}
+			entries[i] = entry
+		}
+
+		return entries, nil
+	}
+
+	var (
+		indexes  = make(chan int)
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				name := names[i]
+				// the pairs already run in parallel, not their bands
+				fileOpts := append([]pixelmatch.Option{pixelmatch.WithParallelism(1)}, opts(name)...)
+
+				entry, err := compareFile(name, baselineDir, candidateDir, sink, fileOpts)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+				entries[i] = entry
+			}
+		}()
+	}
+
+	for i := range names {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return entries, nil
+}
+
+// compareFile compares a single pair; the error is returned only when
+// the diff image cannot be written.
+func compareFile(name, baselineDir, candidateDir string, sink pixelmatch.Sink, opts []pixelmatch.Option) (Entry, error) {
+	output, result, err := diffFiles(filepath.Join(baselineDir, name), filepath.Join(candidateDir, name), opts)
+
+	entry := Entry{Name: name, Result: result}
+	switch {
+	case err != nil && !errors.Is(err, pixelmatch.ErrDiffBudgetExceeded):
+		entry.Status = StatusError
+		entry.Err = err
+
+	case result.Passed():
+		entry.Status = StatusPassed
+
+	default:
+		entry.Status = StatusFailed
+		entry.DiffName = strings.TrimSuffix(name, path.Ext(name)) + ".png"
+		if dir, ok := sink.(*pixelmatch.DirSink); ok {
+			entry.DiffPath = dir.Path(entry.DiffName)
+		}
+
+		if err := sink.WriteDiffImage(entry.DiffName, output); err != nil {
+			return entry, err
+		}
+	}
+
+	return entry, nil
+}
+
+func diffFiles(pathA, pathB string, opts []pixelmatch.Option) (*image.NRGBA, pixelmatch.DiffResult, error) {
+	imgA, err := pixelmatch.DecodeFile(pathA, opts...)
+	if err != nil {
+		return nil, pixelmatch.DiffResult{}, err
+	}
+
+	imgB, err := pixelmatch.DecodeFile(pathB, opts...)
+	if err != nil {
+		return nil, pixelmatch.DiffResult{}, err
+	}
+
+	return pixelmatch.DiffNew(imgA, imgB, opts...)
+}
+
+// listImages returns the relative paths of all images under dir.
+func listImages(dir string) (map[string]bool, error) {
+	names := make(map[string]bool)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !isImage(path) {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		names[filepath.ToSlash(rel)] = true
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+func isImage(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,213 @@
+package baseline
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+func writeTestImage(t *testing.T, path string, changed bool) {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+	if changed {
+		img.SetNRGBA(3, 3, color.NRGBA{R: 255, A: 255})
+	}
+
+	if err := pixelmatch.NewDirSink(filepath.Dir(path)).WriteDiffImage(filepath.Base(path), img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompareDirs(t *testing.T) {
+	var (
+		root      = t.TempDir()
+		baseline  = filepath.Join(root, "baseline")
+		candidate = filepath.Join(root, "candidate")
+		report    = filepath.Join(root, "report")
+	)
+
+	writeTestImage(t, filepath.Join(baseline, "home.png"), false)
+	writeTestImage(t, filepath.Join(candidate, "home.png"), false)
+	writeTestImage(t, filepath.Join(baseline, "pages", "login.png"), false)
+	writeTestImage(t, filepath.Join(candidate, "pages", "login.png"), true)
+	writeTestImage(t, filepath.Join(baseline, "removed.png"), false)
+	writeTestImage(t, filepath.Join(candidate, "added.png"), false)
+	if err := os.WriteFile(filepath.Join(baseline, "broken.png"), []byte("not a png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeTestImage(t, filepath.Join(candidate, "broken.png"), false)
+	if err := os.WriteFile(filepath.Join(baseline, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := CompareDirs(baseline, candidate, report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name   string
+		status Status
+	}{
+		{"added.png", StatusNew},
+		{"broken.png", StatusError},
+		{"home.png", StatusPassed},
+		{"pages/login.png", StatusFailed},
+		{"removed.png", StatusMissing},
+	}
+	if len(r.Entries) != len(want) {
+		t.Fatalf("Expected %d entries, got - %+v", len(want), r.Entries)
+	}
+	for i, w := range want {
+		if e := r.Entries[i]; e.Name != w.name || e.Status != w.status {
+			t.Errorf("Expected %s to be %v, got - %s %v (%v)", w.name, w.status, e.Name, e.Status, e.Err)
+		}
+	}
+
+	login := r.Entries[3]
+	if login.Result.DiffPixels != 1 {
+		t.Errorf("Expected 1 different pixel, got - %d", login.Result.DiffPixels)
+	}
+	f, err := os.Open(filepath.Join(report, "pages", "login.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := png.Decode(f); err != nil {
+		t.Errorf("Expected a diff image at %s, got - %v", login.DiffPath, err)
+	}
+	if _, err := os.Stat(filepath.Join(report, "home.png")); !os.IsNotExist(err) {
+		t.Error("Expected no diff image for a passed comparison")
+	}
+
+	if r.Passed() || r.Count(StatusFailed) != 1 {
+		t.Errorf("Expected a failed report with 1 failure, got - %+v", r)
+	}
+}
+
+func TestCompareDirsTo(t *testing.T) {
+	var (
+		root      = t.TempDir()
+		baseline  = filepath.Join(root, "baseline")
+		candidate = filepath.Join(root, "candidate")
+		sink      = pixelmatch.NewMemorySink()
+	)
+
+	writeTestImage(t, filepath.Join(baseline, "home.png"), false)
+	writeTestImage(t, filepath.Join(candidate, "home.png"), true)
+	writeTestImage(t, filepath.Join(baseline, "removed.png"), false)
+
+	r, err := CompareDirsTo(baseline, candidate, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if home := r.Entries[0]; home.DiffName != "home.png" || home.DiffPath != "" || sink.DiffImage("home.png") == nil {
+		t.Errorf("Expected the diff image in the sink, got - %+v", home)
+	}
+
+	if err := r.WriteReport(sink, "report.json"); err != nil {
+		t.Fatal(err)
+	}
+
+	var report struct {
+		Passed  bool `json:"passed"`
+		Entries []struct {
+			Name   string          `json:"name"`
+			Status string          `json:"status"`
+			Result json.RawMessage `json:"result"`
+			Diff   string          `json:"diff"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(sink.Report("report.json"), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed || len(report.Entries) != 2 {
+		t.Fatalf("Expected a failed report of 2 entries, got - %+v", report)
+	}
+	if home := report.Entries[0]; home.Status != "failed" || home.Diff != "home.png" || home.Result == nil {
+		t.Errorf("Expected the failed entry with its result, got - %+v", home)
+	}
+	if removed := report.Entries[1]; removed.Status != "missing" || removed.Result != nil {
+		t.Errorf("Expected the missing entry without a result, got - %+v", removed)
+	}
+}
+
+func TestCompareDirsWith(t *testing.T) {
+	var (
+		root      = t.TempDir()
+		baseline  = filepath.Join(root, "baseline")
+		candidate = filepath.Join(root, "candidate")
+	)
+
+	for _, name := range []string{"strict.png", "lenient.png"} {
+		writeTestImage(t, filepath.Join(baseline, name), false)
+		writeTestImage(t, filepath.Join(candidate, name), true)
+	}
+
+	r, err := CompareDirsWith(baseline, candidate, pixelmatch.NewMemorySink(), func(name string) []pixelmatch.Option {
+		if name == "lenient.png" {
+			return []pixelmatch.Option{pixelmatch.WithFailureThreshold(1)}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Entries[0].Name != "lenient.png" || r.Entries[0].Status != StatusPassed || r.Entries[1].Status != StatusFailed {
+		t.Errorf("Expected only the strict pair to fail, got - %+v", r.Entries)
+	}
+}
+
+func TestCompareDirsParallel(t *testing.T) {
+	var (
+		root      = t.TempDir()
+		baseline  = filepath.Join(root, "baseline")
+		candidate = filepath.Join(root, "candidate")
+	)
+
+	for i := 0; i < 12; i++ {
+		name := filepath.Join(fmt.Sprintf("dir%d", i%3), fmt.Sprintf("page%d.png", i))
+		writeTestImage(t, filepath.Join(baseline, name), false)
+		writeTestImage(t, filepath.Join(candidate, name), i%2 == 0)
+	}
+	writeTestImage(t, filepath.Join(baseline, "removed.png"), false)
+
+	opts := func(string) []pixelmatch.Option { return nil }
+	want, err := CompareDirsWith(baseline, candidate, pixelmatch.NewMemorySink(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sink := pixelmatch.NewMemorySink()
+	got, err := CompareDirsParallel(baseline, candidate, sink, 4, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Entries) != len(want.Entries) {
+		t.Fatalf("Expected %d entries, got - %d", len(want.Entries), len(got.Entries))
+	}
+	for i, e := range got.Entries {
+		if w := want.Entries[i]; e.Name != w.Name || e.Status != w.Status || e.Result.DiffPixels != w.Result.DiffPixels {
+			t.Errorf("Expected %+v, got - %+v", w, e)
+		}
+		if e.Status == StatusFailed && sink.DiffImage(e.DiffName) == nil {
+			t.Errorf("%s: expected the diff image in the sink", e.Name)
+		}
+	}
+	if n := got.Count(StatusFailed); n != 6 {
+		t.Errorf("Expected 6 failed pairs, got - %d", n)
+	}
+}
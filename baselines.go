@@ -0,0 +1,76 @@
+package pixelmatch
+
+import (
+	"context"
+	"errors"
+	"image"
+)
+
+// ErrNoBaselines is returned by DiffAgainstBaselines without baselines.
+var ErrNoBaselines = errors.New("no baselines")
+
+// DiffAgainstBaselines compares the candidate with every baseline, e.g. the
+// screenshots of a page on every OS, and returns the index of the baseline
+// with the fewest different pixels along with its result. See
+// DiffAgainstBaselinesContext.
+func DiffAgainstBaselines(candidate image.Image, baselines []image.Image, opts ...Option) (int, DiffResult, error) {
+	return DiffAgainstBaselinesContext(context.Background(), candidate, baselines, opts...)
+}
+
+// DiffAgainstBaselinesContext compares the candidate with the baselines in
+// order as the second image of Diff, converting it only once, and returns
+// the index of the baseline with the fewest different pixels, the first one
+// of equally close baselines, along with its result. It stops at the first
+// identical baseline, and the comparison of every following baseline stops
+// as soon as it differs more than the closest one so far. Baselines that
+// cannot be compared, e.g. of another size, are skipped; when none can, it
+// returns -1 and the error of the first one, with the partial result for
+// ErrDiffBudgetExceeded. It returns ctx.Err() as soon as ctx is done.
+func DiffAgainstBaselinesContext(ctx context.Context, candidate image.Image, baselines []image.Image, opts ...Option) (int, DiffResult, error) {
+	if len(baselines) == 0 {
+		return -1, DiffResult{}, ErrNoBaselines
+	}
+
+	options := newOptions(opts...)
+	if !isEmptyImg(candidate) && !isHighDepth(candidate) && options.alphaMode != AlphaPremultiplied {
+		candidate = toNRGBA(options.alphaImage(candidate))
+	}
+
+	var (
+		best       = -1
+		bestResult DiffResult
+		firstErr   error
+		errResult  DiffResult
+	)
+	for i, baseline := range baselines {
+		o := options
+		if best >= 0 && (!o.failFast || bestResult.DiffPixels < o.failFastMax) {
+			// a baseline differing in more pixels cannot be closer
+			o.failFast, o.failFastMax = true, bestResult.DiffPixels
+		}
+
+		_, result, err := diff(ctx, baseline, candidate, nil, false, o)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return best, bestResult, ctxErr
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr, errResult = err, result
+			}
+			continue
+		}
+
+		if best < 0 || result.DiffPixels < bestResult.DiffPixels {
+			best, bestResult = i, result
+		}
+		if result.DiffPixels == 0 {
+			break
+		}
+	}
+
+	if best < 0 {
+		return -1, errResult, firstErr
+	}
+
+	return best, bestResult, nil
+}
@@ -0,0 +1,65 @@
+package pixelmatch
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDiffAgainstBaselines(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	candidate := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for i := range candidate.Pix {
+		candidate.Pix[i] = 255
+	}
+
+	withBlock := func(size int) *image.NRGBA {
+		img := solidImage(20, 20, white)
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				img.SetNRGBA(x, y, color.NRGBA{A: 255})
+			}
+		}
+		return img
+	}
+
+	var compared []Observation
+	observer := WithObserver(ObserverFunc(func(o Observation) {
+		compared = append(compared, o)
+	}))
+
+	baselines := []image.Image{withBlock(5), solidImage(10, 10, white), withBlock(2), withBlock(3), withBlock(2), withBlock(0), withBlock(1)}
+	i, result, err := DiffAgainstBaselines(candidate, baselines, observer)
+	if err != nil || i != 5 || result.DiffPixels != 0 {
+		t.Fatalf("Expected the identical baseline 5, got - %d %d %v", i, result.DiffPixels, err)
+	}
+	if len(compared) != 6 {
+		t.Errorf("Expected to stop at the identical baseline, got - %d comparisons", len(compared))
+	}
+	if !errors.Is(compared[1].Err, ErrImageSize) || !errors.Is(compared[3].Err, ErrDiffBudgetExceeded) {
+		t.Errorf("Expected the other size to fail and the farther baseline to stop early, got - %v, %v", compared[1].Err, compared[3].Err)
+	}
+
+	i, result, err = DiffAgainstBaselines(candidate, baselines[:5])
+	if err != nil || i != 2 || result.DiffPixels != 4 {
+		t.Errorf("Expected the first closest baseline 2, got - %d %d %v", i, result.DiffPixels, err)
+	}
+
+	if i, _, err := DiffAgainstBaselines(candidate, baselines[1:2]); i != -1 || !errors.Is(err, ErrImageSize) {
+		t.Errorf("Expected ErrImageSize, got - %d %v", i, err)
+	}
+	if _, _, err := DiffAgainstBaselines(candidate, nil); !errors.Is(err, ErrNoBaselines) {
+		t.Errorf("Expected ErrNoBaselines, got - %v", err)
+	}
+	if i, result, err := DiffAgainstBaselines(candidate, baselines[:1], WithFailFast(10)); i != -1 || result.DiffPixels <= 10 || !errors.Is(err, ErrDiffBudgetExceeded) {
+		t.Errorf("Expected ErrDiffBudgetExceeded with the partial result, got - %d %d %v", i, result.DiffPixels, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := DiffAgainstBaselinesContext(ctx, candidate, baselines); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got - %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package pixelmatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+)
+
+// ImagePair is a pair of images compared by DiffBatch.
+type ImagePair struct {
+	// name identifying the pair in errors, e.g. the file name
+	Name string
+
+	A, B image.Image
+
+	// diff image drawn by the comparison; may be nil
+	Output *image.NRGBA
+
+	// label of a CalibrateThreshold sample: the images are expected
+	// to differ; ignored by DiffBatch
+	Different bool
+}
+
+// BatchResult is the outcome of comparing a single ImagePair.
+type BatchResult struct {
+	Name   string
+	Result DiffResult
+	Err    error
+}
+
+// DiffBatch compares every pair of images and returns their results
+// in the order of pairs. See DiffBatchContext.
+func DiffBatch(pairs []ImagePair, opts ...Option) []BatchResult {
+	return DiffBatchContext(context.Background(), pairs, opts...)
+}
+
+// DiffBatchContext compares every pair of images with the same options on
+// a bounded pool of goroutines and returns their results in the order of
+// pairs. WithParallelism sets the number of pairs compared at once, every
+// pair is compared by a single goroutine; WithSequential compares them in
+// order on the calling goroutine. WithSink stores the diff images of pairs
+// that do not pass. Pairs not compared by the time ctx is done fail with
+// ctx.Err(); use BatchErr to collect the failures.
+func DiffBatchContext(ctx context.Context, pairs []ImagePair, opts ...Option) []BatchResult {
+	options := newOptions(opts...)
+	workers := options.workers()
+	options.parallelism = 1
+
+	var (
+		results = make([]BatchResult, len(pairs))
+		indexes = make(chan int)
+		wg      sync.WaitGroup
+	)
+
+	if workers == 1 {
+		for i, pair := range pairs {
+			results[i].Name = pair.Name
+			if results[i].Err = ctx.Err(); results[i].Err == nil {
+				results[i].Result, results[i].Err = diffPair(ctx, i, pair, options)
+			}
+		}
+
+		return results
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				pair := pairs[i]
+				results[i].Result, results[i].Err = diffPair(ctx, i, pair, options)
+			}
+		}()
+	}
+
+feed:
+	for i := range pairs {
+		results[i].Name = pairs[i].Name
+
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			for ; i < len(pairs); i++ {
+				results[i].Name = pairs[i].Name
+				results[i].Err = ctx.Err()
+			}
+			break feed
+		}
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	return results
+}
+
+// diffPair compares the i-th pair of a batch and writes its diff image
+// to the sink of the options unless the pair passes.
+func diffPair(ctx context.Context, i int, pair ImagePair, options Options) (DiffResult, error) {
+	if options.sink == nil {
+		_, result, err := diff(ctx, pair.A, pair.B, pair.Output, false, options)
+		return result, err
+	}
+
+	output, result, err := diff(ctx, pair.A, pair.B, pair.Output, pair.Output == nil, options)
+	if err != nil && !errors.Is(err, ErrDiffBudgetExceeded) || result.Passed() {
+		return result, err
+	}
+
+	if werr := options.sink.WriteDiffImage(diffImageName(pair.Name, i), output); werr != nil {
+		return result, fmt.Errorf("write diff image: %w", werr)
+	}
+
+	return result, err
+}
+
+// BatchError lists the failed comparisons of a batch.
+type BatchError struct {
+	Failed []BatchResult
+}
+
+func (e *BatchError) Error() string {
+	first := e.Failed[0]
+	if len(e.Failed) == 1 {
+		return fmt.Sprintf("comparison %q failed: %v", first.Name, first.Err)
+	}
+
+	return fmt.Sprintf("%d comparisons failed, first %q: %v", len(e.Failed), first.Name, first.Err)
+}
+
+// Unwrap returns the error of the first failed comparison.
+func (e *BatchError) Unwrap() error {
+	return e.Failed[0].Err
+}
+
+// BatchErr returns a *BatchError with the failed comparisons of results
+// or nil if all of them succeeded.
+func BatchErr(results []BatchResult) error {
+	var failed []BatchResult
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return &BatchError{Failed: failed}
+}
@@ -0,0 +1,62 @@
+package pixelmatch
+
+import (
+	"context"
+	"errors"
+	"image"
+	"testing"
+)
+
+func TestDiffBatch(t *testing.T) {
+	img1 := decodeTestImage(t, "./testdata/img1.png")
+	img2 := decodeTestImage(t, "./testdata/img2.png")
+
+	want, err := Diff(img1, img2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pairs := []ImagePair{
+		{Name: "changed", A: img1, B: img2, Output: image.NewNRGBA(img1.Bounds())},
+		{Name: "same", A: img1, B: img1},
+		{Name: "empty", A: img1, B: image.NewNRGBA(image.Rectangle{})},
+		{Name: "reversed", A: img2, B: img1},
+	}
+
+	results := DiffBatch(pairs, WithParallelism(2))
+	if len(results) != len(pairs) {
+		t.Fatalf("Expected %d results, got - %d", len(pairs), len(results))
+	}
+	for i, r := range results {
+		if r.Name != pairs[i].Name {
+			t.Errorf("Expected result %d to be %q, got - %q", i, pairs[i].Name, r.Name)
+		}
+	}
+	if results[0].Err != nil || results[0].Result.DiffPixels != want.DiffPixels {
+		t.Errorf("Expected %d different pixels, got - %d (%v)", want.DiffPixels, results[0].Result.DiffPixels, results[0].Err)
+	}
+	if results[1].Err != nil || results[1].Result.DiffPixels != 0 {
+		t.Errorf("Expected equal images, got - %+v", results[1])
+	}
+
+	err = BatchErr(results)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Failed) != 1 || batchErr.Failed[0].Name != "empty" {
+		t.Fatalf("Expected the empty pair to fail, got - %v", err)
+	}
+	if !errors.Is(err, ErrEmptyImage) {
+		t.Errorf("Expected ErrEmptyImage, got - %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, r := range DiffBatchContext(ctx, pairs) {
+		if !errors.Is(r.Err, context.Canceled) && !errors.Is(r.Err, ErrEmptyImage) {
+			t.Errorf("Expected %q to be canceled, got - %v", r.Name, r.Err)
+		}
+	}
+
+	if err := BatchErr(DiffBatch(pairs[:2])); err != nil {
+		t.Errorf("Expected no failures, got - %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package pixelmatch
+
+import (
+	"context"
+	"encoding/binary"
+	"image"
+	"math"
+)
+
+// WithBlur blurs both images with a Gaussian of the given standard deviation
+// in pixels before comparing them, which suppresses sub-pixel rendering noise
+// of fonts and gradients; sigma <= 0 disables it. The diff image is drawn
+// over the blurred first image.
+func WithBlur(sigma float64) Option {
+	return func(o *Options) {
+		o.blur = sigma
+	}
+}
+
+// gaussianKernel returns the normalized weights of a Gaussian of the given
+// sigma from -radius to radius, where the radius is ceil(3*sigma).
+func gaussianKernel(sigma float64) []float32 {
+	radius := int(math.Ceil(3 * sigma))
+	weights := make([]float64, 2*radius+1)
+
+	var sum float64
+	for i := range weights {
+		d := float64(i - radius)
+		weights[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += weights[i]
+	}
+
+	kernel := make([]float32, len(weights))
+	for i, w := range weights {
+		kernel[i] = float32(w / sum)
+	}
+
+	return kernel
+}
+
+// blurRows convolves the rows of r with the kernel horizontally and then
+// vertically, repeating the edge pixels. load fills row with the
+// premultiplied channels of row y scaled to 0..1, store gets them blurred.
+func blurRows(r image.Rectangle, kernel []float32, workers int, load, store func(y int, row []float32)) {
+	var (
+		w, h   = r.Dx(), r.Dy()
+		radius = len(kernel) / 2
+		rowLen = w * 4
+		tmp    = make([]float32, rowLen*h)
+		ctx    = context.Background()
+	)
+
+	runBands(ctx, r, workers, func(band image.Rectangle) {
+		src := make([]float32, rowLen)
+		for y := band.Min.Y; y < band.Max.Y; y++ {
+			load(y, src)
+
+			dst := tmp[(y-r.Min.Y)*rowLen:][:rowLen]
+			for x := 0; x < w; x++ {
+				var sum [4]float32
+				for k, weight := range kernel {
+					p := src[clampInt(x+k-radius, 0, w-1)*4:][:4]
+					sum[0] += weight * p[0]
+					sum[1] += weight * p[1]
+					sum[2] += weight * p[2]
+					sum[3] += weight * p[3]
+				}
+				copy(dst[x*4:], sum[:])
+			}
+		}
+	})
+
+	runBands(ctx, r, workers, func(band image.Rectangle) {
+		row := make([]float32, rowLen)
+		for y := band.Min.Y; y < band.Max.Y; y++ {
+			for i := range row {
+				row[i] = 0
+			}
+
+			for k, weight := range kernel {
+				src := tmp[clampInt(y-r.Min.Y+k-radius, 0, h-1)*rowLen:][:rowLen]
+				for i, v := range src {
+					row[i] += weight * v
+				}
+			}
+
+			store(y, row)
+		}
+	})
+}
+
+// blurNRGBA returns a blurred copy of img taken from the pool.
+func blurNRGBA(img *image.NRGBA, options *Options) *image.NRGBA {
+	dst := options.pool.newNRGBA(img.Rect)
+	blurRows(img.Rect, gaussianKernel(options.blur), options.workers(), loadNRGBA(img), storeNRGBA(dst))
+
+	return dst
+}
+
+// blurNRGBA64 is blurNRGBA for 16-bit images.
+func blurNRGBA64(img *image.NRGBA64, options *Options) *image.NRGBA64 {
+	dst := image.NewNRGBA64(img.Rect)
+	blurRows(img.Rect, gaussianKernel(options.blur), options.workers(), loadNRGBA64(img), storeNRGBA64(dst))
+
+	return dst
+}
+
+// loadNRGBA returns a function filling row with the premultiplied channels
+// of row y of img scaled to 0..1, as blurRows and scaleRows expect.
+func loadNRGBA(img *image.NRGBA) func(y int, row []float32) {
+	return func(y int, row []float32) {
+		pix := img.Pix[img.PixOffset(img.Rect.Min.X, y):][:len(row)]
+		for k := 0; k < len(row); k += 4 {
+			a := float32(pix[k+3]) / 255
+			row[k] = float32(pix[k]) / 255 * a
+			row[k+1] = float32(pix[k+1]) / 255 * a
+			row[k+2] = float32(pix[k+2]) / 255 * a
+			row[k+3] = a
+		}
+	}
+}
+
+// storeNRGBA returns a function writing a row filled like by loadNRGBA
+// into row y of img.
+func storeNRGBA(img *image.NRGBA) func(y int, row []float32) {
+	return func(y int, row []float32) {
+		pix := img.Pix[img.PixOffset(img.Rect.Min.X, y):][:len(row)]
+		for k := 0; k < len(row); k += 4 {
+			a := row[k+3]
+			if a <= 0 {
+				pix[k], pix[k+1], pix[k+2], pix[k+3] = 0, 0, 0, 0
+				continue
+			}
+
+			pix[k] = clampUint8(float64(row[k] / a * 255))
+			pix[k+1] = clampUint8(float64(row[k+1] / a * 255))
+			pix[k+2] = clampUint8(float64(row[k+2] / a * 255))
+			pix[k+3] = clampUint8(float64(a * 255))
+		}
+	}
+}
+
+// loadNRGBA64 is loadNRGBA for 16-bit images.
+func loadNRGBA64(img *image.NRGBA64) func(y int, row []float32) {
+	return func(y int, row []float32) {
+		pix := img.Pix[img.PixOffset(img.Rect.Min.X, y):][:2*len(row)]
+		for k := 0; k < len(row); k += 4 {
+			p := pix[2*k:]
+			a := float32(binary.BigEndian.Uint16(p[6:])) / 0xffff
+			row[k] = float32(binary.BigEndian.Uint16(p)) / 0xffff * a
+			row[k+1] = float32(binary.BigEndian.Uint16(p[2:])) / 0xffff * a
+			row[k+2] = float32(binary.BigEndian.Uint16(p[4:])) / 0xffff * a
+			row[k+3] = a
+		}
+	}
+}
+
+// storeNRGBA64 is storeNRGBA for 16-bit images.
+func storeNRGBA64(img *image.NRGBA64) func(y int, row []float32) {
+	return func(y int, row []float32) {
+		pix := img.Pix[img.PixOffset(img.Rect.Min.X, y):][:2*len(row)]
+		for k := 0; k < len(row); k += 4 {
+			p := pix[2*k:]
+			a := row[k+3]
+			if a <= 0 {
+				for i := range p[:8] {
+					p[i] = 0
+				}
+				continue
+			}
+
+			binary.BigEndian.PutUint16(p, clampUint16(row[k]/a))
+			binary.BigEndian.PutUint16(p[2:], clampUint16(row[k+1]/a))
+			binary.BigEndian.PutUint16(p[4:], clampUint16(row[k+2]/a))
+			binary.BigEndian.PutUint16(p[6:], clampUint16(a))
+		}
+	}
+}
+
+// clampUint16 converts a channel value from 0..1 to the nearest 16-bit one.
+func clampUint16(v float32) uint16 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 0xffff
+	default:
+		return uint16(v*0xffff + 0.5)
+	}
+}
+
+func clampInt(v, lo, hi int) int {
+	switch {
+	case v < lo:
+		return lo
+	case v > hi:
+		return hi
+	default:
+		return v
+	}
+}
@@ -0,0 +1,115 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+// noiseImages returns a flat gray image and its copy with isolated
+// brighter pixels, like the sub-pixel noise of two renderers.
+func noiseImages() (*image.NRGBA, *image.NRGBA) {
+	rect := image.Rect(0, 0, 32, 32)
+	img1, img2 := image.NewNRGBA(rect), image.NewNRGBA(rect)
+	draw.Draw(img1, rect, &image.Uniform{C: color.NRGBA{R: 128, G: 128, B: 128, A: 255}}, image.Point{}, draw.Src)
+	draw.Draw(img2, rect, img1, image.Point{}, draw.Src)
+
+	for y := 4; y < 32; y += 8 {
+		for x := 4; x < 32; x += 8 {
+			img2.SetNRGBA(x, y, color.NRGBA{R: 170, G: 170, B: 170, A: 255})
+		}
+	}
+
+	return img1, img2
+}
+
+func TestWithBlur(t *testing.T) {
+	img1, img2 := noiseImages()
+
+	result, err := Diff(img1, img2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 16 {
+		t.Fatalf("Expected 16 noisy pixels without blur, got - %d", result.DiffPixels)
+	}
+
+	output := image.NewNRGBA(img1.Bounds())
+	result, err = Diff(img1, img2, output, WithBlur(1.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 0 {
+		t.Errorf("Expected the blur to hide the noise, got - %d", result.DiffPixels)
+	}
+
+	count, err := Compare(img1, img2, WithBlur(1.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != result.DiffPixels {
+		t.Errorf("Expected Compare to agree with Diff, got - %d", count)
+	}
+
+	if img2.NRGBAAt(4, 4).R != 170 {
+		t.Error("Expected the input to stay untouched")
+	}
+}
+
+func TestWithBlurHighDepth(t *testing.T) {
+	img1, img2 := noiseImages()
+	a, b := image.NewNRGBA64(img1.Rect), image.NewNRGBA64(img2.Rect)
+	draw.Draw(a, a.Rect, img1, image.Point{}, draw.Src)
+	draw.Draw(b, b.Rect, img2, image.Point{}, draw.Src)
+
+	result, err := Diff(a, b, nil, WithBlur(1.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 0 {
+		t.Errorf("Expected the blur to hide the 16-bit noise, got - %d", result.DiffPixels)
+	}
+}
+
+func TestBlurNRGBA(t *testing.T) {
+	rect := image.Rect(2, 3, 12, 9)
+	img := image.NewNRGBA(rect)
+	red := color.NRGBA{R: 200, G: 10, B: 10, A: 255}
+	draw.Draw(img, image.Rect(2, 3, 7, 9), &image.Uniform{C: red}, image.Point{}, draw.Src)
+
+	options := newOptions(WithBlur(1))
+	blurred := blurNRGBA(img, &options)
+
+	if !blurred.Rect.Eq(rect) {
+		t.Fatalf("Expected bounds %v, got - %v", rect, blurred.Rect)
+	}
+	if c := blurred.NRGBAAt(2, 3); c != red {
+		t.Errorf("Expected the flat part to keep its color, got - %v", c)
+	}
+
+	// transparent pixels must not darken the color next to them
+	c := blurred.NRGBAAt(7, 5)
+	if c.R != red.R || c.A == 0 || c.A == 255 {
+		t.Errorf("Expected a half transparent red at the edge, got - %v", c)
+	}
+}
+
+func TestGaussianKernel(t *testing.T) {
+	kernel := gaussianKernel(1)
+	if len(kernel) != 7 {
+		t.Fatalf("Expected 7 weights, got - %d", len(kernel))
+	}
+
+	var sum float64
+	for i, w := range kernel {
+		sum += float64(w)
+		if w != kernel[len(kernel)-1-i] {
+			t.Errorf("Expected a symmetric kernel, got - %v", kernel)
+		}
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		t.Errorf("Expected weights summing to 1, got - %f", sum)
+	}
+}
@@ -0,0 +1,123 @@
+package pixelmatch
+
+import (
+	"image"
+	"sort"
+)
+
+// WithBoundingBoxes reports up to maxBoxes rectangles covering all different
+// pixels in DiffResult.Boxes, e.g. to annotate the changed areas in review
+// tools. Every group of contiguous different pixels gets its bounding box
+// grown by padding pixels, boxes that overlap are merged, and while there
+// are more than maxBoxes the two whose union adds the least area are merged.
+// A maxBoxes of 0 or less only merges the overlapping boxes.
+func WithBoundingBoxes(maxBoxes, padding int) Option {
+	return func(o *Options) {
+		o.boxes = true
+		o.maxBoxes = maxBoxes
+		o.boxPadding = padding
+	}
+}
+
+// findBoxes returns the merged bounding boxes of the set, top to bottom and
+// left to right.
+func findBoxes(diff *pixelSet, maxBoxes, padding int) []image.Rectangle {
+	if diff == nil {
+		return nil
+	}
+
+	var boxes []image.Rectangle
+	for _, cluster := range findClusters(diff, 0) {
+		boxes = append(boxes, cluster.Bounds.Inset(-maxInt(padding, 0)).Intersect(diff.rect))
+	}
+	boxes = mergeBoxes(boxes, maxBoxes)
+
+	sort.Slice(boxes, func(i, j int) bool {
+		if boxes[i].Min.Y != boxes[j].Min.Y {
+			return boxes[i].Min.Y < boxes[j].Min.Y
+		}
+		return boxes[i].Min.X < boxes[j].Min.X
+	})
+
+	return boxes
+}
+
+// mergeBoxes merges the boxes that overlap and then the pairs adding the
+// least area until at most maxBoxes are left. Every box remembers its
+// cheapest partner, so a merge only rescans the boxes that lost theirs.
+func mergeBoxes(boxes []image.Rectangle, maxBoxes int) []image.Rectangle {
+	var (
+		n       = len(boxes)
+		merged  = make([]bool, n)
+		partner = make([]int, n)
+		cost    = make([]int64, n)
+	)
+
+	best := func(i int) {
+		partner[i], cost[i] = -1, 0
+		for j := range boxes {
+			if j == i || merged[j] {
+				continue
+			}
+			if c := mergeCost(boxes[i], boxes[j]); partner[i] < 0 || c < cost[i] {
+				partner[i], cost[i] = j, c
+			}
+		}
+	}
+	for i := range boxes {
+		best(i)
+	}
+
+	for left := n; left > 1; left-- {
+		i := -1
+		for k := range boxes {
+			if !merged[k] && (i < 0 || cost[k] < cost[i]) {
+				i = k
+			}
+		}
+		// nothing overlaps and the count is within the limit
+		if cost[i] >= 0 && (maxBoxes <= 0 || left <= maxBoxes) {
+			break
+		}
+
+		j := partner[i]
+		boxes[i] = boxes[i].Union(boxes[j])
+		merged[j] = true
+
+		best(i)
+		for k := range boxes {
+			if k == i || merged[k] {
+				continue
+			}
+			if partner[k] == i || partner[k] == j {
+				best(k)
+			} else if c := mergeCost(boxes[k], boxes[i]); c < cost[k] {
+				partner[k], cost[k] = i, c
+			}
+		}
+	}
+
+	kept := boxes[:0]
+	for i, box := range boxes {
+		if !merged[i] {
+			kept = append(kept, box)
+		}
+	}
+
+	return kept
+}
+
+// mergeCost returns -1 for boxes that overlap and otherwise the area their
+// union adds to theirs.
+func mergeCost(a, b image.Rectangle) int64 {
+	if a.Overlaps(b) {
+		return -1
+	}
+
+	return boxArea(a.Union(b)) - boxArea(a) - boxArea(b)
+}
+
+// boxArea returns the number of pixels of r.
+func boxArea(r image.Rectangle) int64 {
+	return int64(r.Dx()) * int64(r.Dy())
+}
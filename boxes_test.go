@@ -0,0 +1,55 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestWithBoundingBoxes(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+
+	red := color.NRGBA{R: 255, A: 255}
+	// two blocks next to each other, a distant pixel and one in the corner
+	for y := 2; y < 5; y++ {
+		for x := 2; x < 5; x++ {
+			img2.SetNRGBA(x, y, red)
+			img2.SetNRGBA(x+4, y, red)
+		}
+	}
+	img2.SetNRGBA(30, 10, red)
+	img2.SetNRGBA(39, 19, red)
+
+	for _, tc := range []struct {
+		name         string
+		max, padding int
+		want         []image.Rectangle
+	}{
+		{"separate", 0, 0, []image.Rectangle{
+			image.Rect(2, 2, 5, 5), image.Rect(6, 2, 9, 5), image.Rect(30, 10, 31, 11), image.Rect(39, 19, 40, 20),
+		}},
+		{"padded", 0, 1, []image.Rectangle{
+			image.Rect(1, 1, 10, 6), image.Rect(29, 9, 32, 12), image.Rect(38, 18, 40, 20),
+		}},
+		{"limited", 2, 0, []image.Rectangle{
+			image.Rect(2, 2, 9, 5), image.Rect(30, 10, 40, 20),
+		}},
+		{"single", 1, 0, []image.Rectangle{
+			image.Rect(2, 2, 40, 20),
+		}},
+	} {
+		result, err := Diff(img1, img2, nil, WithBoundingBoxes(tc.max, tc.padding))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(result.Boxes, tc.want) {
+			t.Errorf("%s: expected boxes %v, got - %v", tc.name, tc.want, result.Boxes)
+		}
+	}
+
+	if result, _ := Diff(img1, img1, nil, WithBoundingBoxes(2, 4)); result.Boxes != nil {
+		t.Errorf("Expected no boxes for equal images, got - %v", result.Boxes)
+	}
+}
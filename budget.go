@@ -0,0 +1,44 @@
+package pixelmatch
+
+import "sync/atomic"
+
+// WithFailFast stops the comparison as soon as more than maxDiffPixels pixels
+// differ; Diff and Compare then return ErrDiffBudgetExceeded along with
+// the partial result counted so far.
+func WithFailFast(maxDiffPixels uint64) Option {
+	return func(o *Options) {
+		o.failFast = true
+		o.failFastMax = maxDiffPixels
+	}
+}
+
+// diffBudget counts different pixels across all bands of a comparison.
+// A nil *diffBudget is never exceeded.
+type diffBudget struct {
+	max   uint64
+	spent uint64
+}
+
+func newDiffBudget(options *Options, spent uint64) *diffBudget {
+	if !options.failFast {
+		return nil
+	}
+
+	return &diffBudget{
+		max:   options.failFastMax,
+		spent: spent,
+	}
+}
+
+// spend adds n different pixels and reports whether the budget is exceeded.
+func (b *diffBudget) spend(n uint64) bool {
+	if b == nil {
+		return false
+	}
+
+	return atomic.AddUint64(&b.spent, n) > b.max
+}
+
+func (b *diffBudget) exceeded() bool {
+	return b != nil && atomic.LoadUint64(&b.spent) > b.max
+}
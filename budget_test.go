@@ -0,0 +1,41 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"testing"
+)
+
+func TestFailFast(t *testing.T) {
+	img1 := decodeTestImage(t, "./testdata/img1.png")
+	img2 := decodeTestImage(t, "./testdata/img2.png")
+
+	result, err := Diff(img1, img2, nil, WithFailFast(100))
+	if !errors.Is(err, ErrDiffBudgetExceeded) {
+		t.Fatalf("Expected ErrDiffBudgetExceeded, got - %v", err)
+	}
+	if result.DiffPixels <= 100 {
+		t.Errorf("Expected partial count above the budget, got - %d", result.DiffPixels)
+	}
+
+	count, err := Compare(img1, img2, WithFailFast(100))
+	if !errors.Is(err, ErrDiffBudgetExceeded) {
+		t.Fatalf("Expected ErrDiffBudgetExceeded, got - %v", err)
+	}
+	if count <= 100 {
+		t.Errorf("Expected partial count above the budget, got - %d", count)
+	}
+
+	full, err := Compare(img1, img2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Compare(img1, img2, WithFailFast(full)); err != nil {
+		t.Errorf("Expected no error within the budget, got - %v", err)
+	}
+
+	same := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	if _, err := Diff(same, same, nil, WithFailFast(0)); err != nil {
+		t.Errorf("Expected no error for equal images, got - %v", err)
+	}
+}
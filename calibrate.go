@@ -0,0 +1,130 @@
+package pixelmatch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrCalibration is returned by CalibrateThreshold when no threshold
+// meets the target false positive rate.
+var ErrCalibration = errors.New("no threshold meets the target false positive rate")
+
+// calibrationPrecision is the precision thresholds are calibrated to.
+const calibrationPrecision = 1e-3
+
+// Calibration is a threshold recommended by CalibrateThreshold
+// with its error rates on the sample set.
+type Calibration struct {
+	Threshold float64 `json:"threshold"`
+
+	// share of the samples expected to match that differ at Threshold
+	FalsePositiveRate float64 `json:"falsePositiveRate"`
+
+	// share of the samples expected to differ that differ at Threshold;
+	// 0 without such samples
+	TruePositiveRate float64 `json:"truePositiveRate"`
+}
+
+// CalibrateThreshold recommends a threshold for a suite of screenshots
+// from a labeled sample set: pairs labeled Different are expected to
+// differ, the others, e.g. renderings of the same page on different
+// machines, to match. A pair differs when at least one pixel differs.
+//
+// The recommendation is the most sensitive threshold at which at most
+// targetFalsePositiveRate (0 to 1) of the matching pairs differ, so it
+// detects as many of the Different pairs as possible. The other options
+// are those of the comparisons, so pass the ones the suite runs with.
+func CalibrateThreshold(samples []ImagePair, targetFalsePositiveRate float64, opts ...Option) (Calibration, error) {
+	var matching, different []float64
+	for _, sample := range samples {
+		t, err := passingThreshold(sample, opts)
+		if err != nil {
+			return Calibration{}, fmt.Errorf("sample %q: %w", sample.Name, err)
+		}
+
+		if sample.Different {
+			different = append(different, t)
+		} else {
+			matching = append(matching, t)
+		}
+	}
+
+	if len(matching) == 0 {
+		return Calibration{}, fmt.Errorf("%w: no samples expected to match", ErrCalibration)
+	}
+
+	// allow the matching pairs passing at the highest thresholds to differ
+	sort.Sort(sort.Reverse(sort.Float64Slice(matching)))
+	allowed := int(math.Floor(targetFalsePositiveRate * float64(len(matching))))
+
+	var result Calibration
+	if allowed < len(matching) {
+		result.Threshold = matching[allowed]
+	}
+	if result.Threshold > 1 {
+		return Calibration{}, fmt.Errorf("%w: samples expected to match differ at threshold 1", ErrCalibration)
+	}
+
+	result.FalsePositiveRate = differRate(matching, result.Threshold)
+	result.TruePositiveRate = differRate(different, result.Threshold)
+
+	return result, nil
+}
+
+// passingThreshold returns the lowest threshold at which the images of
+// a sample have no different pixels, to calibrationPrecision, or a value
+// above 1 if they differ at any threshold.
+func passingThreshold(sample ImagePair, opts []Option) (float64, error) {
+	differs := func(threshold float64) (bool, error) {
+		_, err := Compare(sample.A, sample.B, append(opts[:len(opts):len(opts)], WithThreshold(threshold), WithFailFast(0))...)
+		if errors.Is(err, ErrDiffBudgetExceeded) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	if d, err := differs(1); d || err != nil {
+		return math.Inf(1), err
+	}
+	if d, err := differs(0); !d || err != nil {
+		return 0, err
+	}
+
+	// differs at lo, matches at hi
+	lo, hi := 0.0, 1.0
+	for hi-lo > calibrationPrecision {
+		mid := (lo + hi) / 2
+		d, err := differs(mid)
+		if err != nil {
+			return 0, err
+		}
+
+		if d {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	// round up to keep a threshold the sample passes at
+	return math.Ceil(hi/calibrationPrecision) * calibrationPrecision, nil
+}
+
+// differRate returns the share of samples with a passing threshold above threshold.
+func differRate(passing []float64, threshold float64) float64 {
+	if len(passing) == 0 {
+		return 0
+	}
+
+	var n int
+	for _, t := range passing {
+		if t > threshold {
+			n++
+		}
+	}
+
+	return float64(n) / float64(len(passing))
+}
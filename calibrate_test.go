@@ -0,0 +1,72 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// patchedImage returns a gray image with a 3x3 patch of another gray.
+func patchedImage(patch uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	draw.Draw(img, img.Rect, &image.Uniform{C: color.NRGBA{R: 128, G: 128, B: 128, A: 255}}, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(6, 6, 9, 9), &image.Uniform{C: color.NRGBA{R: patch, G: patch, B: patch, A: 255}}, image.Point{}, draw.Src)
+
+	return img
+}
+
+func TestCalibrateThreshold(t *testing.T) {
+	base := patchedImage(128)
+	samples := []ImagePair{
+		{Name: "same", A: base, B: patchedImage(128)},
+		{Name: "noise", A: base, B: patchedImage(133)},
+		{Name: "more noise", A: base, B: patchedImage(148)},
+		{Name: "changed", A: base, B: patchedImage(0), Different: true},
+	}
+
+	strict, err := CalibrateThreshold(samples, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strict.FalsePositiveRate != 0 || strict.TruePositiveRate != 1 {
+		t.Errorf("Expected no false positives and all changes detected, got - %+v", strict)
+	}
+
+	// the threshold passes the noisier samples but not the changed one
+	for _, sample := range samples {
+		n, err := Compare(sample.A, sample.B, WithThreshold(strict.Threshold))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (n > 0) != sample.Different {
+			t.Errorf("%s: expected different %t at threshold %f, got %d different pixels", sample.Name, sample.Different, strict.Threshold, n)
+		}
+	}
+
+	// a third of the matching samples may differ
+	loose, err := CalibrateThreshold(samples, 0.34)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loose.Threshold >= strict.Threshold || loose.FalsePositiveRate < 0.33 || loose.FalsePositiveRate > 0.34 {
+		t.Errorf("Expected a more sensitive threshold than %f with a third of false positives, got - %+v", strict.Threshold, loose)
+	}
+
+	if all, err := CalibrateThreshold(samples, 1); err != nil || all.Threshold != 0 {
+		t.Errorf("Expected threshold 0, got - %+v, %v", all, err)
+	}
+}
+
+func TestCalibrateThresholdErrors(t *testing.T) {
+	changed := []ImagePair{{Name: "changed", A: patchedImage(128), B: patchedImage(0), Different: true}}
+	if _, err := CalibrateThreshold(changed, 0); !errors.Is(err, ErrCalibration) {
+		t.Errorf("Expected ErrCalibration without matching samples, got - %v", err)
+	}
+
+	sized := []ImagePair{{Name: "sized", A: patchedImage(128), B: image.NewNRGBA(image.Rect(0, 0, 4, 4))}}
+	if _, err := CalibrateThreshold(sized, 0); !errors.Is(err, ErrImageSize) {
+		t.Errorf("Expected ErrImageSize, got - %v", err)
+	}
+}
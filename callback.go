@@ -0,0 +1,70 @@
+package pixelmatch
+
+import "image"
+
+// PixelKind tells why a pixel was reported to the pixel callback.
+type PixelKind int
+
+const (
+	// PixelDiff is a pixel that differs between the images.
+	PixelDiff PixelKind = iota
+
+	// PixelAntialiased is a pixel above the threshold that was
+	// detected as anti-aliasing and not counted as different.
+	PixelAntialiased
+)
+
+// String returns the name of the kind.
+func (k PixelKind) String() string {
+	switch k {
+	case PixelDiff:
+		return "diff"
+	case PixelAntialiased:
+		return "antialiased"
+	default:
+		return "unknown"
+	}
+}
+
+// PixelFunc receives every pixel above the threshold along with its delta,
+// which is negative when the pixel got darker in the second image.
+type PixelFunc func(x, y int, delta float64, kind PixelKind)
+
+// WithPixelCallback calls fn for every different and anti-aliased pixel,
+// so custom overlays and statistics can be built without re-scanning the
+// diff image. Rows are compared concurrently, so fn must be safe for
+// concurrent use; with WithParallelism(1) it is called in row order.
+func WithPixelCallback(fn PixelFunc) Option {
+	return func(o *Options) {
+		o.pixelFunc = fn
+	}
+}
+
+// BandFunc receives a compared band of rows along with its statistics;
+// Percent is the share of different pixels of the band.
+type BandFunc func(band image.Rectangle, part DiffResult)
+
+// WithBandCallback calls fn every time a band of rows is compared
+// completely, by the time its rows of the diff image are drawn, so GUIs can
+// paint the diff of large images progressively while the comparison is
+// still running. Other render modes than RenderDiff draw the diff image
+// only at the end. Calls never overlap; bands are reported in row order
+// with WithParallelism(1) only.
+func WithBandCallback(fn BandFunc) Option {
+	return func(o *Options) {
+		o.bandFunc = fn
+	}
+}
+
+// reportBand calls the band callback for a compared band; the caller
+// serializes the calls.
+func (o *Options) reportBand(band image.Rectangle, part DiffResult) {
+	if o.bandFunc == nil {
+		return
+	}
+
+	if part.TotalPixels > 0 {
+		part.Percent = float64(part.DiffPixels) * 100 / float64(part.TotalPixels)
+	}
+	o.bandFunc(band, part)
+}
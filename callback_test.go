@@ -0,0 +1,103 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithPixelCallback(t *testing.T) {
+	img1, img2 := edgeImage(false), edgeImage(true)
+	img2.SetNRGBA(1, 1, color.NRGBA{R: 255, A: 255})
+
+	var (
+		pixels []image.Point
+		kinds  = map[PixelKind]int{}
+	)
+	result, err := Diff(img1, img2, nil, WithParallelism(1), WithPixelCallback(func(x, y int, delta float64, kind PixelKind) {
+		if delta == 0 {
+			t.Errorf("Expected a non-zero delta at %d,%d", x, y)
+		}
+		kinds[kind]++
+		if kind == PixelDiff {
+			pixels = append(pixels, image.Pt(x, y))
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pixels) != 1 || pixels[0] != image.Pt(1, 1) {
+		t.Errorf("Expected a single different pixel at 1,1, got - %v", pixels)
+	}
+	if uint64(kinds[PixelDiff]) != result.DiffPixels || uint64(kinds[PixelAntialiased]) != result.AAPixels {
+		t.Errorf("Expected callbacks to match the result %+v, got - %v", result, kinds)
+	}
+	if result.AAPixels == 0 {
+		t.Error("Expected anti-aliased pixels to be reported")
+	}
+
+	count, err := Compare(img1, img2, WithPixelCallback(func(x, y int, delta float64, kind PixelKind) {}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != result.DiffPixels {
+		t.Errorf("Expected Compare to report %d pixels, got - %d", result.DiffPixels, count)
+	}
+}
+
+func TestWithBandCallback(t *testing.T) {
+	img1 := toNRGBA(decodeTestImage(t, "testdata/img1.png"))
+	img2 := toNRGBA(decodeTestImage(t, "testdata/img2.png"))
+
+	want, wantResult, err := DiffNew(img1, img2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		output = image.NewNRGBA(img1.Rect)
+		rows   = make([]int, img1.Rect.Dy())
+		sum    DiffResult
+		active int32
+	)
+	result, err := Diff(img1, img2, output, WithParallelism(4), WithBandCallback(func(band image.Rectangle, part DiffResult) {
+		if atomic.AddInt32(&active, 1) != 1 {
+			t.Error("Expected the calls not to overlap")
+		}
+		defer atomic.AddInt32(&active, -1)
+
+		for y := band.Min.Y; y < band.Max.Y; y++ {
+			rows[y]++
+			i := output.PixOffset(band.Min.X, y)
+			if !bytes.Equal(output.Pix[i:i+band.Dx()*4], want.Pix[i:i+band.Dx()*4]) {
+				t.Errorf("Expected row %d to be drawn", y)
+			}
+		}
+		if part.DiffPixels > 0 && part.Percent != float64(part.DiffPixels)*100/float64(part.TotalPixels) {
+			t.Errorf("Expected the percent of the band, got - %v", part.Percent)
+		}
+		sum.add(part)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for y, n := range rows {
+		if n != 1 {
+			t.Fatalf("Expected row %d to be reported once, got - %d", y, n)
+		}
+	}
+	if sum.DiffPixels != result.DiffPixels || sum.TotalPixels != result.TotalPixels || sum.Bounds != wantResult.Bounds {
+		t.Errorf("Expected the bands to add up to the result, got - %+v", sum)
+	}
+
+	var bands []image.Rectangle
+	if _, err := Diff(img1, img1, nil, WithBandCallback(func(band image.Rectangle, part DiffResult) {
+		bands = append(bands, band)
+	})); err != nil || len(bands) != 1 || bands[0] != img1.Rect {
+		t.Errorf("Expected identical images to be reported at once, got - %v %v", bands, err)
+	}
+}
@@ -0,0 +1,80 @@
+package pixelmatch
+
+import "image/color"
+
+// WithChannelDiff compares the R, G, B and A channels of every pixel
+// independently and reports how many pixels differ in each channel in
+// DiffResult.Channels; a channel differs when its values are further apart
+// than threshold * 255. With render set, pixels with drifted channels are drawn
+// into the output in the colors of those channels (red, green, blue or
+// their mix; white when only alpha differs) instead of the diff color.
+func WithChannelDiff(render bool) Option {
+	return func(o *Options) {
+		o.channelDiff = true
+		o.channelRender = render
+	}
+}
+
+// ChannelDiff holds the number of pixels that differ in every channel.
+type ChannelDiff struct {
+	R uint64 `json:"r"`
+	G uint64 `json:"g"`
+	B uint64 `json:"b"`
+	A uint64 `json:"a"`
+}
+
+func (d *ChannelDiff) add(part ChannelDiff) {
+	d.R += part.R
+	d.G += part.G
+	d.B += part.B
+	d.A += part.A
+}
+
+// channelMask returns a bit per channel (R is 1, A is 8)
+// whose values differ by more than maxDiff.
+func channelMask(c1, c2 [4]uint8, maxDiff float64) uint8 {
+	var mask uint8
+	for i := 0; i < 4; i++ {
+		d := float64(c1[i]) - float64(c2[i])
+		if d > maxDiff || -d > maxDiff {
+			mask |= 1 << i
+		}
+	}
+
+	return mask
+}
+
+func (d *ChannelDiff) count(mask uint8) {
+	if mask&1 != 0 {
+		d.R++
+	}
+	if mask&2 != 0 {
+		d.G++
+	}
+	if mask&4 != 0 {
+		d.B++
+	}
+	if mask&8 != 0 {
+		d.A++
+	}
+}
+
+// channelColor draws every drifted color channel at full intensity.
+func channelColor(mask uint8) color.NRGBA {
+	if mask&7 == 0 {
+		return color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+
+	c := color.NRGBA{A: 255}
+	if mask&1 != 0 {
+		c.R = 255
+	}
+	if mask&2 != 0 {
+		c.G = 255
+	}
+	if mask&4 != 0 {
+		c.B = 255
+	}
+
+	return c
+}
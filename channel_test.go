@@ -0,0 +1,39 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestChannelDiff(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	for x := 0; x < 4; x++ {
+		img1.SetNRGBA(x, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+	}
+	img2.SetNRGBA(0, 0, color.NRGBA{R: 200, G: 100, B: 100, A: 255})
+	img2.SetNRGBA(1, 0, color.NRGBA{R: 100, G: 200, B: 200, A: 255})
+	img2.SetNRGBA(2, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 100})
+	img2.SetNRGBA(3, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+
+	output, result, err := DiffNew(img1, img2, WithChannelDiff(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := (ChannelDiff{R: 1, G: 1, B: 1, A: 1}); result.Channels != want {
+		t.Errorf("Expected %+v, got - %+v", want, result.Channels)
+	}
+
+	for x, want := range []color.NRGBA{
+		{R: 255, A: 255},
+		{G: 255, B: 255, A: 255},
+		{R: 255, G: 255, B: 255, A: 255},
+		{},
+	} {
+		if got := output.NRGBAAt(x, 0); got != want {
+			t.Errorf("pixel %d: expected %v, got - %v", x, want, got)
+		}
+	}
+}
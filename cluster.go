@@ -0,0 +1,86 @@
+package pixelmatch
+
+import (
+	"image"
+	"sort"
+)
+
+// WithClusters groups contiguous different pixels (8-connected) into clusters
+// reported in DiffResult.Clusters; clusters smaller than minSize pixels are dropped.
+func WithClusters(minSize int) Option {
+	return func(o *Options) {
+		o.clusters = true
+		o.clusterMinSize = minSize
+	}
+}
+
+// Cluster is a group of contiguous different pixels.
+type Cluster struct {
+	// number of pixels in the cluster
+	Pixels uint64 `json:"pixels"`
+
+	// bounding box of the cluster
+	Bounds image.Rectangle `json:"bounds"`
+
+	// center of mass of the cluster pixels
+	CentroidX float64 `json:"centroidX"`
+	CentroidY float64 `json:"centroidY"`
+}
+
+// findClusters labels the connected components of the set, largest first.
+func findClusters(diff *pixelSet, minSize int) []Cluster {
+	var (
+		clusters []Cluster
+		visited  = newPixelSet(diff.rect)
+		stack    []image.Point
+	)
+
+	for y := diff.rect.Min.Y; y < diff.rect.Max.Y; y++ {
+		for x := diff.rect.Min.X; x < diff.rect.Max.X; x++ {
+			if !diff.has(x, y) || visited.has(x, y) {
+				continue
+			}
+
+			var (
+				cluster    Cluster
+				sumX, sumY float64
+			)
+
+			visited.set(x, y)
+			stack = append(stack[:0], image.Point{X: x, Y: y})
+			for len(stack) > 0 {
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+
+				cluster.Pixels++
+				cluster.Bounds = cluster.Bounds.Union(image.Rect(p.X, p.Y, p.X+1, p.Y+1))
+				sumX += float64(p.X)
+				sumY += float64(p.Y)
+
+				// go through 8 adjacent pixels
+				for ny := p.Y - 1; ny <= p.Y+1; ny++ {
+					for nx := p.X - 1; nx <= p.X+1; nx++ {
+						if diff.has(nx, ny) && !visited.has(nx, ny) {
+							visited.set(nx, ny)
+							stack = append(stack, image.Point{X: nx, Y: ny})
+						}
+					}
+				}
+			}
+
+			if cluster.Pixels < uint64(minSize) {
+				continue
+			}
+
+			cluster.CentroidX = sumX / float64(cluster.Pixels)
+			cluster.CentroidY = sumY / float64(cluster.Pixels)
+			clusters = append(clusters, cluster)
+		}
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Pixels > clusters[j].Pixels
+	})
+
+	return clusters
+}
@@ -0,0 +1,44 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDiffClusters(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+
+	red := color.NRGBA{R: 255, A: 255}
+	// a 3x2 block, a diagonal pair and a single noisy pixel
+	for y := 10; y < 12; y++ {
+		for x := 4; x < 7; x++ {
+			img2.SetNRGBA(x, y, red)
+		}
+	}
+	img2.SetNRGBA(15, 15, red)
+	img2.SetNRGBA(16, 16, red)
+	img2.SetNRGBA(0, 19, red)
+
+	result, err := Diff(img1, img2, nil, WithClusters(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got - %+v", result.Clusters)
+	}
+
+	block := result.Clusters[0]
+	if block.Pixels != 6 || !block.Bounds.Eq(image.Rect(4, 10, 7, 12)) {
+		t.Errorf("Unexpected cluster %+v", block)
+	}
+	if block.CentroidX != 5 || block.CentroidY != 10.5 {
+		t.Errorf("Unexpected centroid %v,%v", block.CentroidX, block.CentroidY)
+	}
+
+	if pair := result.Clusters[1]; pair.Pixels != 2 || !pair.Bounds.Eq(image.Rect(15, 15, 17, 17)) {
+		t.Errorf("Unexpected cluster %+v", pair)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/inotnako/pixelmatch-go"
+	"github.com/inotnako/pixelmatch-go/baseline"
+)
+
+// runDir compares the images of two directory trees paired by their
+// relative paths on -jobs goroutines, writes the diff images of the pairs
+// that fail to -out and prints a summary table. The JSON report goes to
+// -report, by default report.json in -out. Flags may follow the
+// directories.
+func runDir(args []string) int {
+	fs := flag.NewFlagSet("pixelmatch dir", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pixelmatch dir [flags] baseline/ candidate/")
+		fs.PrintDefaults()
+	}
+
+	var (
+		flags      = newCompareFlags(fs)
+		out        = fs.String("out", "", "write the diff images of the failed pairs and report.json to the given directory")
+		jobs       = fs.Int("jobs", 0, "number of pairs compared at once; 0 means the number of CPUs")
+		policyPath = fs.String("policy", "", "JSON or YAML file of per-image thresholds and ignore regions by glob pattern")
+	)
+
+	dirs, err := parseInterspersed(fs, args)
+	if err != nil {
+		return exitFailure
+	}
+	if len(dirs) != 2 || !isDir(dirs[0]) || !isDir(dirs[1]) {
+		fs.Usage()
+		return exitFailure
+	}
+
+	opts, err := flags.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	var pol policy
+	if *policyPath != "" {
+		if pol, err = loadPolicy(*policyPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	}
+
+	reportPath := *flags.report
+	if reportPath == "" && *out != "" {
+		reportPath = filepath.Join(*out, "report.json")
+	}
+
+	return runDirs(dirs[0], dirs[1], *out, reportPath, *jobs, opts, pol)
+}
+
+// parseInterspersed parses the flags of args wherever they are and
+// returns the other arguments; the arguments after "--" are not flags.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return rest, nil
+		}
+		if parsed := len(args) - fs.NArg(); parsed > 0 && args[parsed-1] == "--" {
+			return append(rest, fs.Args()...), nil
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// runDirs compares the images of two directories with the options of the
+// policy for their relative paths on jobs goroutines, writes the diff
+// images of the pairs that fail to diffDir unless it is empty and prints
+// a summary table. It fails with exitDiff when a pair fails or has no
+// counterpart.
+func runDirs(baselineDir, candidateDir, diffDir, reportPath string, jobs int, opts []pixelmatch.Option, pol policy) int {
+	var sink pixelmatch.Sink = discardSink{}
+	if diffDir != "" {
+		sink = pixelmatch.NewDirSink(diffDir)
+	}
+
+	report, err := baseline.CompareDirsParallel(baselineDir, candidateDir, sink, jobs, func(name string) []pixelmatch.Option {
+		return append(opts[:len(opts):len(opts)], pol.options(name)...)
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	code := exitOK
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATUS\tDIFFERENT\tPIXELS")
+	for _, e := range report.Entries {
+		switch e.Status {
+		case baseline.StatusPassed, baseline.StatusFailed:
+			fmt.Fprintf(tw, "%s\t%s\t%.2f%%\t%d\n", e.Name, e.Status, e.Result.Percent, e.Result.DiffPixels)
+		case baseline.StatusError:
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\n", e.Name, e.Status)
+			fmt.Fprintf(os.Stderr, "%s: %v\n", e.Name, e.Err)
+			code = exitFailure
+		default:
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\n", e.Name, e.Status)
+		}
+	}
+	tw.Flush()
+	fmt.Printf("passed: %d, failed: %d, missing: %d, new: %d, errors: %d\n", report.Count(baseline.StatusPassed),
+		report.Count(baseline.StatusFailed), report.Count(baseline.StatusMissing), report.Count(baseline.StatusNew),
+		report.Count(baseline.StatusError))
+
+	if reportPath != "" {
+		sink, name := artifactSink(reportPath)
+		if err := report.WriteReport(sink, name); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	}
+
+	if code == exitOK && !report.Passed() {
+		code = exitDiff
+	}
+
+	return code
+}
+
+// discardSink drops the artifacts written to it.
+type discardSink struct{}
+
+func (discardSink) WriteDiffImage(string, image.Image) error { return nil }
+func (discardSink) WriteReport(string, []byte) error         { return nil }
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRunDir(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		baseline  = filepath.Join(dir, "baseline")
+		candidate = filepath.Join(dir, "candidate")
+		out       = filepath.Join(dir, "diffs")
+	)
+
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("pages/%d/page.png", i)
+		for path, changed := range map[string]bool{filepath.Join(baseline, name): false, filepath.Join(candidate, name): i == 3} {
+			img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+			if changed {
+				img.SetNRGBA(4, 4, color.NRGBA{R: 255, A: 255})
+			}
+			sink, file := artifactSink(path)
+			if err := sink.WriteDiffImage(file, img); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if code := run([]string{"dir", baseline, candidate, "-out", out, "--jobs", "4"}); code != exitDiff {
+		t.Errorf("Expected exit code %d, got - %d", exitDiff, code)
+	}
+
+	entries, err := filepath.Glob(filepath.Join(out, "pages", "*", "*.png"))
+	if err != nil || len(entries) != 1 || entries[0] != filepath.Join(out, "pages", "3", "page.png") {
+		t.Errorf("Expected the diff image of the failed pair only, got - %v %v", entries, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Passed  bool `json:"passed"`
+		Entries []struct {
+			Status string `json:"status"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil || report.Passed || len(report.Entries) != 8 || report.Entries[3].Status != "failed" {
+		t.Errorf("Expected a report of 8 pairs with one failure, got - %s %v", data, err)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"dir", "-max-diff", "1", baseline, candidate}, exitOK},
+		{[]string{"dir", baseline}, exitFailure},
+		{[]string{"dir", baseline, filepath.Join(dir, "missing")}, exitFailure},
+	} {
+		if code := run(tc.args); code != tc.code {
+			t.Errorf("%v: expected exit code %d, got - %d", tc.args, tc.code, code)
+		}
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	jobs := fs.Int("jobs", 0, "")
+
+	rest, err := parseInterspersed(fs, []string{"a", "-jobs", "3", "b", "--", "-c"})
+	if err != nil || *jobs != 3 || !reflect.DeepEqual(rest, []string{"a", "b", "-c"}) {
+		t.Errorf("Unexpected arguments %q %d %v", rest, *jobs, err)
+	}
+}
@@ -0,0 +1,269 @@
+// Command pixelmatch compares two images and writes a diff image,
+// mirroring the CLI of the pixelmatch npm package:
+//
+//	pixelmatch [flags] image1.png image2.png [diff.png]
+//
+// It prints the number and share of different pixels and exits with
+// code 66 when more pixels differ than allowed by -max-diff / -max-percent.
+// Given two directories it compares the images with the same relative
+// paths, writing the diff images of the failed pairs to the third one, and
+// -policy applies per-image options to them, see policy.
+//
+//	pixelmatch watch [flags] image1.png image2.png [diff.png]
+//
+// compares the images again whenever one of them changes, see runWatch, and
+//
+//	pixelmatch dir [flags] baseline/ candidate/ -out diffs/ -jobs 8
+//
+// compares two directory trees concurrently, see runDir.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+const (
+	exitOK      = 0
+	exitDiff    = 66
+	exitFailure = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "watch":
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			return runWatch(ctx, args[1:])
+		case "dir":
+			return runDir(args[1:])
+		}
+	}
+
+	fs := flag.NewFlagSet("pixelmatch", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pixelmatch [flags] image1.png image2.png [diff.png]")
+		fmt.Fprintln(fs.Output(), "       pixelmatch [flags] baseline/ candidate/ [diffs/]")
+		fmt.Fprintln(fs.Output(), "       pixelmatch watch [flags] image1.png image2.png [diff.png]")
+		fmt.Fprintln(fs.Output(), "       pixelmatch dir [flags] baseline/ candidate/")
+		fs.PrintDefaults()
+	}
+
+	var (
+		flags      = newCompareFlags(fs)
+		policyPath = fs.String("policy", "", "JSON or YAML file of per-image thresholds and ignore regions by glob pattern")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+	if fs.NArg() < 2 || fs.NArg() > 3 {
+		fs.Usage()
+		return exitFailure
+	}
+
+	opts, err := flags.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	var pol policy
+	if *policyPath != "" {
+		if pol, err = loadPolicy(*policyPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	}
+
+	if isDir(fs.Arg(0)) && isDir(fs.Arg(1)) {
+		return runDirs(fs.Arg(0), fs.Arg(1), fs.Arg(2), *flags.report, 1, opts, pol)
+	}
+	opts = append(opts, pol.pathOptions(fs.Arg(1))...)
+
+	start := time.Now()
+	result, err := flags.compareFiles(fs.Arg(0), fs.Arg(1), fs.Arg(2), opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	fmt.Printf("matched in: %s\n", time.Since(start).Round(time.Millisecond))
+	fmt.Printf("different pixels: %d\n", result.DiffPixels)
+	fmt.Printf("error: %.2f%%\n", result.Percent)
+
+	if !result.Passed() {
+		return exitDiff
+	}
+
+	return exitOK
+}
+
+// compareFlags are the flags setting the options of a comparison and
+// the artifacts it writes, shared by the commands.
+type compareFlags struct {
+	threshold  *float64
+	alpha      *float64
+	mask       *bool
+	includeAA  *bool
+	palette    *string
+	diffColor  *string
+	aaColor    *string
+	maxDiff    *uint64
+	maxPercent *float64
+	report     *string
+	svg        *string
+	autoOrient *bool
+	colorMgmt  *bool
+}
+
+func newCompareFlags(fs *flag.FlagSet) *compareFlags {
+	return &compareFlags{
+		threshold:  fs.Float64("threshold", 0.1, "matching threshold (0 to 1); smaller is more sensitive"),
+		alpha:      fs.Float64("alpha", 0.1, "opacity of original image in diff output"),
+		mask:       fs.Bool("mask", false, "draw the diff over a transparent background"),
+		includeAA:  fs.Bool("include-aa", false, "count anti-aliased pixels as different"),
+		palette:    fs.String("palette", "default", "colors of the diff: default, color-blind-safe or high-contrast"),
+		diffColor:  fs.String("diff-color", "", "color of different pixels, e.g. #ff00ff or magenta"),
+		aaColor:    fs.String("aa-color", "", "color of anti-aliased pixels, e.g. #ffff00 or yellow"),
+		maxDiff:    fs.Uint64("max-diff", 0, "maximum number of different pixels before failing"),
+		maxPercent: fs.Float64("max-percent", -1, "maximum share of different pixels (0 to 100) before failing; overrides -max-diff"),
+		report:     fs.String("report", "", "write the result as JSON to the given file"),
+		svg:        fs.String("svg", "", "write an SVG overlay outlining the changed areas to the given file"),
+		autoOrient: fs.Bool("auto-orient", false, "turn JPEG images as their EXIF orientation tag says"),
+		colorMgmt:  fs.Bool("color-management", false, "convert images with an embedded ICC profile to sRGB"),
+	}
+}
+
+// options returns the options of the parsed flags.
+func (f *compareFlags) options() ([]pixelmatch.Option, error) {
+	p, err := pixelmatch.ParsePalette(*f.palette)
+	if err != nil {
+		return nil, fmt.Errorf("-palette: %w", err)
+	}
+
+	opts := []pixelmatch.Option{
+		pixelmatch.WithPalette(p),
+		pixelmatch.WithThreshold(*f.threshold),
+		pixelmatch.WithAlpha(*f.alpha),
+		pixelmatch.WithDiffMask(*f.mask),
+		pixelmatch.WithIncludeAA(*f.includeAA),
+		pixelmatch.WithFailureThreshold(*f.maxDiff),
+	}
+	if *f.autoOrient {
+		opts = append(opts, pixelmatch.WithAutoOrient())
+	}
+	if *f.colorMgmt {
+		opts = append(opts, pixelmatch.WithColorManagement())
+	}
+	if *f.svg != "" {
+		opts = append(opts, pixelmatch.WithClusters(1))
+	}
+	if *f.maxPercent >= 0 {
+		opts = append(opts, pixelmatch.WithFailureThresholdPercent(*f.maxPercent))
+	}
+	for _, colorFlag := range []struct {
+		name, value string
+		option      func(color.Color) pixelmatch.Option
+	}{
+		{"diff-color", *f.diffColor, pixelmatch.WithDiffColor},
+		{"aa-color", *f.aaColor, pixelmatch.WithAAColor},
+	} {
+		if colorFlag.value == "" {
+			continue
+		}
+		c, err := pixelmatch.ParseColor(colorFlag.value)
+		if err != nil {
+			return nil, fmt.Errorf("-%s: %w", colorFlag.name, err)
+		}
+		opts = append(opts, colorFlag.option(c))
+	}
+
+	return opts, nil
+}
+
+// compareFiles compares two image files and writes the diff image to
+// diffPath unless it is empty, and the report and the SVG overlay of the
+// flags.
+func (f *compareFlags) compareFiles(path1, path2, diffPath string, opts []pixelmatch.Option) (pixelmatch.DiffResult, error) {
+	img1, err := readImage(path1, opts)
+	if err != nil {
+		return pixelmatch.DiffResult{}, err
+	}
+	img2, err := readImage(path2, opts)
+	if err != nil {
+		return pixelmatch.DiffResult{}, err
+	}
+
+	output := image.NewNRGBA(img1.Bounds())
+	result, err := pixelmatch.Diff(img1, img2, output, opts...)
+	if err != nil {
+		return result, err
+	}
+
+	if diffPath != "" {
+		sink, name := artifactSink(diffPath)
+		if err := sink.WriteDiffImage(name, output); err != nil {
+			return result, err
+		}
+	}
+
+	if *f.report != "" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err == nil {
+			sink, name := artifactSink(*f.report)
+			err = sink.WriteReport(name, data)
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if *f.svg != "" {
+		sink, name := artifactSink(*f.svg)
+		if err := sink.WriteReport(name, result.SVG(img1.Bounds())); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+func readImage(path string, opts []pixelmatch.Option) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := pixelmatch.Decode(f, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	return img, nil
+}
+
+// artifactSink returns the sink writing the file at path and the name of the file in it.
+func artifactSink(path string) (pixelmatch.Sink, string) {
+	return pixelmatch.NewDirSink(filepath.Dir(path)), filepath.Base(path)
+}
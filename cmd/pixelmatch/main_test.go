@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	img1 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img2.SetNRGBA(4, 4, color.NRGBA{R: 255, A: 255})
+
+	path1 := filepath.Join(dir, "a.png")
+	path2 := filepath.Join(dir, "b.png")
+	for path, img := range map[string]image.Image{path1: img1, path2: img2} {
+		sink, name := artifactSink(path)
+		if err := sink.WriteDiffImage(name, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{path1, path1}, exitOK},
+		{[]string{path1, path2, filepath.Join(dir, "diff.png")}, exitDiff},
+		{[]string{"-report", filepath.Join(dir, "reports", "result.json"), path1, path2}, exitDiff},
+		{[]string{"-svg", filepath.Join(dir, "overlay.svg"), path1, path2}, exitDiff},
+		{[]string{"-max-diff", "1", path1, path2}, exitOK},
+		{[]string{"-max-percent", "0.5", path1, path2}, exitDiff},
+		{[]string{"-max-percent", "1", path1, path2}, exitOK},
+		{[]string{"-diff-color", "#00f", "-aa-color", "orange", path1, path2}, exitDiff},
+		{[]string{"-diff-color", "bluish", path1, path2}, exitFailure},
+		{[]string{"-palette", "color-blind-safe", path1, path2}, exitDiff},
+		{[]string{"-palette", "sepia", path1, path2}, exitFailure},
+		{[]string{path1}, exitFailure},
+		{[]string{path1, filepath.Join(dir, "missing.png")}, exitFailure},
+	} {
+		if code := run(tc.args); code != tc.code {
+			t.Errorf("%v: expected exit code %d, got - %d", tc.args, tc.code, code)
+		}
+	}
+
+	if _, err := readImage(filepath.Join(dir, "diff.png"), nil); err != nil {
+		t.Errorf("Expected diff image to be written: %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "overlay.svg")); err != nil || !strings.Contains(string(data), `<rect x="4" y="4" width="1" height="1"`) {
+		t.Errorf("Expected an overlay outlining the different pixel, got - %s %v", data, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "reports", "result.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		DiffPixels uint64 `json:"diffPixels"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil || result.DiffPixels != 1 {
+		t.Errorf("Expected a report of 1 different pixel, got - %s %v", data, err)
+	}
+}
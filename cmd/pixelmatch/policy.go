@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+// policy maps glob patterns of image names to the options comparing them,
+// read from a JSON or YAML file of rules:
+//
+//	rules:
+//	  - match: "icons/**"
+//	    threshold: 0
+//	  - match: "charts/*.png"
+//	    ignoreRegions:
+//	      - {min: {x: 0, y: 0}, max: {x: 200, y: 40}}
+//	    failureThreshold: {value: 0.5, type: percent}
+//
+// Every rule has the keys of pixelmatch.OptionsFromConfig next to match.
+// The rules matching a name apply in order after the flags, so later rules
+// override earlier ones.
+type policy []policyRule
+
+type policyRule struct {
+	match string
+	opts  []pixelmatch.Option
+}
+
+// loadPolicy reads the policy file at path.
+func loadPolicy(path string) (policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Rules []map[string]interface{} `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("policy %s: %w", path, err)
+	}
+
+	var p policy
+	for i, fields := range file.Rules {
+		match, ok := fields["match"].(string)
+		if !ok || match == "" {
+			return nil, fmt.Errorf("policy %s: rule %d has no match pattern", path, i+1)
+		}
+		delete(fields, "match")
+
+		config, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: rule %d: %w", path, i+1, err)
+		}
+		opts, err := pixelmatch.OptionsFromConfig(bytes.NewReader(config))
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: rule %d: %w", path, i+1, err)
+		}
+
+		p = append(p, policyRule{match: match, opts: opts})
+	}
+
+	return p, nil
+}
+
+// options returns the options of the rules matching the slash-separated
+// name of an image.
+func (p policy) options(name string) []pixelmatch.Option {
+	var opts []pixelmatch.Option
+	for _, rule := range p {
+		if matchGlob(rule.match, name) {
+			opts = append(opts, rule.opts...)
+		}
+	}
+
+	return opts
+}
+
+// pathOptions returns the options of the rules matching the trailing
+// elements of the path of an image compared outside of a directory,
+// e.g. "icons/*.png" for "out/icons/save.png".
+func (p policy) pathOptions(file string) []pixelmatch.Option {
+	elems := strings.Split(filepath.ToSlash(filepath.Clean(file)), "/")
+
+	var opts []pixelmatch.Option
+	for _, rule := range p {
+		for i := range elems {
+			if matchGlob(rule.match, strings.Join(elems[i:], "/")) {
+				opts = append(opts, rule.opts...)
+				break
+			}
+		}
+	}
+
+	return opts
+}
+
+// matchGlob reports whether name matches the pattern of path.Match, where
+// a "**" element also matches any number of directories. A pattern without
+// a slash matches the base name, like in .gitignore files.
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
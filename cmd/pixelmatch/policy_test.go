@@ -0,0 +1,91 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"*.png", "home.png", true},
+		{"*.png", "pages/login.png", true},
+		{"home.png", "pages/home.png", true},
+		{"pages/*.png", "pages/login.png", true},
+		{"pages/*.png", "pages/admin/login.png", false},
+		{"pages/**", "pages/admin/login.png", true},
+		{"**/login.png", "login.png", true},
+		{"**/login.png", "pages/admin/login.png", true},
+		{"pages/**/*.png", "pages/login.png", true},
+		{"icons/**", "pages/login.png", false},
+	} {
+		if got := matchGlob(tc.pattern, tc.name); got != tc.match {
+			t.Errorf("%q %q: expected %v, got - %v", tc.pattern, tc.name, tc.match, got)
+		}
+	}
+}
+
+func TestRunPolicy(t *testing.T) {
+	var (
+		dir       = t.TempDir()
+		baseline  = filepath.Join(dir, "baseline")
+		candidate = filepath.Join(dir, "candidate")
+	)
+
+	// a changed pixel in the top-left corner of every candidate
+	for _, name := range []string{"icons/save.png", "charts/sales.png"} {
+		for _, f := range []struct {
+			path    string
+			changed bool
+		}{
+			{filepath.Join(baseline, name), false},
+			{filepath.Join(candidate, name), true},
+		} {
+			img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+			if f.changed {
+				img.SetNRGBA(1, 1, color.NRGBA{R: 255, A: 255})
+			}
+			sink, file := artifactSink(f.path)
+			if err := sink.WriteDiffImage(file, img); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	writePolicy := func(name, policy string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(policy), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	lenient := writePolicy("lenient.yaml", `
+rules:
+  - match: "charts/**"
+    ignoreRegions:
+      - {min: {x: 0, y: 0}, max: {x: 5, y: 5}}
+  - match: "icons/*.png"
+    failureThreshold: {value: 1}
+`)
+	for _, tc := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{baseline, candidate}, exitDiff},
+		{[]string{"-policy", lenient, baseline, candidate, filepath.Join(dir, "diffs")}, exitOK},
+		{[]string{"-policy", lenient, filepath.Join(baseline, "icons", "save.png"), filepath.Join(candidate, "icons", "save.png")}, exitOK},
+		{[]string{"-policy", writePolicy("charts.yaml", "rules: [{match: 'charts/**', failureThreshold: {value: 1}}]"), baseline, candidate}, exitDiff},
+		{[]string{"-policy", writePolicy("nomatch.yaml", "rules: [{threshold: 0}]"), baseline, candidate}, exitFailure},
+		{[]string{"-policy", writePolicy("unknown.json", `{"rules": [{"match": "*.png", "color": "red"}]}`), baseline, candidate}, exitFailure},
+	} {
+		if code := run(tc.args); code != tc.code {
+			t.Errorf("%v: expected exit code %d, got - %d", tc.args, tc.code, code)
+		}
+	}
+}
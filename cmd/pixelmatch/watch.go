@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// runWatch compares two image files again whenever one of them changes,
+// e.g. for designers iterating on renders. It checks the modification
+// times and sizes of the files every -interval, prints a summary line per
+// comparison and rewrites the diff image, the report and the SVG overlay.
+// Problems such as a half-written file are printed and the next change is
+// awaited. It runs until ctx is done.
+func runWatch(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("pixelmatch watch", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pixelmatch watch [flags] image1.png image2.png [diff.png]")
+		fs.PrintDefaults()
+	}
+
+	var (
+		flags    = newCompareFlags(fs)
+		interval = fs.Duration("interval", 500*time.Millisecond, "how often to check the images for changes")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return exitFailure
+	}
+	if fs.NArg() < 2 || fs.NArg() > 3 || *interval <= 0 {
+		fs.Usage()
+		return exitFailure
+	}
+
+	opts, err := flags.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	var last [2]fileState
+	for {
+		if state := [2]fileState{statFile(fs.Arg(0)), statFile(fs.Arg(1))}; state != last {
+			last = state
+
+			now := time.Now().Format("15:04:05")
+			result, err := flags.compareFiles(fs.Arg(0), fs.Arg(1), fs.Arg(2), opts)
+			switch {
+			case err != nil:
+				fmt.Printf("%s error: %v\n", now, err)
+			case result.Passed():
+				fmt.Printf("%s passed: %s\n", now, result)
+			default:
+				fmt.Printf("%s failed: %s\n", now, result)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return exitOK
+		case <-ticker.C:
+		}
+	}
+}
+
+// fileState identifies a version of a file; zero if it does not exist.
+type fileState struct {
+	modTime int64
+	size    int64
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+
+	return fileState{modTime: info.ModTime().UnixNano(), size: info.Size()}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunWatch(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		path1  = filepath.Join(dir, "a.png")
+		path2  = filepath.Join(dir, "b.png")
+		report = filepath.Join(dir, "result.json")
+	)
+
+	writeImage := func(path string, changed bool, modTime time.Time) {
+		t.Helper()
+		img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+		if changed {
+			img.SetNRGBA(4, 4, color.NRGBA{R: 255, A: 255})
+		}
+		sink, name := artifactSink(path)
+		if err := sink.WriteDiffImage(name, img); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// waitReport waits for the report of the given number of different pixels
+	waitReport := func(diffPixels uint64) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			var result struct {
+				DiffPixels uint64 `json:"diffPixels"`
+			}
+			if data, err := os.ReadFile(report); err == nil && json.Unmarshal(data, &result) == nil && result.DiffPixels == diffPixels {
+				return
+			}
+		}
+		t.Fatalf("Expected a report of %d different pixels", diffPixels)
+	}
+
+	start := time.Now().Add(-time.Hour)
+	writeImage(path1, false, start)
+	writeImage(path2, true, start)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	go func() {
+		done <- runWatch(ctx, []string{"-interval", "5ms", "-report", report, path1, path2, filepath.Join(dir, "diff.png")})
+	}()
+
+	waitReport(1)
+	writeImage(path2, false, start.Add(time.Minute))
+	waitReport(0)
+
+	cancel()
+	if code := <-done; code != exitOK {
+		t.Errorf("Expected exit code %d, got - %d", exitOK, code)
+	}
+	if _, err := readImage(filepath.Join(dir, "diff.png"), nil); err != nil {
+		t.Errorf("Expected diff image to be written: %v", err)
+	}
+
+	if code := runWatch(context.Background(), []string{path1}); code != exitFailure {
+		t.Errorf("Expected exit code %d, got - %d", exitFailure, code)
+	}
+}
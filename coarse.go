@@ -0,0 +1,105 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"context"
+	"image"
+)
+
+// coarseTile is the size of the tiles of the coarse pass, the images are
+// compared at 1/coarseTile of their size first.
+const coarseTile = 8
+
+// WithCoarsePass makes Diff find the changed 8x8 tiles of the images first,
+// a map of the images at 1/8 of their size, and compare only the pixels of
+// those tiles; the pixels of the other tiles are identical and counted and
+// drawn as similar ones without computing their color differences. It
+// speeds up comparisons of mostly identical images, returning almost at
+// once when no tile changed, with the same results. It is ignored for
+// images of more than 8 bits per channel and with WithSampleRate.
+func WithCoarsePass() Option {
+	return func(o *Options) {
+		o.coarse = true
+	}
+}
+
+// coarseTiles returns the tiles of r, one pixel of the set per tile from
+// 0, 0, in which any pixel of a and b differs.
+func coarseTiles(ctx context.Context, a, b *image.NRGBA, r image.Rectangle, options *Options) *pixelSet {
+	tiles := newPixelSet(image.Rect(0, 0, (r.Dx()+coarseTile-1)/coarseTile, (r.Dy()+coarseTile-1)/coarseTile))
+
+	runBands(ctx, tiles.rect, options.workers(), func(band image.Rectangle) {
+		for ty := band.Min.Y; ty < band.Max.Y && ctx.Err() == nil; ty++ {
+			for y := r.Min.Y + ty*coarseTile; y < minInt(r.Min.Y+(ty+1)*coarseTile, r.Max.Y); y++ {
+				i, j := a.PixOffset(r.Min.X, y), b.PixOffset(r.Min.X, y)
+				row1, row2 := a.Pix[i:i+r.Dx()*4], b.Pix[j:j+r.Dx()*4]
+
+				for tx := 0; tx < tiles.rect.Dx(); tx++ {
+					start, end := tx*coarseTile*4, minInt((tx+1)*coarseTile*4, len(row1))
+					if !tiles.has(tx, ty) && !bytes.Equal(row1[start:end], row2[start:end]) {
+						tiles.set(tx, ty)
+					}
+				}
+			}
+		}
+	})
+
+	return tiles
+}
+
+// compareTiles compares row y like compareRow, only the pixels of the
+// tiles found by the coarse pass; the other ones are counted and drawn as
+// similar pixels.
+func (c *pixelComparer) compareTiles(a, b, output *image.NRGBA, y, minX, maxX int, part *DiffResult) {
+	ty := (y - c.region.Min.Y) / coarseTile
+
+	for x := minX; x < maxX; {
+		dirty := c.coarse.has((x-c.region.Min.X)/coarseTile, ty)
+
+		// the run of tiles of the same kind
+		end := x
+		for end < maxX && c.coarse.has((end-c.region.Min.X)/coarseTile, ty) == dirty {
+			end = minInt(c.region.Min.X+((end-c.region.Min.X)/coarseTile+1)*coarseTile, maxX)
+		}
+
+		if dirty {
+			c.compareRow(a, b, output, y, x, end, part)
+		} else {
+			c.similarRow(a, b, output, y, x, end, part)
+		}
+		x = end
+	}
+}
+
+// similarRow counts the pixels of row y from minX to maxX as compared and
+// draws them as similar ones without comparing them.
+func (c *pixelComparer) similarRow(a, b, output *image.NRGBA, y, minX, maxX int, part *DiffResult) {
+	options := c.options
+
+	if c.ignore == nil && (output == nil || options.diffMask) {
+		part.TotalPixels += uint64(maxX - minX)
+		return
+	}
+
+	for x := minX; x < maxX; x++ {
+		var out []uint8
+		if output != nil {
+			o := output.PixOffset(x, y)
+			out = output.Pix[o : o+4 : o+4]
+		}
+
+		if c.ignore.ignored(x, y) {
+			if options.ignoreColor != nil {
+				putPixel(out, 0, *options.ignoreColor)
+			} else if !options.diffMask {
+				putPixel(out, 0, backgroundColor(getColor(a, x, y), getColor(b, x, y), options))
+			}
+			continue
+		}
+		part.TotalPixels++
+
+		if !options.diffMask {
+			putPixel(out, 0, backgroundColor(getColor(a, x, y), getColor(b, x, y), options))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWithCoarsePass(t *testing.T) {
+	img1 := toNRGBA(decodeTestImage(t, "testdata/img1.png"))
+	img2 := toNRGBA(decodeTestImage(t, "testdata/img2.png"))
+
+	for name, opts := range map[string][]Option{
+		"default":  nil,
+		"mask":     {WithDiffMask(true)},
+		"ignore":   {WithIgnoreRegions(image.Rect(10, 10, 200, 100)), WithIgnoreColor(color.NRGBA{B: 255, A: 255})},
+		"region":   {WithRegion(image.Rect(3, 5, 301, 211)), WithParallelism(3)},
+		"channels": {WithChannelDiff(true)},
+	} {
+		want, wantResult, err := DiffNew(img1, img2, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, result, err := DiffNew(img1, img2, append(opts, WithCoarsePass())...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.DiffPixels != wantResult.DiffPixels || result.AAPixels != wantResult.AAPixels || result.TotalPixels != wantResult.TotalPixels || result.Channels != wantResult.Channels {
+			t.Errorf("%s: expected %+v, got - %+v", name, wantResult, result)
+		}
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("%s: expected the same diff image", name)
+		}
+	}
+}
+
+func TestCoarseTiles(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	img1, img2 := solidImage(30, 20, white), solidImage(30, 20, white)
+	img2.SetNRGBA(9, 0, color.NRGBA{A: 255})
+	img2.SetNRGBA(29, 19, color.NRGBA{R: 254, G: 255, B: 255, A: 255})
+
+	options := newOptions()
+	tiles := coarseTiles(context.Background(), img1, img2, img1.Rect, &options)
+	if tiles.rect != image.Rect(0, 0, 4, 3) || tiles.count() != 2 || !tiles.has(1, 0) || !tiles.has(3, 2) {
+		t.Errorf("Expected tiles 1,0 and 3,2 to differ, got - %v %d", tiles.rect, tiles.count())
+	}
+
+	if tiles := coarseTiles(context.Background(), img1, img1, img1.Rect, &options); tiles.count() != 0 {
+		t.Errorf("Expected no tile of identical images to differ, got - %d", tiles.count())
+	}
+}
@@ -0,0 +1,74 @@
+package pixelmatch
+
+import (
+	"encoding/hex"
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// namedColors are the colors ParseColor accepts by name: the basic CSS
+// colors and a few common aliases.
+var namedColors = map[string]color.NRGBA{
+	"black":       {A: 255},
+	"silver":      {R: 192, G: 192, B: 192, A: 255},
+	"gray":        {R: 128, G: 128, B: 128, A: 255},
+	"grey":        {R: 128, G: 128, B: 128, A: 255},
+	"white":       {R: 255, G: 255, B: 255, A: 255},
+	"maroon":      {R: 128, A: 255},
+	"red":         {R: 255, A: 255},
+	"purple":      {R: 128, B: 128, A: 255},
+	"fuchsia":     {R: 255, B: 255, A: 255},
+	"magenta":     {R: 255, B: 255, A: 255},
+	"green":       {G: 128, A: 255},
+	"lime":        {G: 255, A: 255},
+	"olive":       {R: 128, G: 128, A: 255},
+	"yellow":      {R: 255, G: 255, A: 255},
+	"navy":        {B: 128, A: 255},
+	"blue":        {B: 255, A: 255},
+	"teal":        {G: 128, B: 128, A: 255},
+	"aqua":        {G: 255, B: 255, A: 255},
+	"cyan":        {G: 255, B: 255, A: 255},
+	"orange":      {R: 255, G: 165, A: 255},
+	"transparent": {},
+}
+
+// ParseColor parses a color written as #rgb, #rrggbb or #rrggbbaa, or as
+// one of the basic CSS color names such as "red", "magenta" or "transparent",
+// for colors given in configs and command line flags.
+func ParseColor(s string) (color.NRGBA, error) {
+	trimmed := strings.TrimSpace(s)
+	if c, ok := namedColors[strings.ToLower(trimmed)]; ok {
+		return c, nil
+	}
+
+	digits := strings.TrimPrefix(trimmed, "#")
+	if digits == trimmed {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q: neither #hex nor a known name", s)
+	}
+	switch len(digits) {
+	case 3:
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]}) + "ff"
+	case 6:
+		digits += "ff"
+	}
+
+	b, err := hex.DecodeString(digits)
+	if err != nil || len(b) != 4 {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+
+	return color.NRGBA{R: b[0], G: b[1], B: b[2], A: b[3]}, nil
+}
+
+// WithDiffColorHex sets the color of different pixels like WithDiffColor
+// from a color accepted by ParseColor. An invalid color leaves the diff color
+// unchanged, so validate user input with ParseColor first.
+func WithDiffColorHex(s string) Option {
+	c, err := ParseColor(s)
+	if err != nil {
+		return func(*Options) {}
+	}
+
+	return WithDiffColor(c)
+}
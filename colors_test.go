@@ -0,0 +1,41 @@
+package pixelmatch
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		s    string
+		want color.NRGBA
+	}{
+		{"#ff00ff", color.NRGBA{R: 255, B: 255, A: 255}},
+		{"#F0F", color.NRGBA{R: 255, B: 255, A: 255}},
+		{"#00ff0080", color.NRGBA{G: 255, A: 128}},
+		{" Magenta ", color.NRGBA{R: 255, B: 255, A: 255}},
+		{"orange", color.NRGBA{R: 255, G: 165, A: 255}},
+		{"transparent", color.NRGBA{}},
+	}
+
+	for _, tc := range tests {
+		if got, err := ParseColor(tc.s); err != nil || got != tc.want {
+			t.Errorf("Expected %v for %q, got - %v, %v", tc.want, tc.s, got, err)
+		}
+	}
+
+	for _, s := range []string{"", "ff00ff", "#ff00f", "#ff00ff0", "#gg0000", "bluish"} {
+		if _, err := ParseColor(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestWithDiffColorHex(t *testing.T) {
+	if options := newOptions(WithDiffColorHex("#00f")); options.diffColor != (color.NRGBA{B: 255, A: 255}) {
+		t.Errorf("Expected blue, got - %v", options.diffColor)
+	}
+	if options := newOptions(WithDiffColorHex("bluish")); options.diffColor != defaultOptions.diffColor {
+		t.Errorf("Expected the default diff color for an invalid color, got - %v", options.diffColor)
+	}
+}
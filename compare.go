@@ -0,0 +1,155 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// Compare returns the number of mismatched pixels of img1 and img2
+// without rendering a diff image. It honors the same options as Diff
+// but skips everything related to the output, which makes it noticeably
+// faster when only the number is needed.
+func Compare(img1, img2 image.Image, opts ...Option) (uint64, error) {
+	options := newOptions(opts...)
+	if options.err != nil {
+		options.observe(time.Now(), DiffResult{}, options.err)
+		return 0, options.err
+	}
+
+	if isHighDepth(img1) || isHighDepth(img2) || options.pixelFunc != nil || options.shift > 0 || options.blur > 0 || options.edges || options.textTolerant() || options.autoAlign > 0 || options.orientationFix || options.median || options.sampleRate > 1 {
+		// the tight loop below works on unchanged 8-bit pixels only,
+		// compares all of them and neither reports pixels nor looks for shifted ones
+		options.debug("options not supported by the fast path, comparing with Diff")
+		_, result, err := diff(context.Background(), img1, img2, nil, false, options)
+		return result.DiffPixels, err
+	}
+
+	start := time.Now()
+
+	if err := options.checkMemory(img1, img2, false); err != nil {
+		options.observe(start, DiffResult{}, err)
+		return 0, err
+	}
+
+	img1, img2 = options.alphaImages(img1, img2)
+
+	img1, img2, release := scaleImages(img1, img2, &options)
+	defer release()
+
+	a, b, outside, err := alignImages(img1, img2, nil, &options)
+	if err != nil {
+		options.observe(start, DiffResult{}, err)
+		return 0, err
+	}
+
+	var (
+		rect     = a.Bounds()
+		cmp      = newPixelComparer(&options, rect)
+		maxDelta = cmp.maxDelta
+		diff     = outside.DiffPixels
+		budget   = newDiffBudget(&options, outside.DiffPixels)
+		prog     = newProgress(&options, cmp.region.Dy())
+	)
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	options.debugBands(cmp.region, options.workers())
+	if equalPix(a, b, cmp.region) {
+		options.debug("images are byte-identical, skipping the pixel loop")
+		prog.add(cmp.region.Dy())
+	} else {
+		runBands(ctx, cmp.region, options.workers(), func(band image.Rectangle) {
+			var (
+				count  uint64
+				rowLen = band.Dx() * 4
+				y      int
+				batch  = simdRows && cmp.yiq
+				deltas [deltaChunk]float64
+			)
+			if equalPix(a, b, band) {
+				prog.add(band.Dy())
+				return
+			}
+
+			for y = band.Min.Y; y < band.Max.Y && ctx.Err() == nil; y++ {
+				rowDiff := count
+				i, j := a.PixOffset(band.Min.X, y), b.PixOffset(band.Min.X, y)
+				row1, row2 := a.Pix[i:i+rowLen:i+rowLen], b.Pix[j:j+rowLen:j+rowLen]
+
+				for k, x := 0, band.Min.X; k < rowLen; k, x = k+4, x+1 {
+					if batch && (x-band.Min.X)%deltaChunk == 0 {
+						if end := minInt(k+deltaChunk*4, rowLen); !bytes.Equal(row1[k:end], row2[k:end]) {
+							yiqDeltaRow(row1[k:end], row2[k:end], deltas[:(end-k)/4])
+						}
+					}
+
+					p1, p2 := binary.LittleEndian.Uint32(row1[k:]), binary.LittleEndian.Uint32(row2[k:])
+					if p1 == p2 || options.ignoreTransparent && (p1|p2)>>24 == 0 || cmp.ignore.ignored(x, y) {
+						continue
+					}
+
+					var delta float64
+					if batch {
+						delta = deltas[(x-band.Min.X)%deltaChunk]
+					} else {
+						delta = cmp.delta(unpackColor(p1), unpackColor(p2))
+					}
+					if math.Abs(delta) <= maxDelta {
+						continue
+					}
+
+					if !options.includeAA && (antialiased(a, b, x, y, rect) || antialiased(b, a, x, y, rect)) {
+						continue
+					}
+					if options.ignoreIsolated && cmp.isolated(a, b, x, y) {
+						continue
+					}
+
+					count++
+				}
+
+				if budget.spend(count - rowDiff) {
+					stop()
+				}
+			}
+
+			atomic.AddUint64(&diff, count)
+			prog.add(y - band.Min.Y)
+		})
+	}
+
+	// the loop above neither counts the compared nor the anti-aliased pixels
+	observed := DiffResult{
+		DiffPixels:  diff,
+		TotalPixels: uint64(cmp.region.Dx()*cmp.region.Dy()) + outside.TotalPixels,
+	}
+
+	options.debug("compared images", "diff", diff, "total", observed.TotalPixels, "elapsed", time.Since(start))
+
+	if budget.exceeded() {
+		options.debug("stopped early, diff budget exceeded", "max", options.failFastMax)
+		options.observe(start, observed, ErrDiffBudgetExceeded)
+		return diff, ErrDiffBudgetExceeded
+	}
+
+	options.observe(start, observed, nil)
+	return diff, nil
+}
+
+// DiffBounds returns the bounding box of all different pixels of img1 and
+// img2 without rendering a diff image, e.g. to crop the change, and whether
+// any pixel differs at all. It honors the same options as Diff.
+func DiffBounds(img1, img2 image.Image, opts ...Option) (image.Rectangle, bool, error) {
+	_, result, err := diff(context.Background(), img1, img2, nil, false, newOptions(opts...))
+	if err != nil {
+		return image.Rectangle{}, false, err
+	}
+
+	return result.Bounds, result.DiffPixels > 0, nil
+}
@@ -0,0 +1,87 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"os"
+	"testing"
+)
+
+func TestCompareMatchesDiff(t *testing.T) {
+	img1 := decodeTestImage(t, "./testdata/img1.png")
+	img2 := decodeTestImage(t, "./testdata/img2.png")
+
+	for _, opts := range [][]Option{
+		nil,
+		{WithThreshold(0.01)},
+		{WithIgnoreRegions(image.Rect(0, 0, 1000, 900))},
+	} {
+		result, err := Diff(img1, img2, nil, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		count, err := Compare(img1, img2, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if count != result.DiffPixels {
+			t.Errorf("Expected %d, got - %d", result.DiffPixels, count)
+		}
+	}
+}
+
+func decodeTestImage(tb testing.TB, path string) image.Image {
+	tb.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return img
+}
+
+func BenchmarkCompare4K(bench *testing.B) {
+	a, b := benchImages(3840, 2160)
+
+	bench.SetBytes(int64(3840 * 2160 * 4))
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		if _, err := Compare(a, b); err != nil {
+			bench.Fatal(err)
+		}
+	}
+}
+
+func TestDiffBounds(t *testing.T) {
+	img1 := decodeTestImage(t, "testdata/img1.png")
+	img2 := decodeTestImage(t, "testdata/img2.png")
+
+	result, err := Diff(img1, img2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, ok, err := DiffBounds(img1, img2); err != nil || !ok || r != result.Bounds {
+		t.Errorf("Expected %v, got - %v %v %v", result.Bounds, r, ok, err)
+	}
+
+	if r, ok, err := DiffBounds(img1, img1); err != nil || ok || !r.Empty() {
+		t.Errorf("Expected no bounds, got - %v %v %v", r, ok, err)
+	}
+
+	region := image.Rect(0, 0, 100, 100)
+	if r, ok, err := DiffBounds(img1, img2, WithIgnoreRegions(result.Bounds)); err != nil || ok || r != (image.Rectangle{}) {
+		t.Errorf("Expected the ignored changes not to count, got - %v %v %v", r, ok, err)
+	}
+	if _, _, err := DiffBounds(img1, image.NewNRGBA(region)); !errors.Is(err, ErrImageSize) {
+		t.Errorf("Expected ErrImageSize, got - %v", err)
+	}
+}
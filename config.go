@@ -0,0 +1,363 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// ErrConfig is returned by OptionsFromConfig for malformed configs.
+var ErrConfig = errors.New("invalid config")
+
+// config is the file form of the options, with the keys of Options.MarshalJSON.
+type config struct {
+	Palette           string            `json:"palette"`
+	Threshold         *float64          `json:"threshold"`
+	IncludeAA         *bool             `json:"includeAA"`
+	Alpha             *float64          `json:"alpha"`
+	AAColor           string            `json:"aaColor"`
+	DiffColor         string            `json:"diffColor"`
+	DiffColorAlt      string            `json:"diffColorAlt"`
+	DiffMask          *bool             `json:"diffMask"`
+	RenderMode        string            `json:"renderMode"`
+	BaseImage         string            `json:"baseImage"`
+	Heatmap           bool              `json:"heatmap"`
+	Metric            string            `json:"metric"`
+	SizeMismatch      string            `json:"sizeMismatch"`
+	PadColor          string            `json:"padColor"`
+	IgnoreRegions     []image.Rectangle `json:"ignoreRegions"`
+	IgnoreColor       string            `json:"ignoreColor"`
+	Region            *image.Rectangle  `json:"region"`
+	IgnoreColors      bool              `json:"ignoreColors"`
+	IgnoreAlpha       bool              `json:"ignoreAlpha"`
+	IgnoreLessThan    float64           `json:"ignoreLessThan"`
+	IgnoreTransparent bool              `json:"ignoreTransparent"`
+	Shift             int               `json:"shiftTolerance"`
+	Blur              float64           `json:"blur"`
+	Edges             bool              `json:"edges"`
+	TextThreshold     float64           `json:"textThreshold"`
+	Scale             float64           `json:"scale"`
+	MaxDimension      int               `json:"maxDimension"`
+	AutoAlign         int               `json:"autoAlign"`
+	Orientation       string            `json:"orientation"`
+	AutoOrient        bool              `json:"autoOrient"`
+	ColorManagement   bool              `json:"colorManagement"`
+	LinearLight       bool              `json:"linearLight"`
+	AlphaMode         string            `json:"alphaMode"`
+	MedianFilter      bool              `json:"medianFilter"`
+	IgnoreIsolated    bool              `json:"ignoreIsolated"`
+	MaskErode         int               `json:"maskErode"`
+	MaskDilate        int               `json:"maskDilate"`
+	SampleRate        int               `json:"sampleRate"`
+	CoarsePass        bool              `json:"coarsePass"`
+	ChannelDiff       bool              `json:"channelDiff"`
+	ExtraMetrics      bool              `json:"extraMetrics"`
+	AAMask            bool              `json:"aaMask"`
+
+	FailFast *struct {
+		MaxDiffPixels uint64 `json:"maxDiffPixels"`
+	} `json:"failFast"`
+
+	FailureThreshold *struct {
+		Value float64 `json:"value"`
+		Type  string  `json:"type"`
+	} `json:"failureThreshold"`
+
+	Grid *struct {
+		Cols int `json:"cols"`
+		Rows int `json:"rows"`
+	} `json:"grid"`
+
+	Clusters *struct {
+		MinSize int `json:"minSize"`
+	} `json:"clusters"`
+
+	Boxes *struct {
+		Max     int `json:"max"`
+		Padding int `json:"padding"`
+	} `json:"boxes"`
+}
+
+// OptionsFromConfig reads options from a JSON config with the keys of
+// Options.MarshalJSON, so the options archived with a result can be loaded
+// back and shared across tools:
+//
+//	{
+//		"threshold": 0.05,
+//		"includeAA": true,
+//		"diffColor": "#ff00ff",
+//		"diffMask": false,
+//		"ignoreRegions": [{"min": {"x": 0, "y": 0}, "max": {"x": 100, "y": 20}}]
+//	}
+//
+// Colors are written as accepted by ParseColor, rectangles have min and max
+// points. Unset keys keep the defaults; unknown keys are an error. Other
+// formats such as YAML can be decoded into plain values and re-encoded to
+// JSON, as the policy files of the CLI are.
+func OptionsFromConfig(r io.Reader) ([]Option, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var c config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%w: %v", ErrConfig, err)
+	}
+
+	return c.options()
+}
+
+// options converts the set keys of the config.
+func (c *config) options() ([]Option, error) {
+	var opts []Option
+
+	// the colors of the palette may be overridden one by one below
+	if c.Palette != "" {
+		p, err := ParsePalette(c.Palette)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrConfig, err)
+		}
+		opts = append(opts, WithPalette(p))
+	}
+	if c.Threshold != nil {
+		opts = append(opts, WithThreshold(*c.Threshold))
+	}
+	if c.IncludeAA != nil {
+		opts = append(opts, WithIncludeAA(*c.IncludeAA))
+	}
+	if c.Alpha != nil {
+		opts = append(opts, WithAlpha(*c.Alpha))
+	}
+	if c.DiffMask != nil {
+		opts = append(opts, WithDiffMask(*c.DiffMask))
+	}
+
+	for _, col := range []struct {
+		name   string
+		value  string
+		option func(color.Color) Option
+	}{
+		{"aaColor", c.AAColor, WithAAColor},
+		{"diffColor", c.DiffColor, WithDiffColor},
+		{"diffColorAlt", c.DiffColorAlt, WithDiffColorAlt},
+		{"padColor", c.PadColor, WithPadColor},
+		{"ignoreColor", c.IgnoreColor, WithIgnoreColor},
+	} {
+		if col.value == "" {
+			continue
+		}
+		nrgba, err := ParseColor(col.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrConfig, col.name, err)
+		}
+		opts = append(opts, col.option(nrgba))
+	}
+
+	if c.RenderMode != "" {
+		mode, ok := parseRenderMode(c.RenderMode)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown renderMode %q", ErrConfig, c.RenderMode)
+		}
+		opts = append(opts, WithRenderMode(mode))
+	}
+	if c.BaseImage != "" {
+		base, ok := parseBaseImage(c.BaseImage)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown baseImage %q", ErrConfig, c.BaseImage)
+		}
+		opts = append(opts, WithBaseImage(base))
+	}
+	if c.Metric != "" {
+		metric, ok := parseMetric(c.Metric)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown metric %q", ErrConfig, c.Metric)
+		}
+		opts = append(opts, WithMetric(metric))
+	}
+	if c.SizeMismatch != "" {
+		mode, ok := parseSizeMismatch(c.SizeMismatch)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown sizeMismatch %q", ErrConfig, c.SizeMismatch)
+		}
+		opts = append(opts, WithSizeMismatch(mode))
+	}
+
+	if c.Heatmap {
+		opts = append(opts, WithHeatmap())
+	}
+	if len(c.IgnoreRegions) > 0 {
+		opts = append(opts, WithIgnoreRegions(c.IgnoreRegions...))
+	}
+	if c.Region != nil {
+		opts = append(opts, WithRegion(*c.Region))
+	}
+	if c.IgnoreColors {
+		opts = append(opts, IgnoreColors())
+	}
+	if c.IgnoreAlpha {
+		opts = append(opts, IgnoreAlpha())
+	}
+	if c.IgnoreLessThan > 0 {
+		opts = append(opts, IgnoreLessThan(c.IgnoreLessThan))
+	}
+	if c.IgnoreTransparent {
+		opts = append(opts, WithIgnoreTransparent())
+	}
+	if c.Shift > 0 {
+		opts = append(opts, WithShiftTolerance(c.Shift))
+	}
+	if c.Blur > 0 {
+		opts = append(opts, WithBlur(c.Blur))
+	}
+	if c.Edges {
+		opts = append(opts, WithEdges())
+	}
+	if c.TextThreshold > 0 {
+		opts = append(opts, WithTextTolerance(c.TextThreshold))
+	}
+	if c.Scale > 0 {
+		opts = append(opts, WithScale(c.Scale))
+	}
+	if c.MaxDimension > 0 {
+		opts = append(opts, WithMaxDimension(c.MaxDimension))
+	}
+	if c.AutoAlign > 0 {
+		opts = append(opts, WithAutoAlign(c.AutoAlign))
+	}
+	switch c.Orientation {
+	case "":
+	case "detect", "normalize":
+		opts = append(opts, WithOrientationCheck(c.Orientation == "normalize"))
+	default:
+		return nil, fmt.Errorf("%w: unknown orientation %q", ErrConfig, c.Orientation)
+	}
+	if c.AutoOrient {
+		opts = append(opts, WithAutoOrient())
+	}
+	if c.ColorManagement {
+		opts = append(opts, WithColorManagement())
+	}
+	if c.LinearLight {
+		opts = append(opts, WithLinearLight())
+	}
+	if c.MedianFilter {
+		opts = append(opts, WithMedianFilter())
+	}
+	if c.IgnoreIsolated {
+		opts = append(opts, IgnoreIsolatedPixels())
+	}
+	if c.MaskErode > 0 {
+		opts = append(opts, WithMaskErode(c.MaskErode))
+	}
+	if c.MaskDilate > 0 {
+		opts = append(opts, WithMaskDilate(c.MaskDilate))
+	}
+	if c.SampleRate > 1 {
+		opts = append(opts, WithSampleRate(c.SampleRate))
+	}
+	if c.CoarsePass {
+		opts = append(opts, WithCoarsePass())
+	}
+	if c.AlphaMode != "" {
+		mode, ok := parseAlphaMode(c.AlphaMode)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown alphaMode %q", ErrConfig, c.AlphaMode)
+		}
+		opts = append(opts, WithAlphaMode(mode))
+	}
+	if c.FailFast != nil {
+		opts = append(opts, WithFailFast(c.FailFast.MaxDiffPixels))
+	}
+	if t := c.FailureThreshold; t != nil {
+		switch {
+		case t.Type == "percent":
+			opts = append(opts, WithFailureThresholdPercent(t.Value))
+		case (t.Type == "pixel" || t.Type == "") && t.Value >= 0 && t.Value == float64(uint64(t.Value)):
+			opts = append(opts, WithFailureThreshold(uint64(t.Value)))
+		default:
+			return nil, fmt.Errorf("%w: invalid failureThreshold %v %q", ErrConfig, t.Value, t.Type)
+		}
+	}
+	if c.Grid != nil {
+		opts = append(opts, WithGrid(c.Grid.Cols, c.Grid.Rows))
+	}
+	if c.Clusters != nil {
+		opts = append(opts, WithClusters(c.Clusters.MinSize))
+	}
+	if c.Boxes != nil {
+		opts = append(opts, WithBoundingBoxes(c.Boxes.Max, c.Boxes.Padding))
+	}
+	if c.ChannelDiff {
+		opts = append(opts, WithChannelDiff(false))
+	}
+	if c.ExtraMetrics {
+		opts = append(opts, WithExtraMetrics())
+	}
+	if c.AAMask {
+		opts = append(opts, WithAAMask())
+	}
+
+	return opts, nil
+}
+
+// parseMetric returns the built-in metric named like metricName does.
+func parseMetric(name string) (Metric, bool) {
+	for _, m := range []Metric{MetricYIQ, MetricSSIM, MetricCIE76, MetricCIEDE2000, MetricRGB} {
+		if metricName(m) == name {
+			return m, true
+		}
+	}
+
+	return nil, false
+}
+
+// parseBaseImage returns the base image named name by BaseImage.String.
+func parseBaseImage(name string) (BaseImage, bool) {
+	for _, b := range []BaseImage{BaseGray, BaseFirst, BaseSecond, BaseNone} {
+		if b.String() == name {
+			return b, true
+		}
+	}
+
+	return BaseGray, false
+}
+
+// parseRenderMode returns the mode named name by RenderMode.String.
+func parseRenderMode(name string) (RenderMode, bool) {
+	for _, m := range []RenderMode{RenderDiff, RenderSideBySide, RenderOnionSkin} {
+		if m.String() == name {
+			return m, true
+		}
+	}
+
+	return RenderDiff, false
+}
+
+// parseAlphaMode returns the mode named name by AlphaMode.String.
+func parseAlphaMode(name string) (AlphaMode, bool) {
+	for _, m := range []AlphaMode{AlphaAuto, AlphaStraight, AlphaPremultiplied} {
+		if m.String() == name {
+			return m, true
+		}
+	}
+
+	return AlphaAuto, false
+}
+
+// parseSizeMismatch returns the mode named name by SizeMismatch.String.
+func parseSizeMismatch(name string) (SizeMismatch, bool) {
+	for _, m := range []SizeMismatch{SizeMismatchFail, SizeMismatchPad, SizeMismatchCrop} {
+		if m.String() == name {
+			return m, true
+		}
+	}
+
+	return SizeMismatchFail, false
+}
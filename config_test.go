@@ -0,0 +1,101 @@
+package pixelmatch
+
+import (
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestOptionsFromConfig(t *testing.T) {
+	config := `{"threshold": 0.05, "includeAA": true, "diffColor": "#f0f", "diffColorAlt": "#00ff0080",
+		"diffMask": false, "metric": "cie76", "ignoreRegions": [{"min": {"x": 0, "y": 0}, "max": {"x": 100, "y": 20}}],
+		"grid": {"cols": 2, "rows": 3}}`
+
+	opts, err := OptionsFromConfig(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := newOptions(opts...)
+	if options.threshold != 0.05 || !options.includeAA || options.diffMask {
+		t.Errorf("Unexpected threshold, includeAA or diffMask in %+v", options)
+	}
+	if options.diffColor != (color.NRGBA{R: 255, B: 255, A: 255}) || *options.diffColorAlt != (color.NRGBA{G: 255, A: 128}) {
+		t.Errorf("Unexpected colors %v, %v", options.diffColor, *options.diffColorAlt)
+	}
+	if options.metric != MetricCIE76 || options.gridCols != 2 || options.gridRows != 3 {
+		t.Errorf("Unexpected metric or grid in %+v", options)
+	}
+	if len(options.ignoreRegions) != 1 || options.ignoreRegions[0] != image.Rect(0, 0, 100, 20) {
+		t.Errorf("Unexpected ignore regions %v", options.ignoreRegions)
+	}
+
+	if opts, err := OptionsFromConfig(strings.NewReader("")); err != nil || len(opts) != 0 {
+		t.Errorf("Expected no options of an empty config, got - %d, %v", len(opts), err)
+	}
+}
+
+func TestOptionsFromConfigRoundTrip(t *testing.T) {
+	options := newOptions(
+		WithThreshold(0.2),
+		WithDiffColorAlt(color.NRGBA{B: 255, A: 255}),
+		WithRenderMode(RenderSideBySide),
+		WithSizeMismatch(SizeMismatchPad),
+		WithRegion(image.Rect(1, 2, 30, 40)),
+		WithFailFast(10),
+		WithFailureThresholdPercent(0.5),
+		WithClusters(4),
+		WithBoundingBoxes(3, 2),
+		IgnoreLessThan(3),
+		WithMetric(MetricSSIM),
+		WithOrientationCheck(true),
+		WithAutoOrient(),
+		WithColorManagement(),
+		WithLinearLight(),
+		WithAlphaMode(AlphaPremultiplied),
+		WithMedianFilter(),
+		IgnoreIsolatedPixels(),
+		WithMaskErode(1),
+		WithMaskDilate(2),
+		WithSampleRate(16),
+		WithCoarsePass(),
+		WithBaseImage(BaseSecond),
+	)
+	want, err := json.Marshal(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := OptionsFromConfig(strings.NewReader(string(want)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(newOptions(opts...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("Expected %s, got - %s", want, got)
+	}
+}
+
+func TestOptionsFromConfigErrors(t *testing.T) {
+	for _, config := range []string{
+		`{"treshold": 0.1}`,
+		`{"threshold": [1]}`,
+		`{"diffColor": "ff00ff"}`,
+		`{"diffColor": "#ff00f"}`,
+		`{"metric": "lab"}`,
+		`{"renderMode": "split"}`,
+		`{"sizeMismatch": "stretch"}`,
+		`threshold: 0.1`,
+		`{"threshold": 0.1`,
+	} {
+		if _, err := OptionsFromConfig(strings.NewReader(config)); !errors.Is(err, ErrConfig) {
+			t.Errorf("Expected ErrConfig for %q, got - %v", config, err)
+		}
+	}
+}
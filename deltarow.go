@@ -0,0 +1,20 @@
+package pixelmatch
+
+import "encoding/binary"
+
+// deltaChunk is how many pixels compareRow passes to yiqDeltaRow at once,
+// small enough for the deltas to stay on the stack.
+const deltaChunk = 64
+
+// yiqDeltaRowGeneric is the portable yiqDeltaRow.
+func yiqDeltaRowGeneric(row1, row2 []uint8, deltas []float64) {
+	for i := range deltas {
+		p1, p2 := binary.LittleEndian.Uint32(row1[i*4:]), binary.LittleEndian.Uint32(row2[i*4:])
+		if p1 == p2 {
+			deltas[i] = 0
+			continue
+		}
+
+		deltas[i] = colorDelta(unpackColor(p1), unpackColor(p2), false)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build pixelmatch_simd && amd64
+
+package pixelmatch
+
+import "golang.org/x/sys/cpu"
+
+// simdRows reports whether yiqDeltaRow is vectorized, only then the
+// comparison computes the deltas of whole rows ahead of the pixel loop.
+var simdRows = cpu.X86.HasAVX2
+
+// yiqDeltaAVX2 computes the deltas of n groups of 4 pixels.
+//
+//go:noescape
+func yiqDeltaAVX2(row1, row2 *uint8, deltas *float64, n int)
+
+// yiqDeltaRow stores the YIQ deltas of the pixels of two rows in deltas,
+// like colorDelta does for every pair, 4 pixels at a time with AVX2.
+func yiqDeltaRow(row1, row2 []uint8, deltas []float64) {
+	n := len(deltas) / 4 * 4
+	if simdRows && n > 0 {
+		_, _ = row1[n*4-1], row2[n*4-1]
+		yiqDeltaAVX2(&row1[0], &row2[0], &deltas[0], n/4)
+	} else {
+		n = 0
+	}
+
+	yiqDeltaRowGeneric(row1[n*4:], row2[n*4:], deltas[n:])
+}
@@ -0,0 +1,136 @@
+//go:build pixelmatch_simd && amd64
+
+#include "textflag.h"
+
+// deinterleaves 4 RGBA pixels into 4 R, 4 G, 4 B and 4 A bytes
+DATA shuffle<>+0(SB)/8, $0x0d0905010c080400
+DATA shuffle<>+8(SB)/8, $0x0f0b07030e0a0602
+GLOBL shuffle<>(SB), RODATA|NOPTR, $16
+
+#define CONST(name, value) \
+	DATA name<>+0(SB)/8, $value \
+	DATA name<>+8(SB)/8, $value \
+	DATA name<>+16(SB)/8, $value \
+	DATA name<>+24(SB)/8, $value \
+	GLOBL name<>(SB), RODATA|NOPTR, $32
+
+CONST(c255, 255.0)
+CONST(yr, 0.29889531)
+CONST(yg, 0.58662247)
+CONST(yb, 0.11448223)
+CONST(ir, 0.59597799)
+CONST(ig, 0.27417610)
+CONST(ib, 0.32180189)
+CONST(qr, 0.21147017)
+CONST(qg, 0.52261711)
+CONST(qb, 0.31114694)
+CONST(ky, 0.5053)
+CONST(ki, 0.299)
+CONST(kq, 0.1957)
+
+DATA signbit<>+0(SB)/8, $0x8000000000000000
+DATA signbit<>+8(SB)/8, $0x8000000000000000
+DATA signbit<>+16(SB)/8, $0x8000000000000000
+DATA signbit<>+24(SB)/8, $0x8000000000000000
+GLOBL signbit<>(SB), RODATA|NOPTR, $32
+
+// LOAD loads 4 pixels from ptr and blends them with white like
+// blendColor does, leaving the channels in R, G and B; clobbers X0 and A.
+#define LOAD(ptr, R, G, B, XR, XG, XB, A, XA) \
+	VMOVDQU (ptr), X0 \
+	VPSHUFB X15, X0, X0 \
+	VPMOVZXBD X0, XR \
+	VCVTDQ2PD XR, R \
+	VPSRLDQ $4, X0, XG \
+	VPMOVZXBD XG, XG \
+	VCVTDQ2PD XG, G \
+	VPSRLDQ $8, X0, XB \
+	VPMOVZXBD XB, XB \
+	VCVTDQ2PD XB, B \
+	VPSRLDQ $12, X0, XA \
+	VPMOVZXBD XA, XA \
+	VCVTDQ2PD XA, A \
+	VDIVPD c255<>(SB), A, A \
+	VSUBPD c255<>(SB), R, R \
+	VMULPD A, R, R \
+	VADDPD c255<>(SB), R, R \
+	VSUBPD c255<>(SB), G, G \
+	VMULPD A, G, G \
+	VADDPD c255<>(SB), G, G \
+	VSUBPD c255<>(SB), B, B \
+	VMULPD A, B, B \
+	VADDPD c255<>(SB), B, B
+
+// YDOT, IDOT and QDOT compute rgb2y, rgb2i and rgb2q of R, G and B into D
+// with the same order of operations; clobber T.
+#define YDOT(R, G, B, D, T) \
+	VMULPD yr<>(SB), R, D \
+	VMULPD yg<>(SB), G, T \
+	VADDPD T, D, D \
+	VMULPD yb<>(SB), B, T \
+	VADDPD T, D, D
+
+#define IDOT(R, G, B, D, T) \
+	VMULPD ir<>(SB), R, D \
+	VMULPD ig<>(SB), G, T \
+	VSUBPD T, D, D \
+	VMULPD ib<>(SB), B, T \
+	VSUBPD T, D, D
+
+#define QDOT(R, G, B, D, T) \
+	VMULPD qr<>(SB), R, D \
+	VMULPD qg<>(SB), G, T \
+	VSUBPD T, D, D \
+	VMULPD qb<>(SB), B, T \
+	VADDPD T, D, D
+
+// func yiqDeltaAVX2(row1, row2 *uint8, deltas *float64, n int)
+TEXT ·yiqDeltaAVX2(SB), NOSPLIT, $0-32
+	MOVQ row1+0(FP), SI
+	MOVQ row2+8(FP), DI
+	MOVQ deltas+16(FP), DX
+	MOVQ n+24(FP), CX
+	VMOVDQU shuffle<>(SB), X15
+
+loop:
+	LOAD(SI, Y1, Y2, Y3, X1, X2, X3, Y9, X9)
+	LOAD(DI, Y4, Y5, Y6, X4, X5, X6, Y9, X9)
+
+	// y1 in Y7, y2 in Y8 and y = y1 - y2 in Y10
+	YDOT(Y1, Y2, Y3, Y7, Y9)
+	YDOT(Y4, Y5, Y6, Y8, Y9)
+	VSUBPD Y8, Y7, Y10
+
+	// i in Y11, q in Y12
+	IDOT(Y1, Y2, Y3, Y11, Y9)
+	IDOT(Y4, Y5, Y6, Y12, Y9)
+	VSUBPD Y12, Y11, Y11
+	QDOT(Y1, Y2, Y3, Y12, Y9)
+	QDOT(Y4, Y5, Y6, Y13, Y9)
+	VSUBPD Y13, Y12, Y12
+
+	// delta = 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+	VMULPD ky<>(SB), Y10, Y13
+	VMULPD Y10, Y13, Y13
+	VMULPD ki<>(SB), Y11, Y9
+	VMULPD Y11, Y9, Y9
+	VADDPD Y9, Y13, Y13
+	VMULPD kq<>(SB), Y12, Y9
+	VMULPD Y12, Y9, Y9
+	VADDPD Y9, Y13, Y13
+
+	// negative when the pixel of the second row is darker (y1 > y2)
+	VCMPPD $0x1e, Y8, Y7, Y9
+	VANDPD signbit<>(SB), Y9, Y9
+	VXORPD Y9, Y13, Y13
+
+	VMOVUPD Y13, (DX)
+
+	ADDQ $16, SI
+	ADDQ $16, DI
+	ADDQ $32, DX
+	DECQ CX
+	JNZ  loop
+
+	VZEROUPPER
+	RET
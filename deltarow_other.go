@@ -0,0 +1,15 @@
+//go:build !pixelmatch_simd || !amd64
+
+package pixelmatch
+
+// simdRows reports whether yiqDeltaRow is vectorized, only then the
+// comparison computes the deltas of whole rows ahead of the pixel loop.
+// Only amd64 with AVX2 has a vectorized version; arm64 and the other
+// platforms use the portable one.
+const simdRows = false
+
+// yiqDeltaRow stores the YIQ deltas of the pixels of two rows in deltas,
+// like colorDelta does for every pair.
+func yiqDeltaRow(row1, row2 []uint8, deltas []float64) {
+	yiqDeltaRowGeneric(row1, row2, deltas)
+}
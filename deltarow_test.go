@@ -0,0 +1,71 @@
+package pixelmatch
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestYIQDeltaRow(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	// odd lengths cover the pixels left over by the vectorized loop
+	for _, n := range []int{0, 1, 4, 7, 64, 101} {
+		row1, row2 := make([]uint8, n*4), make([]uint8, n*4)
+		rnd.Read(row1)
+		rnd.Read(row2)
+
+		// some equal, opaque and transparent pixels
+		for i := 0; i < n; i += 3 {
+			copy(row2[i*4:i*4+4], row1[i*4:i*4+4])
+		}
+		for i := 1; i < n; i += 5 {
+			row1[i*4+3], row2[i*4+3] = 255, 0
+		}
+
+		deltas := make([]float64, n)
+		yiqDeltaRow(row1, row2, deltas)
+
+		for i, got := range deltas {
+			var c1, c2 [4]uint8
+			copy(c1[:], row1[i*4:])
+			copy(c2[:], row2[i*4:])
+
+			if want := colorDelta(c1, c2, false); got != want {
+				t.Fatalf("pixel %d of %d: expected delta %v, got - %v", i, n, want, got)
+			}
+		}
+	}
+}
+
+func BenchmarkYIQDeltaRow(b *testing.B) {
+	rnd := rand.New(rand.NewSource(1))
+	row1, row2 := make([]uint8, 3840*4), make([]uint8, 3840*4)
+	rnd.Read(row1)
+	rnd.Read(row2)
+	deltas := make([]float64, 3840)
+
+	b.SetBytes(int64(len(row1)))
+	for i := 0; i < b.N; i++ {
+		yiqDeltaRow(row1, row2, deltas)
+	}
+}
+
+// BenchmarkCompareReencoded4K compares images differing slightly in every
+// pixel, like re-encoded ones, where computing the deltas dominates.
+func BenchmarkCompareReencoded4K(b *testing.B) {
+	img1, img2 := benchImages(3840, 2160)
+	rnd := rand.New(rand.NewSource(1))
+	for i := range img2.Pix {
+		if i%4 != 3 {
+			img2.Pix[i] = img1.Pix[i] ^ uint8(rnd.Intn(4))
+		}
+	}
+
+	b.SetBytes(int64(len(img1.Pix)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Compare(img1, img2); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
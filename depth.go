@@ -0,0 +1,71 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// isHighDepth reports whether img stores more than 8 bits per channel,
+// like the 16-bit PNGs produced by scientific and medical imaging.
+func isHighDepth(img image.Image) bool {
+	switch img.ColorModel() {
+	case color.RGBA64Model, color.NRGBA64Model, color.Gray16Model:
+		return true
+	}
+
+	return false
+}
+
+// toNRGBA64 returns img itself when it is already *image.NRGBA64 with bounds r
+// or its copy converted to NRGBA64 and aligned to r otherwise, pixels of r
+// outside of img are filled with the pad color as in padImage.
+func toNRGBA64(img image.Image, r image.Rectangle, fill color.NRGBA) *image.NRGBA64 {
+	if nrgba, ok := img.(*image.NRGBA64); ok && nrgba.Rect.Eq(r) {
+		return nrgba
+	}
+
+	dst := image.NewNRGBA64(r)
+	if !r.In(img.Bounds()) {
+		draw.Draw(dst, r, &image.Uniform{C: fill}, image.Point{}, draw.Src)
+	}
+	src := img.Bounds().Intersect(r)
+	draw.Draw(dst, src, img, src.Min, draw.Src)
+
+	return dst
+}
+
+func getColor64(img *image.NRGBA64, x, y int) (c [4]uint16) {
+	i := img.PixOffset(x, y)
+	p := img.Pix[i : i+8 : i+8]
+	for k := range c {
+		c[k] = uint16(p[2*k])<<8 | uint16(p[2*k+1])
+	}
+
+	return
+}
+
+// colorDelta64 is colorDelta for 16-bit colors. The channels keep their full
+// precision but are scaled to 0..255, so the result is comparable with
+// colorDelta and the same threshold applies to both.
+func colorDelta64(c1, c2 [4]uint16, yOnly bool) float64 {
+	if c1 == c2 {
+		return 0
+	}
+
+	r1, g1, b1 := blendColor64(c1)
+	r2, g2, b2 := blendColor64(c2)
+
+	return yiqDelta(r1, g1, b1, r2, g2, b2, yOnly)
+}
+
+// blendColor64 is blendColor for 16-bit colors.
+func blendColor64(c [4]uint16) (r, g, b float64) {
+	r, g, b = float64(c[0])/257, float64(c[1])/257, float64(c[2])/257
+	if c[3] < 0xffff {
+		a := float64(c[3]) / 0xffff
+		r, g, b = blend(r, a), blend(g, a), blend(b, a)
+	}
+
+	return r, g, b
+}
@@ -0,0 +1,79 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDiffHighDepth(t *testing.T) {
+	rect := image.Rect(0, 0, 8, 8)
+	img1, img2 := image.NewGray16(rect), image.NewGray16(rect)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img1.SetGray16(x, y, color.Gray16{Y: 0x1000})
+			img2.SetGray16(x, y, color.Gray16{Y: 0x1000})
+		}
+	}
+	// the same 8-bit value but different in 16 bits
+	img2.SetGray16(3, 4, color.Gray16{Y: 0x1080})
+
+	result, err := Diff(img1, img2, nil, WithThreshold(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 1 {
+		t.Errorf("Expected 1 different 16-bit pixel, got - %d", result.DiffPixels)
+	}
+
+	count, err := Compare(img1, img2, WithThreshold(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != result.DiffPixels {
+		t.Errorf("Expected Compare to agree with Diff, got - %d", count)
+	}
+
+	result, err = Diff(toNRGBA(img1), toNRGBA(img2), nil, WithThreshold(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 0 {
+		t.Errorf("Expected no difference after converting to 8 bits, got - %d", result.DiffPixels)
+	}
+}
+
+func TestDiffHighDepthMixed(t *testing.T) {
+	rect := image.Rect(0, 0, 4, 4)
+	img1, img2 := image.NewNRGBA(rect), image.NewRGBA64(rect)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img1.SetNRGBA(x, y, color.NRGBA{R: 0x20, G: 0x40, B: 0x60, A: 255})
+			img2.SetRGBA64(x, y, color.RGBA64{R: 0x2020, G: 0x4040, B: 0x6060, A: 0xffff})
+		}
+	}
+
+	result, err := Diff(img1, img2, image.NewNRGBA(rect), WithThreshold(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 0 {
+		t.Errorf("Expected an 8-bit image to equal its 16-bit copy, got - %d", result.DiffPixels)
+	}
+}
+
+func TestColorDelta64(t *testing.T) {
+	c1 := [4]uint8{10, 120, 250, 128}
+	c2 := [4]uint8{200, 30, 5, 255}
+	wide := func(c [4]uint8) (w [4]uint16) {
+		for i, v := range c {
+			w[i] = uint16(v) * 257
+		}
+		return
+	}
+
+	want := colorDelta(c1, c2, false)
+	if got := colorDelta64(wide(c1), wide(c2), false); got < want-1e-9 || got > want+1e-9 {
+		t.Errorf("Expected %v for colors widened to 16 bits, got - %v", want, got)
+	}
+}
@@ -0,0 +1,99 @@
+package pixelmatch
+
+import (
+	"context"
+	"image"
+	"sync"
+)
+
+// Differ compares images with the same options over and over, reusing the
+// buffers of converted image copies and diff images across calls to take
+// the pressure off the garbage collector in high-volume services.
+// It is safe for concurrent use.
+type Differ struct {
+	options Options
+	pool    pixPool
+}
+
+// New returns a Differ comparing images with the given options.
+func New(opts ...Option) *Differ {
+	d := &Differ{options: newOptions(opts...)}
+	d.options.pool = &d.pool
+
+	return d
+}
+
+// Err returns the error of validating the options of the Differ, see
+// Options.Validate; its comparisons fail with it as well.
+func (d *Differ) Err() error {
+	return d.options.err
+}
+
+// Diff is like the package-level Diff with the options of the Differ.
+func (d *Differ) Diff(img1, img2 image.Image, output *image.NRGBA) (DiffResult, error) {
+	return d.DiffContext(context.Background(), img1, img2, output)
+}
+
+// DiffContext is like the package-level DiffContext with the options of the Differ.
+func (d *Differ) DiffContext(ctx context.Context, img1, img2 image.Image, output *image.NRGBA) (DiffResult, error) {
+	_, result, err := diff(ctx, img1, img2, output, false, d.options)
+
+	return result, err
+}
+
+// DiffNew is like the package-level DiffNew but takes the output from the
+// buffers of the Differ; pass it to Release once it is no longer needed.
+func (d *Differ) DiffNew(img1, img2 image.Image) (*image.NRGBA, DiffResult, error) {
+	return diff(context.Background(), img1, img2, nil, true, d.options)
+}
+
+// Release returns an image created by DiffNew to the buffers of the Differ.
+// The image must not be used afterwards.
+func (d *Differ) Release(img *image.NRGBA) {
+	d.pool.put(img)
+}
+
+// sharesPix reports whether img is src itself or its sub-image,
+// whose pixels end at the same place of the same array.
+func sharesPix(img *image.NRGBA, src image.Image) bool {
+	s, ok := src.(*image.NRGBA)
+	if !ok || cap(img.Pix) == 0 || cap(s.Pix) == 0 {
+		return false
+	}
+
+	return &img.Pix[:cap(img.Pix)][cap(img.Pix)-1] == &s.Pix[:cap(s.Pix)][cap(s.Pix)-1]
+}
+
+// pixPool reuses the pixel buffers of NRGBA images.
+// A nil *pixPool allocates new images and drops released ones.
+type pixPool struct {
+	pool sync.Pool
+}
+
+// newNRGBA returns a blank image with the given bounds.
+func (p *pixPool) newNRGBA(r image.Rectangle) *image.NRGBA {
+	if p == nil {
+		return image.NewNRGBA(r)
+	}
+
+	n := r.Dx() * r.Dy() * 4
+	if pix, ok := p.pool.Get().(*[]uint8); ok && cap(*pix) >= n {
+		img := &image.NRGBA{Pix: (*pix)[:n], Stride: r.Dx() * 4, Rect: r}
+		for i := range img.Pix {
+			img.Pix[i] = 0
+		}
+		return img
+	}
+
+	return image.NewNRGBA(r)
+}
+
+// put makes the pixel buffer of img available to newNRGBA.
+func (p *pixPool) put(img *image.NRGBA) {
+	if p == nil || img == nil || cap(img.Pix) == 0 {
+		return
+	}
+
+	pix := img.Pix[:0]
+	p.pool.Put(&pix)
+}
@@ -0,0 +1,81 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"testing"
+)
+
+func TestDiffer(t *testing.T) {
+	img1 := decodeTestImage(t, "./testdata/img1.png")
+	img2 := decodeTestImage(t, "./testdata/img2.png")
+
+	// *image.RGBA inputs are converted to pooled NRGBA copies
+	rgba1, rgba2 := image.NewRGBA(img1.Bounds()), image.NewRGBA(img2.Bounds())
+	draw.Draw(rgba1, rgba1.Bounds(), img1, img1.Bounds().Min, draw.Src)
+	draw.Draw(rgba2, rgba2.Bounds(), img2, img2.Bounds().Min, draw.Src)
+
+	opts := []Option{WithDiffMask(true)}
+	want, wantResult, err := DiffNew(rgba1, rgba2, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := New(opts...)
+	for i := 0; i < 3; i++ {
+		output, result, err := d.DiffNew(rgba1, rgba2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.DiffPixels != wantResult.DiffPixels {
+			t.Errorf("Expected %d different pixels, got - %d", wantResult.DiffPixels, result.DiffPixels)
+		}
+		if !bytes.Equal(output.Pix, want.Pix) {
+			t.Errorf("Run %d: expected a reused output to be drawn from scratch", i)
+		}
+
+		// dirty the buffer before handing it back
+		for j := range output.Pix {
+			output.Pix[j] = 0xff
+		}
+		d.Release(output)
+	}
+
+	// sub-images of the inputs are never put into the pool
+	nrgba1 := toNRGBA(img1)
+	before := append([]uint8(nil), nrgba1.Pix...)
+	sub := nrgba1.SubImage(image.Rect(0, 0, 10, 10)).(*image.NRGBA)
+	d = New(WithSizeMismatch(SizeMismatchCrop))
+	for i := 0; i < 3; i++ {
+		if _, err := d.Diff(nrgba1, sub, nil); err != nil {
+			t.Fatal(err)
+		}
+		output, _, err := d.DiffNew(rgba1, rgba2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Release(output)
+	}
+	if !bytes.Equal(nrgba1.Pix, before) {
+		t.Error("Expected the input image to stay untouched")
+	}
+}
+
+func BenchmarkDiffer(bench *testing.B) {
+	a, b := benchImages(1920, 1080)
+	rgba1, rgba2 := image.NewRGBA(a.Bounds()), image.NewRGBA(b.Bounds())
+	draw.Draw(rgba1, rgba1.Bounds(), a, image.Point{}, draw.Src)
+	draw.Draw(rgba2, rgba2.Bounds(), b, image.Point{}, draw.Src)
+
+	d := New()
+	bench.ReportAllocs()
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		output, _, err := d.DiffNew(rgba1, rgba2)
+		if err != nil {
+			bench.Fatal(err)
+		}
+		d.Release(output)
+	}
+}
@@ -0,0 +1,13 @@
+// Package pixelmatch compares images pixel by pixel, telling rendering
+// differences from anti-aliasing, and draws the differences.
+//
+// Comparisons split the images into row bands compared on several
+// goroutines. The bands only read the input images, write disjoint rows of
+// the output image and merge their statistics under a lock, so a comparison
+// is race-free by itself. Concurrent comparisons may share input images and
+// options; concurrent comparisons drawing into the same output image take
+// turns, the last one leaves its diff in it. An output image must not share
+// its pixels with an input image, which makes Diff fail with
+// ErrOutputShared. Callbacks passed in options, such as WithPixelCallback,
+// WithProgress and WithObserver, are called from several goroutines.
+package pixelmatch
@@ -0,0 +1,43 @@
+package pixelmatch
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/draw"
+)
+
+// DiffInto is like Diff but draws the difference into any draw.Image, e.g.
+// an existing *image.RGBA canvas or a paletted GIF frame, whose bounds must
+// match those of the diff image. See DiffIntoContext.
+func DiffInto(img1, img2 image.Image, output draw.Image, opts ...Option) (DiffResult, error) {
+	return DiffIntoContext(context.Background(), img1, img2, output, opts...)
+}
+
+// DiffIntoContext is like DiffContext but draws the difference into any
+// draw.Image. An *image.NRGBA output is drawn into directly; other images
+// are copied to an NRGBA buffer, so pixels the diff does not draw, as with
+// WithDiffMask, keep their colors, and the diff is copied back with
+// draw.Draw, which converts the colors with the color model of output,
+// e.g. to the nearest colors of a palette.
+func DiffIntoContext(ctx context.Context, img1, img2 image.Image, output draw.Image, opts ...Option) (DiffResult, error) {
+	if nrgba, ok := output.(*image.NRGBA); ok || output == nil {
+		return DiffContext(ctx, img1, img2, nrgba, opts...)
+	}
+
+	options := newOptions(opts...)
+
+	r := output.Bounds()
+	buf := options.pool.newNRGBA(r)
+	defer options.pool.put(buf)
+	draw.Draw(buf, r, output, r.Min, draw.Src)
+
+	_, result, err := diff(ctx, img1, img2, buf, false, options)
+	if err != nil && !errors.Is(err, ErrDiffBudgetExceeded) {
+		return result, err
+	}
+
+	draw.Draw(output, r, buf, r.Min, draw.Src)
+
+	return result, err
+}
@@ -0,0 +1,62 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestDiffInto(t *testing.T) {
+	img1 := toNRGBA(decodeTestImage(t, "testdata/img1.png"))
+	img2 := toNRGBA(decodeTestImage(t, "testdata/img2.png"))
+
+	want, wantResult, err := DiffNew(img1, img2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rgba := image.NewRGBA(img1.Rect)
+	result, err := DiffInto(img1, img2, rgba)
+	if err != nil || result.DiffPixels != wantResult.DiffPixels {
+		t.Fatalf("Expected %d different pixels, got - %d %v", wantResult.DiffPixels, result.DiffPixels, err)
+	}
+	if got := toNRGBA(rgba); !bytes.Equal(got.Pix, want.Pix) {
+		t.Error("Expected the RGBA canvas to hold the diff image")
+	}
+
+	nrgba := image.NewNRGBA(img1.Rect)
+	if _, err := DiffInto(img1, img2, nrgba); err != nil || !bytes.Equal(nrgba.Pix, want.Pix) {
+		t.Errorf("Expected the NRGBA output to be drawn directly, got - %v", err)
+	}
+
+	// the mask leaves the canvas as it is where the images match
+	blue := color.RGBA{B: 255, A: 255}
+	draw.Draw(rgba, rgba.Rect, &image.Uniform{C: blue}, image.Point{}, draw.Src)
+	if _, err := DiffInto(img1, img2, rgba, WithDiffMask(true)); err != nil {
+		t.Fatal(err)
+	}
+	mask, _, err := DiffMask(img1, img2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.IndexByte(mask.Pix, 1)
+	p := image.Pt(i%mask.Stride, i/mask.Stride).Add(mask.Rect.Min)
+	if rgba.RGBAAt(0, 0) != blue || rgba.RGBAAt(p.X, p.Y) == blue {
+		t.Errorf("Expected the diff over the canvas, got - %v %v", rgba.RGBAAt(0, 0), rgba.RGBAAt(p.X, p.Y))
+	}
+
+	// a paletted frame takes the nearest colors
+	frame := image.NewPaletted(img1.Rect, color.Palette{color.White, color.Black, color.RGBA{R: 255, A: 255}})
+	if _, err := DiffInto(img1, img2, frame, WithDiffMask(true)); err != nil {
+		t.Fatal(err)
+	}
+	if frame.ColorIndexAt(0, 0) != 0 || frame.ColorIndexAt(p.X, p.Y) != 2 {
+		t.Errorf("Expected white and the red diff color, got - %d %d", frame.ColorIndexAt(0, 0), frame.ColorIndexAt(p.X, p.Y))
+	}
+
+	if _, err := DiffInto(img1, img2, image.NewRGBA(image.Rect(0, 0, 5, 5))); err == nil {
+		t.Error("Expected an output of another size to fail")
+	}
+}
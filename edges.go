@@ -0,0 +1,69 @@
+package pixelmatch
+
+import (
+	"context"
+	"image"
+)
+
+// edgeLevel is the brightness step (0 to 255) of a sharp edge the Sobel
+// gradient has to reach for a pixel to be an edge with WithEdges.
+const edgeLevel = 24
+
+// WithEdges compares the layout of the images instead of their colors: both
+// images are reduced to maps of their edges found by the Sobel operator, so
+// moved, resized or missing elements are caught while flat color changes,
+// e.g. of a new theme, are not. A pixel is an edge where the brightness
+// changes by about a tenth of its range. Edges are found after WithBlur,
+// which helps with noisy images; the diff image is drawn over the edge map
+// of the first image, white edges on black.
+func WithEdges() Option {
+	return func(o *Options) {
+		o.edges = true
+	}
+}
+
+// edgesNRGBA returns the edge map of img: opaque white where the Sobel
+// gradient of the brightness is at least edgeLevel and opaque black elsewhere.
+func edgesNRGBA(img *image.NRGBA, options *Options) *image.NRGBA {
+	var (
+		r    = img.Rect
+		w, h = r.Dx(), r.Dy()
+		luma = make([]float32, w*h)
+		dst  = options.pool.newNRGBA(r)
+	)
+
+	for y := 0; y < h; y++ {
+		row := img.Pix[img.PixOffset(r.Min.X, r.Min.Y+y):][:w*4]
+		for x := 0; x < w; x++ {
+			var c [4]uint8
+			copy(c[:], row[x*4:x*4+4])
+			luma[y*w+x] = float32(rgb2y(blendColor(c)))
+		}
+	}
+
+	// a sharp step of edgeLevel gives a gradient of 4*edgeLevel
+	const minSquared = 4 * edgeLevel * 4 * edgeLevel
+
+	runBands(context.Background(), r, options.workers(), func(band image.Rectangle) {
+		for y := band.Min.Y - r.Min.Y; y < band.Max.Y-r.Min.Y; y++ {
+			// rows above and below, repeating the edge rows
+			up, mid, down := luma[maxInt(y-1, 0)*w:][:w], luma[y*w:][:w], luma[minInt(y+1, h-1)*w:][:w]
+			out := dst.Pix[dst.PixOffset(r.Min.X, r.Min.Y+y):][:w*4]
+
+			for x := 0; x < w; x++ {
+				left, right := maxInt(x-1, 0), minInt(x+1, w-1)
+
+				gx := up[right] + 2*mid[right] + down[right] - up[left] - 2*mid[left] - down[left]
+				gy := down[left] + 2*down[x] + down[right] - up[left] - 2*up[x] - up[right]
+
+				var v uint8
+				if gx*gx+gy*gy >= minSquared {
+					v = 255
+				}
+				out[x*4], out[x*4+1], out[x*4+2], out[x*4+3] = v, v, v, 255
+			}
+		}
+	})
+
+	return dst
+}
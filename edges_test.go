@@ -0,0 +1,80 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// boxImage draws a box of the given color at x, y over white.
+func boxImage(x, y int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	draw.Draw(img, img.Rect, image.White, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(x, y, x+12, y+8), image.NewUniform(c), image.Point{}, draw.Src)
+
+	return img
+}
+
+func TestWithEdges(t *testing.T) {
+	var (
+		blue  = boxImage(10, 10, color.NRGBA{B: 255, A: 255})
+		red   = boxImage(10, 10, color.NRGBA{R: 200, A: 255})
+		moved = boxImage(13, 10, color.NRGBA{B: 255, A: 255})
+	)
+
+	if result, err := Diff(blue, red, nil); err != nil || result.DiffPixels == 0 {
+		t.Fatalf("Expected the recolored box to differ by color, got - %d, %v", result.DiffPixels, err)
+	}
+
+	tests := []struct {
+		name string
+		b    image.Image
+		want bool
+	}{
+		{"recolored", red, false},
+		{"moved", moved, true},
+	}
+
+	for _, tc := range tests {
+		result, err := Diff(blue, tc.b, nil, WithEdges())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := result.DiffPixels > 0; got != tc.want {
+			t.Errorf("%s: expected different %v, got - %d pixels", tc.name, tc.want, result.DiffPixels)
+		}
+
+		count, err := Compare(blue, tc.b, WithEdges())
+		if err != nil || count != result.DiffPixels {
+			t.Errorf("%s: expected Compare to count %d pixels, got - %d, %v", tc.name, result.DiffPixels, count, err)
+		}
+	}
+
+	// 16-bit images are compared by their edges too
+	deep := image.NewNRGBA64(red.Rect)
+	draw.Draw(deep, deep.Rect, red, image.Point{}, draw.Src)
+	if result, err := Diff(blue, deep, nil, WithEdges()); err != nil || result.DiffPixels != 0 {
+		t.Errorf("Expected no differences of the 16-bit recolored box, got - %d, %v", result.DiffPixels, err)
+	}
+}
+
+func TestEdgesNRGBA(t *testing.T) {
+	options := newOptions()
+	edges := edgesNRGBA(boxImage(10, 10, color.Black), &options)
+
+	for _, tc := range []struct {
+		x, y int
+		want uint8
+	}{
+		{0, 0, 0},     // flat background
+		{15, 14, 0},   // flat inside of the box
+		{10, 14, 255}, // left side of the box
+		{9, 14, 255},  // just outside of it
+		{15, 17, 255}, // bottom side
+	} {
+		if got := edges.NRGBAAt(tc.x, tc.y); got != (color.NRGBA{R: tc.want, G: tc.want, B: tc.want, A: 255}) {
+			t.Errorf("Expected %d at (%d,%d), got - %v", tc.want, tc.x, tc.y, got)
+		}
+	}
+}
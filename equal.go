@@ -0,0 +1,60 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"image"
+)
+
+// equalPix reports whether a and b hold the same bytes in r, comparing
+// the whole Pix slices at once when r covers both images.
+func equalPix(a, b *image.NRGBA, r image.Rectangle) bool {
+	if r.Empty() {
+		return true
+	}
+
+	if a.Rect == r && b.Rect == r && a.Stride == r.Dx()*4 && b.Stride == a.Stride {
+		n := r.Dy() * a.Stride
+		return bytes.Equal(a.Pix[:n], b.Pix[:n])
+	}
+
+	n := r.Dx() * 4
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i, j := a.PixOffset(r.Min.X, y), b.PixOffset(r.Min.X, y)
+		if !bytes.Equal(a.Pix[i:i+n], b.Pix[j:j+n]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// skipEqual counts the pixels of r as compared similar ones and reports
+// true when a and b hold the same bytes in r and nothing is drawn for
+// them, so byte-identical images and bands skip the pixel loop.
+func (c *pixelComparer) skipEqual(a, b, output *image.NRGBA, r image.Rectangle, part *DiffResult) bool {
+	if output != nil || c.a64 != nil || c.options.sampleRate > 1 || !equalPix(a, b, r) {
+		return false
+	}
+
+	part.TotalPixels += c.compared(r)
+
+	return true
+}
+
+// compared returns the number of pixels of r that are not ignored.
+func (c *pixelComparer) compared(r image.Rectangle) uint64 {
+	n := uint64(r.Dx() * r.Dy())
+	if c.ignore == nil {
+		return n
+	}
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if c.ignore.ignored(x, y) {
+				n--
+			}
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,66 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestEqualPix(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	a, b := solidImage(20, 10, white), solidImage(20, 10, white)
+	if !equalPix(a, b, a.Rect) {
+		t.Error("Expected identical images to be equal")
+	}
+
+	b.SetNRGBA(15, 8, color.NRGBA{A: 255})
+	if equalPix(a, b, a.Rect) || equalPix(a, b, image.Rect(10, 5, 20, 10)) {
+		t.Error("Expected the changed pixel to be found")
+	}
+	if !equalPix(a, b, image.Rect(0, 0, 15, 10)) || !equalPix(a, b, image.Rect(0, 0, 20, 8)) {
+		t.Error("Expected the rectangles without the pixel to be equal")
+	}
+
+	// sub-images share the Pix of larger images
+	sub := b.SubImage(image.Rect(2, 2, 12, 7)).(*image.NRGBA)
+	if !equalPix(sub, a.SubImage(sub.Rect).(*image.NRGBA), sub.Rect) {
+		t.Error("Expected the equal sub-images to be equal")
+	}
+}
+
+func TestDiffEqualImages(t *testing.T) {
+	img := toNRGBA(decodeTestImage(t, "testdata/img1.png"))
+	same := image.NewNRGBA(img.Rect)
+	copy(same.Pix, img.Pix)
+
+	for name, opts := range map[string][]Option{
+		"default": nil,
+		"ignore":  {WithIgnoreRegions(image.Rect(0, 0, 100, 100))},
+		"region":  {WithRegion(image.Rect(10, 20, 300, 400)), WithParallelism(4)},
+	} {
+		// drawing the diff image compares every pixel
+		_, want, err := DiffNew(img, same, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Diff(img, same, nil, opts...)
+		if err != nil || got.DiffPixels != 0 || got.TotalPixels != want.TotalPixels {
+			t.Errorf("%s: expected %d compared pixels, got - %d %v", name, want.TotalPixels, got.TotalPixels, err)
+		}
+		if n, err := Compare(img, same, opts...); err != nil || n != 0 {
+			t.Errorf("%s: expected no different pixels, got - %d %v", name, n, err)
+		}
+	}
+
+	// a band with a change is compared, the others are skipped
+	changed := image.NewNRGBA(img.Rect)
+	copy(changed.Pix, img.Pix)
+	changed.SetNRGBA(10, 10, color.NRGBA{R: 255, A: 255})
+	want, _ := Diff(img, changed, nil, WithParallelism(1))
+	if got, err := Diff(img, changed, nil, WithParallelism(8)); err != nil || got.DiffPixels != want.DiffPixels || got.TotalPixels != want.TotalPixels {
+		t.Errorf("Expected %+v, got - %+v %v", want, got, err)
+	}
+	if n, _ := Compare(img, changed, WithParallelism(8)); n != want.DiffPixels {
+		t.Errorf("Expected %d different pixels, got - %d", want.DiffPixels, n)
+	}
+}
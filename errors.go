@@ -0,0 +1,49 @@
+package pixelmatch
+
+import (
+	"fmt"
+	"image"
+)
+
+// Indexes of the images in EmptyImageError and SizeMismatchError.
+const (
+	FirstImage = iota
+	SecondImage
+	OutputImage
+)
+
+// EmptyImageError reports a nil image or an image with empty bounds.
+// It matches ErrEmptyImage with errors.Is.
+type EmptyImageError struct {
+	// FirstImage, SecondImage or OutputImage
+	Index int
+}
+
+func (e *EmptyImageError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrEmptyImage, indexImgStr(e.Index))
+}
+
+// Unwrap returns ErrEmptyImage.
+func (e *EmptyImageError) Unwrap() error {
+	return ErrEmptyImage
+}
+
+// SizeMismatchError reports an image whose bounds differ from those of the
+// first image, e.g. to crop it and compare again. It matches ErrImageSize
+// with errors.Is.
+type SizeMismatchError struct {
+	// SecondImage or OutputImage
+	Index int
+
+	// bounds of the image and of the first image
+	Got, Want image.Rectangle
+}
+
+func (e *SizeMismatchError) Error() string {
+	return fmt.Sprintf("%v: %s %v != %s %v", ErrImageSize, indexImgStr(e.Index), e.Got, indexImgStr(FirstImage), e.Want)
+}
+
+// Unwrap returns ErrImageSize.
+func (e *SizeMismatchError) Unwrap() error {
+	return ErrImageSize
+}
@@ -0,0 +1,63 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"testing"
+)
+
+func TestEmptyImageError(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+
+	tests := []struct {
+		name       string
+		a, b       image.Image
+		output     *image.NRGBA
+		wantIndex  int
+		wantString string
+	}{
+		{"first", nil, img, nil, FirstImage, "image is empty: first img"},
+		{"second", img, image.NewNRGBA(image.Rect(2, 2, 2, 5)), nil, SecondImage, "image is empty: second img"},
+		{"output", img, img, image.NewNRGBA(image.Rectangle{}), OutputImage, "image is empty: output img"},
+	}
+
+	for _, tc := range tests {
+		_, err := Diff(tc.a, tc.b, tc.output)
+
+		var emptyErr *EmptyImageError
+		if !errors.As(err, &emptyErr) || !errors.Is(err, ErrEmptyImage) {
+			t.Fatalf("%s: expected *EmptyImageError, got - %v", tc.name, err)
+		}
+		if emptyErr.Index != tc.wantIndex || err.Error() != tc.wantString {
+			t.Errorf("%s: expected index %d and %q, got - %d, %q", tc.name, tc.wantIndex, tc.wantString, emptyErr.Index, err)
+		}
+	}
+}
+
+func TestSizeMismatchError(t *testing.T) {
+	a, b := image.NewNRGBA(image.Rect(0, 0, 4, 4)), image.NewNRGBA(image.Rect(1, 0, 5, 3))
+
+	_, err := Diff(a, b, nil)
+
+	var sizeErr *SizeMismatchError
+	if !errors.As(err, &sizeErr) || !errors.Is(err, ErrImageSize) {
+		t.Fatalf("Expected *SizeMismatchError, got - %v", err)
+	}
+	if sizeErr.Index != SecondImage || sizeErr.Got != b.Rect || sizeErr.Want != a.Rect {
+		t.Errorf("Expected the bounds of the second image, got - %+v", sizeErr)
+	}
+	if want := "size of images must be equals: second img (1,0)-(5,3) != first img (0,0)-(4,4)"; err.Error() != want {
+		t.Errorf("Expected %q, got - %q", want, err)
+	}
+
+	// react to the mismatch by comparing the common area
+	common := sizeErr.Got.Intersect(sizeErr.Want)
+	if _, err := Diff(a.SubImage(common), b.SubImage(common), nil); err != nil {
+		t.Errorf("Expected the cropped images to compare, got - %v", err)
+	}
+
+	_, err = Diff(a, a, image.NewNRGBA(image.Rect(0, 0, 3, 3)))
+	if !errors.As(err, &sizeErr) || sizeErr.Index != OutputImage {
+		t.Errorf("Expected *SizeMismatchError of the output, got - %v", err)
+	}
+}
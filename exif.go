@@ -0,0 +1,99 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// WithAutoOrient turns JPEG inputs as their EXIF orientation tag says before
+// comparing, like image viewers display them, so a portrait photo stored
+// sideways by a camera matches its upright baseline. It applies where this
+// package decodes the images: DiffFiles, DiffReaders and Decode; images
+// passed to Diff are compared as they are.
+func WithAutoOrient() Option {
+	return func(o *Options) {
+		o.autoOrient = true
+	}
+}
+
+// jpegOrientation returns the EXIF orientation tag of a JPEG file,
+// OrientationNormal if it has none or the tag is invalid.
+func jpegOrientation(data []byte) Orientation {
+	o := OrientationNormal
+	forEachJPEGSegment(data, func(marker byte, segment []byte) bool {
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			o = tiffOrientation(segment[6:])
+			return false
+		}
+		return true
+	})
+
+	return o
+}
+
+// forEachJPEGSegment calls fn with the marker and payload of the segments
+// of a JPEG file before the image data, until fn returns false.
+func forEachJPEGSegment(data []byte, fn func(marker byte, segment []byte) bool) {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return
+	}
+
+	for i := 2; i+4 <= len(data) && data[i] == 0xff; {
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xda || size < 2 || i+2+size > len(data) {
+			return
+		}
+
+		if !fn(marker, data[i+4:i+2+size]) {
+			return
+		}
+
+		i += 2 + size
+	}
+}
+
+// tiffOrientation returns the orientation tag of the first IFD of the TIFF
+// structure holding the EXIF data.
+func tiffOrientation(tiff []byte) Orientation {
+	if len(tiff) < 8 {
+		return OrientationNormal
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return OrientationNormal
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return OrientationNormal
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			break
+		}
+
+		// orientation tag, a single SHORT stored in the value field
+		if order.Uint16(tiff[entry:]) != 0x0112 {
+			continue
+		}
+		if order.Uint16(tiff[entry+2:]) != 3 || order.Uint32(tiff[entry+4:]) != 1 {
+			break
+		}
+		if o := Orientation(order.Uint16(tiff[entry+8:])); o >= OrientationNormal && o <= OrientationRotate270 {
+			return o
+		}
+		break
+	}
+
+	return OrientationNormal
+}
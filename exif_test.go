@@ -0,0 +1,96 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifSegment returns an APP1 segment with an EXIF orientation tag.
+func exifSegment(order binary.AppendByteOrder, o Orientation) []byte {
+	tiff := []byte("MM\x00\x2a")
+	if order == binary.LittleEndian {
+		tiff = []byte("II\x2a\x00")
+	}
+	tiff = order.AppendUint32(tiff, 8)
+	tiff = order.AppendUint16(tiff, 1)
+	tiff = order.AppendUint16(tiff, 0x0112)
+	tiff = order.AppendUint16(tiff, 3)
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint16(tiff, uint16(o))
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xff, 0xe1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+
+	return append(segment, payload...)
+}
+
+// orientedJPEG returns img encoded as JPEG with the given EXIF segment.
+func orientedJPEG(tb testing.TB, img image.Image, segment []byte) []byte {
+	tb.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		tb.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	img := texturedImage(12, 8, 1)
+
+	for _, order := range []binary.AppendByteOrder{binary.BigEndian, binary.LittleEndian} {
+		for o := OrientationNormal; o <= OrientationRotate270; o++ {
+			if got := jpegOrientation(orientedJPEG(t, img, exifSegment(order, o))); got != o {
+				t.Errorf("%v: expected %v, got - %v", order, o, got)
+			}
+		}
+	}
+
+	for name, data := range map[string][]byte{
+		"no exif":       orientedJPEG(t, img, nil),
+		"invalid value": orientedJPEG(t, img, exifSegment(binary.BigEndian, 9)),
+		"truncated":     orientedJPEG(t, img, exifSegment(binary.BigEndian, OrientationRotate90))[:30],
+		"not a jpeg":    []byte("\x89PNG\r\n"),
+	} {
+		if got := jpegOrientation(data); got != OrientationNormal {
+			t.Errorf("%s: expected normal, got - %v", name, got)
+		}
+	}
+}
+
+func TestWithAutoOrient(t *testing.T) {
+	options := newOptions()
+	photo := scaleNRGBA(toNRGBA(decodeTestImage(t, "testdata/img1.png")), 0.05, &options)
+	data := orientedJPEG(t, photo, exifSegment(binary.BigEndian, OrientationRotate90))
+
+	img, format, err := Decode(bytes.NewReader(data))
+	if err != nil || format != "jpeg" || !img.Bounds().Eq(photo.Rect) {
+		t.Fatalf("Expected the image as stored without the option, got - %v %s %v", img.Bounds(), format, err)
+	}
+
+	// the baseline is the photo as viewers display it
+	var baseline bytes.Buffer
+	if err := png.Encode(&baseline, Orient(img, OrientationRotate90)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, _, err := DiffReaders(bytes.NewReader(baseline.Bytes()), bytes.NewReader(data), WithAutoOrient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 0 {
+		t.Errorf("Expected the turned photo to match, got - %d", result.DiffPixels)
+	}
+
+	if _, _, err := DiffReaders(bytes.NewReader(baseline.Bytes()), bytes.NewReader(data)); err == nil {
+		t.Error("Expected the sideways photo not to fit the baseline without the option")
+	}
+}
@@ -0,0 +1,61 @@
+package pixelmatch
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// WithExtraMetrics computes the mean squared error and the peak
+// signal-to-noise ratio of the images in DiffResult.Metrics,
+// the metrics reported by video quality tools.
+func WithExtraMetrics() Option {
+	return func(o *Options) {
+		o.extraMetrics = true
+	}
+}
+
+// ExtraMetrics holds the metrics computed with WithExtraMetrics over the
+// R, G and B channels of the compared pixels blended with white.
+type ExtraMetrics struct {
+	// mean squared error of the channels, from 0 to 255^2
+	MSE float64 `json:"mse"`
+
+	// peak signal-to-noise ratio in decibels;
+	// +Inf for identical images, encoded as null in JSON
+	PSNR float64 `json:"psnr"`
+}
+
+func newExtraMetrics(squaredError float64, pixels uint64) *ExtraMetrics {
+	m := &ExtraMetrics{PSNR: math.Inf(1)}
+	if pixels == 0 || squaredError == 0 {
+		return m
+	}
+
+	m.MSE = squaredError / float64(3*pixels)
+	m.PSNR = 10 * math.Log10(255*255/m.MSE)
+
+	return m
+}
+
+// MarshalJSON encodes an infinite PSNR as null, which JSON has no number for.
+func (m ExtraMetrics) MarshalJSON() ([]byte, error) {
+	v := struct {
+		MSE  float64  `json:"mse"`
+		PSNR *float64 `json:"psnr"`
+	}{MSE: m.MSE}
+
+	if !math.IsInf(m.PSNR, 0) {
+		v.PSNR = &m.PSNR
+	}
+
+	return json.Marshal(v)
+}
+
+// squaredError returns the sum of the squared differences of the R, G and B
+// channels of two colors blended with white.
+func squaredError(c1, c2 [4]uint8) float64 {
+	r1, g1, b1 := blendColor(c1)
+	r2, g2, b2 := blendColor(c2)
+
+	return (r1-r2)*(r1-r2) + (g1-g2)*(g1-g2) + (b1-b2)*(b1-b2)
+}
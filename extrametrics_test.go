@@ -0,0 +1,76 @@
+package pixelmatch
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWithExtraMetrics(t *testing.T) {
+	rect := image.Rect(0, 0, 2, 1)
+	img1, img2 := image.NewNRGBA(rect), image.NewNRGBA(rect)
+	img1.SetNRGBA(0, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+	img1.SetNRGBA(1, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+	img2.SetNRGBA(0, 0, color.NRGBA{R: 110, G: 100, B: 100, A: 255})
+	img2.SetNRGBA(1, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+
+	result, err := Diff(img1, img2, nil, WithExtraMetrics())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Metrics == nil {
+		t.Fatal("Expected extra metrics")
+	}
+
+	// 10^2 over 3 channels of 2 pixels
+	mse := 100.0 / 6
+	if math.Abs(result.Metrics.MSE-mse) > 1e-9 {
+		t.Errorf("Expected MSE %f, got - %f", mse, result.Metrics.MSE)
+	}
+	if psnr := 10 * math.Log10(255*255/mse); math.Abs(result.Metrics.PSNR-psnr) > 1e-9 {
+		t.Errorf("Expected PSNR %f, got - %f", psnr, result.Metrics.PSNR)
+	}
+
+	s := NewStreamDiffer(2, 1, WithExtraMetrics())
+	if err := s.WriteRows(img1.Pix, img2.Pix); err != nil {
+		t.Fatal(err)
+	}
+	streamed, err := s.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if streamed.Metrics == nil || *streamed.Metrics != *result.Metrics {
+		t.Errorf("Expected the stream to report %+v, got - %+v", *result.Metrics, streamed.Metrics)
+	}
+
+	result, err = Diff(img1, img2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Metrics != nil {
+		t.Errorf("Expected no extra metrics by default, got - %+v", *result.Metrics)
+	}
+}
+
+func TestExtraMetricsIdentical(t *testing.T) {
+	img := decodeTestImage(t, "./testdata/img1.png")
+
+	result, err := Diff(img, img, nil, WithExtraMetrics())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Metrics.MSE != 0 || !math.IsInf(result.Metrics.PSNR, 1) {
+		t.Errorf("Expected zero MSE and infinite PSNR, got - %+v", *result.Metrics)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"metrics":{"mse":0,"psnr":null}`) {
+		t.Errorf("Expected infinite PSNR encoded as null, got - %s", data)
+	}
+}
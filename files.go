@@ -0,0 +1,171 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DiffFiles decodes the images at pathA and pathB, compares them and,
+// unless outPath is empty, writes the diff image to outPath. The output format
+// follows the extension of outPath: .jpg/.jpeg, .gif or PNG otherwise.
+//
+// PNG, JPEG and GIF inputs are supported; WebP is added by building with
+// the webp tag. The images are decoded with Decode, so WithColorManagement
+// and WithAutoOrient apply.
+func DiffFiles(pathA, pathB, outPath string, opts ...Option) (DiffResult, error) {
+	imgA, err := DecodeFile(pathA, opts...)
+	if err != nil {
+		return DiffResult{}, err
+	}
+
+	imgB, err := DecodeFile(pathB, opts...)
+	if err != nil {
+		return DiffResult{}, err
+	}
+
+	if outPath == "" {
+		return Diff(imgA, imgB, nil, opts...)
+	}
+
+	output, result, err := DiffNew(imgA, imgB, opts...)
+	if err != nil {
+		return result, err
+	}
+
+	if err := EncodeFile(outPath, output); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// DiffReaders decodes both images from the readers, compares them and
+// returns the result together with the diff image. It supports the same
+// formats as DiffFiles.
+func DiffReaders(a, b io.Reader, opts ...Option) (DiffResult, image.Image, error) {
+	imgA, _, err := Decode(a, opts...)
+	if err != nil {
+		return DiffResult{}, nil, fmt.Errorf("decode first image: %w", err)
+	}
+
+	imgB, _, err := Decode(b, opts...)
+	if err != nil {
+		return DiffResult{}, nil, fmt.Errorf("decode second image: %w", err)
+	}
+
+	output, result, err := DiffNew(imgA, imgB, opts...)
+	if err != nil {
+		return result, nil, err
+	}
+
+	return result, output, nil
+}
+
+// Decode decodes an image like image.Decode and applies the options that
+// work on encoded images: WithColorManagement converts it to sRGB using its
+// embedded ICC profile and WithAutoOrient turns JPEG images as their EXIF
+// orientation tag says. Other options are ignored, so the options of a
+// comparison can be passed as they are.
+func Decode(r io.Reader, opts ...Option) (image.Image, string, error) {
+	options := newOptions(opts...)
+	if !options.autoOrient && !options.colorManagement {
+		return image.Decode(r)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, format, err
+	}
+
+	if options.colorManagement {
+		img = options.toSRGB(img, data, format)
+	}
+
+	if options.autoOrient && format == "jpeg" {
+		if o := jpegOrientation(data); o != OrientationNormal {
+			options.debug("applying EXIF orientation", "orientation", o)
+			img = Orient(img, o)
+		}
+	}
+
+	return img, format, nil
+}
+
+// toSRGB converts img to sRGB using the ICC profile embedded in data,
+// leaving it as it is without a supported profile.
+func (o *Options) toSRGB(img image.Image, data []byte, format string) image.Image {
+	icc, err := embeddedProfile(data, format)
+	if err == nil && icc == nil {
+		return img
+	}
+
+	var profile *ColorProfile
+	if err == nil {
+		profile, err = ParseICCProfile(icc)
+	}
+	if err != nil {
+		o.debug("ignoring ICC profile", "error", err)
+		return img
+	}
+	if profile.isSRGB() {
+		return img
+	}
+
+	o.debug("converting to sRGB")
+	return ConvertToSRGB(img, profile)
+}
+
+// DecodeFile decodes the image at path with Decode, so the formats and the
+// options of DiffFiles apply.
+func DecodeFile(path string, opts ...Option) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := Decode(f, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	return img, nil
+}
+
+// EncodeFile writes img to path in the format its extension names like the
+// diff images of DiffFiles: .jpg/.jpeg, .gif or PNG otherwise.
+func EncodeFile(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		err = jpeg.Encode(f, img, &jpeg.Options{Quality: 95})
+	case ".gif":
+		err = gif.Encode(f, img, nil)
+	default:
+		err = png.Encode(f, img)
+	}
+
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("encode %s: %w", path, err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,92 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	img1 := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	for x := 10; x < 20; x++ {
+		img2.SetNRGBA(x, 5, color.NRGBA{R: 255, A: 255})
+	}
+
+	path1, path2 := filepath.Join(dir, "a.png"), filepath.Join(dir, "b.png")
+	for path, img := range map[string]image.Image{path1: img1, path2: img2} {
+		if err := EncodeFile(path, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"diff.png", "diff.jpg", "diff.gif", ""} {
+		outPath := ""
+		if name != "" {
+			outPath = filepath.Join(dir, name)
+		}
+
+		result, err := DiffFiles(path1, path2, outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.DiffPixels != 10 {
+			t.Errorf("%q: expected 10, got - %d", name, result.DiffPixels)
+		}
+
+		if outPath == "" {
+			continue
+		}
+
+		f, err := os.Open(outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg, _, err := image.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%q: %v", name, err)
+		}
+		if cfg.Width != 40 || cfg.Height != 30 {
+			t.Errorf("%q: unexpected size %dx%d", name, cfg.Width, cfg.Height)
+		}
+	}
+
+	if _, err := DiffFiles(filepath.Join(dir, "missing.png"), path2, ""); !os.IsNotExist(err) {
+		t.Errorf("Expected not exist error, got - %v", err)
+	}
+}
+
+func TestDiffReaders(t *testing.T) {
+	fileA, err := os.Open("./testdata/img1.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fileA.Close()
+
+	fileB, err := os.Open("./testdata/img2.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fileB.Close()
+
+	result, output, err := DiffReaders(fileA, fileB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels == 0 {
+		t.Error("Expected different pixels")
+	}
+	if output == nil || output.Bounds().Dx() != 2880 {
+		t.Errorf("Unexpected output %v", output)
+	}
+
+	if _, _, err := DiffReaders(strings.NewReader("not an image"), fileB); err == nil {
+		t.Error("Expected decode error")
+	}
+}
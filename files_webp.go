@@ -0,0 +1,6 @@
+//go:build webp
+
+package pixelmatch
+
+// registers the WebP decoder for DiffFiles and DiffReaders
+import _ "golang.org/x/image/webp"
@@ -0,0 +1,87 @@
+package pixelmatch
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fixturesDir holds pairs of rendered images and their diff images as drawn
+// by this package, see its README.
+const fixturesDir = "testdata/fixtures"
+
+// fixtureCases are modeled on the diffTest cases of the test suite of the
+// JavaScript pixelmatch library, but run on images generated by
+// fixturesDir/gen.go: the counts are those this package found when they
+// were generated, not those of the library.
+var fixtureCases = []struct {
+	a, b, diff string
+	opts       []Option
+	want       uint64
+}{
+	{"1a", "1b", "1diff", []Option{WithThreshold(0.05)}, 155},
+	{"1a", "1b", "1diffdefaultthreshold", nil, 142},
+	{"1a", "1b", "1diffmask", []Option{WithThreshold(0.05), WithIncludeAA(false), WithDiffMask(true)}, 155},
+	{"1a", "1a", "1emptydiffmask", []Option{WithThreshold(0), WithDiffMask(true)}, 0},
+	{"2a", "2b", "2diff", []Option{
+		WithThreshold(0.05),
+		WithAlpha(0.5),
+		WithAAColor(color.NRGBA{G: 192, A: 255}),
+		WithDiffColor(color.NRGBA{R: 255, B: 255, A: 255}),
+	}, 1610},
+	{"3a", "3b", "3diff", []Option{WithThreshold(0.05)}, 186},
+	{"4a", "4b", "4diff", []Option{WithThreshold(0.05)}, 8705},
+	{"5a", "5b", "5diff", []Option{WithThreshold(0.05)}, 0},
+	{"6a", "6b", "6diff", []Option{WithThreshold(0.05)}, 12},
+	{"6a", "6a", "6empty", []Option{WithThreshold(0)}, 0},
+	{"7a", "7b", "7diff", []Option{WithDiffColorAlt(color.NRGBA{G: 255, A: 255})}, 6000},
+	{"8a", "5b", "8diff", []Option{WithThreshold(0.05)}, 18172},
+}
+
+// TestFixtureSnapshots is a regression snapshot: it checks the pixel counts
+// and the diff images, pixel for pixel, against those recorded in
+// fixturesDir.
+func TestFixtureSnapshots(t *testing.T) {
+	for _, tc := range fixtureCases {
+		tc := tc
+		t.Run(tc.diff, func(t *testing.T) {
+			paths := []string{tc.a, tc.b, tc.diff}
+			for i, name := range paths {
+				paths[i] = filepath.Join(fixturesDir, name+".png")
+				if _, err := os.Stat(paths[i]); err != nil {
+					t.Fatalf("%v, see %s/README.md", err, fixturesDir)
+				}
+			}
+
+			opts := append([]Option{WithDiffMask(false)}, tc.opts...)
+			output, result, err := DiffNew(decodeTestImage(t, paths[0]), decodeTestImage(t, paths[1]), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.DiffPixels != tc.want {
+				t.Errorf("Expected %d different pixels, got - %d", tc.want, result.DiffPixels)
+			}
+
+			want := toNRGBA(decodeTestImage(t, paths[2]))
+			if !want.Rect.Eq(output.Rect) {
+				t.Fatalf("Expected a diff image of %v, got - %v", want.Rect, output.Rect)
+			}
+
+			var mismatched int
+			for y := want.Rect.Min.Y; y < want.Rect.Max.Y; y++ {
+				for x := want.Rect.Min.X; x < want.Rect.Max.X; x++ {
+					if got, exp := output.NRGBAAt(x, y), want.NRGBAAt(x, y); got != exp {
+						if mismatched == 0 {
+							t.Errorf("Expected %v at (%d,%d), got - %v", exp, x, y, got)
+						}
+						mismatched++
+					}
+				}
+			}
+			if mismatched > 0 {
+				t.Errorf("%d pixels of the diff image differ from %s", mismatched, paths[2])
+			}
+		})
+	}
+}
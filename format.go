@@ -0,0 +1,124 @@
+package pixelmatch
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TextStyle selects the layout of the summaries written by DiffResult.Format.
+type TextStyle int
+
+const (
+	// TextLine is a single line such as "0.42% different, 18,233 px,
+	// largest cluster 120×48 at (312,88)", e.g. for logs and the CLI.
+	TextLine TextStyle = iota
+
+	// TextDetailed lists the counts, the bounding box, the clusters, the
+	// outcome and the time of the comparison on separate lines.
+	TextDetailed
+
+	// TextMarkdown is the detailed summary as a Markdown list under a bold
+	// headline, e.g. for chat-bot notifications and pull request comments.
+	TextMarkdown
+)
+
+// String returns the name of the style.
+func (s TextStyle) String() string {
+	switch s {
+	case TextLine:
+		return "line"
+	case TextDetailed:
+		return "detailed"
+	case TextMarkdown:
+		return "markdown"
+	default:
+		return "unknown"
+	}
+}
+
+// String returns the single line summary of the result, see TextLine.
+func (r DiffResult) String() string {
+	var b strings.Builder
+	r.Format(&b, TextLine)
+
+	return b.String()
+}
+
+// Format writes a human-friendly summary of the result in the given style.
+// The largest cluster is named only with WithClusters, the outcome only
+// when the result carries the options of its comparison.
+func (r DiffResult) Format(w io.Writer, style TextStyle) error {
+	headline := fmt.Sprintf("%.2f%% different", r.Percent)
+
+	var largest string
+	if len(r.Clusters) > 0 {
+		c := r.Clusters[0]
+		largest = fmt.Sprintf("%s, %s px", rectText(c.Bounds), groupDigits(c.Pixels))
+	}
+
+	if style == TextLine {
+		line := fmt.Sprintf("%s, %s px", headline, groupDigits(r.DiffPixels))
+		switch {
+		case largest != "":
+			line += ", largest cluster " + rectText(r.Clusters[0].Bounds)
+		case r.DiffPixels > 0:
+			line += " in " + rectText(r.Bounds)
+		}
+		_, err := io.WriteString(w, line)
+		return err
+	}
+
+	lines := []string{
+		fmt.Sprintf("different: %s of %s px", groupDigits(r.DiffPixels), groupDigits(r.TotalPixels)),
+		fmt.Sprintf("anti-aliased: %s px", groupDigits(r.AAPixels)),
+	}
+	if r.DiffPixels > 0 {
+		lines = append(lines, "bounds: "+rectText(r.Bounds))
+	}
+	if largest != "" {
+		lines = append(lines, fmt.Sprintf("clusters: %d, largest %s", len(r.Clusters), largest))
+	}
+	if r.options != nil {
+		outcome := "failed"
+		if r.Passed() {
+			outcome = "passed"
+		}
+		lines = append(lines, "outcome: "+outcome)
+	}
+	lines = append(lines, "elapsed: "+r.Elapsed.String())
+
+	var b strings.Builder
+	switch style {
+	case TextMarkdown:
+		b.WriteString("**" + headline + "**\n\n")
+		for _, line := range lines {
+			b.WriteString("- " + line + "\n")
+		}
+	default:
+		b.WriteString(headline + "\n")
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// rectText formats r as its size and top-left corner, e.g. "120×48 at (312,88)".
+func rectText(r image.Rectangle) string {
+	return fmt.Sprintf("%d×%d at (%d,%d)", r.Dx(), r.Dy(), r.Min.X, r.Min.Y)
+}
+
+// groupDigits formats n with commas between groups of three digits.
+func groupDigits(n uint64) string {
+	s := strconv.FormatUint(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+
+	return s
+}
@@ -0,0 +1,54 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestDiffResultFormat(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+	for y := 20; y < 30; y++ {
+		for x := 10; x < 50; x++ {
+			img2.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	img2.SetNRGBA(90, 90, color.NRGBA{R: 255, A: 255})
+
+	result, err := Diff(img1, img2, nil, WithClusters(1), WithFailureThreshold(500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := result.String(), "4.01% different, 401 px, largest cluster 40×10 at (10,20)"; got != want {
+		t.Errorf("Expected %q, got - %q", want, got)
+	}
+
+	var b strings.Builder
+	if err := result.Format(&b, TextMarkdown); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"**4.01% different**\n\n",
+		"- different: 401 of 10,000 px\n",
+		"- bounds: 81×71 at (10,20)\n",
+		"- clusters: 2, largest 40×10 at (10,20), 400 px\n",
+		"- outcome: passed\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected %q in the summary, got - %s", want, b.String())
+		}
+	}
+
+	result, _ = Diff(img1, img2, nil)
+	if got, want := result.String(), "4.01% different, 401 px in 81×71 at (10,20)"; got != want {
+		t.Errorf("Expected %q, got - %q", want, got)
+	}
+
+	for n, want := range map[uint64]string{0: "0", 999: "999", 1000: "1,000", 18233: "18,233", 1234567: "1,234,567"} {
+		if got := groupDigits(n); got != want {
+			t.Errorf("%d: expected %q, got - %q", n, want, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package pixelmatch
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// fuzzImages builds two small images from the fuzzer's bytes: the first one
+// from pix repeated as needed and the second one from it with the bytes of
+// changes xor-ed in, so both random and nearly equal pairs come up.
+func fuzzImages(w, h uint8, pix, changes []byte) (a, b *image.NRGBA) {
+	rect := image.Rect(0, 0, int(w%12)+1, int(h%12)+1)
+	a, b = image.NewNRGBA(rect), image.NewNRGBA(rect)
+
+	if len(pix) > 0 {
+		for i := range a.Pix {
+			a.Pix[i] = pix[i%len(pix)]
+		}
+	}
+	copy(b.Pix, a.Pix)
+	for i, c := range changes {
+		if i < len(b.Pix) {
+			b.Pix[i] ^= c
+		}
+	}
+
+	return a, b
+}
+
+func FuzzColorDelta(f *testing.F) {
+	f.Add([]byte{255, 255, 255, 255}, []byte{0, 0, 0, 255})
+	f.Add([]byte{10, 200, 30, 0}, []byte{10, 200, 31, 128})
+
+	f.Fuzz(func(t *testing.T, b1, b2 []byte) {
+		var c1, c2 [4]uint8
+		copy(c1[:], b1)
+		copy(c2[:], b2)
+
+		if d := colorDelta(c1, c1, false); d != 0 {
+			t.Fatalf("Expected no delta of %v with itself, got - %v", c1, d)
+		}
+
+		d12, d21 := colorDelta(c1, c2, false), colorDelta(c2, c1, false)
+		if math.Abs(d12) != math.Abs(d21) || math.Abs(d12) > MetricYIQ.MaxDelta() {
+			t.Fatalf("Expected symmetric deltas within %v for %v and %v, got - %v, %v", MetricYIQ.MaxDelta(), c1, c2, d12, d21)
+		}
+
+		y12, y21 := colorDelta(c1, c2, true), colorDelta(c2, c1, true)
+		if y12 != -y21 && !(y12 == 0 && y21 == 0) {
+			t.Fatalf("Expected opposite brightness deltas for %v and %v, got - %v, %v", c1, c2, y12, y21)
+		}
+	})
+}
+
+func FuzzAntialiased(f *testing.F) {
+	f.Add(uint8(0), uint8(0), []byte{1, 2, 3, 255}, []byte{0, 0, 255})
+	f.Add(uint8(0), uint8(7), []byte{255, 255, 255, 255, 0, 0, 0, 255}, []byte{})
+	f.Add(uint8(9), uint8(5), []byte{128, 0, 64, 255, 255, 255, 255, 255, 0}, []byte{0, 0, 0, 0, 255, 255, 255})
+
+	f.Fuzz(func(t *testing.T, w, h uint8, pix, changes []byte) {
+		a, b := fuzzImages(w, h, pix, changes)
+
+		for y := a.Rect.Min.Y; y < a.Rect.Max.Y; y++ {
+			for x := a.Rect.Min.X; x < a.Rect.Max.X; x++ {
+				antialiased(a, b, x, y, a.Rect)
+			}
+		}
+
+		same, err := Diff(a, a, nil, WithDiffMask(false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if same.DiffPixels != 0 || same.AAPixels != 0 {
+			t.Fatalf("Expected no differences of an image with itself, got - %+v", same)
+		}
+
+		ab, err := Diff(a, b, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ba, err := Diff(b, a, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ab.DiffPixels != ba.DiffPixels || ab.AAPixels != ba.AAPixels {
+			t.Fatalf("Expected the same counts both ways, got - %d/%d, %d/%d", ab.DiffPixels, ab.AAPixels, ba.DiffPixels, ba.AAPixels)
+		}
+
+		count, err := Compare(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != ab.DiffPixels {
+			t.Fatalf("Expected Compare to agree with Diff on %d pixels, got - %d", ab.DiffPixels, count)
+		}
+	})
+}
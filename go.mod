@@ -1,3 +1,9 @@
 module github.com/inotnako/pixelmatch-go
 
 go 1.19
+
+require (
+	golang.org/x/image v0.18.0
+	golang.org/x/sys v0.20.0
+	gopkg.in/yaml.v3 v3.0.1
+)
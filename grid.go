@@ -0,0 +1,136 @@
+package pixelmatch
+
+import (
+	"image"
+	"sync/atomic"
+)
+
+// WithGrid partitions the compared area into cols×rows cells and reports
+// the diff statistics of every cell in DiffResult.Grid.
+func WithGrid(cols, rows int) Option {
+	return func(o *Options) {
+		o.gridCols = cols
+		o.gridRows = rows
+	}
+}
+
+// GridCell holds the diff statistics of a single grid cell.
+type GridCell struct {
+	// area of the cell
+	Bounds image.Rectangle `json:"bounds"`
+
+	// number of different pixels in the cell
+	DiffPixels uint64 `json:"diffPixels"`
+
+	// number of compared pixels in the cell
+	TotalPixels uint64 `json:"totalPixels"`
+
+	// share of different pixels in the cell, from 0 to 100
+	Percent float64 `json:"percent"`
+}
+
+// DiffGrid is a coarse heatmap of the differences.
+type DiffGrid struct {
+	// number of cells per row and per column
+	Cols int `json:"cols"`
+	Rows int `json:"rows"`
+
+	// cells laid out row by row
+	Cells []GridCell `json:"cells"`
+}
+
+// At returns the cell in the given column and row.
+func (g *DiffGrid) At(col, row int) GridCell {
+	return g.Cells[row*g.Cols+col]
+}
+
+// diffGrid counts different pixels per cell while comparing.
+// A nil *diffGrid counts nothing.
+type diffGrid struct {
+	rect       image.Rectangle
+	cols, rows int
+	diff       []uint64
+}
+
+func newDiffGrid(rect image.Rectangle, options *Options) *diffGrid {
+	cols, rows := options.gridCols, options.gridRows
+	if cols <= 0 || rows <= 0 {
+		return nil
+	}
+
+	// a cell is at least one pixel wide and high
+	if cols > rect.Dx() {
+		cols = rect.Dx()
+	}
+	if rows > rect.Dy() {
+		rows = rect.Dy()
+	}
+
+	return &diffGrid{
+		rect: rect,
+		cols: cols,
+		rows: rows,
+		diff: make([]uint64, cols*rows),
+	}
+}
+
+func (g *diffGrid) markDiff(x, y int) {
+	if g == nil {
+		return
+	}
+
+	col := (x - g.rect.Min.X) * g.cols / g.rect.Dx()
+	row := (y - g.rect.Min.Y) * g.rows / g.rect.Dy()
+	atomic.AddUint64(&g.diff[row*g.cols+col], 1)
+}
+
+func (g *diffGrid) cellBounds(col, row int) image.Rectangle {
+	return image.Rect(
+		g.rect.Min.X+g.rect.Dx()*col/g.cols,
+		g.rect.Min.Y+g.rect.Dy()*row/g.rows,
+		g.rect.Min.X+g.rect.Dx()*(col+1)/g.cols,
+		g.rect.Min.Y+g.rect.Dy()*(row+1)/g.rows,
+	)
+}
+
+// result builds the reported grid; ignored pixels are not counted as compared.
+func (g *diffGrid) result(ignore *ignoreMap) *DiffGrid {
+	if g == nil {
+		return nil
+	}
+
+	grid := &DiffGrid{
+		Cols:  g.cols,
+		Rows:  g.rows,
+		Cells: make([]GridCell, 0, g.cols*g.rows),
+	}
+
+	for row := 0; row < g.rows; row++ {
+		for col := 0; col < g.cols; col++ {
+			bounds := g.cellBounds(col, row)
+			cell := GridCell{
+				Bounds:      bounds,
+				DiffPixels:  atomic.LoadUint64(&g.diff[row*g.cols+col]),
+				TotalPixels: uint64(bounds.Dx() * bounds.Dy()),
+			}
+
+			if ignore != nil {
+				for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+					for x := bounds.Min.X; x < bounds.Max.X; x++ {
+						if ignore.ignored(x, y) {
+							cell.TotalPixels--
+						}
+					}
+				}
+			}
+
+			if cell.TotalPixels > 0 {
+				cell.Percent = float64(cell.DiffPixels) * 100 / float64(cell.TotalPixels)
+			}
+
+			grid.Cells = append(grid.Cells, cell)
+		}
+	}
+
+	return grid
+}
@@ -0,0 +1,48 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDiffGrid(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img2.SetNRGBA(1, 1, color.NRGBA{R: 255, A: 255})
+	img2.SetNRGBA(2, 2, color.NRGBA{R: 255, A: 255})
+	img2.SetNRGBA(8, 7, color.NRGBA{R: 255, A: 255})
+
+	result, err := Diff(img1, img2, nil,
+		WithGrid(2, 2),
+		WithIgnoreRegions(image.Rect(5, 0, 10, 1)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grid := result.Grid
+	if grid == nil || grid.Cols != 2 || grid.Rows != 2 {
+		t.Fatalf("Unexpected grid %+v", grid)
+	}
+
+	for _, tc := range []struct {
+		col, row    int
+		diff, total uint64
+		bounds      image.Rectangle
+	}{
+		{0, 0, 2, 25, image.Rect(0, 0, 5, 5)},
+		{1, 0, 0, 20, image.Rect(5, 0, 10, 5)},
+		{0, 1, 0, 25, image.Rect(0, 5, 5, 10)},
+		{1, 1, 1, 25, image.Rect(5, 5, 10, 10)},
+	} {
+		cell := grid.At(tc.col, tc.row)
+		if cell.DiffPixels != tc.diff || cell.TotalPixels != tc.total || !cell.Bounds.Eq(tc.bounds) {
+			t.Errorf("cell %d,%d: unexpected %+v", tc.col, tc.row, cell)
+		}
+	}
+
+	if grid.At(0, 0).Percent != 8 {
+		t.Errorf("Expected 8%%, got - %v", grid.At(0, 0).Percent)
+	}
+}
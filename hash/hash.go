@@ -0,0 +1,289 @@
+// Package hash computes perceptual hashes of images, which stay close for
+// images that look alike, and uses them to triage pairs of images before an
+// expensive pixel diff in high-volume pipelines.
+package hash
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"math"
+	"math/bits"
+	"sort"
+	"sync"
+)
+
+// Hash is a 64-bit perceptual hash of an image.
+type Hash uint64
+
+// Distance returns the number of bits that differ in h and o (the Hamming
+// distance), from 0 for images that look alike to 64.
+func (h Hash) Distance(o Hash) int {
+	return bits.OnesCount64(uint64(h ^ o))
+}
+
+// String returns the hash as 16 hex digits.
+func (h Hash) String() string {
+	return fmt.Sprintf("%016x", uint64(h))
+}
+
+// DHash returns the difference hash of img: every bit tells whether
+// a cell of a 9x8 grayscale thumbnail is brighter than the next one in
+// its row. It is the cheapest hash and robust to brightness changes.
+func DHash(img image.Image) Hash {
+	const w, h = 9, 8
+	gray := thumbnail(img, w, h)
+
+	var hash Hash
+	for y := 0; y < h; y++ {
+		for x := 0; x < w-1; x++ {
+			hash <<= 1
+			if gray[y*w+x] > gray[y*w+x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+// dctSize is the side of the thumbnail PHash transforms,
+// phashSize the side of the block of low frequencies it keeps.
+const (
+	dctSize   = 32
+	phashSize = 8
+)
+
+// PHash returns the DCT-based perceptual hash of img: every bit tells
+// whether one of the 8x8 lowest frequencies of a 32x32 grayscale thumbnail
+// is above their median. It tolerates scaling, compression and small
+// edits better than DHash.
+func PHash(img image.Image) Hash {
+	gray := thumbnail(img, dctSize, dctSize)
+	coeffs := lowFrequencies(gray)
+
+	// the DC term is the mean brightness, left out of the median
+	sorted := append([]float64(nil), coeffs[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var hash Hash
+	for _, c := range coeffs {
+		hash <<= 1
+		if c > median {
+			hash |= 1
+		}
+	}
+
+	return hash
+}
+
+var (
+	dctOnce  sync.Once
+	dctTable [phashSize][dctSize]float64
+)
+
+// lowFrequencies returns the top-left phashSize x phashSize coefficients
+// of the two-dimensional DCT-II of a dctSize x dctSize plane, row by row.
+func lowFrequencies(gray []float64) []float64 {
+	dctOnce.Do(func() {
+		for u := range dctTable {
+			for x := range dctTable[u] {
+				dctTable[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * dctSize))
+			}
+		}
+	})
+
+	// transform the rows, then the columns of the kept frequencies
+	var rows [dctSize][phashSize]float64
+	for y := 0; y < dctSize; y++ {
+		for u := 0; u < phashSize; u++ {
+			var sum float64
+			for x := 0; x < dctSize; x++ {
+				sum += gray[y*dctSize+x] * dctTable[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+
+	coeffs := make([]float64, 0, phashSize*phashSize)
+	for v := 0; v < phashSize; v++ {
+		for u := 0; u < phashSize; u++ {
+			var sum float64
+			for y := 0; y < dctSize; y++ {
+				sum += rows[y][u] * dctTable[v][y]
+			}
+			coeffs = append(coeffs, sum)
+		}
+	}
+
+	return coeffs
+}
+
+// samplesPerCell is how many pixels per row and column of a thumbnail cell
+// are averaged, so hashing costs the same for any image size.
+const samplesPerCell = 4
+
+// thumbnail returns the brightness of img blended with white, averaged
+// over a w x h grid of cells covering the image, from 0 to 255.
+func thumbnail(img image.Image, w, h int) []float64 {
+	r := img.Bounds()
+	gray := make([]float64, w*h)
+	if r.Empty() {
+		return gray
+	}
+
+	luma := lumaFunc(img)
+
+	for cy := 0; cy < h; cy++ {
+		for cx := 0; cx < w; cx++ {
+			var sum float64
+			for sy := 0; sy < samplesPerCell; sy++ {
+				y := r.Min.Y + int((float64(cy)+(float64(sy)+0.5)/samplesPerCell)*float64(r.Dy())/float64(h))
+				for sx := 0; sx < samplesPerCell; sx++ {
+					x := r.Min.X + int((float64(cx)+(float64(sx)+0.5)/samplesPerCell)*float64(r.Dx())/float64(w))
+					sum += luma(x, y)
+				}
+			}
+			gray[cy*w+cx] = sum / (samplesPerCell * samplesPerCell)
+		}
+	}
+
+	return gray
+}
+
+// lumaFunc returns a function returning the brightness of the pixel
+// at x, y of img blended with white, from 0 to 255.
+func lumaFunc(img image.Image) func(x, y int) float64 {
+	switch img := img.(type) {
+	case *image.NRGBA:
+		return func(x, y int) float64 {
+			p := img.Pix[img.PixOffset(x, y):][:4]
+			a := float64(p[3]) / 255
+			white := 255 * (1 - a)
+
+			return 0.299*(float64(p[0])*a+white) + 0.587*(float64(p[1])*a+white) + 0.114*(float64(p[2])*a+white)
+		}
+	case *image.RGBA:
+		return func(x, y int) float64 {
+			p := img.Pix[img.PixOffset(x, y):][:4]
+			white := float64(255 - p[3])
+
+			return 0.299*(float64(p[0])+white) + 0.587*(float64(p[1])+white) + 0.114*(float64(p[2])+white)
+		}
+	default:
+		return func(x, y int) float64 {
+			// premultiplied channels blended with white are c + (1-a) * white
+			r, g, b, a := img.At(x, y).RGBA()
+			white := float64(0xffff - a)
+
+			return (0.299*(float64(r)+white) + 0.587*(float64(g)+white) + 0.114*(float64(b)+white)) / 257
+		}
+	}
+}
+
+// Verdict is the outcome of QuickCompare.
+type Verdict int
+
+const (
+	// Identical images have the same bounds and pixels.
+	Identical Verdict = iota
+
+	// Similar images look alike according to their hashes,
+	// only a pixel diff tells whether and where they differ.
+	Similar
+
+	// Different images differ in size or look different
+	// according to their hashes.
+	Different
+)
+
+// String returns the name of the verdict.
+func (v Verdict) String() string {
+	switch v {
+	case Identical:
+		return "identical"
+	case Similar:
+		return "similar"
+	case Different:
+		return "different"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultMaxDistance is the largest distance of the PHash of two images
+// QuickCompare considers similar.
+const DefaultMaxDistance = 10
+
+// QuickCompare triages a pair of images before a pixel diff. It returns
+// Different right away for images of different sizes or with PHash values
+// more than DefaultMaxDistance apart, Identical for images with the same
+// pixels and Similar otherwise, meaning the pixel diff has to decide.
+// Pixels are compared exactly only for images of the same type
+// from the image package.
+func QuickCompare(a, b image.Image) Verdict {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return Different
+	}
+
+	if PHash(a).Distance(PHash(b)) > DefaultMaxDistance {
+		return Different
+	}
+
+	if samePixels(a, b) {
+		return Identical
+	}
+
+	return Similar
+}
+
+// samePixels reports whether a and b store the same pixels,
+// false when it cannot tell without decoding every pixel.
+func samePixels(a, b image.Image) bool {
+	if a.ColorModel() != b.ColorModel() {
+		return false
+	}
+
+	pixA, size, okA := pixData(a)
+	pixB, _, okB := pixData(b)
+	if !okA || !okB {
+		return false
+	}
+
+	var (
+		ra, rb = a.Bounds(), b.Bounds()
+		offA   = a.(interface{ PixOffset(x, y int) int }).PixOffset
+		offB   = b.(interface{ PixOffset(x, y int) int }).PixOffset
+		rowLen = ra.Dx() * size
+	)
+	for y := 0; y < ra.Dy(); y++ {
+		i, j := offA(ra.Min.X, ra.Min.Y+y), offB(rb.Min.X, rb.Min.Y+y)
+		if !bytes.Equal(pixA[i:i+rowLen], pixB[j:j+rowLen]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pixData returns the pixel bytes of the image types of the image package
+// along with the size of a pixel in bytes.
+func pixData(img image.Image) (pix []uint8, size int, ok bool) {
+	switch img := img.(type) {
+	case *image.NRGBA:
+		return img.Pix, 4, true
+	case *image.RGBA:
+		return img.Pix, 4, true
+	case *image.NRGBA64:
+		return img.Pix, 8, true
+	case *image.RGBA64:
+		return img.Pix, 8, true
+	case *image.Gray:
+		return img.Pix, 1, true
+	case *image.Gray16:
+		return img.Pix, 2, true
+	default:
+		return nil, 0, false
+	}
+}
@@ -0,0 +1,130 @@
+package hash
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/png"
+	"os"
+	"testing"
+)
+
+func decodeImage(tb testing.TB, path string) image.Image {
+	tb.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return img
+}
+
+// gradient returns an image getting brighter from left to right,
+// or from right to left when reversed.
+func gradient(w, h int, reversed bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / (w - 1))
+			if reversed {
+				v = 255 - v
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v / 2, B: uint8(y), A: 255})
+		}
+	}
+
+	return img
+}
+
+func copyImage(img image.Image) *image.NRGBA {
+	dst := image.NewNRGBA(img.Bounds())
+	draw.Draw(dst, dst.Rect, img, img.Bounds().Min, draw.Src)
+
+	return dst
+}
+
+func TestHashDistance(t *testing.T) {
+	img1 := decodeImage(t, "../testdata/img1.png")
+	img2 := decodeImage(t, "../testdata/img2.png")
+	other := gradient(img1.Bounds().Dx(), img1.Bounds().Dy(), true)
+
+	for name, hash := range map[string]func(image.Image) Hash{"dHash": DHash, "pHash": PHash} {
+		if d := hash(img1).Distance(hash(copyImage(img1))); d != 0 {
+			t.Errorf("%s: expected the same hash for a converted copy, got distance %d", name, d)
+		}
+
+		similar := hash(img1).Distance(hash(img2))
+		different := hash(img1).Distance(hash(other))
+		if similar > DefaultMaxDistance || similar >= different {
+			t.Errorf("%s: expected similar images closer than different ones, got distances %d and %d", name, similar, different)
+		}
+	}
+}
+
+func TestHashString(t *testing.T) {
+	if s := Hash(0xabc).String(); s != "0000000000000abc" {
+		t.Errorf("Expected 16 hex digits, got - %s", s)
+	}
+	if d := Hash(0b1011).Distance(0b0110); d != 3 {
+		t.Errorf("Expected distance 3, got - %d", d)
+	}
+}
+
+func TestQuickCompare(t *testing.T) {
+	img := copyImage(decodeImage(t, "../testdata/img1.png"))
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	changed := copyImage(img)
+	changed.SetNRGBA(10, 10, color.NRGBA{R: 255, A: 255})
+
+	tests := []struct {
+		name string
+		a, b image.Image
+		want Verdict
+	}{
+		{"copy", img, copyImage(img), Identical},
+		{"sub-image", img.SubImage(image.Rect(0, 0, 32, 24)), copyImage(img.SubImage(image.Rect(0, 0, 32, 24))), Identical},
+		{"one pixel", img, changed, Similar},
+		{"blank", img, image.NewRGBA(img.Rect), Different},
+		{"other image", img, gradient(w, h, true), Different},
+		{"size", img, copyImage(img.SubImage(image.Rect(0, 0, w, h-1))), Different},
+	}
+
+	for _, tc := range tests {
+		if got := QuickCompare(tc.a, tc.b); got != tc.want {
+			t.Errorf("%s: expected %s, got - %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestLumaFunc(t *testing.T) {
+	c := color.NRGBA{R: 200, G: 100, B: 50, A: 128}
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	nrgba.SetNRGBA(0, 0, c)
+	rgba := image.NewRGBA(nrgba.Rect)
+	rgba.Set(0, 0, c)
+	paletted := image.NewPaletted(nrgba.Rect, color.Palette{c})
+
+	want := lumaFunc(paletted)(0, 0)
+	for _, img := range []image.Image{nrgba, rgba} {
+		if got := lumaFunc(img)(0, 0); got < want-1 || got > want+1 {
+			t.Errorf("%T: expected brightness %.1f, got - %.1f", img, want, got)
+		}
+	}
+}
+
+func BenchmarkQuickCompare4K(b *testing.B) {
+	img1, img2 := gradient(3840, 2160, false), gradient(3840, 2160, false)
+	img2.SetNRGBA(100, 100, color.NRGBA{A: 255})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		QuickCompare(img1, img2)
+	}
+}
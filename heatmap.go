@@ -0,0 +1,37 @@
+package pixelmatch
+
+import (
+	"image/color"
+	"math"
+)
+
+// WithHeatmap draws every different pixel in a color from blue to red
+// by the size of its delta: blue for a pixel just above the threshold,
+// red for the largest possible difference, so reviewers tell rendering
+// noise from gross changes at a glance. WithRenderFunc takes precedence.
+func WithHeatmap() Option {
+	return func(o *Options) {
+		o.heatmap = true
+	}
+}
+
+// heatColor returns the heatmap color of a different pixel.
+func (o *Options) heatColor(delta float64) color.NRGBA {
+	maxDelta := o.metric.MaxDelta()
+	if o.ignoreColors {
+		maxDelta = MetricYIQ.MaxDelta()
+	}
+
+	// the threshold is relative to the square root of the delta
+	t := 1.0
+	if o.threshold < 1 {
+		t = (math.Sqrt(math.Abs(delta)/maxDelta) - o.threshold) / (1 - o.threshold)
+	}
+	t = math.Max(0, math.Min(t, 1))
+
+	return color.NRGBA{
+		R: clampUint8(255 * t),
+		B: clampUint8(255 * (1 - t)),
+		A: 255,
+	}
+}
@@ -0,0 +1,53 @@
+package pixelmatch
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestWithHeatmap(t *testing.T) {
+	base := patchedImage(255)
+
+	// a small and a large change of the patch from white
+	slight, _, err := DiffNew(base, patchedImage(200), WithHeatmap(), WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gross, _, err := DiffNew(base, patchedImage(0), WithHeatmap(), WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, g := slight.NRGBAAt(7, 7), gross.NRGBAAt(7, 7)
+	if s.B <= s.R || g.R <= g.B || s.A != 255 || g.A != 255 {
+		t.Errorf("Expected a blue slight and a red gross change, got - %v, %v", s, g)
+	}
+	if got := slight.NRGBAAt(0, 0); got.A != 0 {
+		t.Errorf("Expected an unchanged pixel transparent, got - %v", got)
+	}
+}
+
+func TestHeatColor(t *testing.T) {
+	options := newOptions(WithThreshold(0.2))
+	max := MetricYIQ.MaxDelta()
+
+	tests := []struct {
+		delta float64
+		want  color.NRGBA
+	}{
+		{max * 0.2 * 0.2, color.NRGBA{B: 255, A: 255}},
+		{-max * 0.4 * 0.4, color.NRGBA{R: 64, B: 191, A: 255}},
+		{max, color.NRGBA{R: 255, A: 255}},
+	}
+
+	for _, tc := range tests {
+		if got := options.heatColor(tc.delta); got != tc.want {
+			t.Errorf("Expected %v for delta %.0f, got - %v", tc.want, tc.delta, got)
+		}
+	}
+
+	options = newOptions(WithThreshold(1))
+	if got := options.heatColor(max); got != (color.NRGBA{R: 255, A: 255}) {
+		t.Errorf("Expected red at threshold 1, got - %v", got)
+	}
+}
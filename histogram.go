@@ -0,0 +1,91 @@
+package pixelmatch
+
+import (
+	"image"
+	"math"
+)
+
+// histogramBins is the number of bins per color channel,
+// the histograms have histogramBins^3 bins.
+const histogramBins = 8
+
+// HistogramResult describes how close the color histograms of two images are.
+type HistogramResult struct {
+	// Bhattacharyya coefficient of the histograms, from 0 for images without
+	// a common color to 1 for images of the same color distribution
+	Similarity float64 `json:"similarity"`
+
+	// symmetric chi-square distance of the histograms, from 0 for
+	// images of the same color distribution to 1
+	ChiSquare float64 `json:"chiSquare"`
+}
+
+// CompareHistograms compares the color distributions of img1 and img2
+// rather than their pixels, which makes the result robust to translations,
+// small rotations and different sizes; a cheap triage metric for
+// deduplicating images. Colors are blended with white by their alpha, so
+// fully transparent pixels count as white. WithRegion, WithIgnoreRegions and
+// WithIgnoreMask limit the pixels counted, the other options are ignored.
+func CompareHistograms(img1, img2 image.Image, opts ...Option) (HistogramResult, error) {
+	if err := checkEmptyImages(img1, img2); err != nil {
+		return HistogramResult{}, err
+	}
+
+	options := newOptions(opts...)
+	h1, h2 := colorHistogram(img1, &options), colorHistogram(img2, &options)
+
+	var result HistogramResult
+	for i := range h1 {
+		p, q := h1[i], h2[i]
+		result.Similarity += math.Sqrt(p * q)
+		if p+q > 0 {
+			result.ChiSquare += (p - q) * (p - q) / (p + q)
+		}
+	}
+	result.ChiSquare /= 2
+
+	// keep rounding errors within the documented ranges
+	result.Similarity = math.Min(result.Similarity, 1)
+
+	return result, nil
+}
+
+// colorHistogram returns the normalized histogram of the colors of img
+// compared with the options; all zeros when there are no such pixels.
+func colorHistogram(img image.Image, options *Options) []float64 {
+	var (
+		src    = toNRGBA(img)
+		r      = options.compareRect(src.Rect)
+		ignore = newIgnoreMap(src.Rect, options)
+		counts = make([]uint64, histogramBins*histogramBins*histogramBins)
+		total  uint64
+	)
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := src.Pix[src.PixOffset(r.Min.X, y):][:r.Dx()*4]
+		for k, x := 0, r.Min.X; k < len(row); k, x = k+4, x+1 {
+			if ignore.ignored(x, y) {
+				continue
+			}
+
+			cr, cg, cb := blendColor([4]uint8{row[k], row[k+1], row[k+2], row[k+3]})
+			counts[histogramBin(cr)*histogramBins*histogramBins+histogramBin(cg)*histogramBins+histogramBin(cb)]++
+			total++
+		}
+	}
+
+	hist := make([]float64, len(counts))
+	if total == 0 {
+		return hist
+	}
+	for i, n := range counts {
+		hist[i] = float64(n) / float64(total)
+	}
+
+	return hist
+}
+
+// histogramBin returns the bin of a channel value from 0 to 255.
+func histogramBin(v float64) int {
+	return clampInt(int(v)*histogramBins/256, 0, histogramBins-1)
+}
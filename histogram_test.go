@@ -0,0 +1,66 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestCompareHistograms(t *testing.T) {
+	img := texturedImage(120, 90, 1)
+	red, blue := image.NewNRGBA(img.Rect), image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	draw.Draw(red, red.Rect, &image.Uniform{C: color.NRGBA{R: 255, A: 255}}, image.Point{}, draw.Src)
+	draw.Draw(blue, blue.Rect, &image.Uniform{C: color.NRGBA{B: 255, A: 255}}, image.Point{}, draw.Src)
+
+	tests := []struct {
+		name           string
+		a, b           image.Image
+		opts           []Option
+		minSim, maxSim float64
+		minChi, maxChi float64
+	}{
+		{"same", img, img, nil, 1, 1, 0, 0},
+		{"moved", img, movedImage(img, image.Pt(5, -4)), nil, 0.9, 1, 0, 0.1},
+		{"different colors", red, blue, nil, 0, 0, 1, 1},
+		{"other sizes", blue, blue.SubImage(image.Rect(3, 5, 10, 8)), nil, 1, 1, 0, 0},
+		{"region", red, redBlueImage(), []Option{WithRegion(image.Rect(0, 0, 60, 90))}, 1, 1, 0, 0},
+		{"ignored", red, redBlueImage(), []Option{WithIgnoreRegions(image.Rect(60, 0, 120, 90))}, 1, 1, 0, 0},
+		{"half", red, redBlueImage(), nil, 0.7, 0.71, 0.33, 0.34},
+	}
+
+	for _, tc := range tests {
+		result, err := CompareHistograms(tc.a, tc.b, tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if result.Similarity < tc.minSim-1e-9 || result.Similarity > tc.maxSim+1e-9 {
+			t.Errorf("%s: expected similarity within %.2f..%.2f, got - %f", tc.name, tc.minSim, tc.maxSim, result.Similarity)
+		}
+		if result.ChiSquare < tc.minChi-1e-9 || result.ChiSquare > tc.maxChi+1e-9 {
+			t.Errorf("%s: expected chi-square within %.2f..%.2f, got - %f", tc.name, tc.minChi, tc.maxChi, result.ChiSquare)
+		}
+	}
+
+	if _, err := CompareHistograms(img, image.NewNRGBA(image.Rect(0, 0, 0, 0))); !errors.Is(err, ErrEmptyImage) {
+		t.Errorf("Expected ErrEmptyImage, got - %v", err)
+	}
+}
+
+// redBlueImage returns a 120x90 image with a red left half and a blue right half.
+func redBlueImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 120, 90))
+	draw.Draw(img, image.Rect(0, 0, 60, 90), &image.Uniform{C: color.NRGBA{R: 255, A: 255}}, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(60, 0, 120, 90), &image.Uniform{C: color.NRGBA{B: 255, A: 255}}, image.Point{}, draw.Src)
+
+	return img
+}
+
+func TestHistogramBin(t *testing.T) {
+	for v, want := range map[float64]int{0: 0, 31.9: 0, 32: 1, 255: histogramBins - 1, 300: histogramBins - 1} {
+		if got := histogramBin(v); got != want {
+			t.Errorf("Expected bin %d for %.1f, got - %d", want, v, got)
+		}
+	}
+}
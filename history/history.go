@@ -0,0 +1,268 @@
+// Package history tracks the results of a comparison across runs, e.g. of
+// the nightly screenshot tests of a page, and flags regressions by how fast
+// the share of different pixels grows rather than by its absolute value:
+//
+//	h, err := history.LoadFile("history/home.jsonl")
+//	h.Add(time.Now(), result)
+//	if trend := h.Trend(10); trend.Regressed(0.5) {
+//		log.Printf("home: %s", trend)
+//	}
+//	err = history.AppendFile("history/home.jsonl", time.Now(), result)
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"time"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+// Run is the result of a single past comparison.
+type Run struct {
+	// when the comparison ran; zero if the archived result does not say
+	Time time.Time
+
+	// the counts, Percent, Bounds, SSIM and Elapsed of the archived result;
+	// the other fields are not kept
+	Result pixelmatch.DiffResult
+}
+
+// History is the runs of a comparison, oldest first.
+type History struct {
+	Runs []Run
+}
+
+// record is a run as written by Save and AppendFile, one per line.
+type record struct {
+	Time   time.Time              `json:"time"`
+	Result *pixelmatch.DiffResult `json:"result"`
+}
+
+// result has the fields of the JSON of a DiffResult that a Run keeps.
+type result struct {
+	DiffPixels  uint64          `json:"diffPixels"`
+	AAPixels    uint64          `json:"aaPixels"`
+	TotalPixels uint64          `json:"totalPixels"`
+	Percent     float64         `json:"percent"`
+	Bounds      image.Rectangle `json:"bounds"`
+	SSIM        float64         `json:"ssim"`
+	Elapsed     time.Duration   `json:"elapsed"`
+}
+
+// Load reads the runs from a stream of JSON values, in order: the records
+// written by Save and AppendFile, or plain DiffResult documents such as
+// the reports of the CLI, which have no time.
+func Load(r io.Reader) (*History, error) {
+	h := &History{}
+
+	dec := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return h, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("run %d: %w", n, err)
+		}
+
+		var v struct {
+			Time   time.Time       `json:"time"`
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("run %d: %w", n, err)
+		}
+		if v.Result == nil {
+			v.Result = raw
+		}
+
+		var res result
+		if err := json.Unmarshal(v.Result, &res); err != nil {
+			return nil, fmt.Errorf("run %d: %w", n, err)
+		}
+
+		h.Runs = append(h.Runs, Run{Time: v.Time, Result: pixelmatch.DiffResult{
+			DiffPixels:  res.DiffPixels,
+			AAPixels:    res.AAPixels,
+			TotalPixels: res.TotalPixels,
+			Percent:     res.Percent,
+			Bounds:      res.Bounds,
+			SSIM:        res.SSIM,
+			Elapsed:     res.Elapsed,
+		}})
+	}
+}
+
+// LoadFile is like Load for the file at path; a missing file is an
+// empty history.
+func LoadFile(path string) (*History, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &History{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h, err := Load(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return h, nil
+}
+
+// Add appends the result of a run at t.
+func (h *History) Add(t time.Time, result pixelmatch.DiffResult) {
+	h.Runs = append(h.Runs, Run{Time: t, Result: result})
+}
+
+// Save writes the runs to w, one JSON record per line.
+func (h *History) Save(w io.Writer) error {
+	for _, run := range h.Runs {
+		line, err := encodeRun(run.Time, run.Result)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AppendFile appends the result of a run at t to the history file at path,
+// creating it if needed, e.g. after every CI run.
+func AppendFile(path string, t time.Time, result pixelmatch.DiffResult) error {
+	line, err := encodeRun(t, result)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// encodeRun returns the record of a run as a line of JSON.
+func encodeRun(t time.Time, result pixelmatch.DiffResult) ([]byte, error) {
+	data, err := json.Marshal(record{Time: t, Result: &result})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bytes.TrimSpace(data), '\n'), nil
+}
+
+// Trend describes how the share of different pixels evolved over the last
+// runs of a history.
+type Trend struct {
+	// number of runs considered
+	Runs int
+
+	// Percent of the last run and of the one before; equal with a single run
+	Percent, PrevPercent float64
+
+	// change of Percent since the previous run, in percentage points
+	Delta float64
+
+	// change of DiffPixels since the previous run
+	DeltaPixels int64
+
+	// Delta relative to PrevPercent, e.g. 0.5 when the share grew by half;
+	// +Inf when it grew from zero
+	Rate float64
+
+	// least-squares change of Percent per run over the runs considered,
+	// in percentage points
+	Slope float64
+}
+
+// Trend returns the trend over the last window runs, all of them with
+// a window of 0 or less.
+func (h *History) Trend(window int) Trend {
+	runs := h.Runs
+	if window > 0 && len(runs) > window {
+		runs = runs[len(runs)-window:]
+	}
+
+	t := Trend{Runs: len(runs)}
+	if len(runs) == 0 {
+		return t
+	}
+
+	last, prev := runs[len(runs)-1].Result, runs[len(runs)-1].Result
+	if len(runs) > 1 {
+		prev = runs[len(runs)-2].Result
+	}
+
+	t.Percent, t.PrevPercent = last.Percent, prev.Percent
+	t.Delta = last.Percent - prev.Percent
+	t.DeltaPixels = int64(last.DiffPixels) - int64(prev.DiffPixels)
+	switch {
+	case prev.Percent != 0:
+		t.Rate = t.Delta / prev.Percent
+	case t.Delta > 0:
+		t.Rate = math.Inf(1)
+	}
+
+	if len(runs) > 1 {
+		// least squares fit of the percentages against the run indexes
+		var sumX, sumY, sumXY, sumXX float64
+		for i, run := range runs {
+			x := float64(i)
+			sumX += x
+			sumY += run.Result.Percent
+			sumXY += x * run.Result.Percent
+			sumXX += x * x
+		}
+		n := float64(len(runs))
+		t.Slope = (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	}
+
+	return t
+}
+
+// Regressed reports whether the share of different pixels grew by more than
+// maxIncrease percentage points per run, either at once since the previous
+// run or steadily over the runs of the trend, whatever its absolute value.
+func (t Trend) Regressed(maxIncrease float64) bool {
+	return t.Delta > maxIncrease || t.Slope > maxIncrease
+}
+
+// String describes the change since the previous run, e.g. "diff percentage
+// increased by 0.12 points since the last run (0.30% → 0.42%), +0.05 points
+// per run over 10 runs".
+func (t Trend) String() string {
+	if t.Runs < 2 {
+		return fmt.Sprintf("%.2f%% different, no earlier runs", t.Percent)
+	}
+
+	change := fmt.Sprintf("increased by %.2f points", t.Delta)
+	switch {
+	case t.Delta < 0:
+		change = fmt.Sprintf("decreased by %.2f points", -t.Delta)
+	case t.Delta == 0:
+		change = "unchanged"
+	}
+
+	return fmt.Sprintf("diff percentage %s since the last run (%.2f%% → %.2f%%), %+.2f points per run over %d runs",
+		change, t.PrevPercent, t.Percent, t.Slope, t.Runs)
+}
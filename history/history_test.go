@@ -0,0 +1,101 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+func TestLoadSave(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img2.Pix[0], img2.Pix[3] = 255, 255
+
+	result, err := pixelmatch.Diff(img1, img2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a report of the CLI followed by a record
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	buf.Write(report)
+	(&History{Runs: []Run{{Time: start, Result: result}}}).Save(&buf)
+
+	h, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Runs) != 2 || !h.Runs[0].Time.IsZero() || !h.Runs[1].Time.Equal(start) {
+		t.Fatalf("Expected a report and a record, got - %+v", h.Runs)
+	}
+	for _, run := range h.Runs {
+		if got := run.Result; got.DiffPixels != 1 || got.TotalPixels != 100 || got.Percent != 1 || !got.Bounds.Eq(image.Rect(0, 0, 1, 1)) {
+			t.Errorf("Unexpected result %+v", got)
+		}
+	}
+
+	if _, err := Load(strings.NewReader(`{"diffPixels": 1} {"diffPixels": "x"}`)); err == nil || !strings.Contains(err.Error(), "run 2") {
+		t.Errorf("Expected an error of the second run, got - %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "home.jsonl")
+	if h, err := LoadFile(path); err != nil || len(h.Runs) != 0 {
+		t.Errorf("Expected an empty history of a missing file, got - %v %v", h, err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := AppendFile(path, start.Add(time.Duration(i)*time.Hour), result); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if h, err := LoadFile(path); err != nil || len(h.Runs) != 3 || !h.Runs[2].Time.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("Expected 3 appended runs, got - %+v %v", h, err)
+	}
+}
+
+func TestTrend(t *testing.T) {
+	history := func(percents ...float64) *History {
+		h := &History{}
+		for _, p := range percents {
+			h.Add(time.Time{}, pixelmatch.DiffResult{Percent: p, DiffPixels: uint64(p * 100), TotalPixels: 10000})
+		}
+		return h
+	}
+
+	trend := history(0.1, 0.2, 0.3, 0.4).Trend(0)
+	if trend.Runs != 4 || math.Abs(trend.Delta-0.1) > 1e-9 || math.Abs(trend.Slope-0.1) > 1e-9 || trend.DeltaPixels != 10 ||
+		math.Abs(trend.Rate-1.0/3) > 1e-9 {
+		t.Errorf("Unexpected trend %+v", trend)
+	}
+	if !trend.Regressed(0.05) || trend.Regressed(0.2) {
+		t.Errorf("Expected a regression above 0.05 points per run only, got - %+v", trend)
+	}
+	if got, want := trend.String(), "diff percentage increased by 0.10 points since the last run (0.30% → 0.40%), +0.10 points per run over 4 runs"; got != want {
+		t.Errorf("Expected %q, got - %q", want, got)
+	}
+
+	// a high but stable share is no regression, a jump from zero is
+	if trend := history(0, 0, 5, 5, 5).Trend(3); trend.Regressed(0.01) || trend.Slope != 0 || trend.Runs != 3 {
+		t.Errorf("Expected a stable trend, got - %+v", trend)
+	}
+	if trend := history(0, 0.5).Trend(0); !math.IsInf(trend.Rate, 1) || !trend.Regressed(0.2) {
+		t.Errorf("Expected a jump from zero, got - %+v", trend)
+	}
+	if trend := history(2).Trend(0); trend.Delta != 0 || trend.Regressed(0) || trend.String() != "2.00% different, no earlier runs" {
+		t.Errorf("Unexpected trend of a single run %+v", trend)
+	}
+	if trend := (&History{}).Trend(5); trend.Runs != 0 {
+		t.Errorf("Unexpected trend of no runs %+v", trend)
+	}
+}
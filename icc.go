@@ -0,0 +1,384 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"sort"
+)
+
+// ErrICC is returned for ICC profiles which are malformed or not supported.
+var ErrICC = errors.New("unsupported ICC profile")
+
+// ColorProfile describes the color space of RGB images: how their values map
+// to CIE XYZ colors. Only matrix/TRC profiles are supported, which covers
+// sRGB, Display P3, Adobe RGB and the profiles of most displays.
+type ColorProfile struct {
+	// linear RGB to XYZ, adapted to the D50 white point like in ICC profiles
+	toXYZ [3][3]float64
+
+	// tone response curves of R, G and B: encoded value to linear light
+	trc [3]toneCurve
+}
+
+// toneCurve maps an encoded value from 0 to 1 to linear light.
+type toneCurve func(v float64) float64
+
+var (
+	// ProfileSRGB is the color space of the sRGB standard,
+	// which images without a profile are assumed to have.
+	ProfileSRGB = &ColorProfile{
+		toXYZ: [3][3]float64{
+			{0.4360747, 0.3850649, 0.1430804},
+			{0.2225045, 0.7168786, 0.0606169},
+			{0.0139322, 0.0971045, 0.7141733},
+		},
+		trc: [3]toneCurve{srgbToLinear, srgbToLinear, srgbToLinear},
+	}
+
+	// ProfileDisplayP3 is the color space of the screens and screenshots of
+	// Apple devices: the P3 primaries with the sRGB tone curve.
+	ProfileDisplayP3 = &ColorProfile{
+		toXYZ: [3][3]float64{
+			{0.5151215, 0.2919769, 0.1571474},
+			{0.2411803, 0.6922455, 0.0665742},
+			{-0.0010498, 0.0418804, 0.7840690},
+		},
+		trc: [3]toneCurve{srgbToLinear, srgbToLinear, srgbToLinear},
+	}
+)
+
+// ParseICCProfile parses an ICC profile of an RGB color space, such as the
+// ones embedded in PNG and JPEG files.
+func ParseICCProfile(data []byte) (*ColorProfile, error) {
+	if len(data) < 132 || string(data[36:40]) != "acsp" {
+		return nil, fmt.Errorf("%w: missing profile header", ErrICC)
+	}
+	if space := string(data[16:20]); space != "RGB " {
+		return nil, fmt.Errorf("%w: color space %q", ErrICC, space)
+	}
+
+	tags := make(map[string][]byte)
+	count := int(binary.BigEndian.Uint32(data[128:]))
+	for i := 0; i < count && 132+12*(i+1) <= len(data); i++ {
+		entry := data[132+12*i:]
+		offset, size := binary.BigEndian.Uint32(entry[4:]), binary.BigEndian.Uint32(entry[8:])
+		if uint64(offset)+uint64(size) > uint64(len(data)) {
+			return nil, fmt.Errorf("%w: tag %q out of bounds", ErrICC, entry[:4])
+		}
+		tags[string(entry[:4])] = data[offset : offset+size]
+	}
+
+	p := &ColorProfile{}
+	for i, channel := range []string{"r", "g", "b"} {
+		xyz, ok := tags[channel+"XYZ"]
+		if !ok || len(xyz) < 20 || string(xyz[:4]) != "XYZ " {
+			return nil, fmt.Errorf("%w: missing %sXYZ tag of a matrix/TRC profile", ErrICC, channel)
+		}
+		for j := 0; j < 3; j++ {
+			p.toXYZ[j][i] = s15Fixed16(xyz[8+4*j:])
+		}
+
+		trc, err := parseToneCurve(tags[channel+"TRC"])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %sTRC: %v", ErrICC, channel, err)
+		}
+		p.trc[i] = trc
+	}
+
+	if _, ok := invert3(p.toXYZ); !ok {
+		return nil, fmt.Errorf("%w: singular matrix", ErrICC)
+	}
+
+	return p, nil
+}
+
+// s15Fixed16 decodes a signed fixed point number of an ICC profile.
+func s15Fixed16(b []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+}
+
+// parseToneCurve parses a curv or para tag.
+func parseToneCurve(tag []byte) (toneCurve, error) {
+	if len(tag) < 12 {
+		return nil, errors.New("missing tag")
+	}
+
+	switch string(tag[:4]) {
+	case "curv":
+		n := int(binary.BigEndian.Uint32(tag[8:]))
+		switch {
+		case len(tag) < 12+2*n:
+			return nil, errors.New("truncated curve")
+		case n == 0:
+			return func(v float64) float64 { return v }, nil
+		case n == 1:
+			gamma := float64(binary.BigEndian.Uint16(tag[12:])) / 256
+			return func(v float64) float64 { return math.Pow(v, gamma) }, nil
+		}
+
+		table := make([]float64, n)
+		for i := range table {
+			table[i] = float64(binary.BigEndian.Uint16(tag[12+2*i:])) / 65535
+		}
+		return func(v float64) float64 {
+			// interpolate linearly between the entries
+			pos := v * float64(n-1)
+			i := int(pos)
+			if i >= n-1 {
+				return table[n-1]
+			}
+			return table[i] + (table[i+1]-table[i])*(pos-float64(i))
+		}, nil
+
+	case "para":
+		// parameters of the function types 0 to 4: g, a, b, c, d, e, f
+		sizes := []int{1, 3, 4, 5, 7}
+		kind := int(binary.BigEndian.Uint16(tag[8:]))
+		if kind >= len(sizes) || len(tag) < 12+4*sizes[kind] {
+			return nil, fmt.Errorf("invalid parametric curve of type %d", kind)
+		}
+
+		p := [7]float64{1: 1}
+		for i := 0; i < sizes[kind]; i++ {
+			p[i] = s15Fixed16(tag[12+4*i:])
+		}
+		g, a, b, c, d, e, f := p[0], p[1], p[2], p[3], p[4], p[5], p[6]
+
+		return func(v float64) float64 {
+			switch kind {
+			case 0:
+				return math.Pow(v, g)
+			case 1:
+				if v < -b/a {
+					return 0
+				}
+				return math.Pow(a*v+b, g)
+			case 2:
+				if v < -b/a {
+					return c
+				}
+				return math.Pow(a*v+b, g) + c
+			case 3:
+				if v < d {
+					return c * v
+				}
+				return math.Pow(a*v+b, g)
+			default:
+				if v < d {
+					return c*v + f
+				}
+				return math.Pow(a*v+b, g) + e
+			}
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown curve type %q", tag[:4])
+	}
+}
+
+// ConvertToSRGB returns a copy of img, whose colors are in the color space
+// of p, with the same colors in sRGB, 8 bits per channel. Colors outside of
+// the sRGB gamut are clipped.
+func ConvertToSRGB(img image.Image, p *ColorProfile) *image.NRGBA {
+	src := toNRGBA(img)
+	dst := image.NewNRGBA(image.Rect(0, 0, src.Rect.Dx(), src.Rect.Dy()))
+
+	// XYZ to linear sRGB; the inverse of a valid profile exists
+	fromXYZ, _ := invert3(ProfileSRGB.toXYZ)
+	m := multiply3(fromXYZ, p.toXYZ)
+
+	var decode [3][256]float64
+	for c := range decode {
+		for v := range decode[c] {
+			decode[c][v] = p.trc[c](float64(v) / 255)
+		}
+	}
+
+	// linear light to sRGB in steps fine enough for 8-bit results
+	const steps = 4096
+	var encode [steps + 1]uint8
+	for i := range encode {
+		encode[i] = uint8(math.Round(255 * linearToSRGB(float64(i)/steps)))
+	}
+
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	for y := 0; y < h; y++ {
+		s := src.Pix[src.PixOffset(src.Rect.Min.X, src.Rect.Min.Y+y):][:4*w]
+		d := dst.Pix[y*dst.Stride:][:4*w]
+		for i := 0; i < len(s); i += 4 {
+			r, g, b := decode[0][s[i]], decode[1][s[i+1]], decode[2][s[i+2]]
+			for c := 0; c < 3; c++ {
+				v := m[c][0]*r + m[c][1]*g + m[c][2]*b
+				d[i+c] = encode[int(math.Round(math.Max(0, math.Min(1, v))*steps))]
+			}
+			d[i+3] = s[i+3]
+		}
+	}
+
+	return dst
+}
+
+// linearToSRGB is the inverse of srgbToLinear.
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+// isSRGB reports whether p describes sRGB closely enough for 8-bit images
+// to keep their values.
+func (p *ColorProfile) isSRGB() bool {
+	for i := range p.toXYZ {
+		for j := range p.toXYZ[i] {
+			if math.Abs(p.toXYZ[i][j]-ProfileSRGB.toXYZ[i][j]) > 0.001 {
+				return false
+			}
+		}
+	}
+
+	for c := range p.trc {
+		for v := 0.0; v <= 1; v += 1.0 / 16 {
+			if math.Abs(p.trc[c](v)-srgbToLinear(v)) > 0.001 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func multiply3(a, b [3][3]float64) [3][3]float64 {
+	var m [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				m[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+
+	return m
+}
+
+// invert3 returns the inverse of m, false if m is singular.
+func invert3(m [3][3]float64) ([3][3]float64, bool) {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	if math.Abs(det) < 1e-12 {
+		return m, false
+	}
+
+	var inv [3][3]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			// cofactor of m[j][i]
+			r1, r2 := (j+1)%3, (j+2)%3
+			c1, c2 := (i+1)%3, (i+2)%3
+			inv[i][j] = (m[r1][c1]*m[r2][c2] - m[r1][c2]*m[r2][c1]) / det
+		}
+	}
+
+	return inv, true
+}
+
+// WithColorManagement converts images with an embedded ICC profile to sRGB
+// before comparing, so e.g. a Display P3 screenshot from macOS matches its
+// sRGB baseline. Like WithAutoOrient it applies where this package decodes
+// the images; images passed to Diff can be converted with ConvertToSRGB.
+// Profiles which are not supported are ignored.
+func WithColorManagement() Option {
+	return func(o *Options) {
+		o.colorManagement = true
+	}
+}
+
+// embeddedProfile returns the ICC profile embedded in a PNG or JPEG file,
+// nil if it has none.
+func embeddedProfile(data []byte, format string) ([]byte, error) {
+	switch format {
+	case "png":
+		return pngProfile(data)
+	case "jpeg":
+		return jpegProfile(data)
+	default:
+		return nil, nil
+	}
+}
+
+// pngProfile returns the contents of the iCCP chunk of a PNG file.
+func pngProfile(data []byte) ([]byte, error) {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil, nil
+	}
+
+	for i := len(signature); i+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[i:]))
+		kind := string(data[i+4 : i+8])
+		if kind == "IDAT" || size < 0 || i+12+size > len(data) {
+			break
+		}
+
+		if kind == "iCCP" {
+			// profile name, compression method and the compressed profile
+			chunk := data[i+8 : i+8+size]
+			name := bytes.IndexByte(chunk, 0)
+			if name < 0 || name+2 > len(chunk) {
+				return nil, fmt.Errorf("%w: malformed iCCP chunk", ErrICC)
+			}
+
+			r, err := zlib.NewReader(bytes.NewReader(chunk[name+2:]))
+			if err != nil {
+				return nil, fmt.Errorf("%w: iCCP chunk: %v", ErrICC, err)
+			}
+			defer r.Close()
+
+			return io.ReadAll(r)
+		}
+
+		i += 12 + size
+	}
+
+	return nil, nil
+}
+
+// jpegProfile returns the ICC profile of a JPEG file, which is split
+// into numbered APP2 segments.
+func jpegProfile(data []byte) ([]byte, error) {
+	const prefix = "ICC_PROFILE\x00"
+
+	type part struct {
+		seq  byte
+		data []byte
+	}
+	var parts []part
+
+	forEachJPEGSegment(data, func(marker byte, segment []byte) bool {
+		if marker == 0xe2 && bytes.HasPrefix(segment, []byte(prefix)) && len(segment) > len(prefix)+2 {
+			parts = append(parts, part{seq: segment[len(prefix)], data: segment[len(prefix)+2:]})
+		}
+		return true
+	})
+
+	if len(parts) == 0 {
+		return nil, nil
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].seq < parts[j].seq })
+
+	var profile []byte
+	for _, p := range parts {
+		profile = append(profile, p.data...)
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,223 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+)
+
+// iccProfile returns a matrix/TRC profile of p whose curves are the
+// parametric sRGB curve.
+func iccProfile(p *ColorProfile) []byte {
+	xyz := func(c int) []byte {
+		tag := []byte("XYZ \x00\x00\x00\x00")
+		for r := 0; r < 3; r++ {
+			tag = binary.BigEndian.AppendUint32(tag, uint32(int32(math.Round(p.toXYZ[r][c]*65536))))
+		}
+		return tag
+	}
+	trc := []byte("para\x00\x00\x00\x00\x00\x03\x00\x00")
+	for _, v := range []float64{2.4, 1 / 1.055, 0.055 / 1.055, 1 / 12.92, 0.04045} {
+		trc = binary.BigEndian.AppendUint32(trc, uint32(int32(math.Round(v*65536))))
+	}
+
+	tags := []struct {
+		sig  string
+		data []byte
+	}{{"rXYZ", xyz(0)}, {"gXYZ", xyz(1)}, {"bXYZ", xyz(2)}, {"rTRC", trc}, {"gTRC", trc}, {"bTRC", trc}}
+
+	header := make([]byte, 128)
+	copy(header[12:], "mntr")
+	copy(header[16:], "RGB XYZ ")
+	copy(header[36:], "acsp")
+
+	data := binary.BigEndian.AppendUint32(header, uint32(len(tags)))
+	offset := len(data) + 12*len(tags)
+	var payload []byte
+	for _, t := range tags {
+		data = append(data, t.sig...)
+		data = binary.BigEndian.AppendUint32(data, uint32(offset+len(payload)))
+		data = binary.BigEndian.AppendUint32(data, uint32(len(t.data)))
+		payload = append(payload, t.data...)
+	}
+
+	return append(data, payload...)
+}
+
+// pngWithProfile returns img encoded as PNG with an iCCP chunk.
+func pngWithProfile(tb testing.TB, img image.Image, profile []byte) []byte {
+	tb.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		tb.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(profile)
+	zw.Close()
+
+	chunk := append([]byte("iCCPtest\x00\x00"), compressed.Bytes()...)
+	iccp := binary.BigEndian.AppendUint32(nil, uint32(len(chunk)-4))
+	iccp = append(iccp, chunk...)
+	iccp = binary.BigEndian.AppendUint32(iccp, crc32.ChecksumIEEE(chunk))
+
+	// after the signature and the IHDR chunk
+	const ihdrEnd = 8 + 25
+	return append(append(append([]byte{}, data[:ihdrEnd]...), iccp...), data[ihdrEnd:]...)
+}
+
+// jpegWithProfile returns img encoded as JPEG with the profile split into
+// two APP2 segments, the second one first.
+func jpegWithProfile(tb testing.TB, img image.Image, profile []byte) []byte {
+	tb.Helper()
+
+	half := len(profile) / 2
+
+	var segments []byte
+	for _, part := range []struct {
+		seq  byte
+		data []byte
+	}{{2, profile[half:]}, {1, profile[:half]}} {
+		payload := append([]byte("ICC_PROFILE\x00"), part.seq, 2)
+		payload = append(payload, part.data...)
+		segments = append(segments, 0xff, 0xe2)
+		segments = binary.BigEndian.AppendUint16(segments, uint16(len(payload)+2))
+		segments = append(segments, payload...)
+	}
+
+	return orientedJPEG(tb, img, segments)
+}
+
+// p3Image returns an image of the given colors in a row.
+func p3Image(colors ...color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(colors), 1))
+	for x, c := range colors {
+		img.SetNRGBA(x, 0, c)
+	}
+
+	return img
+}
+
+// maxPixDelta returns the largest difference of the bytes of two images
+// of the same size.
+func maxPixDelta(a, b []uint8) int {
+	var d int
+	for i := range a {
+		if v := int(a[i]) - int(b[i]); v > d {
+			d = v
+		} else if -v > d {
+			d = -v
+		}
+	}
+
+	return d
+}
+
+func TestConvertToSRGB(t *testing.T) {
+	colors := []color.NRGBA{{128, 128, 128, 255}, {100, 150, 200, 255}, {200, 120, 60, 128}, {10, 5, 0, 255}}
+	img := p3Image(colors...)
+
+	if same := ConvertToSRGB(img, ProfileSRGB); !bytes.Equal(same.Pix, img.Pix) {
+		t.Errorf("Expected sRGB to stay as it is, got - %v", same.Pix)
+	}
+
+	// the D65 conversion of linear Display P3 to linear sRGB
+	p3ToSRGB := [3][3]float64{
+		{1.2249, -0.2247, 0},
+		{-0.0420, 1.0419, 0},
+		{-0.0197, -0.0786, 1.0979},
+	}
+
+	converted := ConvertToSRGB(img, ProfileDisplayP3)
+	for x, c := range colors {
+		in := [3]float64{srgbToLinear(float64(c.R) / 255), srgbToLinear(float64(c.G) / 255), srgbToLinear(float64(c.B) / 255)}
+		got := converted.NRGBAAt(x, 0)
+		for i, v := range []uint8{got.R, got.G, got.B} {
+			want := 255 * linearToSRGB(p3ToSRGB[i][0]*in[0]+p3ToSRGB[i][1]*in[1]+p3ToSRGB[i][2]*in[2])
+			if math.Abs(float64(v)-want) > 1 {
+				t.Errorf("%v: expected channel %d to be %.1f, got - %d", c, i, want, v)
+			}
+		}
+		if got.A != c.A {
+			t.Errorf("%v: expected the alpha to stay, got - %d", c, got.A)
+		}
+	}
+}
+
+func TestParseICCProfile(t *testing.T) {
+	p, err := ParseICCProfile(iccProfile(ProfileDisplayP3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := p3Image(color.NRGBA{100, 150, 200, 255}, color.NRGBA{30, 200, 90, 255})
+	if got, want := ConvertToSRGB(img, p).Pix, ConvertToSRGB(img, ProfileDisplayP3).Pix; maxPixDelta(got, want) > 1 {
+		t.Errorf("Expected the parsed profile to convert like Display P3, got - %v, %v", got, want)
+	}
+
+	if p, err := ParseICCProfile(iccProfile(ProfileSRGB)); err != nil || !p.isSRGB() {
+		t.Errorf("Expected an sRGB profile, got - %v", err)
+	}
+
+	cmyk := iccProfile(ProfileSRGB)
+	copy(cmyk[16:], "CMYK")
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"cmyk":      cmyk,
+		"truncated": iccProfile(ProfileSRGB)[:200],
+	} {
+		if _, err := ParseICCProfile(data); !errors.Is(err, ErrICC) {
+			t.Errorf("%s: expected ErrICC, got - %v", name, err)
+		}
+	}
+}
+
+func TestWithColorManagement(t *testing.T) {
+	options := newOptions()
+	screenshot := scaleNRGBA(toNRGBA(decodeTestImage(t, "testdata/img1.png")), 0.05, &options)
+	profile := iccProfile(ProfileDisplayP3)
+
+	var baseline bytes.Buffer
+	if err := png.Encode(&baseline, ConvertToSRGB(screenshot, ProfileDisplayP3)); err != nil {
+		t.Fatal(err)
+	}
+
+	p3 := pngWithProfile(t, screenshot, profile)
+	result, _, err := DiffReaders(bytes.NewReader(baseline.Bytes()), bytes.NewReader(p3), WithColorManagement())
+	if err != nil || result.DiffPixels != 0 {
+		t.Errorf("Expected the converted screenshot to match, got - %d %v", result.DiffPixels, err)
+	}
+	if result, _, _ := DiffReaders(bytes.NewReader(baseline.Bytes()), bytes.NewReader(p3)); result.DiffPixels == 0 {
+		t.Error("Expected the screenshot to differ without conversion")
+	}
+
+	// JPEG compresses the colors, so compare them decoded as they are
+	data := jpegWithProfile(t, screenshot, profile)
+	raw, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, _, err := Decode(bytes.NewReader(data), WithColorManagement())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ConvertToSRGB(raw, ProfileDisplayP3); maxPixDelta(toNRGBA(img).Pix, want.Pix) > 1 {
+		t.Error("Expected the JPEG to be converted with its split profile")
+	}
+
+	// a broken profile is ignored
+	img, _, err = Decode(bytes.NewReader(pngWithProfile(t, screenshot, profile[:100])), WithColorManagement())
+	if err != nil || !bytes.Equal(toNRGBA(img).Pix, screenshot.Pix) {
+		t.Errorf("Expected the image as it is, got - %v", err)
+	}
+}
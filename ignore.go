@@ -0,0 +1,105 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+)
+
+// WithIgnoreRegions excludes the given rectangles from the comparison.
+// Multiple calls accumulate regions.
+func WithIgnoreRegions(rects ...image.Rectangle) Option {
+	return func(o *Options) {
+		o.ignoreRegions = append(o.ignoreRegions, rects...)
+	}
+}
+
+// WithIgnoreMask excludes pixels where the mask is non-zero (its gray value
+// is above zero) from the comparison. The mask shares coordinates with the
+// compared images; pixels outside of its bounds are compared as usual.
+func WithIgnoreMask(mask image.Image) Option {
+	return func(o *Options) {
+		o.ignoreMask = mask
+	}
+}
+
+// WithIgnoreColor draws excluded pixels in the diff output with the given color
+// instead of treating them as similar pixels.
+func WithIgnoreColor(c color.Color) Option {
+	return func(o *Options) {
+		if c == nil {
+			o.nilColor("ignore")
+			return
+		}
+		nrgba := toNRGBAColor(c)
+		o.ignoreColor = &nrgba
+	}
+}
+
+// ignoreMap marks the pixels excluded from the comparison, one bit per pixel.
+// A nil *ignoreMap excludes nothing.
+type ignoreMap struct {
+	rect image.Rectangle
+	bits []uint64
+}
+
+func newIgnoreMap(r image.Rectangle, options *Options) *ignoreMap {
+	if len(options.ignoreRegions) == 0 && options.ignoreMask == nil {
+		return nil
+	}
+
+	m := &ignoreMap{
+		rect: r,
+		bits: make([]uint64, (r.Dx()*r.Dy()+63)/64),
+	}
+
+	for _, region := range options.ignoreRegions {
+		region = region.Intersect(r)
+		for y := region.Min.Y; y < region.Max.Y; y++ {
+			for x := region.Min.X; x < region.Max.X; x++ {
+				m.set(x, y)
+			}
+		}
+	}
+
+	if mask := options.ignoreMask; mask != nil {
+		area := mask.Bounds().Intersect(r)
+		for y := area.Min.Y; y < area.Max.Y; y++ {
+			for x := area.Min.X; x < area.Max.X; x++ {
+				if maskIsSet(mask, x, y) {
+					m.set(x, y)
+				}
+			}
+		}
+	}
+
+	return m
+}
+
+func (m *ignoreMap) offset(x, y int) int {
+	return (y-m.rect.Min.Y)*m.rect.Dx() + (x - m.rect.Min.X)
+}
+
+func (m *ignoreMap) set(x, y int) {
+	i := m.offset(x, y)
+	m.bits[i/64] |= 1 << (i % 64)
+}
+
+func (m *ignoreMap) ignored(x, y int) bool {
+	if m == nil {
+		return false
+	}
+
+	i := m.offset(x, y)
+	return m.bits[i/64]&(1<<(i%64)) != 0
+}
+
+func maskIsSet(mask image.Image, x, y int) bool {
+	switch mask := mask.(type) {
+	case *image.Alpha:
+		return mask.AlphaAt(x, y).A != 0
+	case *image.Gray:
+		return mask.GrayAt(x, y).Y != 0
+	default:
+		return color.GrayModel.Convert(mask.At(x, y)).(color.Gray).Y != 0
+	}
+}
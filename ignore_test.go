@@ -0,0 +1,43 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDiffIgnore(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for _, p := range []image.Point{{1, 1}, {5, 5}, {8, 2}} {
+		img2.SetNRGBA(p.X, p.Y, color.NRGBA{R: 255, A: 255})
+	}
+
+	mask := image.NewAlpha(image.Rect(0, 0, 10, 10))
+	mask.SetAlpha(8, 2, color.Alpha{A: 255})
+
+	ignoreColor := color.NRGBA{B: 255, A: 255}
+	output := image.NewNRGBA(img1.Bounds())
+
+	result, err := Diff(img1, img2, output,
+		WithIgnoreRegions(image.Rect(0, 0, 3, 3)),
+		WithIgnoreMask(mask),
+		WithIgnoreColor(ignoreColor),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.DiffPixels != 1 {
+		t.Errorf("Expected 1, got - %d", result.DiffPixels)
+	}
+	if result.TotalPixels != 100-9-1 {
+		t.Errorf("Expected 90 compared pixels, got - %d", result.TotalPixels)
+	}
+	if got := output.NRGBAAt(8, 2); got != ignoreColor {
+		t.Errorf("Expected ignored pixel drawn as %v, got - %v", ignoreColor, got)
+	}
+	if got := output.NRGBAAt(0, 0); got != ignoreColor {
+		t.Errorf("Expected ignored region drawn as %v, got - %v", ignoreColor, got)
+	}
+}
@@ -0,0 +1,184 @@
+package pixelmatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"time"
+)
+
+// ErrIncremental is returned by Rediff for results it cannot update.
+var ErrIncremental = errors.New("result cannot be updated incrementally")
+
+// aaRadius is how far the anti-aliasing check of a pixel looks: at its
+// siblings and at the siblings of the darkest and brightest of them.
+const aaRadius = 2
+
+// WithIncremental keeps the sets of different and anti-aliased pixels in
+// the result, so Rediff can update it when the images change in a few
+// places. It takes 2 bits per pixel.
+func WithIncremental() Option {
+	return func(o *Options) {
+		o.keepDiff = true
+		o.keepAA = true
+	}
+}
+
+// Rediff updates prev, the result of comparing earlier versions of img1 and
+// img2 with WithIncremental, after their pixels changed only inside the dirty
+// rectangles, e.g. when a screenshot is taken again after a fix of a single
+// widget. Only the dirty rectangles and a margin around them, which the
+// anti-aliasing, shift and isolated pixel checks of their neighbours look
+// at, are compared again, with the options of prev; the diff is drawn into
+// output, the output of the previous comparison, unless it is nil. The
+// result is the same as of comparing the images anew; prev stays unchanged.
+//
+// Options that change the images as a whole (scaling, auto-alignment, blur,
+// median filter, edges, render modes, SSIM), statistics not kept per pixel
+// (grid, channels, extra metrics), mask morphology and sampling make it
+// fail with ErrIncremental, as do images of bounds other than those of prev.
+func Rediff(prev DiffResult, img1, img2 image.Image, output *image.NRGBA, dirty ...image.Rectangle) (DiffResult, error) {
+	if prev.diff == nil || prev.aa == nil || prev.options == nil {
+		return prev, fmt.Errorf("%w: compare with WithIncremental", ErrIncremental)
+	}
+
+	start := time.Now()
+	options := *prev.options
+
+	if err := options.checkIncremental(); err != nil {
+		options.observe(start, DiffResult{}, err)
+		return prev, err
+	}
+
+	imgs := []image.Image{img1, img2}
+	if output != nil {
+		imgs = append(imgs, output)
+	}
+	err := checkEmptyImages(imgs...)
+	if err == nil {
+		err = checkImageSizes(imgs...)
+	}
+	if err == nil && !img1.Bounds().Eq(prev.diff.rect) {
+		err = fmt.Errorf("%w: bounds %v differ from %v of the previous result", ErrIncremental, img1.Bounds(), prev.diff.rect)
+	}
+	if err == nil {
+		err = checkOutput(output, img1, img2)
+	}
+	if err != nil {
+		options.observe(start, DiffResult{}, err)
+		return prev, err
+	}
+	defer outputs.lock(output)()
+
+	var (
+		result  = prev
+		diffSet = prev.diff.clone()
+		aaSet   = prev.aa.clone()
+		region  = options.compareRect(prev.diff.rect)
+		margin  = options.neighbourhood()
+	)
+
+	// the budget applies to the updated result as a whole
+	options.failFast = false
+
+	for _, d := range dirty {
+		r := d.Inset(-margin).Intersect(region)
+		if r.Empty() {
+			continue
+		}
+		options.debug("comparing dirty rectangle again", "rect", r)
+
+		if output != nil && options.diffMask {
+			// similar pixels are not drawn over the transparent background
+			draw.Draw(output, r, image.Transparent, image.Point{}, draw.Src)
+		}
+
+		sub := options
+		sub.region = &r
+
+		_, part, err := diffImages(context.Background(), img1, img2, output, false, sub)
+		if err != nil {
+			options.observe(start, DiffResult{}, err)
+			return prev, err
+		}
+
+		diffSet.copyRect(part.diff, r)
+		aaSet.copyRect(part.aa, r)
+	}
+
+	result.diff, result.aa = diffSet, aaSet
+	result.DiffPixels = diffSet.count()
+	result.AAPixels = aaSet.count()
+	result.Bounds = diffSet.bounds()
+
+	if options.clusters {
+		result.Clusters = findClusters(diffSet, options.clusterMinSize)
+	}
+	if options.boxes {
+		result.Boxes = findBoxes(diffSet, options.maxBoxes, options.boxPadding)
+	}
+	if options.aaMask {
+		result.AAMask = aaSet.paletted()
+	}
+	result.finish(start)
+
+	if prev.options.failFast && result.DiffPixels > prev.options.failFastMax {
+		err = ErrDiffBudgetExceeded
+	}
+	options.observe(start, result, err)
+
+	return result, err
+}
+
+// neighbourhood returns how far from a pixel the comparison of the pixel
+// looks: the anti-aliasing, shift, text and isolated pixel checks.
+func (o *Options) neighbourhood() int {
+	n := aaRadius + o.shift
+	if o.textTolerant() {
+		n += textRadius
+	}
+	if o.ignoreIsolated {
+		n++
+	}
+
+	return n
+}
+
+// checkIncremental returns ErrIncremental when the options make the
+// comparison of a pixel depend on more than its neighbourhood or compute
+// statistics that are not kept per pixel.
+func (o *Options) checkIncremental() error {
+	var unsupported string
+	switch {
+	case o.scale > 0 && o.scale < 1 || o.maxDimension > 0:
+		unsupported = "scaling"
+	case o.autoAlign > 0:
+		unsupported = "auto-alignment"
+	case o.blur > 0:
+		unsupported = "blur"
+	case o.edges:
+		unsupported = "edges"
+	case o.median:
+		unsupported = "median filter"
+	case o.renderMode != RenderDiff:
+		unsupported = "render mode " + o.renderMode.String()
+	case o.metric == MetricSSIM:
+		unsupported = "SSIM"
+	case o.gridCols > 0 && o.gridRows > 0:
+		unsupported = "grid"
+	case o.channelDiff:
+		unsupported = "channel diff"
+	case o.extraMetrics:
+		unsupported = "extra metrics"
+	case o.maskErode > 0 || o.maskDilate > 0:
+		unsupported = "mask morphology"
+	case o.sampleRate > 1:
+		unsupported = "sampling"
+	default:
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s is not supported", ErrIncremental, unsupported)
+}
@@ -0,0 +1,97 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"testing"
+)
+
+func TestRediff(t *testing.T) {
+	img1 := toNRGBA(decodeTestImage(t, "testdata/img1.png"))
+	img2 := toNRGBA(decodeTestImage(t, "testdata/img2.png"))
+	r := img1.Bounds()
+
+	// the fix changes two areas of the second image back and one anew
+	fixed := image.NewNRGBA(r)
+	draw.Draw(fixed, r, img2, r.Min, draw.Src)
+	dirty := []image.Rectangle{
+		image.Rect(10, 10, 60, 40).Add(r.Min),
+		image.Rect(r.Dx()/2, r.Dy()/2, r.Dx()/2+30, r.Dy()/2+25).Add(r.Min),
+	}
+	draw.Draw(fixed, dirty[0], img1, dirty[0].Min, draw.Src)
+	draw.Draw(fixed, dirty[1], image.Black, image.Point{}, draw.Src)
+
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"mask", []Option{WithDiffMask(true)}},
+		{"shift", []Option{WithShiftTolerance(2)}},
+		{"text", []Option{WithTextTolerance(0.4), WithClusters(1), WithAAMask()}},
+		{"region", []Option{WithRegion(image.Rect(0, 0, r.Dx()/2+10, r.Dy()).Add(r.Min))}},
+	} {
+		opts := append([]Option{WithIncremental()}, tc.opts...)
+
+		output := image.NewNRGBA(r)
+		prev, err := Diff(img1, img2, output, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prevCount := prev.DiffPixels
+
+		result, err := Rediff(prev, img1, fixed, output, dirty...)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		expectedOutput := image.NewNRGBA(r)
+		expected, err := Diff(img1, fixed, expectedOutput, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result.DiffPixels != expected.DiffPixels || result.AAPixels != expected.AAPixels || result.Bounds != expected.Bounds {
+			t.Errorf("%s: expected %d/%d pixels in %v, got - %d/%d in %v", tc.name,
+				expected.DiffPixels, expected.AAPixels, expected.Bounds, result.DiffPixels, result.AAPixels, result.Bounds)
+		}
+		if len(result.Clusters) != len(expected.Clusters) {
+			t.Errorf("%s: expected %d clusters, got - %d", tc.name, len(expected.Clusters), len(result.Clusters))
+		}
+		if !bytes.Equal(output.Pix, expectedOutput.Pix) {
+			t.Errorf("%s: expected the updated output to match a new diff", tc.name)
+		}
+		if prev.DiffPixels != prevCount || result.DiffPixels == prevCount {
+			t.Errorf("%s: expected prev to stay %d and the result to change, got - %d %d", tc.name, prevCount, prev.DiffPixels, result.DiffPixels)
+		}
+	}
+}
+
+func TestRediffUnsupported(t *testing.T) {
+	a, b := patchedImage(0), patchedImage(255)
+
+	plain, _ := Diff(a, b, nil)
+	if _, err := Rediff(plain, a, b, nil); !errors.Is(err, ErrIncremental) {
+		t.Errorf("Expected a result without WithIncremental to be rejected, got - %v", err)
+	}
+
+	blurred, _ := Diff(a, b, nil, WithIncremental(), WithBlur(1))
+	if _, err := Rediff(blurred, a, b, nil); !errors.Is(err, ErrIncremental) {
+		t.Errorf("Expected blur to be rejected, got - %v", err)
+	}
+
+	prev, _ := Diff(a, b, nil, WithIncremental())
+	if _, err := Rediff(prev, texturedImage(8, 8, 1), texturedImage(8, 8, 2), nil); !errors.Is(err, ErrIncremental) {
+		t.Errorf("Expected images of other bounds to be rejected, got - %v", err)
+	}
+
+	budget, err := Diff(a, b, nil, WithIncremental(), WithFailFast(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Rediff(budget, a, texturedImage(16, 16, 3), nil, a.Bounds()); !errors.Is(err, ErrDiffBudgetExceeded) {
+		t.Errorf("Expected the updated result to exceed the budget, got - %v", err)
+	}
+}
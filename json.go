@@ -0,0 +1,191 @@
+package pixelmatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// MarshalJSON encodes the result together with the options of the
+// comparison, so CI systems can archive it without custom serialization.
+// Elapsed is encoded in nanoseconds.
+func (r DiffResult) MarshalJSON() ([]byte, error) {
+	// result has the fields of DiffResult without its methods
+	type result DiffResult
+
+	return json.Marshal(struct {
+		result
+		Options *Options `json:"options,omitempty"`
+	}{result(r), r.options})
+}
+
+// MarshalJSON encodes the settings that affect the outcome of a comparison.
+func (o Options) MarshalJSON() ([]byte, error) {
+	type failFast struct {
+		MaxDiffPixels uint64 `json:"maxDiffPixels"`
+	}
+
+	type grid struct {
+		Cols int `json:"cols"`
+		Rows int `json:"rows"`
+	}
+
+	type clusters struct {
+		MinSize int `json:"minSize"`
+	}
+
+	type boxes struct {
+		Max     int `json:"max"`
+		Padding int `json:"padding"`
+	}
+
+	type failureThreshold struct {
+		Value float64 `json:"value"`
+		Type  string  `json:"type"`
+	}
+
+	v := struct {
+		Threshold         float64           `json:"threshold"`
+		IncludeAA         bool              `json:"includeAA"`
+		Alpha             float64           `json:"alpha"`
+		AAColor           string            `json:"aaColor"`
+		DiffColor         string            `json:"diffColor"`
+		DiffColorAlt      string            `json:"diffColorAlt,omitempty"`
+		DiffMask          bool              `json:"diffMask"`
+		RenderMode        string            `json:"renderMode,omitempty"`
+		BaseImage         string            `json:"baseImage,omitempty"`
+		Heatmap           bool              `json:"heatmap,omitempty"`
+		Metric            string            `json:"metric"`
+		SizeMismatch      string            `json:"sizeMismatch"`
+		IgnoreRegions     []image.Rectangle `json:"ignoreRegions,omitempty"`
+		IgnoreMask        bool              `json:"ignoreMask,omitempty"`
+		Region            *image.Rectangle  `json:"region,omitempty"`
+		IgnoreColors      bool              `json:"ignoreColors,omitempty"`
+		IgnoreAlpha       bool              `json:"ignoreAlpha,omitempty"`
+		IgnoreLessThan    float64           `json:"ignoreLessThan,omitempty"`
+		IgnoreTransparent bool              `json:"ignoreTransparent,omitempty"`
+		Shift             int               `json:"shiftTolerance,omitempty"`
+		Blur              float64           `json:"blur,omitempty"`
+		Edges             bool              `json:"edges,omitempty"`
+		TextThreshold     float64           `json:"textThreshold,omitempty"`
+		Scale             float64           `json:"scale,omitempty"`
+		MaxDimension      int               `json:"maxDimension,omitempty"`
+		AutoAlign         int               `json:"autoAlign,omitempty"`
+		Orientation       string            `json:"orientation,omitempty"`
+		AutoOrient        bool              `json:"autoOrient,omitempty"`
+		ColorManagement   bool              `json:"colorManagement,omitempty"`
+		LinearLight       bool              `json:"linearLight,omitempty"`
+		AlphaMode         string            `json:"alphaMode,omitempty"`
+		MedianFilter      bool              `json:"medianFilter,omitempty"`
+		IgnoreIsolated    bool              `json:"ignoreIsolated,omitempty"`
+		MaskErode         int               `json:"maskErode,omitempty"`
+		MaskDilate        int               `json:"maskDilate,omitempty"`
+		SampleRate        int               `json:"sampleRate,omitempty"`
+		CoarsePass        bool              `json:"coarsePass,omitempty"`
+		FailFast          *failFast         `json:"failFast,omitempty"`
+		FailureThreshold  *failureThreshold `json:"failureThreshold,omitempty"`
+		Grid              *grid             `json:"grid,omitempty"`
+		Clusters          *clusters         `json:"clusters,omitempty"`
+		Boxes             *boxes            `json:"boxes,omitempty"`
+		ChannelDiff       bool              `json:"channelDiff,omitempty"`
+		ExtraMetrics      bool              `json:"extraMetrics,omitempty"`
+		AAMask            bool              `json:"aaMask,omitempty"`
+	}{
+		Threshold:         o.threshold,
+		IncludeAA:         o.includeAA,
+		Alpha:             o.alpha,
+		AAColor:           hexColor(o.aaColor),
+		DiffColor:         hexColor(o.diffColor),
+		DiffMask:          o.diffMask,
+		Heatmap:           o.heatmap,
+		Metric:            metricName(o.metric),
+		SizeMismatch:      o.sizeMismatch.String(),
+		IgnoreRegions:     o.ignoreRegions,
+		IgnoreMask:        o.ignoreMask != nil,
+		Region:            o.region,
+		IgnoreColors:      o.ignoreColors,
+		IgnoreAlpha:       o.ignoreAlpha,
+		IgnoreLessThan:    o.ignoreLessThan,
+		IgnoreTransparent: o.ignoreTransparent,
+		Shift:             o.shift,
+		Blur:              o.blur,
+		Edges:             o.edges,
+		TextThreshold:     o.textThreshold,
+		Scale:             o.scale,
+		MaxDimension:      o.maxDimension,
+		AutoAlign:         o.autoAlign,
+		Orientation:       o.orientationMode(),
+		AutoOrient:        o.autoOrient,
+		ColorManagement:   o.colorManagement,
+		LinearLight:       o.linearLight,
+		MedianFilter:      o.median,
+		IgnoreIsolated:    o.ignoreIsolated,
+		MaskErode:         o.maskErode,
+		MaskDilate:        o.maskDilate,
+		SampleRate:        o.sampleRate,
+		CoarsePass:        o.coarse,
+		ChannelDiff:       o.channelDiff,
+		ExtraMetrics:      o.extraMetrics,
+		AAMask:            o.aaMask,
+	}
+
+	if o.diffColorAlt != nil {
+		v.DiffColorAlt = hexColor(*o.diffColorAlt)
+	}
+	if o.renderMode != RenderDiff {
+		v.RenderMode = o.renderMode.String()
+	}
+	if o.baseImage != BaseGray {
+		v.BaseImage = o.baseImage.String()
+	}
+	if o.alphaMode != AlphaAuto {
+		v.AlphaMode = o.alphaMode.String()
+	}
+	if o.failFast {
+		v.FailFast = &failFast{MaxDiffPixels: o.failFastMax}
+	}
+	if o.failureThreshold > 0 {
+		v.FailureThreshold = &failureThreshold{Value: o.failureThreshold, Type: "pixel"}
+		if o.failurePercent {
+			v.FailureThreshold.Type = "percent"
+		}
+	}
+	if o.gridCols > 0 && o.gridRows > 0 {
+		v.Grid = &grid{Cols: o.gridCols, Rows: o.gridRows}
+	}
+	if o.clusters {
+		v.Clusters = &clusters{MinSize: o.clusterMinSize}
+	}
+	if o.boxes {
+		v.Boxes = &boxes{Max: o.maxBoxes, Padding: o.boxPadding}
+	}
+
+	return json.Marshal(v)
+}
+
+// hexColor formats c as #rrggbbaa.
+func hexColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// metricName returns the name of a built-in metric, the String of a metric
+// implementing fmt.Stringer or the type name of the metric otherwise.
+func metricName(m Metric) string {
+	switch m.(type) {
+	case yiqMetric:
+		return "yiq"
+	case ssimMetric:
+		return "ssim"
+	case cie76Metric:
+		return "cie76"
+	case ciede2000Metric:
+		return "ciede2000"
+	case rgbMetric:
+		return "rgb"
+	case fmt.Stringer:
+		return m.(fmt.Stringer).String()
+	default:
+		return fmt.Sprintf("%T", m)
+	}
+}
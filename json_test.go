@@ -0,0 +1,56 @@
+package pixelmatch
+
+import (
+	"encoding/json"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestDiffResultMarshalJSON(t *testing.T) {
+	img1 := decodeTestImage(t, "./testdata/img1.png")
+	img2 := decodeTestImage(t, "./testdata/img2.png")
+
+	result, err := Diff(img1, img2, nil, WithThreshold(0.05), WithClusters(10), WithMetric(MetricCIE76))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded struct {
+		DiffPixels uint64          `json:"diffPixels"`
+		Percent    float64         `json:"percent"`
+		Bounds     image.Rectangle `json:"bounds"`
+		Clusters   []Cluster       `json:"clusters"`
+		Options    struct {
+			Threshold float64 `json:"threshold"`
+			Metric    string  `json:"metric"`
+			DiffColor string  `json:"diffColor"`
+			Clusters  struct {
+				MinSize int `json:"minSize"`
+			} `json:"clusters"`
+		} `json:"options"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.DiffPixels != result.DiffPixels || decoded.Percent != result.Percent || decoded.Bounds != result.Bounds {
+		t.Errorf("Expected the statistics of %+v, got - %s", result, data)
+	}
+	if len(decoded.Clusters) != len(result.Clusters) || len(decoded.Clusters) == 0 || decoded.Clusters[0] != result.Clusters[0] {
+		t.Errorf("Expected clusters %v, got - %v", result.Clusters, decoded.Clusters)
+	}
+
+	opts := decoded.Options
+	if opts.Threshold != 0.05 || opts.Metric != "cie76" || opts.DiffColor != "#ff0000ff" || opts.Clusters.MinSize != 10 {
+		t.Errorf("Unexpected options %s", data)
+	}
+	if strings.Contains(string(data), "ssimMap") {
+		t.Errorf("Expected unused fields to be omitted, got - %s", data)
+	}
+}
@@ -0,0 +1,45 @@
+package pixelmatch
+
+// WithLinearLight converts the sRGB values of the pixels to linear light
+// before computing their YIQ difference. Steps between dark colors shrink
+// and steps between bright ones grow, so differences follow the physical
+// amount of light, as photography and rendering work needs, rather than the
+// encoded values. It applies to the YIQ and SSIM metrics and to the color
+// tolerance presets; 16-bit images are then compared by their upper 8 bits.
+func WithLinearLight() Option {
+	return func(o *Options) {
+		o.linearLight = true
+	}
+}
+
+// linearValues maps 8-bit sRGB values to linear light scaled to 0..255.
+var linearValues = func() (t [256]float64) {
+	for v := range t {
+		t[v] = 255 * srgbToLinear(float64(v)/255)
+	}
+
+	return t
+}()
+
+// linearColorDelta is colorDelta of the colors in linear light.
+func linearColorDelta(c1, c2 [4]uint8, yOnly bool) float64 {
+	if colorEq(c1, c2) {
+		return 0
+	}
+
+	r1, g1, b1 := linearBlendColor(c1)
+	r2, g2, b2 := linearBlendColor(c2)
+
+	return yiqDelta(r1, g1, b1, r2, g2, b2, yOnly)
+}
+
+// linearBlendColor is blendColor in linear light, where white is 255 as well.
+func linearBlendColor(c [4]uint8) (r, g, b float64) {
+	r, g, b = linearValues[c[0]], linearValues[c[1]], linearValues[c[2]]
+	if c[3] < 255 {
+		a := float64(c[3]) / 255
+		r, g, b = blend(r, a), blend(g, a), blend(b, a)
+	}
+
+	return r, g, b
+}
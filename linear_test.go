@@ -0,0 +1,42 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWithLinearLight(t *testing.T) {
+	// a dark step and a bright one of similar size in linear light
+	img1, img2 := image.NewNRGBA(image.Rect(0, 0, 2, 1)), image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img1.SetNRGBA(0, 0, color.NRGBA{10, 10, 10, 255})
+	img2.SetNRGBA(0, 0, color.NRGBA{30, 30, 30, 255})
+	img1.SetNRGBA(1, 0, color.NRGBA{230, 230, 230, 255})
+	img2.SetNRGBA(1, 0, color.NRGBA{238, 238, 238, 255})
+
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		diff int
+	}{
+		{"sRGB", nil, 0},
+		{"linear", []Option{WithLinearLight()}, 1},
+		{"linear brightness", []Option{WithLinearLight(), IgnoreColors()}, 1},
+		{"linear SSIM", []Option{WithLinearLight(), WithMetric(MetricSSIM)}, 1},
+	} {
+		opts := append([]Option{WithThreshold(0.05), WithIncludeAA(true)}, tc.opts...)
+
+		output := image.NewNRGBA(img1.Rect)
+		result, err := Diff(img1, img2, output, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.DiffPixels != 1 || output.NRGBAAt(tc.diff, 0) != (color.NRGBA{255, 0, 0, 255}) {
+			t.Errorf("%s: expected pixel %d to differ, got - %d %v", tc.name, tc.diff, result.DiffPixels, output.Pix)
+		}
+
+		if count, err := Compare(img1, img2, opts...); err != nil || count != 1 {
+			t.Errorf("%s: expected Compare to count 1, got - %d %v", tc.name, count, err)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package pixelmatch
+
+import (
+	"fmt"
+	"image"
+)
+
+// debugLogger receives the debug logs of comparisons; *slog.Logger is one,
+// see WithLogger.
+type debugLogger interface {
+	Debug(msg string, args ...any)
+}
+
+// debug logs msg with the key-value pairs of args when a logger is set.
+func (o *Options) debug(msg string, args ...any) {
+	if o.logger != nil {
+		o.logger.Debug(msg, args...)
+	}
+}
+
+// debugConverted logs the images that are copied to *image.NRGBA.
+func (o *Options) debugConverted(imgs ...image.Image) {
+	if o.logger == nil {
+		return
+	}
+
+	for i, img := range imgs {
+		if _, ok := img.(*image.NRGBA); !ok {
+			o.debug("converted image to NRGBA", "image", i+1, "type", fmt.Sprintf("%T", img))
+		}
+	}
+}
+
+// debugBands logs how the rows of r are split among the workers.
+func (o *Options) debugBands(r image.Rectangle, workers int) {
+	if o.logger == nil {
+		return
+	}
+
+	o.debug("comparing rows in bands", "region", r, "workers", workers, "bands", len(splitBands(r, workers)))
+}
@@ -0,0 +1,18 @@
+//go:build go1.21
+
+package pixelmatch
+
+import "log/slog"
+
+// WithLogger logs how comparisons are made at the debug level: conversions,
+// scaling, padding and cropping of the images, the bands the rows are split
+// into, early exits and the final counts, e.g. to find out why two images
+// that look identical differ in a few pixels. It needs Go 1.21.
+func WithLogger(l *slog.Logger) Option {
+	return func(o *Options) {
+		o.logger = nil
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
@@ -0,0 +1,39 @@
+//go:build go1.21
+
+package pixelmatch
+
+import (
+	"bytes"
+	"image"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	a := image.NewGray(image.Rect(0, 0, 16, 16))
+	b := patchedImage(255)
+	if _, err := Diff(a, b, nil, WithLogger(logger), WithSequential(), WithFailFast(1)); err != ErrDiffBudgetExceeded {
+		t.Fatalf("Expected ErrDiffBudgetExceeded, got - %v", err)
+	}
+
+	logs := buf.String()
+	for _, expected := range []string{
+		`msg="converted image to NRGBA" image=1 type=*image.Gray`,
+		`msg="comparing rows in bands" region=(0,0)-(16,16) workers=1 bands=4`,
+		`msg="stopped early, diff budget exceeded" max=1`,
+		`msg="compared images" diff=16 aa=0 total=16`,
+	} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("Expected the logs to contain %s, got:\n%s", expected, logs)
+		}
+	}
+
+	buf.Reset()
+	if _, err := Compare(b, b, WithLogger(nil)); err != nil || buf.Len() != 0 {
+		t.Errorf("Expected no logs without a logger, got - %v %q", err, buf.String())
+	}
+}
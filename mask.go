@@ -0,0 +1,246 @@
+package pixelmatch
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math/bits"
+)
+
+// ErrMask is returned for malformed encodings of a Mask.
+var ErrMask = errors.New("invalid mask encoding")
+
+// MaskPalette is the palette of the masks returned by DiffMask:
+// index 0 (black) for similar pixels and 1 (white) for different ones.
+// The PNG encoder stores images with such a palette at 1 bit per pixel.
+var MaskPalette = color.Palette{
+	color.NRGBA{A: 255},
+	color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+}
+
+// DiffMask compares img1 and img2 like Diff but returns the diff as a compact
+// paletted mask (see MaskPalette) where every different pixel is 1 and
+// everything else, including anti-aliased and ignored pixels, is 0.
+// Such masks are cheap to store and easy to feed into morphology tools.
+func DiffMask(img1, img2 image.Image, opts ...Option) (*image.Paletted, DiffResult, error) {
+	options := newOptions(opts...)
+	options.keepDiff = true
+
+	_, result, err := diff(context.Background(), img1, img2, nil, false, options)
+	if result.diff == nil {
+		return nil, result, err
+	}
+
+	return result.diff.paletted(), result, err
+}
+
+// WithAAMask keeps the pixels detected as anti-aliasing in DiffResult.AAMask,
+// a mask like those of DiffMask where every anti-aliased pixel is 1, for
+// auditing whether the anti-aliasing detector swallows real differences.
+// With WithIncludeAA(true) no pixels are detected and the mask is empty.
+func WithAAMask() Option {
+	return func(o *Options) {
+		o.aaMask = true
+	}
+}
+
+// paletted draws the set as a mask with MaskPalette.
+func (s *pixelSet) paletted() *image.Paletted {
+	mask := image.NewPaletted(s.rect, MaskPalette)
+
+	for y := s.rect.Min.Y; y < s.rect.Max.Y; y++ {
+		row := s.words[(y-s.rect.Min.Y)*s.stride:][:s.stride]
+		off := mask.PixOffset(s.rect.Min.X, y)
+
+		for i, word := range row {
+			for ; word != 0; word &= word - 1 {
+				mask.Pix[off+i*64+bits.TrailingZeros64(word)] = 1
+			}
+		}
+	}
+
+	return mask
+}
+
+// WithResultMask keeps the different pixels of the comparison for
+// DiffResult.Mask.
+func WithResultMask() Option {
+	return func(o *Options) {
+		o.keepDiff = true
+	}
+}
+
+// Mask is the set of different pixels of a comparison, see DiffResult.Mask.
+// Its binary encoding stores the lengths of the runs of similar and
+// different pixels row by row, a few bytes per blob of changes, so masks
+// can be stored by the million and drawn again on demand with Image.
+// The zero Mask is empty.
+type Mask struct {
+	set *pixelSet
+}
+
+// Mask returns the different pixels of the comparison, after WithMaskErode
+// and WithMaskDilate; nil unless WithResultMask, DiffMask or WithIncremental
+// kept them. The mask must not be modified.
+func (r DiffResult) Mask() *Mask {
+	if r.diff == nil {
+		return nil
+	}
+
+	return &Mask{set: r.diff}
+}
+
+// Bounds returns the bounds of the compared images.
+func (m *Mask) Bounds() image.Rectangle {
+	if m.set == nil {
+		return image.Rectangle{}
+	}
+
+	return m.set.rect
+}
+
+// Has reports whether the pixel at x, y differs.
+func (m *Mask) Has(x, y int) bool {
+	return m.set.has(x, y)
+}
+
+// Count returns the number of different pixels.
+func (m *Mask) Count() uint64 {
+	if m.set == nil {
+		return 0
+	}
+
+	return m.set.count()
+}
+
+// Image draws the mask with MaskPalette like DiffMask.
+func (m *Mask) Image() *image.Paletted {
+	if m.set == nil {
+		return image.NewPaletted(image.Rectangle{}, MaskPalette)
+	}
+
+	return m.set.paletted()
+}
+
+// maskMagic starts the binary encoding of a Mask, followed by its version.
+const maskMagic = "PXM\x01"
+
+// maxMaskPixels limits the size of decoded masks, the largest 8-bit
+// images the comparison can hold.
+const maxMaskPixels = 1 << 32
+
+// MarshalBinary encodes the mask: the magic bytes, the bounds as varints
+// and then the lengths of the alternating runs of similar and different
+// pixels in row-major order as uvarints, starting with similar pixels.
+func (m *Mask) MarshalBinary() ([]byte, error) {
+	r := m.Bounds()
+
+	buf := []byte(maskMagic)
+	buf = binary.AppendVarint(buf, int64(r.Min.X))
+	buf = binary.AppendVarint(buf, int64(r.Min.Y))
+	buf = binary.AppendUvarint(buf, uint64(r.Dx()))
+	buf = binary.AppendUvarint(buf, uint64(r.Dy()))
+	if m.set == nil {
+		return buf, nil
+	}
+
+	var (
+		s   = m.set
+		w   = r.Dx()
+		on  bool
+		run uint64
+	)
+	add := func(set bool, n int) {
+		if set != on {
+			buf = binary.AppendUvarint(buf, run)
+			on, run = set, 0
+		}
+		run += uint64(n)
+	}
+
+	for y := 0; y < r.Dy(); y++ {
+		row := s.words[y*s.stride:][:s.stride]
+		for i, word := range row {
+			n := minInt(64, w-i*64)
+			switch {
+			case word == 0:
+				add(false, n)
+			case n == 64 && word == ^uint64(0):
+				add(true, n)
+			default:
+				for b := 0; b < n; b++ {
+					add(word&(1<<b) != 0, 1)
+				}
+			}
+		}
+	}
+
+	// the similar pixels at the end are implied
+	if on {
+		buf = binary.AppendUvarint(buf, run)
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a mask encoded by MarshalBinary.
+func (m *Mask) UnmarshalBinary(data []byte) error {
+	if len(data) < len(maskMagic) || string(data[:len(maskMagic)]) != maskMagic {
+		return fmt.Errorf("%w: missing signature", ErrMask)
+	}
+	data = data[len(maskMagic):]
+
+	var header [4]int64
+	for i := range header {
+		var n int
+		if i < 2 {
+			header[i], n = binary.Varint(data)
+		} else {
+			var v uint64
+			v, n = binary.Uvarint(data)
+			header[i] = int64(v)
+			if v > maxMaskPixels {
+				n = -1
+			}
+		}
+		if n <= 0 {
+			return fmt.Errorf("%w: invalid bounds", ErrMask)
+		}
+		data = data[n:]
+	}
+
+	w, h := header[2], header[3]
+	if h > 0 && w > maxMaskPixels/h {
+		return fmt.Errorf("%w: size %dx%d exceeds %d pixels", ErrMask, w, h, int64(maxMaskPixels))
+	}
+
+	r := image.Rect(int(header[0]), int(header[1]), int(header[0]+w), int(header[1]+h))
+	s := newPixelSet(r)
+
+	var (
+		pos, total = int64(0), w * h
+		on         bool
+	)
+	for len(data) > 0 {
+		run, n := binary.Uvarint(data)
+		if n <= 0 || run > uint64(total-pos) {
+			return fmt.Errorf("%w: run beyond the bounds", ErrMask)
+		}
+		data = data[n:]
+
+		if on {
+			for p := pos; p < pos+int64(run); p++ {
+				s.set(r.Min.X+int(p%w), r.Min.Y+int(p/w))
+			}
+		}
+		pos += int64(run)
+		on = !on
+	}
+
+	m.set = s
+
+	return nil
+}
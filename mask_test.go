@@ -0,0 +1,198 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestDiffMask(t *testing.T) {
+	rect := image.Rect(0, 0, 100, 20)
+	img1, img2 := image.NewNRGBA(rect), image.NewNRGBA(rect)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img1.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+			img2.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+
+	changed := []image.Point{{0, 0}, {63, 0}, {64, 0}, {99, 19}, {40, 7}}
+	for _, p := range changed {
+		img2.SetNRGBA(p.X, p.Y, color.NRGBA{A: 255})
+	}
+
+	mask, result, err := DiffMask(img1, img2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != uint64(len(changed)) {
+		t.Errorf("Expected %d different pixels, got - %d", len(changed), result.DiffPixels)
+	}
+	if !mask.Bounds().Eq(rect) {
+		t.Fatalf("Expected mask bounds %v, got - %v", rect, mask.Bounds())
+	}
+
+	var ones int
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if mask.ColorIndexAt(x, y) == 1 {
+				ones++
+			}
+		}
+	}
+	for _, p := range changed {
+		if mask.ColorIndexAt(p.X, p.Y) != 1 {
+			t.Errorf("Expected %v to be set in the mask", p)
+		}
+	}
+	if ones != len(changed) {
+		t.Errorf("Expected %d set pixels, got - %d", len(changed), ones)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, mask); err != nil {
+		t.Fatal(err)
+	}
+	// IHDR bit depth right after the width and height
+	if depth := buf.Bytes()[24]; depth != 1 {
+		t.Errorf("Expected a 1-bit PNG, got bit depth - %d", depth)
+	}
+}
+
+func TestWithAAMask(t *testing.T) {
+	img1 := decodeTestImage(t, "./testdata/img1.png")
+	img2 := decodeTestImage(t, "./testdata/img2.png")
+
+	var aa []image.Point
+	callback := func(x, y int, delta float64, kind PixelKind) {
+		if kind == PixelAntialiased {
+			aa = append(aa, image.Pt(x, y))
+		}
+	}
+
+	result, err := Diff(img1, img2, nil, WithAAMask(), WithPixelCallback(callback), WithParallelism(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AAMask == nil || result.AAPixels == 0 || !result.AAMask.Rect.Eq(img1.Bounds()) {
+		t.Fatalf("Expected a mask of anti-aliased pixels, got - %v", result.AAMask)
+	}
+	if ones := maskOnes(result.AAMask); ones != int(result.AAPixels) {
+		t.Errorf("Expected %d anti-aliased pixels in the mask, got - %d", result.AAPixels, ones)
+	}
+	for _, p := range aa {
+		if result.AAMask.ColorIndexAt(p.X, p.Y) != 1 {
+			t.Fatalf("Expected anti-aliased pixel %v in the mask", p)
+		}
+	}
+
+	result, err = Diff(img1, img2, nil, WithAAMask(), WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AAMask == nil || maskOnes(result.AAMask) != 0 {
+		t.Errorf("Expected an empty mask with WithIncludeAA(true)")
+	}
+
+	if result, _ := Diff(img1, img2, nil); result.AAMask != nil {
+		t.Errorf("Expected no mask without WithAAMask")
+	}
+}
+
+func maskOnes(mask *image.Paletted) int {
+	var n int
+	for _, v := range mask.Pix {
+		n += int(v)
+	}
+
+	return n
+}
+
+func TestDiffResultMask(t *testing.T) {
+	rect := image.Rect(-3, 5, 130, 40)
+	img1, img2 := image.NewNRGBA(rect), image.NewNRGBA(rect)
+	for i := range img1.Pix {
+		img1.Pix[i], img2.Pix[i] = 255, 255
+	}
+	for y := 10; y < 30; y++ {
+		for x := 20; x < 110; x++ {
+			img2.SetNRGBA(x, y, color.NRGBA{A: 255})
+		}
+	}
+	img2.SetNRGBA(-3, 5, color.NRGBA{A: 255})
+	img2.SetNRGBA(129, 39, color.NRGBA{A: 255})
+
+	if result, err := Diff(img1, img2, nil); err != nil || result.Mask() != nil {
+		t.Fatalf("Expected no mask without WithResultMask, got - %v", err)
+	}
+
+	result, err := Diff(img1, img2, nil, WithResultMask())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mask := result.Mask()
+	if mask == nil || mask.Count() != result.DiffPixels || mask.Bounds() != rect {
+		t.Fatalf("Expected a mask of the %d pixels, got - %v", result.DiffPixels, mask)
+	}
+
+	data, err := mask.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a handful of runs per row of the changed block
+	if len(data) > 100 {
+		t.Errorf("Expected a compact encoding, got - %d bytes", len(data))
+	}
+
+	var decoded Mask
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != rect || decoded.Count() != mask.Count() {
+		t.Fatalf("Expected the decoded mask to match, got - %v %d", decoded.Bounds(), decoded.Count())
+	}
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if decoded.Has(x, y) != mask.Has(x, y) {
+				t.Fatalf("Expected %d,%d to match", x, y)
+			}
+		}
+	}
+	if !bytes.Equal(decoded.Image().Pix, mask.Image().Pix) {
+		t.Error("Expected the decoded mask to draw the same image")
+	}
+
+	var empty Mask
+	data, _ = empty.MarshalBinary()
+	if err := decoded.UnmarshalBinary(data); err != nil || decoded.Count() != 0 || !decoded.Bounds().Empty() {
+		t.Errorf("Expected an empty mask, got - %v", err)
+	}
+}
+
+func TestMaskUnmarshalBinary(t *testing.T) {
+	header := []byte(maskMagic + "\x00\x00\x04\x02")
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"signature": []byte("PNG\x01\x00\x00\x04\x02"),
+		"bounds":    []byte(maskMagic + "\x00\x00"),
+		"size":      []byte(maskMagic + "\x00\x00\xff\xff\xff\xff\x0f\xff\xff\xff\xff\x0f"),
+		"run":       append(header, 2, 7),
+		"truncated": append(header, 0x80),
+	} {
+		var m Mask
+		if err := m.UnmarshalBinary(data); !errors.Is(err, ErrMask) {
+			t.Errorf("%s: expected ErrMask, got - %v", name, err)
+		}
+	}
+
+	var m Mask
+	if err := m.UnmarshalBinary(append(header, 3, 2, 1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if m.Count() != 4 || !m.Has(3, 0) || !m.Has(0, 1) || m.Has(1, 1) || !m.Has(2, 1) {
+		t.Errorf("Expected the runs to set 3,0 0,1 2,1 3,1, got - %v", m.Image().Pix)
+	}
+}
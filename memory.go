@@ -0,0 +1,113 @@
+package pixelmatch
+
+import (
+	"errors"
+	"fmt"
+	"image"
+)
+
+// ErrMemoryLimit is returned by comparisons that would take more memory
+// than allowed by WithMaxMemory.
+var ErrMemoryLimit = errors.New("comparison exceeds memory limit")
+
+// WithMaxMemory refuses comparisons the estimated memory of which, see
+// EstimateMemory, exceeds bytes with ErrMemoryLimit before anything is
+// allocated, e.g. to protect a service from huge uploads. StreamDiffer
+// compares images of any size in memory proportional to their width.
+func WithMaxMemory(bytes uint64) Option {
+	return func(o *Options) {
+		o.maxMemory = bytes
+	}
+}
+
+// EstimateMemory returns an estimate of the bytes Diff allocates to compare
+// images with the given (union of) bounds, assuming they have 8-bit channels
+// and are not *image.NRGBA, so both get converted. Images with 16-bit
+// channels take 16 bytes per pixel more, DiffNew takes 4 bytes per pixel of
+// the output on top of it.
+func EstimateMemory(bounds image.Rectangle, opts ...Option) uint64 {
+	options := newOptions(opts...)
+
+	return options.memoryEstimate(bounds, false, false)
+}
+
+// memoryEstimate returns the estimate of EstimateMemory, including the output
+// when newOutput is set. The estimate covers the large buffers whose size
+// grows with the number of pixels: the converted and preprocessed copies of
+// the images, the output and the per-pixel sets.
+func (o *Options) memoryEstimate(r image.Rectangle, highDepth, newOutput bool) uint64 {
+	var (
+		bytes uint64
+		w, h  = r.Dx(), r.Dy()
+	)
+
+	if s := o.scaleFactor(r); s < 1 {
+		// the source of one image is converted at a time and released once scaled
+		bytes += 4 * uint64(w) * uint64(h)
+		scaled := scaleRect(r, s)
+		w, h = scaled.Dx(), scaled.Dy()
+	}
+
+	n := uint64(w) * uint64(h)
+	if newOutput {
+		out := o.renderMode.Bounds(image.Rect(0, 0, w, h))
+		bytes += 4 * uint64(out.Dx()) * uint64(out.Dy())
+	}
+
+	// converted, padded or cropped copies of both images
+	bytes += 2 * 4 * n
+
+	if o.blur > 0 {
+		// the blurred copies and the float32 channels of one of them
+		bytes += 2*4*n + 4*4*n
+	}
+	if o.median {
+		// the filtered copies
+		bytes += 2 * 4 * n
+	}
+	if o.edges {
+		// the edge maps and the float32 brightness of one of the images
+		bytes += 2*4*n + 4*n
+	}
+	if o.autoAlign > 0 {
+		// the shifted image and the brightness pyramids of both images
+		bytes += 4*n + 2*4*n*4/3
+	}
+	if o.renderMode != RenderDiff {
+		// the diff drawn before composing the output
+		bytes += 4 * n
+	}
+	if o.textTolerant() {
+		// the brightness of one of the images and the set of text-like pixels
+		bytes += 4*n + n/8
+	}
+	if highDepth && !o.edges && !o.median {
+		bytes += 2 * 8 * n
+	}
+
+	sets := 0
+	for _, on := range []bool{o.clusters || o.boxes, o.clusters || o.boxes || o.keepDiff, o.aaMask || o.keepAA, len(o.ignoreRegions) > 0 || o.ignoreMask != nil} {
+		if on {
+			sets++
+		}
+	}
+	bytes += uint64(sets) * n / 8
+
+	return bytes
+}
+
+// checkMemory returns ErrMemoryLimit when comparing the images would exceed
+// the memory limit of the options.
+func (o *Options) checkMemory(img1, img2 image.Image, newOutput bool) error {
+	if o.maxMemory == 0 || isEmptyImg(img1) || isEmptyImg(img2) {
+		return nil
+	}
+
+	estimate := o.memoryEstimate(img1.Bounds().Union(img2.Bounds()), isHighDepth(img1) || isHighDepth(img2), newOutput)
+	if estimate > o.maxMemory {
+		o.debug("refusing comparison over the memory limit", "estimate", estimate, "max", o.maxMemory)
+		return fmt.Errorf("%w: estimated %d bytes, at most %d allowed", ErrMemoryLimit, estimate, o.maxMemory)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"testing"
+)
+
+func TestEstimateMemory(t *testing.T) {
+	r := image.Rect(0, 0, 100, 100)
+
+	base := EstimateMemory(r)
+	if base != 2*4*100*100 {
+		t.Errorf("Expected the converted copies of both images, got - %d", base)
+	}
+	if blurred := EstimateMemory(r, WithBlur(1)); blurred <= base {
+		t.Errorf("Expected blurring to take more memory than %d, got - %d", base, blurred)
+	}
+	if scaled := EstimateMemory(r, WithScale(0.5)); scaled >= base {
+		t.Errorf("Expected downscaling to take less memory than %d, got - %d", base, scaled)
+	}
+
+	huge := EstimateMemory(image.Rect(0, 0, 20000, 20000))
+	if huge != 2*4*20000*20000 {
+		t.Errorf("Expected the estimate not to overflow, got - %d", huge)
+	}
+}
+
+func TestWithMaxMemory(t *testing.T) {
+	a, b := patchedImage(0), patchedImage(255)
+	limit := WithMaxMemory(EstimateMemory(a.Bounds()) - 1)
+
+	if _, err := Diff(a, b, nil, limit); !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("Expected Diff to fail with ErrMemoryLimit, got - %v", err)
+	}
+	if _, err := Compare(a, b, limit); !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("Expected Compare to fail with ErrMemoryLimit, got - %v", err)
+	}
+
+	// the output of DiffNew counts too
+	limit = WithMaxMemory(EstimateMemory(a.Bounds()))
+	if _, err := Compare(a, b, limit); err != nil {
+		t.Errorf("Expected Compare within the limit to succeed, got - %v", err)
+	}
+	if _, _, err := DiffNew(a, b, limit); !errors.Is(err, ErrMemoryLimit) {
+		t.Errorf("Expected DiffNew to fail with ErrMemoryLimit, got - %v", err)
+	}
+}
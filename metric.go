@@ -0,0 +1,238 @@
+package pixelmatch
+
+import "math"
+
+// Metric measures the difference between two colors.
+type Metric interface {
+	// Delta returns the squared distance between two NRGBA colors,
+	// negative when c2 is darker than c1 and zero for equal colors.
+	Delta(c1, c2 [4]uint8) float64
+
+	// MaxDelta returns the largest possible distance. A pixel is different
+	// when the absolute delta exceeds MaxDelta() * threshold * threshold.
+	MaxDelta() float64
+}
+
+var (
+	// MetricYIQ compares colors by the perceived difference in YIQ NTSC
+	// transmission color space; this is the default metric.
+	MetricYIQ Metric = yiqMetric{}
+
+	// MetricCIE76 compares colors by the Euclidean distance in CIELAB (ΔE*ab 1976).
+	MetricCIE76 Metric = cie76Metric{}
+
+	// MetricCIEDE2000 compares colors by the CIEDE2000 color difference (ΔE00),
+	// the most accurate and the slowest of the built-in metrics.
+	MetricCIEDE2000 Metric = ciede2000Metric{}
+
+	// MetricRGB compares colors by the plain Euclidean distance of RGB channels.
+	MetricRGB Metric = rgbMetric{}
+)
+
+// WithMetric sets the metric used to tell whether two pixels differ.
+// Semi-transparent colors are blended with white before measuring.
+func WithMetric(m Metric) Option {
+	return func(o *Options) {
+		if m != nil {
+			o.metric = m
+		}
+	}
+}
+
+type yiqMetric struct{}
+
+func (yiqMetric) Delta(c1, c2 [4]uint8) float64 { return colorDelta(c1, c2, false) }
+
+// 35215 is the maximum possible value for the YIQ difference metric
+func (yiqMetric) MaxDelta() float64 { return 35215 }
+
+type rgbMetric struct{}
+
+func (rgbMetric) Delta(c1, c2 [4]uint8) float64 {
+	if c1 == c2 {
+		return 0
+	}
+
+	r1, g1, b1 := blendColor(c1)
+	r2, g2, b2 := blendColor(c2)
+
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+
+	return darkerSign(rgb2y(r1, g1, b1), rgb2y(r2, g2, b2)) * (dr*dr + dg*dg + db*db)
+}
+
+func (rgbMetric) MaxDelta() float64 { return 3 * 255 * 255 }
+
+type cie76Metric struct{}
+
+func (cie76Metric) Delta(c1, c2 [4]uint8) float64 {
+	if c1 == c2 {
+		return 0
+	}
+
+	l1, a1, b1 := colorToLab(c1)
+	l2, a2, b2 := colorToLab(c2)
+
+	dl, da, db := l1-l2, a1-a2, b1-b2
+
+	return darkerSign(l1, l2) * (dl*dl + da*da + db*db)
+}
+
+// distance from black to white; more saturated pairs may exceed it
+func (cie76Metric) MaxDelta() float64 { return 100 * 100 }
+
+type ciede2000Metric struct{}
+
+func (ciede2000Metric) Delta(c1, c2 [4]uint8) float64 {
+	if c1 == c2 {
+		return 0
+	}
+
+	l1, a1, b1 := colorToLab(c1)
+	l2, a2, b2 := colorToLab(c2)
+
+	de := ciede2000(l1, a1, b1, l2, a2, b2)
+
+	return darkerSign(l1, l2) * de * de
+}
+
+// distance from black to white
+func (ciede2000Metric) MaxDelta() float64 { return 100 * 100 }
+
+// darkerSign returns -1 when the second brightness is lower, 1 otherwise.
+func darkerSign(y1, y2 float64) float64 {
+	if y1 > y2 {
+		return -1
+	}
+
+	return 1
+}
+
+// colorToLab converts an sRGB color blended with white to CIELAB (D65).
+func colorToLab(c [4]uint8) (l, a, b float64) {
+	r, g, bl := blendColor(c)
+	r, g, bl = srgbToLinear(r/255), srgbToLinear(g/255), srgbToLinear(bl/255)
+
+	// linear sRGB to XYZ, normalized by the D65 white point
+	x := (0.4124564*r + 0.3575761*g + 0.1804375*bl) / 0.95047
+	y := 0.2126729*r + 0.7151522*g + 0.0721750*bl
+	z := (0.0193339*r + 0.1191920*g + 0.9503041*bl) / 1.08883
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func labF(t float64) float64 {
+	const epsilon = 216.0 / 24389.0
+	const kappa = 24389.0 / 27.0
+
+	if t > epsilon {
+		return math.Cbrt(t)
+	}
+
+	return (kappa*t + 16) / 116
+}
+
+// ciede2000 computes the CIEDE2000 color difference with kL = kC = kH = 1,
+// following "The CIEDE2000 Color-Difference Formula: Implementation Notes,
+// Supplementary Test Data, and Mathematical Observations" by G. Sharma et al.
+func ciede2000(l1, a1, b1, l2, a2, b2 float64) float64 {
+	const pow25to7 = 6103515625.0 // 25^7
+
+	var (
+		c1   = math.Hypot(a1, b1)
+		c2   = math.Hypot(a2, b2)
+		cAvg = (c1 + c2) / 2
+		c7   = math.Pow(cAvg, 7)
+		g    = 0.5 * (1 - math.Sqrt(c7/(c7+pow25to7)))
+
+		a1p = (1 + g) * a1
+		a2p = (1 + g) * a2
+		c1p = math.Hypot(a1p, b1)
+		c2p = math.Hypot(a2p, b2)
+		h1p = hueAngle(b1, a1p)
+		h2p = hueAngle(b2, a2p)
+
+		dLp = l2 - l1
+		dCp = c2p - c1p
+		dhp float64
+	)
+
+	switch {
+	case c1p*c2p == 0:
+		dhp = 0
+	case math.Abs(h2p-h1p) <= 180:
+		dhp = h2p - h1p
+	case h2p-h1p > 180:
+		dhp = h2p - h1p - 360
+	default:
+		dhp = h2p - h1p + 360
+	}
+
+	dHp := 2 * math.Sqrt(c1p*c2p) * math.Sin(degToRad(dhp/2))
+
+	var (
+		lAvgP = (l1 + l2) / 2
+		cAvgP = (c1p + c2p) / 2
+		hAvgP float64
+	)
+
+	switch {
+	case c1p*c2p == 0:
+		hAvgP = h1p + h2p
+	case math.Abs(h1p-h2p) <= 180:
+		hAvgP = (h1p + h2p) / 2
+	case h1p+h2p < 360:
+		hAvgP = (h1p + h2p + 360) / 2
+	default:
+		hAvgP = (h1p + h2p - 360) / 2
+	}
+
+	var (
+		t = 1 - 0.17*math.Cos(degToRad(hAvgP-30)) +
+			0.24*math.Cos(degToRad(2*hAvgP)) +
+			0.32*math.Cos(degToRad(3*hAvgP+6)) -
+			0.20*math.Cos(degToRad(4*hAvgP-63))
+
+		dTheta = 30 * math.Exp(-((hAvgP-275)/25)*((hAvgP-275)/25))
+		c7p    = math.Pow(cAvgP, 7)
+		rc     = 2 * math.Sqrt(c7p/(c7p+pow25to7))
+		lTerm  = (lAvgP - 50) * (lAvgP - 50)
+		sl     = 1 + 0.015*lTerm/math.Sqrt(20+lTerm)
+		sc     = 1 + 0.045*cAvgP
+		sh     = 1 + 0.015*cAvgP*t
+		rt     = -math.Sin(degToRad(2*dTheta)) * rc
+
+		dl = dLp / sl
+		dc = dCp / sc
+		dh = dHp / sh
+	)
+
+	return math.Sqrt(dl*dl + dc*dc + dh*dh + rt*dc*dh)
+}
+
+func hueAngle(b, a float64) float64 {
+	if a == 0 && b == 0 {
+		return 0
+	}
+
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+
+	return h
+}
+
+func degToRad(d float64) float64 {
+	return d * math.Pi / 180
+}
@@ -0,0 +1,91 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestCIEDE2000(t *testing.T) {
+	// pairs from the supplementary test data by G. Sharma et al.
+	for _, tc := range []struct {
+		lab1, lab2 [3]float64
+		want       float64
+	}{
+		{[3]float64{50, 2.6772, -79.7751}, [3]float64{50, 0, -82.7485}, 2.0425},
+		{[3]float64{50, 0, 0}, [3]float64{50, -1, 2}, 2.3669},
+		{[3]float64{50, 2.5, 0}, [3]float64{73, 25, -18}, 27.1492},
+		{[3]float64{60.2574, -34.0099, 36.2677}, [3]float64{60.4626, -34.1751, 39.4387}, 1.2644},
+		{[3]float64{22.7233, 20.0904, -46.6940}, [3]float64{23.0331, 14.9730, -42.5619}, 2.0373},
+	} {
+		got := ciede2000(tc.lab1[0], tc.lab1[1], tc.lab1[2], tc.lab2[0], tc.lab2[1], tc.lab2[2])
+		if math.Abs(got-tc.want) > 1e-4 {
+			t.Errorf("%v %v: expected %v, got - %v", tc.lab1, tc.lab2, tc.want, got)
+		}
+	}
+}
+
+func TestColorToLab(t *testing.T) {
+	l, a, b := colorToLab([4]uint8{255, 255, 255, 255})
+	if math.Abs(l-100) > 1e-3 || math.Abs(a) > 1e-3 || math.Abs(b) > 1e-3 {
+		t.Errorf("Expected white to be 100,0,0, got - %v,%v,%v", l, a, b)
+	}
+
+	l, _, _ = colorToLab([4]uint8{0, 0, 0, 255})
+	if math.Abs(l) > 1e-9 {
+		t.Errorf("Expected black to be 0, got - %v", l)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	var (
+		white = [4]uint8{255, 255, 255, 255}
+		black = [4]uint8{0, 0, 0, 255}
+		red   = [4]uint8{255, 0, 0, 255}
+	)
+
+	for name, m := range map[string]Metric{
+		"yiq":       MetricYIQ,
+		"cie76":     MetricCIE76,
+		"ciede2000": MetricCIEDE2000,
+		"rgb":       MetricRGB,
+	} {
+		if d := m.Delta(red, red); d != 0 {
+			t.Errorf("%s: expected 0 for equal colors, got - %v", name, d)
+		}
+		if d := m.Delta(white, black); d >= 0 {
+			t.Errorf("%s: expected negative delta when the second color is darker, got - %v", name, d)
+		}
+		if d := m.Delta(black, white); d <= 0 || d > m.MaxDelta()*1.0001 {
+			t.Errorf("%s: expected positive delta up to %v, got - %v", name, m.MaxDelta(), d)
+		}
+	}
+}
+
+func TestDiffWithMetric(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	img1.SetNRGBA(0, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+	img2.SetNRGBA(0, 0, color.NRGBA{R: 104, G: 100, B: 100, A: 255})
+	img1.SetNRGBA(1, 0, color.NRGBA{R: 255, A: 255})
+	img2.SetNRGBA(1, 0, color.NRGBA{B: 255, A: 255})
+
+	for _, m := range []Metric{MetricYIQ, MetricCIE76, MetricCIEDE2000, MetricRGB} {
+		result, err := Diff(img1, img2, nil, WithMetric(m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.DiffPixels != 1 {
+			t.Errorf("%T: expected 1, got - %d", m, result.DiffPixels)
+		}
+
+		count, err := Compare(img1, img2, WithMetric(m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("%T: expected Compare to find 1, got - %d", m, count)
+		}
+	}
+}
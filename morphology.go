@@ -0,0 +1,98 @@
+package pixelmatch
+
+// WithMaskDilate grows the different pixels of the mask of DiffMask and of
+// the pixels grouped by WithClusters by n pixels in every direction, so
+// nearby fragments of one change join into a single blob. DiffPixels and
+// the diff image still report the pixels as compared.
+func WithMaskDilate(n int) Option {
+	return func(o *Options) {
+		o.maskDilate = n
+	}
+}
+
+// WithMaskErode shrinks the different pixels of the mask of DiffMask and of
+// the pixels grouped by WithClusters by n pixels from every side, removing
+// lines thinner than 2n+1 pixels such as hairline anti-aliasing artifacts.
+// Erosion comes before WithMaskDilate, so with the same n both remove thin
+// lines and keep the larger blobs as they are. The edges of the images do
+// not erode the pixels next to them.
+func WithMaskErode(n int) Option {
+	return func(o *Options) {
+		o.maskErode = n
+	}
+}
+
+// morph applies the erosion and dilation of the options to the set.
+func (s *pixelSet) morph(o *Options) {
+	if s == nil {
+		return
+	}
+
+	if o.maskErode > 0 {
+		s.invert()
+		s.dilate(o.maskErode)
+		s.invert()
+	}
+	if o.maskDilate > 0 {
+		s.dilate(o.maskDilate)
+	}
+}
+
+// dilate adds the pixels within n pixels of the set horizontally,
+// vertically and diagonally, a square of 2n+1 pixels around each.
+func (s *pixelSet) dilate(n int) {
+	h := s.rect.Dy()
+	prev := make([]uint64, s.stride)
+
+	for step := 0; step < n; step++ {
+		// one pixel to the left and right
+		for y := 0; y < h; y++ {
+			row := s.words[y*s.stride:][:s.stride]
+			var carry uint64
+			for i, word := range row {
+				var next uint64
+				if i+1 < len(row) {
+					next = row[i+1]
+				}
+				row[i] = word | word<<1 | carry>>63 | word>>1 | next<<63
+				carry = word
+			}
+			s.clearPadding(row)
+		}
+
+		// one pixel up and down
+		copy(prev, s.words[:s.stride])
+		for y := 0; y < h; y++ {
+			row := s.words[y*s.stride:][:s.stride]
+			for i, word := range row {
+				above := prev[i]
+				prev[i] = word
+				if y > 0 {
+					row[i] |= above
+				}
+				if y+1 < h {
+					row[i] |= s.words[(y+1)*s.stride+i]
+				}
+			}
+		}
+	}
+}
+
+// invert replaces the set by its complement within its rectangle.
+func (s *pixelSet) invert() {
+	for y := 0; y < s.rect.Dy(); y++ {
+		row := s.words[y*s.stride:][:s.stride]
+		for i := range row {
+			row[i] = ^row[i]
+		}
+		s.clearPadding(row)
+	}
+}
+
+// clearPadding clears the bits of the last word of a row beyond the
+// width of the rectangle.
+func (s *pixelSet) clearPadding(row []uint64) {
+	if w := s.rect.Dx() % 64; w != 0 && len(row) > 0 {
+		row[len(row)-1] &= 1<<w - 1
+	}
+}
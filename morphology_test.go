@@ -0,0 +1,72 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestPixelSetMorph(t *testing.T) {
+	// a block across the boundary of two words, a hairline and a lone pixel
+	set := func() *pixelSet {
+		s := newPixelSet(image.Rect(10, 0, 80, 12))
+		for y := 2; y < 7; y++ {
+			for x := 70; x < 75; x++ {
+				s.set(x, y)
+			}
+		}
+		for x := 10; x < 80; x++ {
+			s.set(x, 10)
+		}
+		s.set(40, 4)
+		return s
+	}
+
+	s := set()
+	s.morph(&Options{maskErode: 1})
+	if s.count() != 9 || s.bounds() != image.Rect(71, 3, 74, 6) {
+		t.Errorf("Expected the inner 3x3 pixels of the block, got - %d in %v", s.count(), s.bounds())
+	}
+
+	s = set()
+	s.morph(&Options{maskErode: 1, maskDilate: 1})
+	if s.count() != 25 || s.bounds() != image.Rect(70, 2, 75, 7) {
+		t.Errorf("Expected the block alone, got - %d in %v", s.count(), s.bounds())
+	}
+
+	s = newPixelSet(image.Rect(0, 0, 10, 10))
+	s.set(0, 0)
+	s.morph(&Options{maskDilate: 2})
+	if s.count() != 9 || s.bounds() != image.Rect(0, 0, 3, 3) {
+		t.Errorf("Expected the dilation clipped to the set, got - %d in %v", s.count(), s.bounds())
+	}
+
+	// the edges do not erode
+	s.morph(&Options{maskErode: 1})
+	if s.count() != 4 || s.bounds() != image.Rect(0, 0, 2, 2) {
+		t.Errorf("Expected the corner to stay, got - %d in %v", s.count(), s.bounds())
+	}
+}
+
+func TestDiffMaskMorphology(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 30, 30))
+	img2 := image.NewNRGBA(img1.Rect)
+	red := &image.Uniform{C: color.NRGBA{R: 255, A: 255}}
+	draw.Draw(img2, image.Rect(5, 5, 11, 11), red, image.Point{}, draw.Src)
+	draw.Draw(img2, image.Rect(0, 20, 30, 21), red, image.Point{}, draw.Src)
+
+	mask, result, err := DiffMask(img1, img2, WithIncludeAA(true), WithMaskErode(1), WithMaskDilate(1), WithClusters(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 36+30 {
+		t.Errorf("Expected the count of the compared pixels, got - %d", result.DiffPixels)
+	}
+	if len(result.Clusters) != 1 || result.Clusters[0].Pixels != 36 {
+		t.Errorf("Expected the block as the only cluster, got - %+v", result.Clusters)
+	}
+	if mask.ColorIndexAt(5, 5) != 1 || mask.ColorIndexAt(15, 20) != 0 {
+		t.Error("Expected the hairline to be eroded from the mask")
+	}
+}
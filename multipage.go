@@ -0,0 +1,197 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+
+	"golang.org/x/image/tiff"
+)
+
+// ErrTIFF is returned by DecodeTIFFPages for malformed TIFF files.
+var ErrTIFF = errors.New("invalid TIFF")
+
+// PageResult is the outcome of comparing a single page of two documents.
+type PageResult struct {
+	// page number, starting at 1
+	Page int `json:"page"`
+
+	// the page exists only in the second or only in the first document
+	// and was not compared
+	Added   bool `json:"added,omitempty"`
+	Removed bool `json:"removed,omitempty"`
+
+	Result DiffResult `json:"result"`
+	Err    error      `json:"-"`
+}
+
+// MultipageResult describes the outcome of comparing two documents page by page.
+type MultipageResult struct {
+	// results of all pages of the longer document, in order
+	Pages []PageResult `json:"pages"`
+
+	// number of pages of both documents
+	PagesA int `json:"pagesA"`
+	PagesB int `json:"pagesB"`
+
+	// number of compared pages with different pixels
+	DiffPages int `json:"diffPages"`
+}
+
+// Equal reports whether both documents have the same number of pages
+// and every page was compared without different pixels.
+func (r MultipageResult) Equal() bool {
+	return r.PagesA == r.PagesB && r.DiffPages == 0 && r.Err() == nil
+}
+
+// Err returns a *BatchError with the pages that failed to compare, e.g.
+// pages of different sizes, or nil if all of them were compared.
+func (r MultipageResult) Err() error {
+	var results []BatchResult
+	for _, page := range r.Pages {
+		results = append(results, BatchResult{Name: pageName(page.Page), Result: page.Result, Err: page.Err})
+	}
+
+	return BatchErr(results)
+}
+
+// DiffMultipage compares two documents given as their rasterized pages,
+// page N of a with page N of b, on the pool of goroutines of DiffBatch.
+// The extra pages of the longer document are reported as Added or Removed,
+// the pages that fail to compare carry their error instead of failing the
+// whole comparison.
+func DiffMultipage(a, b []image.Image, opts ...Option) MultipageResult {
+	result := MultipageResult{PagesA: len(a), PagesB: len(b)}
+
+	pairs := make([]ImagePair, 0, len(a))
+	for i := 0; i < len(a) && i < len(b); i++ {
+		pairs = append(pairs, ImagePair{Name: pageName(i + 1), A: a[i], B: b[i]})
+	}
+
+	for i, batch := range DiffBatch(pairs, opts...) {
+		result.Pages = append(result.Pages, PageResult{Page: i + 1, Result: batch.Result, Err: batch.Err})
+		if batch.Err == nil && batch.Result.DiffPixels > 0 {
+			result.DiffPages++
+		}
+	}
+
+	for i := len(pairs); i < len(a) || i < len(b); i++ {
+		result.Pages = append(result.Pages, PageResult{Page: i + 1, Added: i >= len(a), Removed: i >= len(b)})
+	}
+
+	return result
+}
+
+// DiffMultipageTIFF decodes two multipage TIFF files and compares them
+// page by page, see DiffMultipage.
+func DiffMultipageTIFF(a, b io.Reader, opts ...Option) (MultipageResult, error) {
+	pagesA, err := DecodeTIFFPages(a)
+	if err != nil {
+		return MultipageResult{}, err
+	}
+
+	pagesB, err := DecodeTIFFPages(b)
+	if err != nil {
+		return MultipageResult{}, err
+	}
+
+	return DiffMultipage(pagesA, pagesB, opts...), nil
+}
+
+// DecodeTIFFPages decodes every page (image file directory) of a TIFF file.
+func DecodeTIFFPages(r io.Reader) ([]image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets, order, err := tiffPageOffsets(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]image.Image, 0, len(offsets))
+	for i, offset := range offsets {
+		page := &tiffPage{Reader: bytes.NewReader(data)}
+		copy(page.header[:], data)
+		order.PutUint32(page.header[4:], offset)
+
+		img, err := tiff.Decode(page)
+		if err != nil {
+			return nil, fmt.Errorf("%w: page %d: %v", ErrTIFF, i+1, err)
+		}
+		pages = append(pages, img)
+	}
+
+	return pages, nil
+}
+
+// tiffPageOffsets returns the offsets of the image file directories
+// of a TIFF file and its byte order.
+func tiffPageOffsets(data []byte) ([]uint32, binary.ByteOrder, error) {
+	if len(data) < 8 {
+		return nil, nil, fmt.Errorf("%w: truncated header", ErrTIFF)
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return nil, nil, fmt.Errorf("%w: malformed header", ErrTIFF)
+	}
+
+	var (
+		offsets []uint32
+		seen    = map[uint32]bool{}
+	)
+
+	for offset := order.Uint32(data[4:]); offset != 0; {
+		if seen[offset] {
+			return nil, nil, fmt.Errorf("%w: directory loop", ErrTIFF)
+		}
+		seen[offset] = true
+
+		if uint64(offset)+2 > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("%w: directory out of bounds", ErrTIFF)
+		}
+		next := uint64(offset) + 2 + 12*uint64(order.Uint16(data[offset:]))
+		if next+4 > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("%w: directory out of bounds", ErrTIFF)
+		}
+
+		offsets = append(offsets, offset)
+		offset = order.Uint32(data[next:])
+	}
+
+	if len(offsets) == 0 {
+		return nil, nil, fmt.Errorf("%w: no pages", ErrTIFF)
+	}
+
+	return offsets, order, nil
+}
+
+// tiffPage reads a TIFF file with the header pointing to one of its
+// pages, as tiff.Decode only decodes the first page.
+type tiffPage struct {
+	*bytes.Reader
+	header [8]byte
+}
+
+func (p *tiffPage) ReadAt(b []byte, off int64) (int, error) {
+	n, err := p.Reader.ReadAt(b, off)
+	if off < int64(len(p.header)) {
+		copy(b[:n], p.header[off:])
+	}
+
+	return n, err
+}
+
+func pageName(page int) string {
+	return fmt.Sprintf("page %d", page)
+}
@@ -0,0 +1,137 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"testing"
+)
+
+// encodeGrayTIFF builds an uncompressed little-endian TIFF with a page per image.
+func encodeGrayTIFF(pages ...*image.Gray) []byte {
+	buf := []byte("II*\x00\x00\x00\x00\x00")
+	next := 4 // position of the offset of the next directory
+
+	for _, page := range pages {
+		w, h := page.Rect.Dx(), page.Rect.Dy()
+		strip := len(buf)
+		for y := 0; y < h; y++ {
+			buf = append(buf, page.Pix[y*page.Stride:][:w]...)
+		}
+
+		binary.LittleEndian.PutUint32(buf[next:], uint32(len(buf)))
+		entries := [][2]uint32{
+			{256, uint32(w)},     // ImageWidth
+			{257, uint32(h)},     // ImageLength
+			{258, 8},             // BitsPerSample
+			{259, 1},             // Compression: none
+			{262, 1},             // PhotometricInterpretation: BlackIsZero
+			{273, uint32(strip)}, // StripOffsets
+			{277, 1},             // SamplesPerPixel
+			{278, uint32(h)},     // RowsPerStrip
+			{279, uint32(w * h)}, // StripByteCounts
+		}
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(len(entries)))
+		for _, e := range entries {
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(e[0]))
+			buf = binary.LittleEndian.AppendUint16(buf, 4) // LONG
+			buf = binary.LittleEndian.AppendUint32(buf, 1)
+			buf = binary.LittleEndian.AppendUint32(buf, e[1])
+		}
+		next = len(buf)
+		buf = append(buf, 0, 0, 0, 0)
+	}
+
+	return buf
+}
+
+func grayPage(w, h int, v uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = v
+	}
+
+	return img
+}
+
+func TestDecodeTIFFPages(t *testing.T) {
+	pages, err := DecodeTIFFPages(bytes.NewReader(encodeGrayTIFF(grayPage(4, 3, 10), grayPage(2, 5, 200))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("Expected 2 pages, got - %d", len(pages))
+	}
+	if b := pages[1].Bounds(); b != image.Rect(0, 0, 2, 5) {
+		t.Errorf("Expected a 2x5 second page, got - %v", b)
+	}
+	if r, _, _, _ := pages[1].At(1, 1).RGBA(); r>>8 != 200 {
+		t.Errorf("Expected gray 200, got - %d", r>>8)
+	}
+
+	for name, data := range map[string][]byte{
+		"not a TIFF": []byte("\x89PNG\r\n\x1a\n"),
+		"no pages":   []byte("II*\x00\x00\x00\x00\x00"),
+		"loop":       []byte("II*\x00\x08\x00\x00\x00\x00\x00\x08\x00\x00\x00"),
+		"truncated":  []byte("II*\x00\x08\x00\x00\x00\x05\x00"),
+	} {
+		if _, err := DecodeTIFFPages(bytes.NewReader(data)); !errors.Is(err, ErrTIFF) {
+			t.Errorf("%s: expected ErrTIFF, got - %v", name, err)
+		}
+	}
+}
+
+func TestDiffMultipageTIFF(t *testing.T) {
+	changed := grayPage(4, 4, 10)
+	changed.Pix[5] = 250
+
+	a := encodeGrayTIFF(grayPage(4, 4, 10), grayPage(4, 4, 10), grayPage(4, 4, 10))
+	b := encodeGrayTIFF(grayPage(4, 4, 10), changed)
+
+	result, err := DiffMultipageTIFF(bytes.NewReader(a), bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Equal() || result.PagesA != 3 || result.PagesB != 2 || result.DiffPages != 1 {
+		t.Errorf("Expected 1 changed page out of 3 and 2, got - %+v", result)
+	}
+	if len(result.Pages) != 3 || !result.Pages[2].Removed || result.Pages[2].Added {
+		t.Fatalf("Expected a removed third page, got - %+v", result.Pages)
+	}
+	if result.Pages[0].Result.DiffPixels != 0 || result.Pages[1].Result.DiffPixels != 1 || result.Pages[1].Page != 2 {
+		t.Errorf("Expected a different pixel on the second page, got - %+v", result.Pages[:2])
+	}
+
+	result, err = DiffMultipageTIFF(bytes.NewReader(a), bytes.NewReader(a))
+	if err != nil || !result.Equal() {
+		t.Errorf("Expected equal documents, got - %+v, %v", result, err)
+	}
+
+	if _, err := DiffMultipageTIFF(bytes.NewReader(a), bytes.NewReader(nil)); !errors.Is(err, ErrTIFF) {
+		t.Errorf("Expected ErrTIFF, got - %v", err)
+	}
+}
+
+func TestDiffMultipageErrors(t *testing.T) {
+	a := []image.Image{grayPage(4, 4, 10), grayPage(4, 4, 10)}
+	b := []image.Image{grayPage(4, 4, 10), grayPage(5, 4, 10), grayPage(4, 4, 10)}
+
+	result := DiffMultipage(a, b)
+	if !result.Pages[2].Added || result.Equal() {
+		t.Errorf("Expected an added third page, got - %+v", result.Pages)
+	}
+
+	var batchErr *BatchError
+	if err := result.Err(); !errors.As(err, &batchErr) || len(batchErr.Failed) != 1 || !errors.Is(err, ErrImageSize) {
+		t.Fatalf("Expected the second page to fail with ErrImageSize, got - %v", err)
+	}
+	if batchErr.Failed[0].Name != "page 2" {
+		t.Errorf("Expected page 2 to fail, got - %s", batchErr.Failed[0].Name)
+	}
+
+	// differently sized pages compare with a size mismatch mode
+	if err := DiffMultipage(a, b, WithSizeMismatch(SizeMismatchCrop)).Err(); err != nil {
+		t.Errorf("Expected no errors, got - %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package pixelmatch
+
+import (
+	"context"
+	"image"
+	"math"
+)
+
+// WithMedianFilter replaces every channel of every pixel of both images by
+// the median of its 3x3 neighbourhood before comparing them, which removes
+// the speckles of camera sensor noise while keeping edges sharp, unlike
+// WithBlur. The diff image is drawn over the filtered first image.
+func WithMedianFilter() Option {
+	return func(o *Options) {
+		o.median = true
+	}
+}
+
+// IgnoreIsolatedPixels does not count different pixels none of whose eight
+// neighbours differ as well, e.g. single noisy pixels of photographs; they
+// are drawn as similar ones. Differences of two pixels or more are reported
+// as before.
+func IgnoreIsolatedPixels() Option {
+	return func(o *Options) {
+		o.ignoreIsolated = true
+	}
+}
+
+// isolated reports whether none of the compared neighbours of x, y
+// differs between a and b.
+func (c *pixelComparer) isolated(a, b *image.NRGBA, x, y int) bool {
+	r := image.Rect(x-1, y-1, x+2, y+2).Intersect(c.bounds).Intersect(a.Rect)
+
+	for ny := r.Min.Y; ny < r.Max.Y; ny++ {
+		for nx := r.Min.X; nx < r.Max.X; nx++ {
+			if nx == x && ny == y || c.ignore.ignored(nx, ny) {
+				continue
+			}
+			if math.Abs(c.delta(getColor(a, nx, ny), getColor(b, nx, ny))) > c.maxDelta {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// medianNRGBA returns a copy of img taken from the pool with every channel
+// replaced by the median of the 3x3 neighbourhood, repeating the edge pixels.
+func medianNRGBA(img *image.NRGBA, options *Options) *image.NRGBA {
+	var (
+		r    = img.Rect
+		w, h = r.Dx(), r.Dy()
+		dst  = options.pool.newNRGBA(r)
+	)
+
+	runBands(context.Background(), r, options.workers(), func(band image.Rectangle) {
+		var window [9]uint8
+
+		for y := band.Min.Y - r.Min.Y; y < band.Max.Y-r.Min.Y; y++ {
+			rows := [3][]uint8{}
+			for i := range rows {
+				rows[i] = img.Pix[img.PixOffset(r.Min.X, r.Min.Y+clampInt(y+i-1, 0, h-1)):][:w*4]
+			}
+			out := dst.Pix[dst.PixOffset(r.Min.X, r.Min.Y+y):][:w*4]
+
+			for x := 0; x < w; x++ {
+				cols := [3]int{maxInt(x-1, 0) * 4, x * 4, minInt(x+1, w-1) * 4}
+				for ch := 0; ch < 4; ch++ {
+					n := 0
+					for _, row := range rows {
+						for _, col := range cols {
+							window[n] = row[col+ch]
+							n++
+						}
+					}
+					out[x*4+ch] = median9(&window)
+				}
+			}
+		}
+	})
+
+	return dst
+}
+
+// median9 returns the median of the values, reordering them.
+func median9(v *[9]uint8) uint8 {
+	// insertion sort, as good as any for nine values
+	for i := 1; i < len(v); i++ {
+		for j := i; j > 0 && v[j] < v[j-1]; j-- {
+			v[j], v[j-1] = v[j-1], v[j]
+		}
+	}
+
+	return v[4]
+}
@@ -0,0 +1,90 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+)
+
+// noisyImage returns a gray image with a dark square and, unless noise is 0,
+// that many single pixels of sensor noise at random places of its upper
+// left 28x28 pixels.
+func noisyImage(noise int, seed int64) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{128, 128, 128, 255})
+		}
+	}
+	for y := 10; y < 20; y++ {
+		for x := 10; x < 20; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{20, 20, 20, 255})
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+	for i := 0; i < noise; i++ {
+		// every other pixel, so no two noisy pixels touch
+		x, y := 2*rnd.Intn(14), 2*rnd.Intn(14)
+		img.SetNRGBA(x, y, color.NRGBA{255, 255, 255, 255})
+	}
+
+	return img
+}
+
+func TestWithMedianFilter(t *testing.T) {
+	clean, noisy := noisyImage(0, 1), noisyImage(30, 1)
+
+	result, err := Diff(clean, noisy, nil, WithIncludeAA(true))
+	if err != nil || result.DiffPixels == 0 {
+		t.Fatalf("Expected the noise to differ, got - %d %v", result.DiffPixels, err)
+	}
+
+	result, err = Diff(clean, noisy, nil, WithIncludeAA(true), WithMedianFilter())
+	if err != nil || result.DiffPixels != 0 {
+		t.Errorf("Expected the filtered noise to match, got - %d %v", result.DiffPixels, err)
+	}
+	if count, err := Compare(clean, noisy, WithIncludeAA(true), WithMedianFilter()); err != nil || count != 0 {
+		t.Errorf("Expected Compare to filter as well, got - %d %v", count, err)
+	}
+
+	// the edges of the square stay sharp
+	options := newOptions()
+	filtered := medianNRGBA(clean, &options)
+	if filtered.NRGBAAt(10, 15) != clean.NRGBAAt(10, 15) || filtered.NRGBAAt(9, 15) != clean.NRGBAAt(9, 15) {
+		t.Errorf("Expected the edge to stay, got - %v %v", filtered.NRGBAAt(10, 15), filtered.NRGBAAt(9, 15))
+	}
+}
+
+func TestIgnoreIsolatedPixels(t *testing.T) {
+	clean, noisy := noisyImage(0, 2), noisyImage(30, 2)
+
+	// a real change of two neighbouring pixels
+	noisy.SetNRGBA(35, 35, color.NRGBA{255, 0, 0, 255})
+	noisy.SetNRGBA(36, 35, color.NRGBA{255, 0, 0, 255})
+
+	output := image.NewNRGBA(clean.Rect)
+	result, err := Diff(clean, noisy, output, WithIncludeAA(true), IgnoreIsolatedPixels())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 2 || output.NRGBAAt(35, 35) != (color.NRGBA{255, 0, 0, 255}) {
+		t.Errorf("Expected only the change of two pixels, got - %d", result.DiffPixels)
+	}
+
+	if count, err := Compare(clean, noisy, WithIncludeAA(true), IgnoreIsolatedPixels()); err != nil || count != 2 {
+		t.Errorf("Expected Compare to count 2, got - %d %v", count, err)
+	}
+
+	// the stream keeps the rows around the compared one
+	s := NewStreamDiffer(40, 40, WithIncludeAA(true), IgnoreIsolatedPixels())
+	for y := 0; y < 40; y++ {
+		if err := s.WriteRows(clean.Pix[y*clean.Stride:][:160], noisy.Pix[y*noisy.Stride:][:160]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if result, err := s.Close(); err != nil || result.DiffPixels != 2 {
+		t.Errorf("Expected the stream to count 2, got - %d %v", result.DiffPixels, err)
+	}
+}
@@ -0,0 +1,61 @@
+package pixelmatch
+
+import "time"
+
+// Observer is told about every comparison, so services can export metrics
+// of the comparisons without wrapping every call; see the pixelmatchprom
+// module for a Prometheus collector. Observe may be called concurrently,
+// e.g. by DiffBatch, and should return quickly.
+type Observer interface {
+	Observe(o Observation)
+}
+
+// Observation describes a single comparison for an Observer.
+type Observation struct {
+	// time spent on the comparison
+	Duration time.Duration
+
+	// number of compared, different and anti-aliased pixels; the fast path
+	// of Compare counts ignored pixels as compared and reports no
+	// anti-aliased ones
+	TotalPixels, DiffPixels, AAPixels uint64
+
+	// number of goroutines the comparison ran on
+	Parallelism int
+
+	// error of the comparison, e.g. ErrDiffBudgetExceeded or ctx.Err()
+	Err error
+}
+
+// ObserverFunc adapts a function to an Observer.
+type ObserverFunc func(o Observation)
+
+// Observe calls f(o).
+func (f ObserverFunc) Observe(o Observation) {
+	f(o)
+}
+
+// WithObserver reports every comparison made with the options to o,
+// including failed ones. Diff, Compare, DiffBatch and the functions built
+// on them report one Observation per pair of images.
+func WithObserver(o Observer) Option {
+	return func(opts *Options) {
+		opts.observer = o
+	}
+}
+
+// observe reports a comparison that began at start to the observer.
+func (o *Options) observe(start time.Time, result DiffResult, err error) {
+	if o.observer == nil {
+		return
+	}
+
+	o.observer.Observe(Observation{
+		Duration:    time.Since(start),
+		TotalPixels: result.TotalPixels,
+		DiffPixels:  result.DiffPixels,
+		AAPixels:    result.AAPixels,
+		Parallelism: o.workers(),
+		Err:         err,
+	})
+}
@@ -0,0 +1,67 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"sync"
+	"testing"
+)
+
+// observations collects the observations of the comparisons.
+type observations struct {
+	mu   sync.Mutex
+	list []Observation
+}
+
+func (o *observations) Observe(obs Observation) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.list = append(o.list, obs)
+}
+
+func TestWithObserver(t *testing.T) {
+	a, b := patchedImage(128), patchedImage(255)
+
+	var obs observations
+	result, err := Diff(a, b, nil, WithObserver(&obs), WithParallelism(3), WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := Compare(a, b, WithObserver(&obs), WithSequential(), WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	DiffBatch([]ImagePair{{A: a, B: b}, {A: a, B: a}}, WithObserver(&obs))
+	_, err = Diff(a, image.NewNRGBA(image.Rect(0, 0, 2, 2)), nil, WithObserver(&obs))
+
+	if len(obs.list) != 5 {
+		t.Fatalf("Expected 5 observations, got - %d", len(obs.list))
+	}
+
+	diffObs, compareObs := obs.list[0], obs.list[1]
+	if diffObs.DiffPixels != result.DiffPixels || diffObs.TotalPixels != 256 || diffObs.Parallelism != 3 || diffObs.Duration <= 0 {
+		t.Errorf("Unexpected observation of Diff %+v", diffObs)
+	}
+	if compareObs.DiffPixels != count || compareObs.TotalPixels != 256 || compareObs.Parallelism != 1 {
+		t.Errorf("Unexpected observation of Compare %+v", compareObs)
+	}
+	for _, o := range obs.list[2:4] {
+		if o.Parallelism != 1 || o.Err != nil {
+			t.Errorf("Unexpected observation of a batch pair %+v", o)
+		}
+	}
+	if last := obs.list[4]; !errors.Is(last.Err, ErrImageSize) || !errors.Is(err, ErrImageSize) {
+		t.Errorf("Expected the size error to be observed, got - %+v", last)
+	}
+}
+
+func TestObserverFunc(t *testing.T) {
+	var got Observation
+	if _, err := Compare(patchedImage(0), patchedImage(0), WithObserver(ObserverFunc(func(o Observation) { got = o }))); err != nil {
+		t.Fatal(err)
+	}
+	if got.TotalPixels != 256 || got.DiffPixels != 0 {
+		t.Errorf("Expected an observation of 256 equal pixels, got - %+v", got)
+	}
+}
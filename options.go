@@ -0,0 +1,360 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"runtime"
+)
+
+type Options struct {
+	// matching threshold (0 to 1); smaller is more sensitive
+	threshold float64
+
+	// whether to count anti-aliased pixels as different;
+	// when false they are detected and reported separately
+	includeAA bool
+
+	// opacity of original image in diff output
+	alpha float64
+
+	// color of anti-aliased pixels in diff output
+	aaColor color.NRGBA
+
+	// color of different pixels in diff output
+	diffColor color.NRGBA
+
+	// whether to detect dark on light differences between img1 and img2
+	//  and set an alternative color to differentiate between the two
+	diffColorAlt *color.NRGBA
+
+	// draw the diff over a transparent background (a mask)
+	diffMask bool
+
+	// color the background is blended with; nil means white
+	backgroundBlend *color.NRGBA
+
+	// draw similar pixels as they are in the base image
+	noBackground bool
+
+	// image drawn under the differences
+	baseImage BaseImage
+
+	// how the output is composed from the images and the diff
+	renderMode RenderMode
+
+	// color of different and anti-aliased pixels in diff output;
+	// nil draws them in diffColor and aaColor
+	renderFunc RenderFunc
+
+	// draw different pixels in colors by the size of their delta
+	heatmap bool
+
+	// number of goroutines comparing row bands; 0 means runtime.NumCPU()
+	parallelism int
+
+	// how to compare images of different bounds
+	sizeMismatch SizeMismatch
+
+	// color of the pixels added by SizeMismatchPad
+	padColor color.NRGBA
+
+	// regions excluded from the comparison
+	ignoreRegions []image.Rectangle
+
+	// non-zero pixels of the mask are excluded from the comparison
+	ignoreMask image.Image
+
+	// color of excluded pixels in diff output; nil draws them as similar pixels
+	ignoreColor *color.NRGBA
+
+	// comparison metric
+	metric Metric
+
+	// number of grid cells per row and column for DiffResult.Grid
+	gridCols, gridRows int
+
+	// group different pixels into clusters of at least clusterMinSize pixels
+	clusters       bool
+	clusterMinSize int
+
+	// report up to maxBoxes merged bounding boxes grown by boxPadding pixels
+	boxes      bool
+	maxBoxes   int
+	boxPadding int
+
+	// count differences per channel and optionally draw them in channel colors
+	channelDiff   bool
+	channelRender bool
+
+	// stop comparing once more than failFastMax pixels differ
+	failFast    bool
+	failFastMax uint64
+
+	// number (or share from 0 to 100 with failurePercent) of different pixels
+	// a comparison passes with, see DiffResult.Passed
+	failureThreshold float64
+	failurePercent   bool
+
+	// color tolerance presets: compare brightness only, ignore the alpha channel
+	// and treat brightness differences below ignoreLessThan as similar
+	ignoreColors   bool
+	ignoreAlpha    bool
+	ignoreLessThan float64
+
+	// pixels fully transparent in both images are equal
+	ignoreTransparent bool
+
+	// distance in pixels a pixel may move and still count as similar
+	shift int
+
+	// standard deviation of the Gaussian blur applied to both images; 0 disables it
+	blur float64
+
+	// compare the edge maps of the images instead of their colors
+	edges bool
+
+	// threshold of text-like regions; applies when above threshold
+	textThreshold float64
+
+	// factor both images are downscaled by and the longest side they are
+	// downscaled to before comparing; 0 keeps them as they are
+	scale        float64
+	maxDimension int
+
+	// largest translation of the second image WithAutoAlign looks for; 0 disables it
+	autoAlign int
+
+	// detect the orientation of the second image and compare it oriented like the first one
+	orientationCheck, orientationFix bool
+
+	// turn JPEG inputs of the file and reader APIs as their EXIF orientation tag says
+	autoOrient bool
+
+	// convert inputs of the file and reader APIs with an embedded ICC profile to sRGB
+	colorManagement bool
+
+	// compare the colors in linear light instead of their sRGB values
+	linearLight bool
+
+	// how the color channels of the inputs relate to their alpha
+	alphaMode AlphaMode
+
+	// filter sensor noise: 3x3 median of both images, no lone different pixels
+	median, ignoreIsolated bool
+
+	// compare one pixel out of every sampleRate; 0 or 1 compares all
+	sampleRate int
+
+	// compare the pixels of the changed tiles only
+	coarse bool
+
+	// results of tiles compared before; nil compares every tile
+	tileCache TileCache
+
+	// region of interest; nil compares the whole images
+	region *image.Rectangle
+
+	// called with the number of compared rows after every band
+	progress func(done, total int)
+
+	// called for every different and anti-aliased pixel
+	pixelFunc PixelFunc
+
+	// called for every compared band of rows
+	bandFunc BandFunc
+
+	// told about every comparison
+	observer Observer
+
+	// receives the debug logs of comparisons
+	logger debugLogger
+
+	// largest estimated memory of a comparison in bytes; 0 is unlimited
+	maxMemory uint64
+
+	// receives the diff images of DiffBatch
+	sink Sink
+
+	// compute the MSE and PSNR of the images
+	extraMetrics bool
+
+	// keep the set of different pixels in the result, see DiffMask and WithResultMask
+	keepDiff bool
+
+	// pixels to erode and then dilate the kept set of different pixels by
+	maskErode, maskDilate int
+
+	// return the mask of anti-aliased pixels in the result
+	aaMask bool
+
+	// keep the set of anti-aliased pixels in the result, see WithIncremental
+	keepAA bool
+
+	// buffers of image copies and outputs; nil allocates new ones, see Differ
+	pool *pixPool
+
+	// options passed a nil color, reported by Validate
+	nilColors []string
+
+	// error of Validate, returned by the comparisons
+	err error
+}
+
+var defaultOptions = Options{
+	threshold: 0.1,
+	includeAA: false,
+	alpha:     0.1,
+
+	aaColor: color.NRGBA{
+		R: 255,
+		G: 255,
+		B: 0,
+		A: 255,
+	},
+
+	diffColor: color.NRGBA{
+		R: 255,
+		G: 0,
+		B: 0,
+		A: 255,
+	},
+
+	diffColorAlt: nil,
+	diffMask:     true,
+	metric:       MetricYIQ,
+}
+
+// Option changes a single comparison setting, see the With* functions.
+type Option func(*Options)
+
+func newOptions(opts ...Option) Options {
+	options := defaultOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	options.err = options.Validate()
+
+	return options
+}
+
+// WithThreshold sets the matching threshold (0 to 1); smaller is more sensitive.
+func WithThreshold(threshold float64) Option {
+	return func(o *Options) {
+		o.threshold = threshold
+	}
+}
+
+// WithAlpha sets the opacity of the original image in the diff output.
+func WithAlpha(alpha float64) Option {
+	return func(o *Options) {
+		o.alpha = alpha
+	}
+}
+
+// WithIncludeAA sets whether anti-aliased pixels count as different.
+// By default they are detected, drawn in the AA color and counted
+// in DiffResult.AAPixels instead of DiffResult.DiffPixels.
+func WithIncludeAA(include bool) Option {
+	return func(o *Options) {
+		o.includeAA = include
+	}
+}
+
+// WithAAColor sets the color of anti-aliased pixels in the diff output.
+func WithAAColor(c color.Color) Option {
+	return func(o *Options) {
+		if c == nil {
+			o.nilColor("AA")
+			return
+		}
+		o.aaColor = toNRGBAColor(c)
+	}
+}
+
+// WithDiffColor sets the color of different pixels in the diff output.
+func WithDiffColor(c color.Color) Option {
+	return func(o *Options) {
+		if c == nil {
+			o.nilColor("diff")
+			return
+		}
+		o.diffColor = toNRGBAColor(c)
+	}
+}
+
+// WithDiffColorAlt sets the color of pixels that are darker in the second
+// image than in the first one, so darkened and lightened pixels can be told apart.
+// By default both are drawn in the diff color.
+func WithDiffColorAlt(c color.Color) Option {
+	return func(o *Options) {
+		if c == nil {
+			o.nilColor("alternative diff")
+			return
+		}
+		nrgba := toNRGBAColor(c)
+		o.diffColorAlt = &nrgba
+	}
+}
+
+// WithDiffMask draws the diff over a transparent background (a mask)
+// instead of a faded grayscale copy of the first image.
+func WithDiffMask(mask bool) Option {
+	return func(o *Options) {
+		o.diffMask = mask
+	}
+}
+
+// WithParallelism sets the number of goroutines comparing the images;
+// n <= 0 means runtime.NumCPU(). With n == 1 no goroutines are started.
+func WithParallelism(n int) Option {
+	return func(o *Options) {
+		o.parallelism = n
+	}
+}
+
+// WithSequential does all the work on the calling goroutine, in row order,
+// like WithParallelism(1): no goroutines are started, so results, pixel
+// callbacks and timings are reproducible across runs, which helps debugging
+// and targets where goroutines are costly such as WebAssembly.
+func WithSequential() Option {
+	return WithParallelism(1)
+}
+
+// diffColorFor returns the color of a different pixel,
+// delta is negative when the pixel of the second image is darker.
+func (o *Options) diffColorFor(delta float64) color.NRGBA {
+	if delta < 0 && o.diffColorAlt != nil {
+		return *o.diffColorAlt
+	}
+
+	return o.diffColor
+}
+
+// maximum acceptable square distance between two colors
+func (o *Options) maxDelta() float64 {
+	return o.maxDeltaFor(o.threshold)
+}
+
+// maxDeltaFor is maxDelta at the given threshold.
+func (o *Options) maxDeltaFor(threshold float64) float64 {
+	if o.ignoreColors {
+		// IgnoreColors measures the brightness part of the YIQ delta whatever the metric is
+		return MetricYIQ.MaxDelta() * threshold * threshold
+	}
+
+	return o.metric.MaxDelta() * threshold * threshold
+}
+
+func (o *Options) workers() int {
+	if o.parallelism > 0 {
+		return o.parallelism
+	}
+
+	return runtime.NumCPU()
+}
+
+func toNRGBAColor(c color.Color) color.NRGBA {
+	return color.NRGBAModel.Convert(c).(color.NRGBA)
+}
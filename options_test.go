@@ -0,0 +1,79 @@
+package pixelmatch
+
+import (
+	"image/color"
+	"runtime"
+	"testing"
+)
+
+func TestNewOptions(t *testing.T) {
+	options := newOptions(
+		WithThreshold(0.05),
+		WithAlpha(0.5),
+		WithAAColor(color.RGBA{R: 0, G: 255, B: 0, A: 255}),
+		WithDiffColor(color.Black),
+		WithDiffMask(false),
+	)
+
+	if options.threshold != 0.05 {
+		t.Errorf("Expected threshold 0.05, got - %v", options.threshold)
+	}
+	if options.alpha != 0.5 {
+		t.Errorf("Expected alpha 0.5, got - %v", options.alpha)
+	}
+	if options.aaColor != (color.NRGBA{G: 255, A: 255}) {
+		t.Errorf("Unexpected aaColor %v", options.aaColor)
+	}
+	if options.diffColor != (color.NRGBA{A: 255}) {
+		t.Errorf("Unexpected diffColor %v", options.diffColor)
+	}
+	if options.diffMask {
+		t.Error("Expected diffMask to be disabled")
+	}
+
+	if defaultOptions.threshold != 0.1 || !defaultOptions.diffMask {
+		t.Error("defaultOptions must not be modified by options")
+	}
+}
+
+func TestWithSequential(t *testing.T) {
+	a, b := texturedImage(64, 300, 1), texturedImage(64, 300, 2)
+	goroutines := runtime.NumGoroutine()
+
+	var (
+		last    = -1
+		inOrder = true
+		spawned bool
+		counted int
+	)
+	result, err := Diff(a, b, nil, WithSequential(), WithPixelCallback(func(x, y int, _ float64, _ PixelKind) {
+		if i := y*64 + x; i <= last {
+			inOrder = false
+		} else {
+			last = i
+		}
+		if runtime.NumGoroutine() > goroutines {
+			spawned = true
+		}
+		counted++
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inOrder || spawned {
+		t.Errorf("Expected the pixels in row order on the calling goroutine, got - ordered %v, goroutines started %v", inOrder, spawned)
+	}
+
+	parallel, err := Diff(a, b, nil, WithParallelism(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != parallel.DiffPixels || result.AAPixels != parallel.AAPixels || counted == 0 {
+		t.Errorf("Expected %+v as in parallel, got - %+v", parallel, result)
+	}
+
+	results := DiffBatch([]ImagePair{{A: a, B: b}, {A: b, B: a}}, WithSequential())
+	if results[0].Err != nil || results[0].Result.DiffPixels != result.DiffPixels || results[1].Result.DiffPixels != result.DiffPixels {
+		t.Errorf("Expected %d different pixels in the batch, got - %+v", result.DiffPixels, results)
+	}
+}
@@ -0,0 +1,211 @@
+package pixelmatch
+
+import (
+	"image"
+	"math"
+)
+
+// Orientation is a rotation or mirroring of an image, numbered like the
+// values of the EXIF orientation tag: Orient(img, o) transforms img the way
+// a viewer displays an image tagged with o.
+type Orientation int
+
+const (
+	OrientationNormal         Orientation = iota + 1 // as it is
+	OrientationFlipHorizontal                        // mirrored left to right
+	OrientationRotate180                             // rotated by 180°
+	OrientationFlipVertical                          // mirrored top to bottom
+	OrientationTranspose                             // mirrored along the main diagonal
+	OrientationRotate90                              // rotated by 90° clockwise
+	OrientationTransverse                            // mirrored along the other diagonal
+	OrientationRotate270                             // rotated by 90° counterclockwise
+)
+
+// String returns the name of the orientation.
+func (o Orientation) String() string {
+	switch o {
+	case OrientationNormal:
+		return "normal"
+	case OrientationFlipHorizontal:
+		return "flip-horizontal"
+	case OrientationRotate180:
+		return "rotate-180"
+	case OrientationFlipVertical:
+		return "flip-vertical"
+	case OrientationTranspose:
+		return "transpose"
+	case OrientationRotate90:
+		return "rotate-90"
+	case OrientationTransverse:
+		return "transverse"
+	case OrientationRotate270:
+		return "rotate-270"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the orientation by its name.
+func (o Orientation) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// swapsSides reports whether the orientation swaps the width and height.
+func (o Orientation) swapsSides() bool {
+	return o >= OrientationTranspose && o <= OrientationRotate270
+}
+
+// Orient returns a copy of img transformed by o, with bounds starting
+// at 0, 0. Unknown orientations leave the image as it is.
+func Orient(img image.Image, o Orientation) *image.NRGBA {
+	src := toNRGBA(img)
+	r := src.Rect
+	w, h := r.Dx(), r.Dy()
+
+	if o.swapsSides() {
+		w, h = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx, sy := orientSource(o, x, y, r.Dx(), r.Dy())
+			i := src.PixOffset(r.Min.X+sx, r.Min.Y+sy)
+			copy(dst.Pix[y*dst.Stride+x*4:][:4], src.Pix[i:i+4])
+		}
+	}
+
+	return dst
+}
+
+// orientSource returns the pixel of a w x h image that o moves to x, y.
+func orientSource(o Orientation, x, y, w, h int) (int, int) {
+	switch o {
+	case OrientationFlipHorizontal:
+		return w - 1 - x, y
+	case OrientationRotate180:
+		return w - 1 - x, h - 1 - y
+	case OrientationFlipVertical:
+		return x, h - 1 - y
+	case OrientationTranspose:
+		return y, x
+	case OrientationRotate90:
+		return y, h - 1 - x
+	case OrientationTransverse:
+		return w - 1 - y, h - 1 - x
+	case OrientationRotate270:
+		return w - 1 - y, x
+	default:
+		return x, y
+	}
+}
+
+// orientationThumb is the longest side of the thumbnails DetectOrientation compares.
+const orientationThumb = 32
+
+// DetectOrientation reports how b is rotated or mirrored against a: the
+// orientation o for which Orient(b, o) looks most like a. Both images are
+// compared as small thumbnails, so the check is cheap enough to run before
+// every comparison. It returns OrientationNormal unless some orientation
+// matches much better than b as it is, e.g. for a phone screenshot taken
+// in landscape compared against its portrait baseline.
+func DetectOrientation(a, b image.Image) Orientation {
+	if isEmptyImg(a) || isEmptyImg(b) {
+		return OrientationNormal
+	}
+
+	var (
+		options = newOptions()
+		thumbA  = thumbnail(a, &options)
+		thumbB  = thumbnail(b, &options)
+		best    = OrientationNormal
+		bestD   = math.Inf(1)
+		normalD = math.Inf(1)
+	)
+
+	for o := OrientationNormal; o <= OrientationRotate270; o++ {
+		// only orientations turning b into the shape of a qualify
+		wa, ha, wb, hb := a.Bounds().Dx(), a.Bounds().Dy(), b.Bounds().Dx(), b.Bounds().Dy()
+		if o.swapsSides() {
+			wb, hb = hb, wb
+		}
+		if wa != wb || ha != hb {
+			continue
+		}
+
+		oriented := Orient(thumbB, o)
+		if !oriented.Rect.Size().Eq(thumbA.Rect.Size()) {
+			// the rounding of the thumbnail sizes differs for swapped sides
+			continue
+		}
+
+		d := meanLumaDelta(thumbA, oriented)
+		if o == OrientationNormal {
+			normalD = d
+		}
+		if d < bestD {
+			best, bestD = o, d
+		}
+	}
+
+	if best == OrientationNormal || bestD*2 >= normalD {
+		return OrientationNormal
+	}
+
+	return best
+}
+
+// thumbnail returns img downscaled to at most orientationThumb pixels
+// on its longest side.
+func thumbnail(img image.Image, options *Options) *image.NRGBA {
+	src := toNRGBA(img)
+	side := maxInt(src.Rect.Dx(), src.Rect.Dy())
+	if side <= orientationThumb {
+		return src
+	}
+
+	return scaleNRGBA(src, float64(orientationThumb)/float64(side), options)
+}
+
+// meanLumaDelta returns the mean absolute difference of the brightness
+// of the pixels of two images of the same size.
+func meanLumaDelta(a, b *image.NRGBA) float64 {
+	var (
+		w, h = a.Rect.Dx(), a.Rect.Dy()
+		sum  float64
+	)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			ca := getColor(a, a.Rect.Min.X+x, a.Rect.Min.Y+y)
+			cb := getColor(b, b.Rect.Min.X+x, b.Rect.Min.Y+y)
+			sum += math.Abs(rgb2y(blendColor(ca)) - rgb2y(blendColor(cb)))
+		}
+	}
+
+	return sum / float64(w*h)
+}
+
+// WithOrientationCheck detects whether the second image is a rotation or
+// mirror of the first one, see DetectOrientation, and reports it in
+// DiffResult.Orientation; with normalize the second image is compared in
+// the orientation of the first one instead of differing almost entirely.
+func WithOrientationCheck(normalize bool) Option {
+	return func(o *Options) {
+		o.orientationCheck = true
+		o.orientationFix = normalize
+	}
+}
+
+// orientationMode returns the name of the orientation check for the JSON
+// encoding of the options: "", "detect" or "normalize".
+func (o *Options) orientationMode() string {
+	switch {
+	case o.orientationFix:
+		return "normalize"
+	case o.orientationCheck:
+		return "detect"
+	default:
+		return ""
+	}
+}
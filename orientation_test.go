@@ -0,0 +1,105 @@
+package pixelmatch
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestOrient(t *testing.T) {
+	// 3x2 image with distinct pixels 0..5
+	img := image.NewNRGBA(image.Rect(10, 10, 13, 12))
+	for i := 0; i < 6; i++ {
+		img.SetNRGBA(10+i%3, 10+i/3, color.NRGBA{R: uint8(i), A: 255})
+	}
+
+	for _, tc := range []struct {
+		o    Orientation
+		rows string
+	}{
+		{OrientationNormal, "012 345"},
+		{OrientationFlipHorizontal, "210 543"},
+		{OrientationRotate180, "543 210"},
+		{OrientationFlipVertical, "345 012"},
+		{OrientationTranspose, "03 14 25"},
+		{OrientationRotate90, "30 41 52"},
+		{OrientationTransverse, "52 41 30"},
+		{OrientationRotate270, "25 14 03"},
+	} {
+		oriented := Orient(img, tc.o)
+
+		var rows []string
+		for y := 0; y < oriented.Rect.Dy(); y++ {
+			var row strings.Builder
+			for x := 0; x < oriented.Rect.Dx(); x++ {
+				row.WriteByte('0' + oriented.NRGBAAt(x, y).R)
+			}
+			rows = append(rows, row.String())
+		}
+		if got := strings.Join(rows, " "); got != tc.rows {
+			t.Errorf("%v: expected %s, got - %s", tc.o, tc.rows, got)
+		}
+	}
+}
+
+func TestDetectOrientation(t *testing.T) {
+	options := newOptions()
+	a := scaleNRGBA(toNRGBA(decodeTestImage(t, "testdata/img1.png")), 0.1, &options)
+
+	// the orientation which undoes each of them
+	inverse := map[Orientation]Orientation{
+		OrientationRotate90:  OrientationRotate270,
+		OrientationRotate270: OrientationRotate90,
+	}
+	for o := OrientationNormal; o <= OrientationRotate270; o++ {
+		want, ok := inverse[o]
+		if !ok {
+			want = o
+		}
+
+		if got := DetectOrientation(a, Orient(a, o)); got != want {
+			t.Errorf("%v: expected %v, got - %v", o, want, got)
+		}
+	}
+
+	if got := DetectOrientation(texturedImage(120, 80, 1), texturedImage(120, 80, 2)); got != OrientationNormal {
+		t.Errorf("Expected unrelated images to be normal, got - %v", got)
+	}
+}
+
+func TestWithOrientationCheck(t *testing.T) {
+	options := newOptions()
+	a := scaleNRGBA(toNRGBA(decodeTestImage(t, "testdata/img1.png")), 0.05, &options)
+	b := Orient(a, OrientationRotate270)
+
+	result, err := Diff(a, b, nil, WithOrientationCheck(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 0 || result.Orientation != OrientationRotate90 {
+		t.Errorf("Expected the rotated image to match once normalized, got - %d %v", result.DiffPixels, result.Orientation)
+	}
+
+	if count, err := Compare(a, b, WithOrientationCheck(true)); err != nil || count != 0 {
+		t.Errorf("Expected Compare to normalize as well, got - %d %v", count, err)
+	}
+
+	square := a.SubImage(image.Rect(0, 0, 80, 80))
+	result, err = Diff(square, Orient(square, OrientationFlipHorizontal), nil, WithOrientationCheck(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels == 0 || result.Orientation != OrientationFlipHorizontal {
+		t.Errorf("Expected the mirror to be reported only, got - %d %v", result.DiffPixels, result.Orientation)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"orientation":"flip-horizontal"`) || !strings.Contains(string(data), `"orientation":"detect"`) {
+		t.Errorf("Expected the orientation and the check in JSON, got - %s", data)
+	}
+}
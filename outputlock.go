@@ -0,0 +1,73 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"sync"
+)
+
+// ErrOutputShared is returned when the output image shares its pixels with
+// one of the compared images, which the comparison would overwrite while
+// still reading them.
+var ErrOutputShared = errors.New("output image shares pixels with an input image")
+
+// outputs serializes the comparisons drawing into the same output image.
+var outputs = outputLocks{locks: make(map[*uint8]*outputLock)}
+
+// outputLocks holds a lock for every output image being drawn, keyed by
+// the last byte of its Pix array, which sub-images of an image share.
+type outputLocks struct {
+	mu    sync.Mutex
+	locks map[*uint8]*outputLock
+}
+
+type outputLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock waits until no other comparison draws into img and returns the
+// function releasing it. A nil or empty img is not locked.
+func (l *outputLocks) lock(img *image.NRGBA) (unlock func()) {
+	if img == nil || cap(img.Pix) == 0 {
+		return func() {}
+	}
+	key := &img.Pix[:cap(img.Pix)][cap(img.Pix)-1]
+
+	l.mu.Lock()
+	ol := l.locks[key]
+	if ol == nil {
+		ol = &outputLock{}
+		l.locks[key] = ol
+	}
+	ol.refs++
+	l.mu.Unlock()
+
+	ol.Lock()
+
+	return func() {
+		ol.Unlock()
+
+		l.mu.Lock()
+		if ol.refs--; ol.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// checkOutput returns ErrOutputShared when output shares its pixels with
+// one of the images.
+func checkOutput(output *image.NRGBA, imgs ...image.Image) error {
+	if output == nil {
+		return nil
+	}
+
+	for _, img := range imgs {
+		if sharesPix(output, img) {
+			return ErrOutputShared
+		}
+	}
+
+	return nil
+}
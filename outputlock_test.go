@@ -0,0 +1,61 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentOutput(t *testing.T) {
+	img1 := toNRGBA(decodeTestImage(t, "testdata/img1.png"))
+	img2 := toNRGBA(decodeTestImage(t, "testdata/img2.png"))
+
+	want, _, err := DiffNew(img1, img2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// run with -race: the comparisons share the inputs and the output
+	output := image.NewNRGBA(img1.Rect)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Diff(img1, img2, output, WithParallelism(3)); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !bytes.Equal(output.Pix, want.Pix) {
+		t.Error("Expected the output of concurrent comparisons to be the diff")
+	}
+	if len(outputs.locks) != 0 {
+		t.Errorf("Expected the output locks to be released, got - %d", len(outputs.locks))
+	}
+}
+
+func TestErrOutputShared(t *testing.T) {
+	img1 := toNRGBA(decodeTestImage(t, "testdata/img1.png"))
+	img2 := toNRGBA(decodeTestImage(t, "testdata/img2.png"))
+
+	if _, err := Diff(img1, img2, img1); !errors.Is(err, ErrOutputShared) {
+		t.Errorf("Expected ErrOutputShared, got - %v", err)
+	}
+	sub := img2.SubImage(image.Rect(0, 0, 10, 10)).(*image.NRGBA)
+	if _, err := Diff(img1.SubImage(sub.Rect), sub, img2.SubImage(sub.Rect).(*image.NRGBA)); !errors.Is(err, ErrOutputShared) {
+		t.Errorf("Expected ErrOutputShared for a sub-image, got - %v", err)
+	}
+
+	prev, err := Diff(img1, img2, nil, WithIncremental())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Rediff(prev, img1, img2, img2, image.Rect(0, 0, 10, 10)); !errors.Is(err, ErrOutputShared) {
+		t.Errorf("Expected Rediff to return ErrOutputShared, got - %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package pixelmatch
+
+import (
+	"fmt"
+	"image/color"
+)
+
+// Palette is a set of colors of the diff output, see WithPalette.
+type Palette struct {
+	// color of different pixels
+	Diff color.NRGBA
+
+	// color of pixels that are darker in the second image;
+	// the same as Diff draws both in the same color
+	DiffAlt color.NRGBA
+
+	// color of anti-aliased pixels
+	AA color.NRGBA
+}
+
+var (
+	// PaletteDefault draws different pixels red and anti-aliased ones yellow,
+	// the colors of the reference implementation.
+	PaletteDefault = Palette{
+		Diff:    color.NRGBA{R: 255, A: 255},
+		DiffAlt: color.NRGBA{R: 255, A: 255},
+		AA:      color.NRGBA{R: 255, G: 255, A: 255},
+	}
+
+	// PaletteColorBlindSafe uses colors of the Okabe-Ito palette that stay
+	// apart with every common color vision deficiency: vermillion for
+	// lightened, blue for darkened and yellow for anti-aliased pixels.
+	PaletteColorBlindSafe = Palette{
+		Diff:    color.NRGBA{R: 213, G: 94, A: 255},
+		DiffAlt: color.NRGBA{G: 114, B: 178, A: 255},
+		AA:      color.NRGBA{R: 240, G: 228, B: 66, A: 255},
+	}
+
+	// PaletteHighContrast draws saturated colors that stand out against
+	// both light and dark images: magenta for lightened, green for darkened
+	// and blue for anti-aliased pixels.
+	PaletteHighContrast = Palette{
+		Diff:    color.NRGBA{R: 255, B: 255, A: 255},
+		DiffAlt: color.NRGBA{G: 160, A: 255},
+		AA:      color.NRGBA{B: 255, A: 255},
+	}
+)
+
+// ParsePalette returns the built-in palette of the given name: "default",
+// "color-blind-safe" or "high-contrast", for palettes given in configs and
+// command line flags.
+func ParsePalette(name string) (Palette, error) {
+	switch name {
+	case "default":
+		return PaletteDefault, nil
+	case "color-blind-safe":
+		return PaletteColorBlindSafe, nil
+	case "high-contrast":
+		return PaletteHighContrast, nil
+	default:
+		return Palette{}, fmt.Errorf("unknown palette %q", name)
+	}
+}
+
+// WithPalette sets the colors of different and anti-aliased pixels at once,
+// e.g. to PaletteColorBlindSafe for reviewers who cannot tell red from green.
+// Later color options override single colors of the palette.
+func WithPalette(p Palette) Option {
+	return func(o *Options) {
+		o.diffColor = p.Diff
+		o.aaColor = p.AA
+		o.diffColorAlt = nil
+		if p.DiffAlt != p.Diff {
+			alt := p.DiffAlt
+			o.diffColorAlt = &alt
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package pixelmatch
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestWithPalette(t *testing.T) {
+	defaults := newOptions(WithPalette(PaletteDefault))
+	if defaults.diffColor != defaultOptions.diffColor || defaults.aaColor != defaultOptions.aaColor || defaults.diffColorAlt != nil {
+		t.Errorf("Expected PaletteDefault to keep the default colors, got - %+v", defaults)
+	}
+
+	// the patch is darker in the second image and lighter in the first one
+	light, dark := patchedImage(255), patchedImage(0)
+
+	for _, p := range []Palette{PaletteColorBlindSafe, PaletteHighContrast} {
+		lightened, _, err := DiffNew(dark, light, WithPalette(p), WithIncludeAA(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		darkened, _, err := DiffNew(light, dark, WithPalette(p), WithIncludeAA(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := lightened.NRGBAAt(7, 7); got != p.Diff {
+			t.Errorf("Expected %v for a lightened pixel, got - %v", p.Diff, got)
+		}
+		if got := darkened.NRGBAAt(7, 7); got != p.DiffAlt {
+			t.Errorf("Expected %v for a darkened pixel, got - %v", p.DiffAlt, got)
+		}
+	}
+
+	if options := newOptions(WithPalette(PaletteHighContrast), WithDiffColorHex("#000")); options.diffColor != (color.NRGBA{A: 255}) || options.aaColor != PaletteHighContrast.AA {
+		t.Errorf("Expected later options to override the palette, got - %+v", options)
+	}
+}
+
+func TestParsePalette(t *testing.T) {
+	if p, err := ParsePalette("color-blind-safe"); err != nil || p != PaletteColorBlindSafe {
+		t.Errorf("Expected PaletteColorBlindSafe, got - %v, %v", p, err)
+	}
+	if _, err := ParsePalette("sepia"); err == nil {
+		t.Error("Expected an error for an unknown palette")
+	}
+
+	opts, err := OptionsFromConfig(strings.NewReader(`{"palette": "high-contrast", "aaColor": "#ffff00"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options := newOptions(opts...); options.diffColor != PaletteHighContrast.Diff || options.aaColor != PaletteDefault.AA {
+		t.Errorf("Expected the high-contrast palette with a yellow AA color, got - %+v", options)
+	}
+}
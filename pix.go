@@ -0,0 +1,46 @@
+package pixelmatch
+
+import (
+	"fmt"
+	"image"
+)
+
+// DiffPix compares two images given as raw non-premultiplied RGBA bytes,
+// such as canvas ImageData or headless browser screencast frames, and draws
+// the diff into out unless it is nil, mirroring the original
+// pixelmatch(img1, img2, output, width, height) signature. Rows start every
+// stride bytes in all three buffers. The buffers are used in place,
+// no pixel data is copied.
+func DiffPix(a, b, out []byte, width, height, stride int, opts ...Option) (DiffResult, error) {
+	if width <= 0 || height <= 0 {
+		return DiffResult{}, fmt.Errorf("%w: size %dx%d", ErrEmptyImage, width, height)
+	}
+
+	if stride < width*4 {
+		return DiffResult{}, fmt.Errorf("%w: stride %d is less than %d bytes of a row", ErrImageSize, stride, width*4)
+	}
+
+	size := (height-1)*stride + width*4
+	for _, buf := range []struct {
+		name string
+		pix  []byte
+	}{{"a", a}, {"b", b}, {"out", out}} {
+		if buf.pix != nil && len(buf.pix) < size {
+			return DiffResult{}, fmt.Errorf("%w: %s has %d bytes, expected at least %d", ErrImageSize, buf.name, len(buf.pix), size)
+		}
+	}
+	if a == nil || b == nil {
+		return DiffResult{}, fmt.Errorf("%w: missing pixels", ErrEmptyImage)
+	}
+
+	rect := image.Rect(0, 0, width, height)
+	img1 := &image.NRGBA{Pix: a, Stride: stride, Rect: rect}
+	img2 := &image.NRGBA{Pix: b, Stride: stride, Rect: rect}
+
+	var output *image.NRGBA
+	if out != nil {
+		output = &image.NRGBA{Pix: out, Stride: stride, Rect: rect}
+	}
+
+	return Diff(img1, img2, output, opts...)
+}
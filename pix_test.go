@@ -0,0 +1,51 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDiffPix(t *testing.T) {
+	img1 := toNRGBA(decodeTestImage(t, "./testdata/img1.png"))
+	img2 := toNRGBA(decodeTestImage(t, "./testdata/img2.png"))
+	w, h := img1.Bounds().Dx(), img1.Bounds().Dy()
+
+	want, wantResult, err := DiffNew(img1, img2, WithDiffMask(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make([]byte, len(img1.Pix))
+	result, err := DiffPix(img1.Pix, img2.Pix, out, w, h, img1.Stride, WithDiffMask(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != wantResult.DiffPixels {
+		t.Errorf("Expected %d different pixels, got - %d", wantResult.DiffPixels, result.DiffPixels)
+	}
+	if !bytes.Equal(out, want.Pix) {
+		t.Error("Expected the same diff image as Diff")
+	}
+
+	if _, err := DiffPix(img1.Pix, img2.Pix, nil, w, h, img1.Stride); err != nil {
+		t.Errorf("Expected out to be optional, got - %v", err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		a, out []byte
+		w, s   int
+		want   error
+	}{
+		{"short buffer", img1.Pix[:len(img1.Pix)-1], nil, w, img1.Stride, ErrImageSize},
+		{"short output", img1.Pix, out[:10], w, img1.Stride, ErrImageSize},
+		{"short stride", img1.Pix, nil, w, w*4 - 1, ErrImageSize},
+		{"empty", img1.Pix, nil, 0, img1.Stride, ErrEmptyImage},
+		{"missing image", nil, nil, w, img1.Stride, ErrEmptyImage},
+	} {
+		if _, err := DiffPix(tc.a, img2.Pix, tc.out, tc.w, h, tc.s); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got - %v", tc.name, tc.want, err)
+		}
+	}
+}
@@ -1,63 +1,18 @@
 package pixelmatch
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"math"
-	"strings"
 	"sync"
-	"sync/atomic"
+	"time"
 )
 
-type Options struct {
-	// matching threshold (0 to 1); smaller is more sensitive
-	threshold float64
-
-	// whether to skip anti-aliasing detection
-	includeAA bool
-
-	// opacity of original image in diff output
-	alpha float32
-
-	// color of anti-aliased pixels in diff output
-	aaColor color.NRGBA
-
-	// color of different pixels in diff output
-	diffColor color.NRGBA
-
-	// whether to detect dark on light differences between img1 and img2
-	//  and set an alternative color to differentiate between the two
-	diffColorAlt color.Color
-
-	// draw the diff over a transparent background (a mask)
-	diffMask bool
-}
-
-var defaultOptions = Options{
-	threshold: 0.1,
-	includeAA: true,
-	alpha:     0.1,
-
-	aaColor: color.NRGBA{
-		R: 255,
-		G: 255,
-		B: 0,
-		A: 255,
-	},
-
-	diffColor: color.NRGBA{
-		R: 255,
-		G: 0,
-		B: 0,
-		A: 255,
-	},
-
-	diffColorAlt: nil,
-	diffMask:     true,
-}
-
 func isEmptyImg(img image.Image) bool {
 	return img == nil || img.Bounds().Empty()
 }
@@ -65,6 +20,10 @@ func isEmptyImg(img image.Image) bool {
 var (
 	ErrEmptyImage = errors.New("image is empty")
 	ErrImageSize  = errors.New("size of images must be equals")
+
+	// ErrDiffBudgetExceeded is returned when more pixels differ
+	// than allowed by WithFailFast; the comparison stops early.
+	ErrDiffBudgetExceeded = errors.New("diff budget exceeded")
 )
 
 func indexImgStr(i int) string {
@@ -81,156 +40,594 @@ func indexImgStr(i int) string {
 }
 
 func checkImages(imgs ...image.Image) error {
-	var (
-		emptyImgs          []string
-		notEqualImgsBySize []string
-	)
+	if err := checkEmptyImages(imgs...); err != nil {
+		return err
+	}
 
+	return checkImageSizes(imgs...)
+}
+
+// checkEmptyImages returns an *EmptyImageError for the first empty image.
+func checkEmptyImages(imgs ...image.Image) error {
 	for i := range imgs {
 		if isEmptyImg(imgs[i]) {
-			emptyImgs = append(emptyImgs, indexImgStr(i))
+			return &EmptyImageError{Index: i}
+		}
+	}
+
+	return nil
+}
+
+// checkImageSizes returns a *SizeMismatchError for the first image
+// whose bounds differ from those of the first one.
+func checkImageSizes(imgs ...image.Image) error {
+	for i := 1; i < len(imgs); i++ {
+		if got, want := imgs[i].Bounds(), imgs[0].Bounds(); !got.Eq(want) {
+			return &SizeMismatchError{Index: i, Got: got, Want: want}
 		}
 	}
 
-	if len(emptyImgs) > 0 {
-		return fmt.Errorf("%w: images: %s", ErrEmptyImage, strings.Join(emptyImgs, `,`))
+	return nil
+}
+
+// Diff compares img1 and img2 pixel by pixel, draws the difference into output
+// and returns the number of mismatched pixels along with other statistics.
+// The output may be nil when only the statistics are needed.
+func Diff(img1, img2 image.Image, output *image.NRGBA, opts ...Option) (DiffResult, error) {
+	return DiffContext(context.Background(), img1, img2, output, opts...)
+}
+
+// DiffContext is like Diff but stops comparing and returns ctx.Err()
+// as soon as ctx is done.
+func DiffContext(ctx context.Context, img1, img2 image.Image, output *image.NRGBA, opts ...Option) (DiffResult, error) {
+	_, result, err := diff(ctx, img1, img2, output, false, newOptions(opts...))
+
+	return result, err
+}
+
+// DiffNew is like Diff but allocates the output image itself.
+func DiffNew(img1, img2 image.Image, opts ...Option) (*image.NRGBA, DiffResult, error) {
+	return diff(context.Background(), img1, img2, nil, true, newOptions(opts...))
+}
+
+// diff compares the images and reports the comparison to the observer of the options.
+func diff(ctx context.Context, img1, img2 image.Image, output *image.NRGBA, newOutput bool, options Options) (*image.NRGBA, DiffResult, error) {
+	start := time.Now()
+	defer outputs.lock(output)()
+
+	output, result, err := diffImages(ctx, img1, img2, output, newOutput, options)
+	options.observe(start, result, err)
+	options.debug("compared images", "diff", result.DiffPixels, "aa", result.AAPixels, "total", result.TotalPixels, "elapsed", time.Since(start), "err", err)
+
+	return output, result, err
+}
+
+func diffImages(ctx context.Context, img1, img2 image.Image, output *image.NRGBA, newOutput bool, options Options) (*image.NRGBA, DiffResult, error) {
+	start := time.Now()
+
+	if options.err != nil {
+		return nil, DiffResult{}, options.err
+	}
+	if err := options.checkMemory(img1, img2, newOutput); err != nil {
+		return nil, DiffResult{}, err
+	}
+	if err := checkOutput(output, img1, img2); err != nil {
+		return nil, DiffResult{}, err
 	}
 
-	for i := 0; i < len(imgs)-1; i++ {
-		if !imgs[i].Bounds().Eq(imgs[i+1].Bounds()) {
-			notEqualImgsBySize = append(
-				notEqualImgsBySize,
-				fmt.Sprintf(
-					"%q (%s) != %q (%s)",
-					indexImgStr(i),
-					imgs[i].Bounds().String(),
-					indexImgStr(i+1),
-					imgs[i+1].Bounds().String(),
-				),
-			)
+	img1, img2 = options.alphaImages(img1, img2)
+
+	var orientation Orientation
+	if options.orientationCheck {
+		orientation = DetectOrientation(img1, img2)
+		options.debug("detected orientation of the second image", "orientation", orientation)
+		if options.orientationFix && orientation != OrientationNormal {
+			img2 = Orient(img2, orientation)
 		}
 	}
 
-	if len(notEqualImgsBySize) > 0 {
-		return fmt.Errorf(
-			"%w: images: %s",
-			ErrImageSize,
-			strings.Join(notEqualImgsBySize, `,`),
-		)
+	img1, img2, releaseScaled := scaleImages(img1, img2, &options)
+	defer releaseScaled()
+
+	// the output of the other render modes is composed after the comparison
+	aligned := output
+	if options.renderMode != RenderDiff {
+		aligned = nil
+	}
+
+	img1Obj, img2Obj, outside, err := alignImages(img1, img2, aligned, &options)
+	if err != nil {
+		return nil, DiffResult{}, err
 	}
 
-	return nil
+	// return the converted copies to the pool once they are compared
+	release := func(img *image.NRGBA) {
+		if !sharesPix(img, img1) && !sharesPix(img, img2) {
+			options.pool.put(img)
+		}
+	}
+
+	if options.blur > 0 {
+		options.debug("blurring images", "sigma", options.blur)
+		a, b := img1Obj, img2Obj
+		img1Obj, img2Obj = blurNRGBA(a, &options), blurNRGBA(b, &options)
+		release(a)
+		release(b)
+	}
+
+	if options.median {
+		options.debug("median filtering images")
+		a, b := img1Obj, img2Obj
+		img1Obj, img2Obj = medianNRGBA(a, &options), medianNRGBA(b, &options)
+		release(a)
+		release(b)
+	}
+
+	if options.edges {
+		options.debug("reducing images to edge maps")
+		a, b := img1Obj, img2Obj
+		img1Obj, img2Obj = edgesNRGBA(a, &options), edgesNRGBA(b, &options)
+		release(a)
+		release(b)
+	}
+
+	rect := img1Obj.Bounds()
+
+	var offset image.Point
+	if options.autoAlign > 0 {
+		offset = estimateOffset(img1Obj, img2Obj, options.autoAlign)
+		options.debug("estimated offset of the second image", "offset", offset)
+	}
+	if offset != (image.Point{}) {
+		shifted, overlap := shiftNRGBA(img1Obj, img2Obj, offset, options.pool)
+		release(img2Obj)
+		img2Obj = shifted
+
+		overlap = options.compareRect(overlap)
+		options.region = &overlap
+	}
+
+	defer func() {
+		release(img1Obj)
+		release(img2Obj)
+	}()
+
+	if newOutput {
+		output = options.pool.newNRGBA(options.renderMode.Bounds(rect))
+	}
+
+	drawn := output
+	if output != nil && options.renderMode != RenderDiff {
+		if !output.Rect.Eq(options.renderMode.Bounds(rect)) {
+			return nil, DiffResult{}, ErrImageSize
+		}
+		if options.renderMode == RenderOnionSkin {
+			options.diffMask = true
+		}
+
+		drawn = options.pool.newNRGBA(rect)
+		defer func() {
+			render(options.renderMode, output, img1Obj, img2Obj, drawn)
+			options.pool.put(drawn)
+		}()
+	}
+
+	var (
+		result DiffResult
+		mu     sync.Mutex
+		cmp    = newPixelComparer(&options, rect)
+		budget = newDiffBudget(&options, outside.DiffPixels)
+		prog   = newProgress(&options, cmp.region.Dy())
+	)
+
+	if options.textTolerant() {
+		cmp.text = textRegions(img1Obj, img2Obj, cmp.region)
+		cmp.textMaxDelta = options.maxDeltaFor(options.textThreshold)
+	}
+
+	// edge maps and median filtered images have no more precision than 8 bits
+	if (isHighDepth(img1) || isHighDepth(img2)) && !options.edges && !options.median {
+		options.debug("comparing 16-bit channels")
+		cmp.a64 = toNRGBA64(img1, rect, options.padColor)
+		cmp.b64 = toNRGBA64(img2, rect, options.padColor)
+
+		if options.blur > 0 {
+			cmp.a64, cmp.b64 = blurNRGBA64(cmp.a64, &options), blurNRGBA64(cmp.b64, &options)
+		}
+		if offset != (image.Point{}) {
+			cmp.b64 = shiftNRGBA64(cmp.a64, cmp.b64, offset)
+		}
+	}
+
+	bandsCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	options.debugBands(cmp.region, options.workers())
+	switch {
+	case cmp.skipEqual(img1Obj, img2Obj, drawn, cmp.region, &result):
+		options.debug("images are byte-identical, skipping the pixel loop")
+		options.reportBand(cmp.region, result)
+		prog.add(cmp.region.Dy())
+	case cmp.cacheTiles(drawn):
+		result = cmp.compareCached(bandsCtx, img1Obj, img2Obj, budget, stop, prog)
+	default:
+		if options.coarse && cmp.a64 == nil && options.sampleRate <= 1 {
+			cmp.coarse = coarseTiles(ctx, img1Obj, img2Obj, cmp.region, &options)
+			options.debug("compared coarse tiles", "dirty", cmp.coarse.count(), "total", cmp.coarse.rect.Dx()*cmp.coarse.rect.Dy())
+		}
+
+		runBands(bandsCtx, cmp.region, options.workers(), func(band image.Rectangle) {
+			var (
+				part DiffResult
+				y    = band.Min.Y
+			)
+			if cmp.skipEqual(img1Obj, img2Obj, drawn, band, &part) {
+				y = band.Max.Y
+			}
+			for ; y < band.Max.Y && bandsCtx.Err() == nil; y++ {
+				rowDiff := part.DiffPixels
+				cmp.compareLine(img1Obj, img2Obj, drawn, y, band.Min.X, band.Max.X, &part)
+
+				if budget.spend(part.DiffPixels - rowDiff) {
+					stop()
+				}
+			}
+
+			mu.Lock()
+			result.add(part)
+			if y == band.Max.Y {
+				options.reportBand(band, part)
+			}
+			mu.Unlock()
+
+			prog.add(y - band.Min.Y)
+		})
+	}
+
+	result.Offset = offset
+	result.Orientation = orientation
+
+	if budget.exceeded() {
+		options.debug("stopped early, diff budget exceeded", "max", options.failFastMax)
+		cmp.finish(&result)
+		result.add(outside)
+		result.finish(start)
+
+		return output, result, ErrDiffBudgetExceeded
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, DiffResult{}, err
+	}
+
+	if options.metric == MetricSSIM {
+		result.SSIM, result.SSIMMap, err = ssim(ctx, img1Obj, img2Obj, cmp.region)
+		if err != nil {
+			return nil, DiffResult{}, err
+		}
+	}
+
+	cmp.finish(&result)
+	result.add(outside)
+	result.finish(start)
+
+	return output, result, nil
 }
 
-func Diff(img1, img2 image.Image, output *image.NRGBA) (uint64, error) {
+// pixelComparer holds the settings shared by all rows of a comparison.
+type pixelComparer struct {
+	options  *Options
+	ignore   *ignoreMap
+	grid     *diffGrid
+	maxDelta float64
+
+	// different pixels; nil unless some option needs them after the comparison
+	diff *pixelSet
+
+	// anti-aliased pixels; nil unless WithAAMask is used
+	aa *pixelSet
 
-	if err := checkImages([]image.Image{img1, img2, output}...); err != nil {
-		return 0, err
+	// text-like pixels compared up to textMaxDelta; nil unless WithTextTolerance is used
+	text         *pixelSet
+	textMaxDelta float64
+
+	// whether the metric is YIQ without any color tolerance presets,
+	// which is called directly in the hot loop
+	yiq bool
+
+	// 16-bit copies of the compared images; nil unless one of the inputs
+	// has a high bit depth, then the YIQ delta is computed from them
+	a64, b64 *image.NRGBA64
+
+	// bounds of the images the anti-aliasing check looks for neighbours in
+	bounds image.Rectangle
+
+	// pixels to compare, the images bounds limited by WithRegion
+	region image.Rectangle
+
+	// tiles of the region whose pixels differ according to the coarse
+	// pass; nil compares all pixels
+	coarse *pixelSet
+}
+
+func newPixelComparer(options *Options, rect image.Rectangle) *pixelComparer {
+	region := options.compareRect(rect)
+
+	c := &pixelComparer{
+		options:  options,
+		ignore:   newIgnoreMap(rect, options),
+		grid:     newDiffGrid(region, options),
+		maxDelta: options.maxDelta(),
+		bounds:   rect,
+		region:   region,
+	}
+
+	if options.clusters || options.boxes || options.keepDiff {
+		c.diff = newPixelSet(rect)
+	}
+	if options.aaMask || options.keepAA {
+		c.aa = newPixelSet(rect)
+	}
+
+	switch options.metric.(type) {
+	case yiqMetric, ssimMetric:
+		c.yiq = !options.hasPresets()
+	}
+
+	return c
+}
+
+// delta returns the distance between two colors according to the metric.
+func (c *pixelComparer) delta(c1, c2 [4]uint8) float64 {
+	if c.yiq {
+		return colorDelta(c1, c2, false)
 	}
 
-	options := defaultOptions
+	return c.options.presetDelta(c1, c2)
+}
 
-	img1Obj, _ := img1.(*image.NRGBA)
-	img2Obj, _ := img2.(*image.NRGBA)
+// compareRow compares the pixels of row y from minX to maxX, draws them into
+// output unless it is nil and adds the statistics of the row to part.
+// It works on the Pix slices of the row directly: computing the offset of
+// every pixel dominates the profile of large images otherwise.
+func (c *pixelComparer) compareRow(a, b, output *image.NRGBA, y, minX, maxX int, part *DiffResult) {
+	options := c.options
+
+	n := (maxX - minX) * 4
+	i, j := a.PixOffset(minX, y), b.PixOffset(minX, y)
+	row1, row2 := a.Pix[i:i+n:i+n], b.Pix[j:j+n:j+n]
+
+	var out []uint8
+	if output != nil {
+		o := output.PixOffset(minX, y)
+		out = output.Pix[o : o+n : o+n]
+	}
 
-	// maximum acceptable square distance between two colors;
-	// 35215 is the maximum possible value for the YIQ difference metric
-	maxDelta := float64(35215.0) * options.threshold * options.threshold
+	// with SIMD the YIQ deltas are computed for chunks of the row at once,
+	// skipping equal chunks as their deltas are never looked at
 	var (
-		diff uint64 = 0
-		h           = output.Bounds().Max.Y
-		w           = output.Bounds().Max.X
-		wg          = sync.WaitGroup{}
+		batch  = simdRows && c.yiq && c.a64 == nil
+		deltas [deltaChunk]float64
 	)
 
-	processSubImage := func(a, b *image.NRGBA, rectangle image.Rectangle) {
-		defer wg.Done()
-
-		var (
-			cc1, cc2 [4]uint8
-		)
-		containerDiff := uint64(0)
-		// compare each pixel of one image against the other one
-		for y := rectangle.Min.Y; y < rectangle.Max.Y; y++ {
-			for x := rectangle.Min.X; x < rectangle.Max.X; x++ {
-				cc1 = getColor(a, x, y)
-				cc2 = getColor(b, x, y)
-
-				// squared YUV distance between colors at this pixel position, negative if the img2 pixel is darker
-				delta := colorDelta(cc1, cc2, false)
-
-				// the color difference is above the threshold
-				if math.Abs(delta) > maxDelta {
-					// check it's a real rendering difference or just anti-aliasing
-					if !options.includeAA && (antialiased(a, b, x, y, w, h) || antialiased(a, b, x, y, w, h)) {
-						// one of the pixels is anti-aliasing; draw as yellow and do not count as difference
-						// note that we do not include such pixels in a mask
-						if !options.diffMask {
-							output.SetNRGBA(x, y, options.aaColor)
-						}
-
-					} else {
-						// found substantial difference not caused by anti-aliasing; draw it as such
-						output.SetNRGBA(x, y, options.diffColor)
-						containerDiff++
-					}
-
-				} else if !options.diffMask {
-					// pixels are similar; draw background as grayscale image blended with white
-					output.SetNRGBA(x, y, grayColor(cc1, options.alpha))
+	for k, x := 0, minX; k < n; k, x = k+4, x+1 {
+		if batch && (x-minX)%deltaChunk == 0 {
+			if end := minInt(k+deltaChunk*4, n); !bytes.Equal(row1[k:end], row2[k:end]) {
+				yiqDeltaRow(row1[k:end], row2[k:end], deltas[:(end-k)/4])
+			}
+		}
+
+		p1 := binary.LittleEndian.Uint32(row1[k:])
+
+		if c.ignore.ignored(x, y) {
+			// excluded from the comparison; draw it in the ignore color if there is one
+			if options.ignoreColor != nil {
+				putPixel(out, k, *options.ignoreColor)
+			} else if !options.diffMask {
+				putPixel(out, k, backgroundColor(unpackColor(p1), unpackColor(binary.LittleEndian.Uint32(row2[k:])), options))
+			}
+			continue
+		}
+		part.TotalPixels++
+
+		p2 := binary.LittleEndian.Uint32(row2[k:])
+		transparent := options.ignoreTransparent && (p1|p2)>>24 == 0
+		if transparent {
+			// invisible in both images whatever the color channels hold
+			p2 = p1
+		}
+
+		if options.extraMetrics && p1 != p2 {
+			part.squaredError += squaredError(unpackColor(p1), unpackColor(p2))
+		}
+
+		// squared distance between colors at this pixel position, negative if the img2 pixel is darker
+		var delta float64
+		if c.a64 != nil && c.yiq && !transparent {
+			delta = colorDelta64(getColor64(c.a64, x, y), getColor64(c.b64, x, y), false)
+		} else if p1 != p2 && batch {
+			delta = deltas[(x-minX)%deltaChunk]
+		} else if p1 != p2 {
+			delta = c.delta(unpackColor(p1), unpackColor(p2))
+		}
+
+		// the color difference is above the threshold, the looser one in text
+		if math.Abs(delta) > c.maxDelta && !(c.text.has(x, y) && math.Abs(delta) <= c.textMaxDelta) {
+			// check it's a real rendering difference or just anti-aliasing
+			if !options.includeAA && (antialiased(a, b, x, y, c.bounds) || antialiased(b, a, x, y, c.bounds)) {
+				// one of the pixels is anti-aliasing; draw as yellow and do not count as difference
+				// note that we do not include such pixels in a mask
+				if !options.diffMask {
+					c.drawPixel(out, k, x, y, PixelAntialiased, p1, p2, delta)
+				}
+				part.AAPixels++
+				c.aa.set(x, y)
+				if options.pixelFunc != nil {
+					options.pixelFunc(x, y, delta, PixelAntialiased)
+				}
+
+			} else if options.shift > 0 && c.shifted(a, b, x, y) {
+				// the pixel just moved a bit; draw it as a similar one
+				if !options.diffMask {
+					putPixel(out, k, backgroundColor(unpackColor(p1), unpackColor(p2), options))
+				}
+
+			} else if options.ignoreIsolated && c.isolated(a, b, x, y) {
+				// a lone different pixel, most likely noise; draw it as a similar one
+				if !options.diffMask {
+					putPixel(out, k, backgroundColor(unpackColor(p1), unpackColor(p2), options))
 				}
+
+			} else {
+				// found substantial difference not caused by anti-aliasing; draw it as such
+				c.drawPixel(out, k, x, y, PixelDiff, p1, p2, delta)
+				part.markDiff(x, y)
+				c.grid.markDiff(x, y)
+				c.diff.set(x, y)
+				if options.pixelFunc != nil {
+					options.pixelFunc(x, y, delta, PixelDiff)
+				}
+			}
+
+		} else if !options.diffMask {
+			// pixels are similar; draw background as grayscale image blended with white
+			putPixel(out, k, backgroundColor(unpackColor(p1), unpackColor(p2), options))
+		}
+
+		if options.channelDiff {
+			mask := channelMask(unpackColor(p1), unpackColor(p2), options.threshold*255)
+			part.Channels.count(mask)
+			if options.channelRender && mask != 0 {
+				putPixel(out, k, channelColor(mask))
 			}
 		}
-		atomic.AddUint64(&diff, containerDiff)
 	}
+}
+
+// finish adds the statistics collected by the comparer itself to the result.
+func (c *pixelComparer) finish(r *DiffResult) {
+	r.options = c.options
+	r.Grid = c.grid.result(c.ignore)
 
-	containerW := 2000
-	containerH := 2000
-	if containerH > h {
-		containerH = h - 1
+	c.diff.morph(c.options)
+
+	if c.options.clusters {
+		r.Clusters = findClusters(c.diff, c.options.clusterMinSize)
 	}
-	if containerW > w {
-		containerW = w - 1
+	if c.options.boxes {
+		r.Boxes = findBoxes(c.diff, c.options.maxBoxes, c.options.boxPadding)
 	}
 
-	/*
-				((0,0),(100,100)) | ((100,0),(200,100)) | ((200,0),(300,100)) ....
-				((0,0),(100,100)) | ((100,0),(200,100)) | ((200,0),(300,100)) ....
-		        ....
-	*/
+	if c.options.keepDiff {
+		r.diff = c.diff
+	}
+	if c.options.keepAA {
+		r.aa = c.aa
+	}
+
+	if c.options.aaMask {
+		r.AAMask = c.aa.paletted()
+	}
 
-	for x0 := 0; x0 < w-containerW; x0 += containerW {
-		for y0 := 0; y0 < h-containerH; y0 += containerH {
-			wg.Add(1)
-			x1 := x0 + containerW
-			y1 := y0 + containerW
+	if c.options.extraMetrics {
+		r.Metrics = newExtraMetrics(r.squaredError, r.TotalPixels)
+	}
+
+	if c.options.sampleRate > 1 {
+		r.Sample = newSampleEstimate(c.options.sampleRate, r.DiffPixels, r.TotalPixels)
+	}
+}
+
+// unpackColor returns the channels of a pixel loaded from Pix as a little-endian word.
+func unpackColor(p uint32) [4]uint8 {
+	return [4]uint8{uint8(p), uint8(p >> 8), uint8(p >> 16), uint8(p >> 24)}
+}
 
-			if x1+containerW > w {
-				x1 = w
+// putPixel draws into the output row at byte offset k unless the row is nil.
+func putPixel(row []uint8, k int, c color.NRGBA) {
+	if row != nil {
+		row[k], row[k+1], row[k+2], row[k+3] = c.R, c.G, c.B, c.A
+	}
+}
+
+// runBands calls fn for every row band of r from the given number of goroutines
+// and waits for them to finish. No new bands are started once ctx is done.
+// A single worker calls fn on the calling goroutine, top to bottom.
+func runBands(ctx context.Context, r image.Rectangle, workers int, fn func(band image.Rectangle)) {
+	if workers == 1 {
+		for _, band := range splitBands(r, workers) {
+			if ctx.Err() != nil {
+				return
 			}
-			if y1+containerH > h {
-				y1 = h
+			fn(band)
+		}
+
+		return
+	}
+
+	var (
+		bands = make(chan image.Rectangle)
+		wg    = sync.WaitGroup{}
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for band := range bands {
+				fn(band)
 			}
-			rect := image.Rect(x0, y0, x1, y1)
+		}()
+	}
 
-			go processSubImage(
-				img1Obj.SubImage(rect).(*image.NRGBA),
-				img2Obj.SubImage(rect).(*image.NRGBA),
-				rect,
-			)
+feed:
+	for _, band := range splitBands(r, workers) {
+		select {
+		case bands <- band:
+		case <-ctx.Done():
+			break feed
 		}
 	}
+	close(bands)
 
 	wg.Wait()
+}
+
+// bandsPerWorker is how many row bands every worker gets on average,
+// a few bands per worker keep all of them busy when some bands are slower.
+const bandsPerWorker = 4
+
+// splitBands splits the rectangle into horizontal row bands
+// that cover every row of it exactly once.
+func splitBands(r image.Rectangle, workers int) []image.Rectangle {
+	h := r.Dy()
+	if h <= 0 {
+		return nil
+	}
+
+	count := workers * bandsPerWorker
+	if count > h {
+		count = h
+	}
+	bandH := (h + count - 1) / count
 
-	return diff, nil
+	bands := make([]image.Rectangle, 0, count)
+	for y0 := r.Min.Y; y0 < r.Max.Y; y0 += bandH {
+		y1 := y0 + bandH
+		if y1 > r.Max.Y {
+			y1 = r.Max.Y
+		}
+		bands = append(bands, image.Rect(r.Min.X, y0, r.Max.X, y1))
+	}
+
+	return bands
 }
 
-func grayColor(c [4]uint8, alpha float32) color.NRGBA {
-	val := blend(
-		uint8(rgb2y(c[0], c[1], c[2])),
-		uint8((alpha*float32(c[3]))/255),
-	)
+func grayColor(c [4]uint8, alpha float64) color.NRGBA {
+	val := clampUint8(blend(
+		rgb2y(float64(c[0]), float64(c[1]), float64(c[2])),
+		alpha*float64(c[3])/255,
+	))
 	return color.NRGBA{
 		R: val,
 		G: val,
@@ -246,23 +643,17 @@ func colorDelta(c1, c2 [4]uint8, yOnly bool) float64 {
 		return 0
 	}
 
-	if c1[3] < 255 {
-		c1[3] /= 255
-		c1[0] = blend(c1[0], c1[3])
-		c1[1] = blend(c1[1], c1[3])
-		c1[2] = blend(c1[2], c1[3])
-	}
+	r1, g1, b1 := blendColor(c1)
+	r2, g2, b2 := blendColor(c2)
 
-	if c2[3] < 255 {
-		c2[3] /= 255
-		c2[0] = blend(c2[0], c2[3])
-		c2[1] = blend(c2[1], c2[3])
-		c2[2] = blend(c2[2], c2[3])
-	}
+	return yiqDelta(r1, g1, b1, r2, g2, b2, yOnly)
+}
 
+// yiqDelta returns the YIQ distance of two colors already blended with white.
+func yiqDelta(r1, g1, b1, r2, g2, b2 float64, yOnly bool) float64 {
 	var (
-		y1 = rgb2y(c1[0], c1[1], c1[2])
-		y2 = rgb2y(c2[0], c2[1], c2[2])
+		y1 = rgb2y(r1, g1, b1)
+		y2 = rgb2y(r2, g2, b2)
 		y  = y1 - y2
 	)
 
@@ -272,8 +663,8 @@ func colorDelta(c1, c2 [4]uint8, yOnly bool) float64 {
 	}
 
 	var (
-		i = rgb2i(c1[0], c1[1], c1[2]) - rgb2i(c2[0], c2[1], c2[2])
-		q = rgb2q(c1[0], c1[1], c1[2]) - rgb2q(c2[0], c2[1], c2[2])
+		i = rgb2i(r1, g1, b1) - rgb2i(r2, g2, b2)
+		q = rgb2q(r1, g1, b1) - rgb2q(r2, g2, b2)
 	)
 
 	delta := 0.5053*y*y + 0.299*i*i + 0.1957*q*q
@@ -286,29 +677,54 @@ func colorDelta(c1, c2 [4]uint8, yOnly bool) float64 {
 	return delta
 }
 
-func rgb2y(r, g, b uint8) float64 {
-	return float64(r)*0.29889531 + float64(g)*0.58662247 + float64(b)*0.11448223
+// blendColor returns the channels of a color blended with white by its alpha.
+func blendColor(c [4]uint8) (r, g, b float64) {
+	r, g, b = float64(c[0]), float64(c[1]), float64(c[2])
+	if c[3] < 255 {
+		a := float64(c[3]) / 255
+		r, g, b = blend(r, a), blend(g, a), blend(b, a)
+	}
+
+	return r, g, b
+}
+
+func rgb2y(r, g, b float64) float64 {
+	return r*0.29889531 + g*0.58662247 + b*0.11448223
 }
-func rgb2i(r, g, b uint8) float64 {
-	return float64(r)*0.59597799 - float64(g)*0.27417610 - float64(b)*0.32180189
+func rgb2i(r, g, b float64) float64 {
+	return r*0.59597799 - g*0.27417610 - b*0.32180189
 }
-func rgb2q(r, g, b uint8) float64 {
-	return float64(r)*0.21147017 - float64(g)*0.52261711 + float64(b)*0.31114694
+func rgb2q(r, g, b float64) float64 {
+	return r*0.21147017 - g*0.52261711 + b*0.31114694
 }
 
 // blend semi-transparent color with white
-func blend(c, a uint8) uint8 {
+func blend(c, a float64) float64 {
 	return 255 + (c-255)*a
 }
 
-// check if a pixel is likely a part of anti-aliasing;
+// clampUint8 rounds a channel value the way a Uint8ClampedArray does in the
+// reference implementation: to the nearest integer, ties to even, within 0..255.
+func clampUint8(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	default:
+		return uint8(math.RoundToEven(v))
+	}
+}
+
+// check if a pixel of a is likely a part of anti-aliasing, comparing it with its
+// siblings in a and checking the extreme siblings in both a and b;
 // based on "Anti-aliased Pixel and Intensity Slope Detector" paper by V. Vysniauskas, 2009
-func antialiased(a, b *image.NRGBA, x1, y1, width, height int) bool {
+func antialiased(a, b *image.NRGBA, x1, y1 int, bounds image.Rectangle) bool {
 	var (
-		x0                             = int(math.Max(float64(x1-1), 0))
-		y0                             = int(math.Max(float64(y1-1), float64(0)))
-		x2                             = int(math.Min(float64(x1+1), float64(width-1)))
-		y2                             = int(math.Min(float64(y1+1), float64(height-1)))
+		x0                             = maxInt(x1-1, bounds.Min.X)
+		y0                             = maxInt(y1-1, bounds.Min.Y)
+		x2                             = minInt(x1+1, bounds.Max.X-1)
+		y2                             = minInt(y1+1, bounds.Max.Y-1)
 		zeroes                         = 0
 		min                    float64 = 0
 		max                    float64 = 0
@@ -319,6 +735,8 @@ func antialiased(a, b *image.NRGBA, x1, y1, width, height int) bool {
 		zeroes = 1
 	}
 
+	center := getColor(a, x1, y1)
+
 	// go through 8 adjacent pixels
 	for x := x0; x <= x2; x++ {
 		for y := y0; y <= y2; y++ {
@@ -327,7 +745,7 @@ func antialiased(a, b *image.NRGBA, x1, y1, width, height int) bool {
 			}
 
 			// brightness delta between the center pixel and adjacent one
-			delta := colorDelta(getColor(a, x1, y1), getColor(b, x, y), true)
+			delta := colorDelta(center, getColor(a, x, y), true)
 
 			// count the number of equal, darker and brighter adjacent pixels
 			if delta == 0 {
@@ -359,8 +777,8 @@ func antialiased(a, b *image.NRGBA, x1, y1, width, height int) bool {
 
 	// if either the darkest or the brightest pixel has 3+ equal siblings in both images
 	// (definitely not anti-aliased), this pixel is anti-aliased
-	return (hasManySiblings(a, minX, minY, width, height) && hasManySiblings(b, minX, minY, width, height)) ||
-		(hasManySiblings(a, maxX, maxY, width, height) && hasManySiblings(b, maxX, maxY, width, height))
+	return (hasManySiblings(a, minX, minY, bounds) && hasManySiblings(b, minX, minY, bounds)) ||
+		(hasManySiblings(a, maxX, maxY, bounds) && hasManySiblings(b, maxX, maxY, bounds))
 }
 
 func getColor(img *image.NRGBA, x, y int) (c [4]uint8) {
@@ -376,12 +794,12 @@ func colorEq(c1, c2 [4]uint8) bool {
 }
 
 // check if a pixel has 3+ adjacent pixels of the same color.
-func hasManySiblings(a *image.NRGBA, x1, y1, width, height int) bool {
+func hasManySiblings(a *image.NRGBA, x1, y1 int, bounds image.Rectangle) bool {
 	var (
-		x0     = int(math.Max(float64(x1-1), 0))
-		y0     = int(math.Max(float64(y1-1), float64(0)))
-		x2     = int(math.Min(float64(x1+1), float64(width-1)))
-		y2     = int(math.Min(float64(y1+1), float64(height-1)))
+		x0     = maxInt(x1-1, bounds.Min.X)
+		y0     = maxInt(y1-1, bounds.Min.Y)
+		x2     = minInt(x1+1, bounds.Max.X-1)
+		y2     = minInt(y1+1, bounds.Max.Y-1)
 		zeroes = 0
 	)
 
@@ -389,6 +807,8 @@ func hasManySiblings(a *image.NRGBA, x1, y1, width, height int) bool {
 		zeroes = 1
 	}
 
+	center := getColor(a, x1, y1)
+
 	// go through 8 adjacent pixels
 	for x := x0; x <= x2; x++ {
 		for y := y0; y <= y2; y++ {
@@ -396,7 +816,7 @@ func hasManySiblings(a *image.NRGBA, x1, y1, width, height int) bool {
 				continue
 			}
 
-			if colorEq(getColor(a, x1, y1), getColor(a, x, y)) {
+			if colorEq(center, getColor(a, x, y)) {
 				zeroes++
 			}
 
@@ -2,8 +2,13 @@ package pixelmatch
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"image"
+	"image/color"
+	"image/draw"
 	"image/png"
+	"math"
 	"os"
 	"testing"
 	"time"
@@ -38,14 +43,14 @@ func TestDiff(t *testing.T) {
 	output := image.NewNRGBA(imgA.Bounds())
 
 	ts := time.Now()
-	diffCount, err := Diff(imgA, imgB, output)
+	result, err := Diff(imgA, imgB, output)
 	t.Log("diff latency", time.Since(ts).String())
 	if err != nil {
 		t.Error("Unexpected error:", err)
 	}
-	t.Log("diffCount", diffCount)
-	if diffCount > 146355 {
-		t.Errorf("Expected 146355, got - %d", diffCount)
+	t.Log("diffCount", result.DiffPixels)
+	if result.DiffPixels > 146355 {
+		t.Errorf("Expected 146355, got - %d", result.DiffPixels)
 	}
 
 	buff := bytes.NewBuffer(make([]byte, 0, lenA))
@@ -61,3 +66,414 @@ func TestDiff(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSplitBands(t *testing.T) {
+	for _, tc := range []struct {
+		rect    image.Rectangle
+		workers int
+	}{
+		{image.Rect(0, 0, 10, 1), 8},
+		{image.Rect(0, 0, 10, 7), 1},
+		{image.Rect(0, 0, 10, 4097), 3},
+		{image.Rect(5, 3, 10, 50), 16},
+	} {
+		bands := splitBands(tc.rect, tc.workers)
+
+		y := tc.rect.Min.Y
+		for _, band := range bands {
+			if band.Min.Y != y || band.Min.X != tc.rect.Min.X || band.Max.X != tc.rect.Max.X || band.Empty() {
+				t.Fatalf("%v: unexpected band %v", tc.rect, band)
+			}
+			y = band.Max.Y
+		}
+		if y != tc.rect.Max.Y {
+			t.Errorf("%v: bands end at %d", tc.rect, y)
+		}
+	}
+}
+
+func TestDiffCoversEdges(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 7, 5))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 7, 5))
+	for _, p := range []image.Point{{0, 0}, {6, 0}, {0, 4}, {6, 4}} {
+		img2.SetNRGBA(p.X, p.Y, color.NRGBA{R: 255, A: 255})
+	}
+
+	for _, n := range []int{1, 2, 16} {
+		result, err := Diff(img1, img2, image.NewNRGBA(img1.Bounds()), WithParallelism(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.DiffPixels != 4 {
+			t.Errorf("parallelism %d: expected 4, got - %d", n, result.DiffPixels)
+		}
+		if result.TotalPixels != 35 {
+			t.Errorf("parallelism %d: expected 35 compared pixels, got - %d", n, result.TotalPixels)
+		}
+		if !result.Bounds.Eq(img1.Bounds()) {
+			t.Errorf("parallelism %d: unexpected bounds %v", n, result.Bounds)
+		}
+	}
+}
+
+func TestDiffTinyImages(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+
+	for _, size := range []image.Point{{1, 1}, {1, 7}, {7, 1}, {2, 2}, {2, 1}, {3, 3}, {16, 16}} {
+		white, changed := image.NewNRGBA(image.Rectangle{Max: size}), image.NewNRGBA(image.Rectangle{Max: size})
+		for i := range white.Pix {
+			white.Pix[i] = 255
+		}
+		draw.Draw(changed, changed.Rect, image.NewUniform(color.Black), image.Point{}, draw.Src)
+		n := uint64(size.X * size.Y)
+
+		for _, tc := range []struct {
+			name string
+			opts []Option
+		}{
+			{"default", nil},
+			{"sequential", []Option{WithSequential()}},
+			{"include aa", []Option{WithIncludeAA(true)}},
+			{"shift", []Option{WithShiftTolerance(1)}},
+			{"blur", []Option{WithBlur(1)}},
+			{"auto align", []Option{WithAutoAlign(2)}},
+		} {
+			output, result, err := DiffNew(white, changed, append(tc.opts, WithDiffMask(false))...)
+			if err != nil {
+				t.Fatalf("%v %s: %v", size, tc.name, err)
+			}
+			if result.DiffPixels != n || result.TotalPixels != n {
+				t.Errorf("%v %s: expected all %d pixels different, got - %d of %d", size, tc.name, n, result.DiffPixels, result.TotalPixels)
+			}
+			for y := 0; y < size.Y; y++ {
+				for x := 0; x < size.X; x++ {
+					if got := output.NRGBAAt(x, y); got != red {
+						t.Errorf("%v %s: expected (%d,%d) drawn as different, got - %v", size, tc.name, x, y, got)
+					}
+				}
+			}
+
+			if count, err := Compare(white, changed, tc.opts...); err != nil || count != n {
+				t.Errorf("%v %s: expected Compare to count %d pixels, got - %d, %v", size, tc.name, n, count, err)
+			}
+			if same, err := Diff(white, white, nil, tc.opts...); err != nil || same.DiffPixels != 0 || same.TotalPixels != n {
+				t.Errorf("%v %s: expected no differences of %d pixels, got - %+v, %v", size, tc.name, n, same, err)
+			}
+		}
+	}
+}
+
+func TestDiffContextCanceled(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := DiffContext(ctx, img1, img2, image.NewNRGBA(img1.Bounds()))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got - %v", err)
+	}
+}
+
+func TestDiffResult(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	img2.SetNRGBA(2, 3, color.NRGBA{B: 255, A: 255})
+	img2.SetNRGBA(5, 7, color.NRGBA{B: 255, A: 255})
+
+	result, err := Diff(img1, img2, image.NewNRGBA(img1.Bounds()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.DiffPixels != 2 || result.TotalPixels != 100 {
+		t.Errorf("Unexpected counts %d/%d", result.DiffPixels, result.TotalPixels)
+	}
+	if result.Percent != 2 {
+		t.Errorf("Expected 2%%, got - %v", result.Percent)
+	}
+	if want := image.Rect(2, 3, 6, 8); !result.Bounds.Eq(want) {
+		t.Errorf("Expected bounds %v, got - %v", want, result.Bounds)
+	}
+	if result.Elapsed <= 0 {
+		t.Error("Expected elapsed time to be measured")
+	}
+}
+
+func TestDiffWithoutOutput(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	img2.SetNRGBA(3, 3, color.NRGBA{G: 255, A: 255})
+
+	result, err := Diff(img1, img2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 1 {
+		t.Errorf("Expected 1, got - %d", result.DiffPixels)
+	}
+
+	output, result, err := DiffNew(img1, img2, WithDiffColor(color.White))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 1 {
+		t.Errorf("Expected 1, got - %d", result.DiffPixels)
+	}
+	if output == nil || !output.Bounds().Eq(img1.Bounds()) {
+		t.Fatalf("Unexpected output %v", output)
+	}
+	if got := output.NRGBAAt(3, 3); got != (color.NRGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("Expected diff pixel drawn white, got - %v", got)
+	}
+
+	if _, _, err := DiffNew(img1, nil); !errors.Is(err, ErrEmptyImage) {
+		t.Errorf("Expected ErrEmptyImage, got - %v", err)
+	}
+}
+
+func TestDiffIncludeAA(t *testing.T) {
+	img1 := decodeTestImage(t, "./testdata/img1.png")
+	img2 := decodeTestImage(t, "./testdata/img2.png")
+
+	detected, err := Diff(img1, img2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detected.AAPixels == 0 {
+		t.Error("Expected some anti-aliased pixels to be detected")
+	}
+
+	included, err := Diff(img1, img2, nil, WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if included.AAPixels != 0 {
+		t.Errorf("Expected no anti-aliased pixels, got - %d", included.AAPixels)
+	}
+
+	if included.DiffPixels != detected.DiffPixels+detected.AAPixels {
+		t.Errorf("Expected %d+%d, got - %d", detected.DiffPixels, detected.AAPixels, included.DiffPixels)
+	}
+}
+
+// alphaGradient returns a black image fading from transparent to opaque
+// and an opaque gray image looking the same over white.
+func alphaGradient() (*image.NRGBA, *image.NRGBA) {
+	transparent := image.NewNRGBA(image.Rect(0, 0, 256, 4))
+	opaque := image.NewNRGBA(image.Rect(0, 0, 256, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 256; x++ {
+			transparent.SetNRGBA(x, y, color.NRGBA{A: uint8(x)})
+			opaque.SetNRGBA(x, y, color.NRGBA{R: uint8(255 - x), G: uint8(255 - x), B: uint8(255 - x), A: 255})
+		}
+	}
+
+	return transparent, opaque
+}
+
+func TestColorDeltaAlpha(t *testing.T) {
+	white := [4]uint8{255, 255, 255, 255}
+
+	if delta := colorDelta([4]uint8{0, 0, 0, 0}, white, false); delta != 0 {
+		t.Errorf("Expected transparent to match white, got - %v", delta)
+	}
+	if delta := colorDelta([4]uint8{0, 0, 0, 128}, [4]uint8{127, 127, 127, 255}, false); math.Abs(delta) > 1e-9 {
+		t.Errorf("Expected half transparent black to match gray, got - %v", delta)
+	}
+	if delta := colorDelta([4]uint8{0, 0, 0, 128}, white, false); delta <= 0 {
+		t.Errorf("Expected white to be brighter than half transparent black, got - %v", delta)
+	}
+
+	if got := grayColor([4]uint8{0, 0, 0, 255}, 0.1); got.R != 230 {
+		t.Errorf("Expected 230, got - %d", got.R)
+	}
+	if got := grayColor([4]uint8{0, 0, 0, 128}, 1); got.R != 127 {
+		t.Errorf("Expected 127, got - %d", got.R)
+	}
+
+	transparent, opaque := alphaGradient()
+	result, err := Diff(transparent, opaque, nil, WithThreshold(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 0 {
+		t.Errorf("Expected alpha gradient to match gray gradient, got - %d", result.DiffPixels)
+	}
+}
+
+func TestDiffColorAlt(t *testing.T) {
+	gray := color.NRGBA{R: 128, G: 128, B: 128, A: 255}
+	img1 := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img1.SetNRGBA(0, 0, gray)
+	img1.SetNRGBA(1, 0, gray)
+	img2.SetNRGBA(0, 0, color.NRGBA{A: 255})
+	img2.SetNRGBA(1, 0, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+
+	green := color.NRGBA{G: 255, A: 255}
+	output, _, err := DiffNew(img1, img2, WithDiffColorAlt(green))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := output.NRGBAAt(0, 0); got != green {
+		t.Errorf("Expected darkened pixel drawn as %v, got - %v", green, got)
+	}
+	if got := output.NRGBAAt(1, 0); got != defaultOptions.diffColor {
+		t.Errorf("Expected lightened pixel drawn as %v, got - %v", defaultOptions.diffColor, got)
+	}
+}
+
+// benchImages returns a pair of synthetic w×h images with a gradient
+// background and a few changed blocks, close to typical screenshots.
+func benchImages(w, h int) (a, b *image.NRGBA) {
+	a, b = image.NewNRGBA(image.Rect(0, 0, w, h)), image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: 255}
+			a.SetNRGBA(x, y, c)
+			if x%97 < 10 && y%89 < 10 {
+				c.R += 64
+			}
+			b.SetNRGBA(x, y, c)
+		}
+	}
+
+	return a, b
+}
+
+func benchmarkDiff(bench *testing.B, w, h int) {
+	a, b := benchImages(w, h)
+	output := image.NewNRGBA(a.Bounds())
+
+	bench.SetBytes(int64(w * h * 4))
+	bench.ResetTimer()
+	for i := 0; i < bench.N; i++ {
+		if _, err := Diff(a, b, output); err != nil {
+			bench.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDiff1080p(b *testing.B) { benchmarkDiff(b, 1920, 1080) }
+func BenchmarkDiff4K(b *testing.B)    { benchmarkDiff(b, 3840, 2160) }
+func BenchmarkDiff8K(b *testing.B)    { benchmarkDiff(b, 7680, 4320) }
+
+// atlasPair returns a pair of images with different, anti-aliased and edge
+// pixels, together with the same pair as sub-images of larger atlases at off.
+func atlasPair(off image.Point) (a, b, subA, subB *image.NRGBA) {
+	a = image.NewNRGBA(image.Rect(0, 0, 64, 48))
+	draw.Draw(a, a.Rect, image.White, image.Point{}, draw.Src)
+	draw.Draw(a, image.Rect(20, 5, 24, 40), image.Black, image.Point{}, draw.Src)
+
+	b = cloneNRGBA(a)
+	draw.Draw(b, image.Rect(24, 5, 25, 40), &image.Uniform{C: color.Gray{Y: 128}}, image.Point{}, draw.Src)
+	draw.Draw(b, image.Rect(40, 10, 45, 15), &image.Uniform{C: color.NRGBA{R: 255, A: 255}}, image.Point{}, draw.Src)
+	b.SetNRGBA(0, 0, color.NRGBA{B: 255, A: 255})
+	b.SetNRGBA(63, 47, color.NRGBA{G: 255, A: 255})
+
+	sub := func(img *image.NRGBA) *image.NRGBA {
+		atlas := texturedImage(200, 150, 3)
+		draw.Draw(atlas, img.Rect.Add(off), img, image.Point{}, draw.Src)
+		return atlas.SubImage(img.Rect.Add(off)).(*image.NRGBA)
+	}
+
+	return a, b, sub(a), sub(b)
+}
+
+func TestDiffSubImages(t *testing.T) {
+	off := image.Pt(37, 23)
+	a, b, subA, subB := atlasPair(off)
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"include AA", []Option{WithIncludeAA(true)}},
+		{"background", []Option{WithDiffMask(false)}},
+		{"shift", []Option{WithShiftTolerance(2)}},
+		{"blur", []Option{WithBlur(1)}},
+		{"auto-align", []Option{WithAutoAlign(3)}},
+		{"SSIM", []Option{WithMetric(MetricSSIM)}},
+		{"grid and clusters", []Option{WithGrid(4, 4), WithClusters(1)}},
+		{"side by side", []Option{WithRenderMode(RenderSideBySide)}},
+		{"onion skin", []Option{WithRenderMode(RenderOnionSkin)}},
+		{"extra metrics", []Option{WithExtraMetrics(), WithAAMask()}},
+	}
+
+	for _, tc := range tests {
+		out, want, err := DiffNew(a, b, tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		subOut, got, err := DiffNew(subA, subB, tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		if got.DiffPixels != want.DiffPixels || got.AAPixels != want.AAPixels || got.TotalPixels != want.TotalPixels {
+			t.Errorf("%s: expected %d different and %d anti-aliased pixels of %d, got - %d, %d of %d",
+				tc.name, want.DiffPixels, want.AAPixels, want.TotalPixels, got.DiffPixels, got.AAPixels, got.TotalPixels)
+		}
+		if want.Bounds.Empty() != got.Bounds.Empty() || !want.Bounds.Empty() && got.Bounds.Size() != want.Bounds.Size() {
+			t.Errorf("%s: expected bounds of size %v, got - %v", tc.name, want.Bounds, got.Bounds)
+		}
+		if got.SSIM != want.SSIM || got.Offset != want.Offset {
+			t.Errorf("%s: expected SSIM %f and offset %v, got - %f, %v", tc.name, want.SSIM, want.Offset, got.SSIM, got.Offset)
+		}
+		if len(got.Clusters) != len(want.Clusters) {
+			t.Errorf("%s: expected %d clusters, got - %d", tc.name, len(want.Clusters), len(got.Clusters))
+		}
+		if got.Grid != nil {
+			for i, cell := range got.Grid.Cells {
+				if cell.DiffPixels != want.Grid.Cells[i].DiffPixels {
+					t.Errorf("%s: expected %d different pixels in cell %d, got - %d", tc.name, want.Grid.Cells[i].DiffPixels, i, cell.DiffPixels)
+				}
+			}
+		}
+		if got.AAMask != nil && maskOnes(got.AAMask) != maskOnes(want.AAMask) {
+			t.Errorf("%s: expected %d pixels in the AA mask, got - %d", tc.name, maskOnes(want.AAMask), maskOnes(got.AAMask))
+		}
+		if out.Rect.Size() != subOut.Rect.Size() || !bytes.Equal(out.Pix, subOut.Pix) {
+			t.Errorf("%s: expected the same output for sub-images", tc.name)
+		}
+
+		count, err := Compare(subA, subB, tc.opts...)
+		if err != nil || count != want.DiffPixels {
+			t.Errorf("%s: expected Compare to count %d different pixels, got - %d, %v", tc.name, want.DiffPixels, count, err)
+		}
+	}
+
+	// scaling samples in the coordinates of the images, so sub-images at even
+	// offsets are scaled like the originals
+	a, b, subA, subB = atlasPair(image.Pt(38, 24))
+	out, want, err := DiffNew(a, b, WithScale(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subOut, got, err := DiffNew(subA, subB, WithScale(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DiffPixels != want.DiffPixels || got.TotalPixels != want.TotalPixels || !bytes.Equal(out.Pix, subOut.Pix) {
+		t.Errorf("scale: expected %d different pixels of %d, got - %d of %d", want.DiffPixels, want.TotalPixels, got.DiffPixels, got.TotalPixels)
+	}
+
+	// 16-bit sub-images
+	a, b, subA, subB = atlasPair(off)
+	deep := func(img *image.NRGBA) *image.NRGBA64 {
+		return toNRGBA64(img, img.Rect, color.NRGBA{})
+	}
+	if want, err = Diff(deep(a), deep(b), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = Diff(deep(subA), deep(subB), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got.DiffPixels != want.DiffPixels || got.AAPixels != want.AAPixels {
+		t.Errorf("16-bit: expected %d different and %d anti-aliased pixels, got - %d, %d", want.DiffPixels, want.AAPixels, got.DiffPixels, got.AAPixels)
+	}
+}
@@ -0,0 +1,19 @@
+module github.com/inotnako/pixelmatch-go/pixelmatchgrpc
+
+go 1.21
+
+require (
+	github.com/inotnako/pixelmatch-go v0.0.0
+	google.golang.org/grpc v1.65.0
+	google.golang.org/protobuf v1.34.2
+)
+
+require (
+	golang.org/x/image v0.18.0 // indirect
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157 // indirect
+)
+
+replace github.com/inotnako/pixelmatch-go => ../
@@ -0,0 +1,656 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: pixelmatchpb/pixelmatch.proto
+
+package pixelmatchpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Options of a comparison; unset fields keep the defaults of the library.
+type Options struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// matching threshold (0 to 1); smaller is more sensitive
+	Threshold *float64 `protobuf:"fixed64,1,opt,name=threshold,proto3,oneof" json:"threshold,omitempty"`
+	// count anti-aliased pixels as different
+	IncludeAa *bool `protobuf:"varint,2,opt,name=include_aa,json=includeAa,proto3,oneof" json:"include_aa,omitempty"`
+	// opacity of original image in diff output
+	Alpha *float64 `protobuf:"fixed64,3,opt,name=alpha,proto3,oneof" json:"alpha,omitempty"`
+	// draw the diff over a transparent background
+	DiffMask *bool `protobuf:"varint,4,opt,name=diff_mask,json=diffMask,proto3,oneof" json:"diff_mask,omitempty"`
+	// do not render the diff image
+	SkipDiffImage bool `protobuf:"varint,5,opt,name=skip_diff_image,json=skipDiffImage,proto3" json:"skip_diff_image,omitempty"`
+}
+
+func (x *Options) Reset() {
+	*x = Options{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Options) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Options) ProtoMessage() {}
+
+func (x *Options) ProtoReflect() protoreflect.Message {
+	mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Options.ProtoReflect.Descriptor instead.
+func (*Options) Descriptor() ([]byte, []int) {
+	return file_pixelmatchpb_pixelmatch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Options) GetThreshold() float64 {
+	if x != nil && x.Threshold != nil {
+		return *x.Threshold
+	}
+	return 0
+}
+
+func (x *Options) GetIncludeAa() bool {
+	if x != nil && x.IncludeAa != nil {
+		return *x.IncludeAa
+	}
+	return false
+}
+
+func (x *Options) GetAlpha() float64 {
+	if x != nil && x.Alpha != nil {
+		return *x.Alpha
+	}
+	return 0
+}
+
+func (x *Options) GetDiffMask() bool {
+	if x != nil && x.DiffMask != nil {
+		return *x.DiffMask
+	}
+	return false
+}
+
+func (x *Options) GetSkipDiffImage() bool {
+	if x != nil {
+		return x.SkipDiffImage
+	}
+	return false
+}
+
+type DiffRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ImageA  []byte   `protobuf:"bytes,1,opt,name=image_a,json=imageA,proto3" json:"image_a,omitempty"`
+	ImageB  []byte   `protobuf:"bytes,2,opt,name=image_b,json=imageB,proto3" json:"image_b,omitempty"`
+	Options *Options `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+}
+
+func (x *DiffRequest) Reset() {
+	*x = DiffRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DiffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffRequest) ProtoMessage() {}
+
+func (x *DiffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffRequest.ProtoReflect.Descriptor instead.
+func (*DiffRequest) Descriptor() ([]byte, []int) {
+	return file_pixelmatchpb_pixelmatch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DiffRequest) GetImageA() []byte {
+	if x != nil {
+		return x.ImageA
+	}
+	return nil
+}
+
+func (x *DiffRequest) GetImageB() []byte {
+	if x != nil {
+		return x.ImageB
+	}
+	return nil
+}
+
+func (x *DiffRequest) GetOptions() *Options {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type DiffChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ImageA  []byte   `protobuf:"bytes,1,opt,name=image_a,json=imageA,proto3" json:"image_a,omitempty"`
+	ImageB  []byte   `protobuf:"bytes,2,opt,name=image_b,json=imageB,proto3" json:"image_b,omitempty"`
+	Options *Options `protobuf:"bytes,3,opt,name=options,proto3" json:"options,omitempty"`
+}
+
+func (x *DiffChunk) Reset() {
+	*x = DiffChunk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DiffChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffChunk) ProtoMessage() {}
+
+func (x *DiffChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffChunk.ProtoReflect.Descriptor instead.
+func (*DiffChunk) Descriptor() ([]byte, []int) {
+	return file_pixelmatchpb_pixelmatch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DiffChunk) GetImageA() []byte {
+	if x != nil {
+		return x.ImageA
+	}
+	return nil
+}
+
+func (x *DiffChunk) GetImageB() []byte {
+	if x != nil {
+		return x.ImageB
+	}
+	return nil
+}
+
+func (x *DiffChunk) GetOptions() *Options {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type Rect struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MinX int32 `protobuf:"varint,1,opt,name=min_x,json=minX,proto3" json:"min_x,omitempty"`
+	MinY int32 `protobuf:"varint,2,opt,name=min_y,json=minY,proto3" json:"min_y,omitempty"`
+	MaxX int32 `protobuf:"varint,3,opt,name=max_x,json=maxX,proto3" json:"max_x,omitempty"`
+	MaxY int32 `protobuf:"varint,4,opt,name=max_y,json=maxY,proto3" json:"max_y,omitempty"`
+}
+
+func (x *Rect) Reset() {
+	*x = Rect{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rect) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rect) ProtoMessage() {}
+
+func (x *Rect) ProtoReflect() protoreflect.Message {
+	mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rect.ProtoReflect.Descriptor instead.
+func (*Rect) Descriptor() ([]byte, []int) {
+	return file_pixelmatchpb_pixelmatch_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Rect) GetMinX() int32 {
+	if x != nil {
+		return x.MinX
+	}
+	return 0
+}
+
+func (x *Rect) GetMinY() int32 {
+	if x != nil {
+		return x.MinY
+	}
+	return 0
+}
+
+func (x *Rect) GetMaxX() int32 {
+	if x != nil {
+		return x.MaxX
+	}
+	return 0
+}
+
+func (x *Rect) GetMaxY() int32 {
+	if x != nil {
+		return x.MaxY
+	}
+	return 0
+}
+
+type DiffResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// number of different pixels
+	DiffPixels uint64 `protobuf:"varint,1,opt,name=diff_pixels,json=diffPixels,proto3" json:"diff_pixels,omitempty"`
+	// number of pixels that differ only because of anti-aliasing
+	AaPixels uint64 `protobuf:"varint,2,opt,name=aa_pixels,json=aaPixels,proto3" json:"aa_pixels,omitempty"`
+	// number of compared pixels
+	TotalPixels uint64 `protobuf:"varint,3,opt,name=total_pixels,json=totalPixels,proto3" json:"total_pixels,omitempty"`
+	// share of different pixels, from 0 to 100
+	Percent float64 `protobuf:"fixed64,4,opt,name=percent,proto3" json:"percent,omitempty"`
+	// bounding box of all different pixels
+	Bounds *Rect `protobuf:"bytes,5,opt,name=bounds,proto3" json:"bounds,omitempty"`
+}
+
+func (x *DiffResult) Reset() {
+	*x = DiffResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DiffResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffResult) ProtoMessage() {}
+
+func (x *DiffResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffResult.ProtoReflect.Descriptor instead.
+func (*DiffResult) Descriptor() ([]byte, []int) {
+	return file_pixelmatchpb_pixelmatch_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DiffResult) GetDiffPixels() uint64 {
+	if x != nil {
+		return x.DiffPixels
+	}
+	return 0
+}
+
+func (x *DiffResult) GetAaPixels() uint64 {
+	if x != nil {
+		return x.AaPixels
+	}
+	return 0
+}
+
+func (x *DiffResult) GetTotalPixels() uint64 {
+	if x != nil {
+		return x.TotalPixels
+	}
+	return 0
+}
+
+func (x *DiffResult) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *DiffResult) GetBounds() *Rect {
+	if x != nil {
+		return x.Bounds
+	}
+	return nil
+}
+
+type DiffResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// set in the only response of Diff and the first response of DiffStream
+	Result *DiffResult `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	// PNG encoded diff image, or a chunk of it in DiffStream
+	DiffPng []byte `protobuf:"bytes,2,opt,name=diff_png,json=diffPng,proto3" json:"diff_png,omitempty"`
+}
+
+func (x *DiffResponse) Reset() {
+	*x = DiffResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DiffResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffResponse) ProtoMessage() {}
+
+func (x *DiffResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pixelmatchpb_pixelmatch_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffResponse.ProtoReflect.Descriptor instead.
+func (*DiffResponse) Descriptor() ([]byte, []int) {
+	return file_pixelmatchpb_pixelmatch_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DiffResponse) GetResult() *DiffResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *DiffResponse) GetDiffPng() []byte {
+	if x != nil {
+		return x.DiffPng
+	}
+	return nil
+}
+
+var File_pixelmatchpb_pixelmatch_proto protoreflect.FileDescriptor
+
+var file_pixelmatchpb_pixelmatch_proto_rawDesc = []byte{
+	0x0a, 0x1d, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x70, 0x62, 0x2f, 0x70,
+	0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x0d, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x22, 0xea,
+	0x01, 0x0a, 0x07, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x21, 0x0a, 0x09, 0x74, 0x68,
+	0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52,
+	0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x88, 0x01, 0x01, 0x12, 0x22, 0x0a,
+	0x0a, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x61, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x48, 0x01, 0x52, 0x09, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x41, 0x61, 0x88, 0x01,
+	0x01, 0x12, 0x19, 0x0a, 0x05, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x02, 0x52, 0x05, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x09,
+	0x64, 0x69, 0x66, 0x66, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x48,
+	0x03, 0x52, 0x08, 0x64, 0x69, 0x66, 0x66, 0x4d, 0x61, 0x73, 0x6b, 0x88, 0x01, 0x01, 0x12, 0x26,
+	0x0a, 0x0f, 0x73, 0x6b, 0x69, 0x70, 0x5f, 0x64, 0x69, 0x66, 0x66, 0x5f, 0x69, 0x6d, 0x61, 0x67,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x73, 0x6b, 0x69, 0x70, 0x44, 0x69, 0x66,
+	0x66, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73,
+	0x68, 0x6f, 0x6c, 0x64, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x5f, 0x61, 0x61, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x61, 0x6c, 0x70, 0x68, 0x61, 0x42, 0x0c, 0x0a,
+	0x0a, 0x5f, 0x64, 0x69, 0x66, 0x66, 0x5f, 0x6d, 0x61, 0x73, 0x6b, 0x22, 0x71, 0x0a, 0x0b, 0x44,
+	0x69, 0x66, 0x66, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x6d,
+	0x61, 0x67, 0x65, 0x5f, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x69, 0x6d, 0x61,
+	0x67, 0x65, 0x41, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x62, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x12, 0x30, 0x0a, 0x07,
+	0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x70, 0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x6f,
+	0x0a, 0x09, 0x44, 0x69, 0x66, 0x66, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x17, 0x0a, 0x07, 0x69,
+	0x6d, 0x61, 0x67, 0x65, 0x5f, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x69, 0x6d,
+	0x61, 0x67, 0x65, 0x41, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x62, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x12, 0x30, 0x0a,
+	0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x4f,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22,
+	0x5a, 0x0a, 0x04, 0x52, 0x65, 0x63, 0x74, 0x12, 0x13, 0x0a, 0x05, 0x6d, 0x69, 0x6e, 0x5f, 0x78,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6d, 0x69, 0x6e, 0x58, 0x12, 0x13, 0x0a, 0x05,
+	0x6d, 0x69, 0x6e, 0x5f, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6d, 0x69, 0x6e,
+	0x59, 0x12, 0x13, 0x0a, 0x05, 0x6d, 0x61, 0x78, 0x5f, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x6d, 0x61, 0x78, 0x58, 0x12, 0x13, 0x0a, 0x05, 0x6d, 0x61, 0x78, 0x5f, 0x79, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6d, 0x61, 0x78, 0x59, 0x22, 0xb4, 0x01, 0x0a, 0x0a,
+	0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x69,
+	0x66, 0x66, 0x5f, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x0a, 0x64, 0x69, 0x66, 0x66, 0x50, 0x69, 0x78, 0x65, 0x6c, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x61,
+	0x61, 0x5f, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08,
+	0x61, 0x61, 0x50, 0x69, 0x78, 0x65, 0x6c, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f, 0x74, 0x61,
+	0x6c, 0x5f, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x50, 0x69, 0x78, 0x65, 0x6c, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70,
+	0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x70, 0x65,
+	0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x2b, 0x0a, 0x06, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74,
+	0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x63, 0x74, 0x52, 0x06, 0x62, 0x6f, 0x75, 0x6e,
+	0x64, 0x73, 0x22, 0x5c, 0x0a, 0x0c, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x31, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x06, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x69, 0x66, 0x66, 0x5f, 0x70, 0x6e,
+	0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x64, 0x69, 0x66, 0x66, 0x50, 0x6e, 0x67,
+	0x32, 0x96, 0x01, 0x0a, 0x0a, 0x50, 0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x12,
+	0x3f, 0x0a, 0x04, 0x44, 0x69, 0x66, 0x66, 0x12, 0x1a, 0x2e, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x6d,
+	0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x47, 0x0a, 0x0a, 0x44, 0x69, 0x66, 0x66, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x18,
+	0x2e, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x69, 0x66, 0x66, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x1a, 0x1b, 0x2e, 0x70, 0x69, 0x78, 0x65, 0x6c,
+	0x6d, 0x61, 0x74, 0x63, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x66, 0x66, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x3f, 0x5a, 0x3d, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x69, 0x6e, 0x6f, 0x74, 0x6e, 0x61, 0x6b, 0x6f,
+	0x2f, 0x70, 0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x2d, 0x67, 0x6f, 0x2f, 0x70,
+	0x69, 0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x69,
+	0x78, 0x65, 0x6c, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_pixelmatchpb_pixelmatch_proto_rawDescOnce sync.Once
+	file_pixelmatchpb_pixelmatch_proto_rawDescData = file_pixelmatchpb_pixelmatch_proto_rawDesc
+)
+
+func file_pixelmatchpb_pixelmatch_proto_rawDescGZIP() []byte {
+	file_pixelmatchpb_pixelmatch_proto_rawDescOnce.Do(func() {
+		file_pixelmatchpb_pixelmatch_proto_rawDescData = protoimpl.X.CompressGZIP(file_pixelmatchpb_pixelmatch_proto_rawDescData)
+	})
+	return file_pixelmatchpb_pixelmatch_proto_rawDescData
+}
+
+var file_pixelmatchpb_pixelmatch_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_pixelmatchpb_pixelmatch_proto_goTypes = []any{
+	(*Options)(nil),      // 0: pixelmatch.v1.Options
+	(*DiffRequest)(nil),  // 1: pixelmatch.v1.DiffRequest
+	(*DiffChunk)(nil),    // 2: pixelmatch.v1.DiffChunk
+	(*Rect)(nil),         // 3: pixelmatch.v1.Rect
+	(*DiffResult)(nil),   // 4: pixelmatch.v1.DiffResult
+	(*DiffResponse)(nil), // 5: pixelmatch.v1.DiffResponse
+}
+var file_pixelmatchpb_pixelmatch_proto_depIdxs = []int32{
+	0, // 0: pixelmatch.v1.DiffRequest.options:type_name -> pixelmatch.v1.Options
+	0, // 1: pixelmatch.v1.DiffChunk.options:type_name -> pixelmatch.v1.Options
+	3, // 2: pixelmatch.v1.DiffResult.bounds:type_name -> pixelmatch.v1.Rect
+	4, // 3: pixelmatch.v1.DiffResponse.result:type_name -> pixelmatch.v1.DiffResult
+	1, // 4: pixelmatch.v1.Pixelmatch.Diff:input_type -> pixelmatch.v1.DiffRequest
+	2, // 5: pixelmatch.v1.Pixelmatch.DiffStream:input_type -> pixelmatch.v1.DiffChunk
+	5, // 6: pixelmatch.v1.Pixelmatch.Diff:output_type -> pixelmatch.v1.DiffResponse
+	5, // 7: pixelmatch.v1.Pixelmatch.DiffStream:output_type -> pixelmatch.v1.DiffResponse
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_pixelmatchpb_pixelmatch_proto_init() }
+func file_pixelmatchpb_pixelmatch_proto_init() {
+	if File_pixelmatchpb_pixelmatch_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pixelmatchpb_pixelmatch_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Options); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pixelmatchpb_pixelmatch_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*DiffRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pixelmatchpb_pixelmatch_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*DiffChunk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pixelmatchpb_pixelmatch_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*Rect); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pixelmatchpb_pixelmatch_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*DiffResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pixelmatchpb_pixelmatch_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*DiffResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_pixelmatchpb_pixelmatch_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pixelmatchpb_pixelmatch_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pixelmatchpb_pixelmatch_proto_goTypes,
+		DependencyIndexes: file_pixelmatchpb_pixelmatch_proto_depIdxs,
+		MessageInfos:      file_pixelmatchpb_pixelmatch_proto_msgTypes,
+	}.Build()
+	File_pixelmatchpb_pixelmatch_proto = out.File
+	file_pixelmatchpb_pixelmatch_proto_rawDesc = nil
+	file_pixelmatchpb_pixelmatch_proto_goTypes = nil
+	file_pixelmatchpb_pixelmatch_proto_depIdxs = nil
+}
@@ -0,0 +1,168 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: pixelmatchpb/pixelmatch.proto
+
+package pixelmatchpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Pixelmatch_Diff_FullMethodName       = "/pixelmatch.v1.Pixelmatch/Diff"
+	Pixelmatch_DiffStream_FullMethodName = "/pixelmatch.v1.Pixelmatch/DiffStream"
+)
+
+// PixelmatchClient is the client API for Pixelmatch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Pixelmatch compares images pixel by pixel.
+type PixelmatchClient interface {
+	// Diff compares two encoded images (PNG, JPEG or GIF).
+	Diff(ctx context.Context, in *DiffRequest, opts ...grpc.CallOption) (*DiffResponse, error)
+	// DiffStream compares images too large for a single message.
+	// The client sends the images in chunks that are appended to each other,
+	// the options are taken from the first chunk. The server replies with
+	// the result first and then with the diff PNG in chunks.
+	DiffStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[DiffChunk, DiffResponse], error)
+}
+
+type pixelmatchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPixelmatchClient(cc grpc.ClientConnInterface) PixelmatchClient {
+	return &pixelmatchClient{cc}
+}
+
+func (c *pixelmatchClient) Diff(ctx context.Context, in *DiffRequest, opts ...grpc.CallOption) (*DiffResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffResponse)
+	err := c.cc.Invoke(ctx, Pixelmatch_Diff_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pixelmatchClient) DiffStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[DiffChunk, DiffResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Pixelmatch_ServiceDesc.Streams[0], Pixelmatch_DiffStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DiffChunk, DiffResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Pixelmatch_DiffStreamClient = grpc.BidiStreamingClient[DiffChunk, DiffResponse]
+
+// PixelmatchServer is the server API for Pixelmatch service.
+// All implementations must embed UnimplementedPixelmatchServer
+// for forward compatibility.
+//
+// Pixelmatch compares images pixel by pixel.
+type PixelmatchServer interface {
+	// Diff compares two encoded images (PNG, JPEG or GIF).
+	Diff(context.Context, *DiffRequest) (*DiffResponse, error)
+	// DiffStream compares images too large for a single message.
+	// The client sends the images in chunks that are appended to each other,
+	// the options are taken from the first chunk. The server replies with
+	// the result first and then with the diff PNG in chunks.
+	DiffStream(grpc.BidiStreamingServer[DiffChunk, DiffResponse]) error
+	mustEmbedUnimplementedPixelmatchServer()
+}
+
+// UnimplementedPixelmatchServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPixelmatchServer struct{}
+
+func (UnimplementedPixelmatchServer) Diff(context.Context, *DiffRequest) (*DiffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Diff not implemented")
+}
+func (UnimplementedPixelmatchServer) DiffStream(grpc.BidiStreamingServer[DiffChunk, DiffResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method DiffStream not implemented")
+}
+func (UnimplementedPixelmatchServer) mustEmbedUnimplementedPixelmatchServer() {}
+func (UnimplementedPixelmatchServer) testEmbeddedByValue()                    {}
+
+// UnsafePixelmatchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PixelmatchServer will
+// result in compilation errors.
+type UnsafePixelmatchServer interface {
+	mustEmbedUnimplementedPixelmatchServer()
+}
+
+func RegisterPixelmatchServer(s grpc.ServiceRegistrar, srv PixelmatchServer) {
+	// If the following call pancis, it indicates UnimplementedPixelmatchServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Pixelmatch_ServiceDesc, srv)
+}
+
+func _Pixelmatch_Diff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PixelmatchServer).Diff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Pixelmatch_Diff_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PixelmatchServer).Diff(ctx, req.(*DiffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Pixelmatch_DiffStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PixelmatchServer).DiffStream(&grpc.GenericServerStream[DiffChunk, DiffResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Pixelmatch_DiffStreamServer = grpc.BidiStreamingServer[DiffChunk, DiffResponse]
+
+// Pixelmatch_ServiceDesc is the grpc.ServiceDesc for Pixelmatch service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Pixelmatch_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pixelmatch.v1.Pixelmatch",
+	HandlerType: (*PixelmatchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Diff",
+			Handler:    _Pixelmatch_Diff_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DiffStream",
+			Handler:       _Pixelmatch_DiffStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pixelmatchpb/pixelmatch.proto",
+}
@@ -0,0 +1,210 @@
+// Package pixelmatchgrpc serves image comparisons over gRPC, see
+// pixelmatchpb/pixelmatch.proto for the service definition:
+//
+//	s := grpc.NewServer()
+//	pixelmatchpb.RegisterPixelmatchServer(s, pixelmatchgrpc.NewServer())
+//
+// It is a separate module, so the main package does not depend on gRPC.
+package pixelmatchgrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative pixelmatchpb/pixelmatch.proto
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/inotnako/pixelmatch-go"
+	"github.com/inotnako/pixelmatch-go/pixelmatchgrpc/pixelmatchpb"
+)
+
+const (
+	// DefaultMaxBytes is the default limit of both images of DiffStream together.
+	DefaultMaxBytes = 256 << 20
+
+	// chunkSize is the size of the diff PNG chunks sent by DiffStream.
+	chunkSize = 1 << 20
+)
+
+// Server implements pixelmatchpb.PixelmatchServer.
+type Server struct {
+	pixelmatchpb.UnimplementedPixelmatchServer
+
+	// limit of both images of DiffStream together; DefaultMaxBytes when zero
+	MaxBytes int64
+
+	opts []pixelmatch.Option
+}
+
+// NewServer returns a server comparing images with the given options,
+// the options of a request are applied on top of them.
+func NewServer(opts ...pixelmatch.Option) *Server {
+	return &Server{opts: opts}
+}
+
+// Diff compares the two images of the request.
+func (s *Server) Diff(ctx context.Context, req *pixelmatchpb.DiffRequest) (*pixelmatchpb.DiffResponse, error) {
+	return s.diff(ctx, req.GetImageA(), req.GetImageB(), req.GetOptions())
+}
+
+// DiffStream compares two images received in chunks and sends back
+// the result followed by the diff PNG in chunks.
+func (s *Server) DiffStream(stream pixelmatchpb.Pixelmatch_DiffStreamServer) error {
+	maxBytes := s.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+
+	var (
+		a, b    []byte
+		options *pixelmatchpb.Options
+		first   = true
+	)
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if first {
+			options = chunk.GetOptions()
+			first = false
+		}
+
+		a = append(a, chunk.GetImageA()...)
+		b = append(b, chunk.GetImageB()...)
+		if int64(len(a)+len(b)) > maxBytes {
+			return status.Errorf(codes.ResourceExhausted, "images exceed %d bytes", maxBytes)
+		}
+	}
+
+	resp, err := s.diff(stream.Context(), a, b, options)
+	if err != nil {
+		return err
+	}
+
+	diffPNG := resp.DiffPng
+	resp.DiffPng = nil
+	if err := stream.Send(resp); err != nil {
+		return err
+	}
+
+	for len(diffPNG) > 0 {
+		n := chunkSize
+		if n > len(diffPNG) {
+			n = len(diffPNG)
+		}
+
+		if err := stream.Send(&pixelmatchpb.DiffResponse{DiffPng: diffPNG[:n]}); err != nil {
+			return err
+		}
+		diffPNG = diffPNG[n:]
+	}
+
+	return nil
+}
+
+func (s *Server) diff(ctx context.Context, a, b []byte, options *pixelmatchpb.Options) (*pixelmatchpb.DiffResponse, error) {
+	imgA, err := decodeImage(a, "image_a")
+	if err != nil {
+		return nil, err
+	}
+
+	imgB, err := decodeImage(b, "image_b")
+	if err != nil {
+		return nil, err
+	}
+
+	var output *image.NRGBA
+	if !options.GetSkipDiffImage() {
+		output = image.NewNRGBA(imgA.Bounds())
+	}
+
+	opts := append(s.opts[:len(s.opts):len(s.opts)], requestOptions(options)...)
+	result, err := pixelmatch.DiffContext(ctx, imgA, imgB, output, opts...)
+	if err != nil {
+		return nil, diffError(err)
+	}
+
+	resp := &pixelmatchpb.DiffResponse{
+		Result: &pixelmatchpb.DiffResult{
+			DiffPixels:  result.DiffPixels,
+			AaPixels:    result.AAPixels,
+			TotalPixels: result.TotalPixels,
+			Percent:     result.Percent,
+			Bounds: &pixelmatchpb.Rect{
+				MinX: int32(result.Bounds.Min.X),
+				MinY: int32(result.Bounds.Min.Y),
+				MaxX: int32(result.Bounds.Max.X),
+				MaxY: int32(result.Bounds.Max.Y),
+			},
+		},
+	}
+
+	if output != nil {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, output); err != nil {
+			return nil, status.Errorf(codes.Internal, "encode diff image: %v", err)
+		}
+		resp.DiffPng = buf.Bytes()
+	}
+
+	return resp, nil
+}
+
+// requestOptions converts the options of a request, leaving unset ones out.
+func requestOptions(options *pixelmatchpb.Options) []pixelmatch.Option {
+	if options == nil {
+		return nil
+	}
+
+	var opts []pixelmatch.Option
+	if options.Threshold != nil {
+		opts = append(opts, pixelmatch.WithThreshold(options.GetThreshold()))
+	}
+	if options.IncludeAa != nil {
+		opts = append(opts, pixelmatch.WithIncludeAA(options.GetIncludeAa()))
+	}
+	if options.Alpha != nil {
+		opts = append(opts, pixelmatch.WithAlpha(options.GetAlpha()))
+	}
+	if options.DiffMask != nil {
+		opts = append(opts, pixelmatch.WithDiffMask(options.GetDiffMask()))
+	}
+
+	return opts
+}
+
+func decodeImage(data []byte, field string) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "decode %s: %v", field, err)
+	}
+
+	return img, nil
+}
+
+// diffError converts an error of the comparison to a gRPC status.
+func diffError(err error) error {
+	switch {
+	case errors.Is(err, pixelmatch.ErrImageSize), errors.Is(err, pixelmatch.ErrEmptyImage):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, fmt.Sprintf("compare: %v", err))
+	}
+}
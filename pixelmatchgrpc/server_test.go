@@ -0,0 +1,206 @@
+package pixelmatchgrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inotnako/pixelmatch-go"
+	"github.com/inotnako/pixelmatch-go/pixelmatchgrpc/pixelmatchpb"
+)
+
+func newClient(t *testing.T, s *Server) pixelmatchpb.PixelmatchClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pixelmatchpb.RegisterPixelmatchServer(srv, s)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return pixelmatchpb.NewPixelmatchClient(conn)
+}
+
+func encodeTestImage(t *testing.T, w, h int, changed bool) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	if changed {
+		img.SetNRGBA(2, 3, color.NRGBA{R: 255, A: 255})
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestServerDiff(t *testing.T) {
+	client := newClient(t, NewServer())
+	a, b := encodeTestImage(t, 16, 16, false), encodeTestImage(t, 16, 16, true)
+
+	resp, err := client.Diff(context.Background(), &pixelmatchpb.DiffRequest{ImageA: a, ImageB: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetResult().GetDiffPixels(); got != 1 {
+		t.Errorf("Expected 1 different pixel, got - %d", got)
+	}
+	if _, err := png.Decode(bytes.NewReader(resp.GetDiffPng())); err != nil {
+		t.Errorf("Expected a PNG diff image, got - %v", err)
+	}
+
+	resp, err = client.Diff(context.Background(), &pixelmatchpb.DiffRequest{
+		ImageA:  a,
+		ImageB:  b,
+		Options: &pixelmatchpb.Options{Threshold: proto.Float64(1), SkipDiffImage: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetResult().GetDiffPixels() != 0 || resp.GetDiffPng() != nil {
+		t.Errorf("Expected no differences and no diff image, got - %v", resp)
+	}
+
+	_, err = client.Diff(context.Background(), &pixelmatchpb.DiffRequest{ImageA: a, ImageB: encodeTestImage(t, 8, 8, false)})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for images of different size, got - %v", err)
+	}
+}
+
+// encodeEdgeImage encodes a vertical white to black edge at column 5,
+// optionally smoothed by a gray anti-aliasing column.
+func encodeEdgeImage(t *testing.T, smooth bool) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			c := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			switch {
+			case x == 5 && smooth:
+				c = color.NRGBA{R: 128, G: 128, B: 128, A: 255}
+			case x > 5:
+				c = color.NRGBA{A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestServerDiffIncludeAA(t *testing.T) {
+	client := newClient(t, NewServer(pixelmatch.WithIncludeAA(true)))
+	a, b := encodeEdgeImage(t, false), encodeEdgeImage(t, true)
+
+	for _, tc := range []struct {
+		name             string
+		options          *pixelmatchpb.Options
+		wantDiff, wantAA uint64
+	}{
+		{"server default", &pixelmatchpb.Options{Threshold: proto.Float64(0.1)}, 10, 0},
+		{"request override", &pixelmatchpb.Options{IncludeAa: proto.Bool(false)}, 0, 10},
+	} {
+		resp, err := client.Diff(context.Background(), &pixelmatchpb.DiffRequest{ImageA: a, ImageB: b, Options: tc.options})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.GetResult(); got.GetDiffPixels() != tc.wantDiff || got.GetAaPixels() != tc.wantAA {
+			t.Errorf("%s: expected %d different and %d anti-aliased pixels, got - %d and %d",
+				tc.name, tc.wantDiff, tc.wantAA, got.GetDiffPixels(), got.GetAaPixels())
+		}
+	}
+}
+
+func TestServerDiffStream(t *testing.T) {
+	client := newClient(t, NewServer())
+	a, b := encodeTestImage(t, 64, 64, false), encodeTestImage(t, 64, 64, true)
+
+	stream, err := client.DiffStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(a) || i < len(b); i += 100 {
+		chunk := &pixelmatchpb.DiffChunk{ImageA: part(a, i, 100), ImageB: part(b, i, 100)}
+		if i == 0 {
+			chunk.Options = &pixelmatchpb.Options{DiffMask: proto.Bool(false)}
+		}
+		if err := stream.Send(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		result  *pixelmatchpb.DiffResult
+		diffPNG []byte
+	)
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Result != nil {
+			result = resp.Result
+		}
+		diffPNG = append(diffPNG, resp.GetDiffPng()...)
+	}
+
+	if result.GetDiffPixels() != 1 || result.GetTotalPixels() != 64*64 {
+		t.Errorf("Expected 1 of %d pixels to differ, got - %v", 64*64, result)
+	}
+	if _, err := png.Decode(bytes.NewReader(diffPNG)); err != nil {
+		t.Errorf("Expected a PNG diff image, got - %v", err)
+	}
+}
+
+func part(data []byte, from, n int) []byte {
+	if from >= len(data) {
+		return nil
+	}
+	if from+n > len(data) {
+		n = len(data) - from
+	}
+
+	return data[from : from+n]
+}
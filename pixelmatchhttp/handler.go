@@ -0,0 +1,152 @@
+// Package pixelmatchhttp serves image comparisons over HTTP, so the
+// comparator can be deployed as a service:
+//
+//	http.Handle("/diff", pixelmatchhttp.NewHandler(pixelmatch.WithThreshold(0.05)))
+//
+// Clients POST a multipart form with the images in the "a" and "b" file
+// fields and get back the JSON result with the base64 diff PNG, or the diff
+// PNG itself with the metrics in headers when they accept image/png.
+package pixelmatchhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+// DefaultMaxBytes is the default limit of the request body size.
+const DefaultMaxBytes = 32 << 20
+
+// Handler compares the two images posted to it.
+type Handler struct {
+	// limit of the request body size; DefaultMaxBytes when zero
+	MaxBytes int64
+
+	opts []pixelmatch.Option
+}
+
+// NewHandler returns a handler comparing images with the given options.
+// A request may override the threshold with the "threshold" form field.
+// Small compressed uploads may decode to huge images, pixelmatch.WithMaxMemory
+// rejects them with 413 Request Entity Too Large.
+func NewHandler(opts ...pixelmatch.Option) *Handler {
+	return &Handler{opts: opts}
+}
+
+// Response is the JSON body of a successful comparison.
+type Response struct {
+	Result pixelmatch.DiffResult `json:"result"`
+
+	// PNG encoded diff image, base64 in JSON
+	Diff []byte `json:"diff"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("only POST is supported"))
+		return
+	}
+
+	maxBytes := h.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+
+	if err := r.ParseMultipartForm(maxBytes); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("parse form: %w", err))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	imgA, err := formImage(r, "a", h.opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	imgB, err := formImage(r, "b", h.opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	opts := h.opts
+	if v := r.FormValue("threshold"); v != "" {
+		threshold, err := strconv.ParseFloat(v, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid threshold %q", v))
+			return
+		}
+		opts = append(opts[:len(opts):len(opts)], pixelmatch.WithThreshold(threshold))
+	}
+
+	output, result, err := pixelmatch.DiffNew(imgA, imgB, opts...)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, pixelmatch.ErrImageSize) || errors.Is(err, pixelmatch.ErrEmptyImage):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, pixelmatch.ErrMemoryLimit):
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, output); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "image/png") {
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("X-Pixelmatch-Diff-Pixels", strconv.FormatUint(result.DiffPixels, 10))
+		w.Header().Set("X-Pixelmatch-Total-Pixels", strconv.FormatUint(result.TotalPixels, 10))
+		w.Header().Set("X-Pixelmatch-Percent", strconv.FormatFloat(result.Percent, 'f', -1, 64))
+		_, _ = w.Write(buf.Bytes())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Result: result, Diff: buf.Bytes()})
+}
+
+func formImage(r *http.Request, field string, opts []pixelmatch.Option) (image.Image, error) {
+	f, _, err := r.FormFile(field)
+	if err != nil {
+		return nil, fmt.Errorf("image %q: %w", field, err)
+	}
+	defer f.Close()
+
+	img, _, err := pixelmatch.Decode(f, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("decode image %q: %w", field, err)
+	}
+
+	return img, nil
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
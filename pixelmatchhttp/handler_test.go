@@ -0,0 +1,129 @@
+package pixelmatchhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+func encodeTestImage(t *testing.T, w, h int, changed bool) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+	if changed {
+		img.SetNRGBA(1, 1, color.NRGBA{A: 255})
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func newRequest(t *testing.T, a, b []byte, fields map[string]string) *http.Request {
+	t.Helper()
+
+	var (
+		body bytes.Buffer
+		mw   = multipart.NewWriter(&body)
+	)
+	for name, data := range map[string][]byte{"a": a, "b": b} {
+		if data == nil {
+			continue
+		}
+		part, err := mw.CreateFormFile(name, name+".png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	for name, value := range fields {
+		mw.WriteField(name, value)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/diff", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return req
+}
+
+func TestHandler(t *testing.T) {
+	var (
+		h       = NewHandler()
+		same    = encodeTestImage(t, 4, 4, false)
+		changed = encodeTestImage(t, 4, 4, true)
+	)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest(t, same, changed, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got - %d %s", rec.Code, rec.Body)
+	}
+
+	var resp struct {
+		Result struct {
+			DiffPixels uint64 `json:"diffPixels"`
+		} `json:"result"`
+		Diff []byte `json:"diff"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Result.DiffPixels != 1 {
+		t.Errorf("Expected 1 different pixel, got - %d", resp.Result.DiffPixels)
+	}
+	if _, err := png.Decode(bytes.NewReader(resp.Diff)); err != nil {
+		t.Errorf("Expected a PNG diff image, got - %v", err)
+	}
+
+	req := newRequest(t, same, changed, map[string]string{"threshold": "0.5"})
+	req.Header.Set("Accept", "image/png")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("Expected a PNG response, got - %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if got := rec.Header().Get("X-Pixelmatch-Diff-Pixels"); got != "1" {
+		t.Errorf("Expected 1 different pixel in the header, got - %q", got)
+	}
+
+	for _, tc := range []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"get", httptest.NewRequest(http.MethodGet, "/diff", nil), http.StatusMethodNotAllowed},
+		{"missing image", newRequest(t, same, nil, nil), http.StatusBadRequest},
+		{"not an image", newRequest(t, same, []byte("text"), nil), http.StatusBadRequest},
+		{"bad threshold", newRequest(t, same, changed, map[string]string{"threshold": "2"}), http.StatusBadRequest},
+		{"size mismatch", newRequest(t, same, encodeTestImage(t, 5, 4, false), nil), http.StatusUnprocessableEntity},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, tc.req)
+		if rec.Code != tc.want {
+			t.Errorf("%s: expected %d, got - %d %s", tc.name, tc.want, rec.Code, rec.Body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	NewHandler(pixelmatch.WithMaxMemory(64)).ServeHTTP(rec, newRequest(t, same, changed, nil))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 over the memory limit, got - %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,107 @@
+// Package pixelmatchprom exports metrics of image comparisons to Prometheus:
+//
+//	c := pixelmatchprom.NewCollector("myapp")
+//	prometheus.MustRegister(c)
+//	result, err := pixelmatch.Diff(a, b, out, pixelmatch.WithObserver(c))
+//
+// It is a separate module, so the main package does not depend on the
+// Prometheus client.
+package pixelmatchprom
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+// Collector is a pixelmatch.Observer that collects the observations
+// into Prometheus metrics, all prefixed with namespace_pixelmatch_:
+//
+//	comparisons_total      counter of comparisons
+//	errors_total           counter of failed comparisons
+//	duration_seconds       histogram of the duration of comparisons
+//	pixels_total           counter of compared pixels
+//	diff_pixels_total      counter of different pixels
+//	aa_pixels_total        counter of anti-aliased pixels
+//	parallelism            histogram of the goroutines of comparisons
+type Collector struct {
+	comparisons prometheus.Counter
+	errors      prometheus.Counter
+	duration    prometheus.Histogram
+	pixels      prometheus.Counter
+	diffPixels  prometheus.Counter
+	aaPixels    prometheus.Counter
+	parallelism prometheus.Histogram
+}
+
+var (
+	_ pixelmatch.Observer  = (*Collector)(nil)
+	_ prometheus.Collector = (*Collector)(nil)
+)
+
+// NewCollector returns a Collector of metrics in the given namespace,
+// which may be empty.
+func NewCollector(namespace string) *Collector {
+	counter := func(name, help string) prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pixelmatch",
+			Name:      name,
+			Help:      help,
+		})
+	}
+
+	return &Collector{
+		comparisons: counter("comparisons_total", "Number of image comparisons."),
+		errors:      counter("errors_total", "Number of image comparisons that failed."),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "pixelmatch",
+			Name:      "duration_seconds",
+			Help:      "Duration of image comparisons.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+		}),
+		pixels:     counter("pixels_total", "Number of compared pixels."),
+		diffPixels: counter("diff_pixels_total", "Number of different pixels."),
+		aaPixels:   counter("aa_pixels_total", "Number of anti-aliased pixels."),
+		parallelism: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "pixelmatch",
+			Name:      "parallelism",
+			Help:      "Number of goroutines of image comparisons.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 7),
+		}),
+	}
+}
+
+// Observe adds the observation to the metrics.
+func (c *Collector) Observe(o pixelmatch.Observation) {
+	c.comparisons.Inc()
+	if o.Err != nil {
+		c.errors.Inc()
+	}
+	c.duration.Observe(o.Duration.Seconds())
+	c.pixels.Add(float64(o.TotalPixels))
+	c.diffPixels.Add(float64(o.DiffPixels))
+	c.aaPixels.Add(float64(o.AAPixels))
+	c.parallelism.Observe(float64(o.Parallelism))
+}
+
+// metrics returns the metrics of the collector.
+func (c *Collector) metrics() []prometheus.Collector {
+	return []prometheus.Collector{c.comparisons, c.errors, c.duration, c.pixels, c.diffPixels, c.aaPixels, c.parallelism}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics() {
+		m.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c.metrics() {
+		m.Collect(ch)
+	}
+}
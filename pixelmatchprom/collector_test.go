@@ -0,0 +1,56 @@
+package pixelmatchprom
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector("test")
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+
+	a := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	b := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	b.SetNRGBA(2, 3, color.NRGBA{R: 255, A: 255})
+
+	if _, err := pixelmatch.Diff(a, b, nil, pixelmatch.WithObserver(c)); err != nil {
+		t.Fatal(err)
+	}
+	c.Observe(pixelmatch.Observation{Parallelism: 1, Err: errors.New("failed")})
+
+	expected := `
+# HELP test_pixelmatch_comparisons_total Number of image comparisons.
+# TYPE test_pixelmatch_comparisons_total counter
+test_pixelmatch_comparisons_total 2
+# HELP test_pixelmatch_errors_total Number of image comparisons that failed.
+# TYPE test_pixelmatch_errors_total counter
+test_pixelmatch_errors_total 1
+# HELP test_pixelmatch_pixels_total Number of compared pixels.
+# TYPE test_pixelmatch_pixels_total counter
+test_pixelmatch_pixels_total 64
+# HELP test_pixelmatch_diff_pixels_total Number of different pixels.
+# TYPE test_pixelmatch_diff_pixels_total counter
+test_pixelmatch_diff_pixels_total 1
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"test_pixelmatch_comparisons_total", "test_pixelmatch_errors_total",
+		"test_pixelmatch_pixels_total", "test_pixelmatch_diff_pixels_total")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if n := testutil.CollectAndCount(c, "test_pixelmatch_duration_seconds", "test_pixelmatch_parallelism"); n != 2 {
+		t.Errorf("Expected 2 histograms, got - %d", n)
+	}
+}
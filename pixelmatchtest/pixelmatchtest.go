@@ -0,0 +1,95 @@
+// Package pixelmatchtest provides snapshot testing of images
+// against golden files for use in go tests.
+package pixelmatchtest
+
+import (
+	"errors"
+	"flag"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+// Update regenerates golden files instead of comparing with them.
+// It is set by the -pixelmatch.update flag, by an -update flag of the test
+// binary if there is one, or by the PIXELMATCH_UPDATE=1 environment variable.
+var Update = flag.Bool("pixelmatch.update", false, "regenerate golden images")
+
+// AssertEqual compares actual with the golden image at the given path and
+// fails the test when they differ, writing the diff image next to the golden
+// file (image.png gets image.diff.png). In update mode the golden file is
+// overwritten with actual instead, see Update. WithFailureThreshold and
+// WithFailureThresholdPercent let the images differ a bit.
+func AssertEqual(t testing.TB, golden string, actual image.Image, opts ...pixelmatch.Option) {
+	t.Helper()
+
+	diffPath := strings.TrimSuffix(golden, filepath.Ext(golden)) + ".diff.png"
+
+	if updating() {
+		if err := writeImage(golden, actual); err != nil {
+			t.Fatalf("pixelmatchtest: update golden: %v", err)
+			return
+		}
+		_ = os.Remove(diffPath)
+		t.Logf("pixelmatchtest: updated %s", golden)
+		return
+	}
+
+	expected, err := pixelmatch.DecodeFile(golden)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("pixelmatchtest: golden %s does not exist, run the test with -pixelmatch.update to create it", golden)
+		return
+	}
+	if err != nil {
+		t.Fatalf("pixelmatchtest: %v", err)
+		return
+	}
+
+	output, result, err := pixelmatch.DiffNew(expected, actual, opts...)
+	if err != nil {
+		t.Fatalf("pixelmatchtest: compare with %s: %v", golden, err)
+		return
+	}
+
+	if result.Passed() {
+		_ = os.Remove(diffPath)
+		return
+	}
+
+	if err := writeImage(diffPath, output); err != nil {
+		t.Errorf("pixelmatchtest: write diff: %v", err)
+	}
+	t.Errorf("pixelmatchtest: image differs from %s, different pixels: %d (%.2f%%) in %v, diff written to %s",
+		golden, result.DiffPixels, result.Percent, result.Bounds, diffPath)
+}
+
+// updating reports whether golden files are to be regenerated.
+func updating() bool {
+	if *Update || os.Getenv("PIXELMATCH_UPDATE") == "1" {
+		return true
+	}
+
+	// the common -update flag defined by the test itself
+	if f := flag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			update, _ := getter.Get().(bool)
+			return update
+		}
+	}
+
+	return false
+}
+
+// writeImage writes img to path with pixelmatch.EncodeFile, creating the
+// directories on the way.
+func writeImage(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return pixelmatch.EncodeFile(path, img)
+}
@@ -0,0 +1,88 @@
+package pixelmatchtest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recorder captures the failures of AssertEqual instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Logf(format string, args ...interface{}) {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func testImage(changed bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+	if changed {
+		img.SetNRGBA(4, 4, color.NRGBA{B: 255, A: 255})
+	}
+
+	return img
+}
+
+func TestAssertEqual(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		golden = filepath.Join(dir, "snapshots", "button.png")
+		diff   = filepath.Join(dir, "snapshots", "button.diff.png")
+	)
+
+	r := &recorder{TB: t}
+	AssertEqual(r, golden, testImage(false))
+	if !r.fatal || !strings.Contains(r.errors[0], "does not exist") {
+		t.Fatalf("Expected a missing golden to fail, got - %v", r.errors)
+	}
+
+	*Update = true
+	r = &recorder{TB: t}
+	AssertEqual(r, golden, testImage(false))
+	*Update = false
+	if len(r.errors) != 0 {
+		t.Fatalf("Expected the golden to be written, got - %v", r.errors)
+	}
+
+	r = &recorder{TB: t}
+	AssertEqual(r, golden, testImage(false))
+	if len(r.errors) != 0 {
+		t.Errorf("Expected equal images to pass, got - %v", r.errors)
+	}
+
+	r = &recorder{TB: t}
+	AssertEqual(r, golden, testImage(true))
+	if len(r.errors) != 1 || r.fatal || !strings.Contains(r.errors[0], "different pixels: 1 ") {
+		t.Errorf("Expected a single failure, got - %v", r.errors)
+	}
+	if _, err := os.Stat(diff); err != nil {
+		t.Errorf("Expected the diff image to be written, got - %v", err)
+	}
+
+	r = &recorder{TB: t}
+	AssertEqual(r, golden, testImage(false))
+	if _, err := os.Stat(diff); !os.IsNotExist(err) {
+		t.Error("Expected a stale diff image to be removed once the images match")
+	}
+}
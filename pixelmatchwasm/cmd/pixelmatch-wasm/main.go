@@ -0,0 +1,14 @@
+//go:build js && wasm
+
+// Command pixelmatch-wasm defines the global JavaScript function pixelmatch,
+// see the pixelmatchwasm package.
+package main
+
+import "github.com/inotnako/pixelmatch-go/pixelmatchwasm"
+
+func main() {
+	pixelmatchwasm.Register("pixelmatch")
+
+	// keep the function callable
+	select {}
+}
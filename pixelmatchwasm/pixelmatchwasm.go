@@ -0,0 +1,129 @@
+// Package pixelmatchwasm exports the comparison to JavaScript when built for
+// WebAssembly, so browser-based review tools run the same implementation and
+// options as the Go services:
+//
+//	GOOS=js GOARCH=wasm go build -o pixelmatch.wasm ./pixelmatchwasm/cmd/pixelmatch-wasm
+//
+// With wasm_exec.js of the Go distribution loaded the module defines
+//
+//	const {output, count, result, error} = pixelmatch(imageData1, imageData2, {threshold: 0.05})
+//
+// taking two ImageData objects of the same size and an optional options
+// object, and returning the diff as ImageData, the number of different pixels
+// and the whole DiffResult as an object. A failed comparison sets error only.
+package pixelmatchwasm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+// options are the options accepted from JavaScript, named like those of the
+// npm pixelmatch package; unset ones keep the defaults of this package.
+type options struct {
+	Threshold    *float64 `json:"threshold"`
+	IncludeAA    *bool    `json:"includeAA"`
+	Alpha        *float64 `json:"alpha"`
+	AAColor      []uint8  `json:"aaColor"`
+	DiffColor    []uint8  `json:"diffColor"`
+	DiffColorAlt []uint8  `json:"diffColorAlt"`
+	DiffMask     *bool    `json:"diffMask"`
+}
+
+// decodeOptions converts the JSON of an options object, rejecting unknown
+// options so typos do not silently fall back to the defaults.
+func decodeOptions(data []byte) ([]pixelmatch.Option, error) {
+	var o options
+	if len(data) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&o); err != nil {
+			return nil, fmt.Errorf("invalid options: %w", err)
+		}
+	}
+
+	// the JS runtime is single-threaded, goroutines would only add overhead
+	opts := []pixelmatch.Option{pixelmatch.WithSequential()}
+	if o.Threshold != nil {
+		opts = append(opts, pixelmatch.WithThreshold(*o.Threshold))
+	}
+	if o.IncludeAA != nil {
+		opts = append(opts, pixelmatch.WithIncludeAA(*o.IncludeAA))
+	}
+	if o.Alpha != nil {
+		opts = append(opts, pixelmatch.WithAlpha(*o.Alpha))
+	}
+	if o.DiffMask != nil {
+		opts = append(opts, pixelmatch.WithDiffMask(*o.DiffMask))
+	}
+
+	for _, c := range []struct {
+		name   string
+		rgba   []uint8
+		option func(color.Color) pixelmatch.Option
+	}{
+		{"aaColor", o.AAColor, pixelmatch.WithAAColor},
+		{"diffColor", o.DiffColor, pixelmatch.WithDiffColor},
+		{"diffColorAlt", o.DiffColorAlt, pixelmatch.WithDiffColorAlt},
+	} {
+		if c.rgba == nil {
+			continue
+		}
+		nrgba, err := toColor(c.rgba)
+		if err != nil {
+			return nil, fmt.Errorf("invalid options: %s: %w", c.name, err)
+		}
+		opts = append(opts, c.option(nrgba))
+	}
+
+	return opts, nil
+}
+
+// toColor converts an [r, g, b] or [r, g, b, a] array.
+func toColor(rgba []uint8) (color.NRGBA, error) {
+	switch len(rgba) {
+	case 3:
+		return color.NRGBA{R: rgba[0], G: rgba[1], B: rgba[2], A: 255}, nil
+	case 4:
+		return color.NRGBA{R: rgba[0], G: rgba[1], B: rgba[2], A: rgba[3]}, nil
+	default:
+		return color.NRGBA{}, errors.New("color must be [r, g, b] or [r, g, b, a]")
+	}
+}
+
+// newImage wraps the RGBA bytes of an ImageData, which are not
+// premultiplied, as an image without copying them.
+func newImage(width, height int, data []byte) (*image.NRGBA, error) {
+	if width < 0 || height < 0 || len(data) != 4*width*height {
+		return nil, fmt.Errorf("image data of %d bytes does not match %dx%d", len(data), width, height)
+	}
+
+	return &image.NRGBA{Pix: data, Stride: 4 * width, Rect: image.Rect(0, 0, width, height)}, nil
+}
+
+// diff compares the images with the JSON options and returns the diff image
+// and the result encoded to JSON.
+func diff(img1, img2 *image.NRGBA, optionsJSON []byte) (*image.NRGBA, []byte, error) {
+	opts, err := decodeOptions(optionsJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output, result, err := pixelmatch.DiffNew(img1, img2, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return output, resultJSON, nil
+}
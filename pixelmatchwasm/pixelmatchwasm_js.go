@@ -0,0 +1,85 @@
+//go:build js && wasm
+
+package pixelmatchwasm
+
+import (
+	"errors"
+	"image"
+	"syscall/js"
+)
+
+// Register defines the comparison as the global JavaScript function name,
+// see the package documentation. The function stays defined until the
+// program exits, so the caller usually blocks after registering it.
+func Register(name string) {
+	js.Global().Set(name, js.FuncOf(call))
+}
+
+// call is the JavaScript function: (img1, img2, options?) => {output, count, result} or {error}.
+func call(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return errorObject("pixelmatch expects two ImageData objects and optional options")
+	}
+
+	img1, err := imageData(args[0])
+	if err != nil {
+		return errorObject("first image: " + err.Error())
+	}
+	img2, err := imageData(args[1])
+	if err != nil {
+		return errorObject("second image: " + err.Error())
+	}
+
+	var optionsJSON []byte
+	if len(args) > 2 && args[2].Truthy() {
+		optionsJSON = []byte(js.Global().Get("JSON").Call("stringify", args[2]).String())
+	}
+
+	output, resultJSON, err := diff(img1, img2, optionsJSON)
+	if err != nil {
+		return errorObject(err.Error())
+	}
+
+	result := js.Global().Get("JSON").Call("parse", string(resultJSON))
+
+	return map[string]interface{}{
+		"output": newImageData(output.Pix, output.Rect.Dx(), output.Rect.Dy()),
+		"count":  result.Get("diffPixels"),
+		"result": result,
+	}
+}
+
+// imageData copies the pixels of an ImageData or of any object with
+// width, height and data fields.
+func imageData(v js.Value) (*image.NRGBA, error) {
+	if v.Type() != js.TypeObject {
+		return nil, errors.New("not an ImageData")
+	}
+
+	data := v.Get("data")
+	if data.Type() != js.TypeObject {
+		return nil, errors.New("image data is not a Uint8ClampedArray")
+	}
+
+	pix := make([]byte, data.Get("length").Int())
+	js.CopyBytesToGo(pix, data)
+
+	return newImage(v.Get("width").Int(), v.Get("height").Int(), pix)
+}
+
+// newImageData copies pix into an ImageData, or into a plain object with the
+// same fields where ImageData is not defined such as in Node.js.
+func newImageData(pix []byte, width, height int) js.Value {
+	data := js.Global().Get("Uint8ClampedArray").New(len(pix))
+	js.CopyBytesToJS(data, pix)
+
+	if ctor := js.Global().Get("ImageData"); ctor.Type() == js.TypeFunction {
+		return ctor.New(data, width, height)
+	}
+
+	return js.ValueOf(map[string]interface{}{"data": data, "width": width, "height": height})
+}
+
+func errorObject(msg string) map[string]interface{} {
+	return map[string]interface{}{"error": msg}
+}
@@ -0,0 +1,65 @@
+package pixelmatchwasm
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	white := bytes.Repeat([]byte{255}, 4*4*4)
+	changed := append([]byte(nil), white...)
+	copy(changed[4*5:], []byte{0, 0, 0})
+
+	img1, err := newImage(4, 4, white)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img2, err := newImage(4, 4, changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, resultJSON, err := diff(img1, img2, []byte(`{"threshold": 0.05, "includeAA": true, "diffColor": [0, 0, 255]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct {
+		DiffPixels int `json:"diffPixels"`
+	}
+	if err := json.Unmarshal(resultJSON, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 1 {
+		t.Errorf("Expected 1 different pixel, got - %s", resultJSON)
+	}
+	if got := output.Pix[4*5:][:4]; !bytes.Equal(got, []byte{0, 0, 255, 255}) {
+		t.Errorf("Expected the diff color, got - %v", got)
+	}
+}
+
+func TestDecodeOptions(t *testing.T) {
+	if opts, err := decodeOptions(nil); err != nil || len(opts) != 1 {
+		t.Errorf("Expected the defaults, got - %d options, %v", len(opts), err)
+	}
+
+	for _, data := range []string{
+		`{"treshold": 0.1}`,
+		`{"aaColor": [255, 0]}`,
+		`{"diffMask": "yes"}`,
+	} {
+		if _, err := decodeOptions([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %s", data)
+		}
+	}
+}
+
+func TestNewImage(t *testing.T) {
+	if _, err := newImage(2, 2, make([]byte, 15)); err == nil {
+		t.Error("Expected an error for short image data")
+	}
+	if img, err := newImage(2, 3, make([]byte, 24)); err != nil || img.Stride != 8 || img.Rect.Dy() != 3 {
+		t.Errorf("Expected a 2x3 image, got - %v, %v", img, err)
+	}
+}
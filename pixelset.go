@@ -0,0 +1,96 @@
+package pixelmatch
+
+import (
+	"image"
+	"math/bits"
+)
+
+// pixelSet is a set of pixels in a rectangle stored one bit per pixel.
+// Every row starts at a new word, so rows can be set concurrently
+// from different goroutines. A nil *pixelSet is empty and ignores writes.
+type pixelSet struct {
+	rect   image.Rectangle
+	stride int
+	words  []uint64
+}
+
+func newPixelSet(r image.Rectangle) *pixelSet {
+	stride := (r.Dx() + 63) / 64
+
+	return &pixelSet{
+		rect:   r,
+		stride: stride,
+		words:  make([]uint64, stride*r.Dy()),
+	}
+}
+
+func (s *pixelSet) index(x, y int) (int, uint64) {
+	x -= s.rect.Min.X
+	return (y-s.rect.Min.Y)*s.stride + x/64, 1 << (x % 64)
+}
+
+func (s *pixelSet) set(x, y int) {
+	if s == nil {
+		return
+	}
+
+	i, bit := s.index(x, y)
+	s.words[i] |= bit
+}
+
+func (s *pixelSet) has(x, y int) bool {
+	if s == nil || !(image.Point{X: x, Y: y}).In(s.rect) {
+		return false
+	}
+
+	i, bit := s.index(x, y)
+	return s.words[i]&bit != 0
+}
+
+// clone returns a copy of the set.
+func (s *pixelSet) clone() *pixelSet {
+	c := *s
+	c.words = append([]uint64(nil), s.words...)
+
+	return &c
+}
+
+// copyRect replaces the pixels of s inside r with those of src,
+// which covers the same rectangle.
+func (s *pixelSet) copyRect(src *pixelSet, r image.Rectangle) {
+	r = r.Intersect(s.rect)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			i, bit := s.index(x, y)
+			s.words[i] = s.words[i]&^bit | src.words[i]&bit
+		}
+	}
+}
+
+// count returns the number of pixels in the set.
+func (s *pixelSet) count() uint64 {
+	var n int
+	for _, word := range s.words {
+		n += bits.OnesCount64(word)
+	}
+
+	return uint64(n)
+}
+
+// bounds returns the bounding box of the pixels in the set.
+func (s *pixelSet) bounds() image.Rectangle {
+	var r image.Rectangle
+	for y := s.rect.Min.Y; y < s.rect.Max.Y; y++ {
+		row := s.words[(y-s.rect.Min.Y)*s.stride:][:s.stride]
+		for i, word := range row {
+			if word == 0 {
+				continue
+			}
+			minX := s.rect.Min.X + i*64 + bits.TrailingZeros64(word)
+			maxX := s.rect.Min.X + i*64 + 63 - bits.LeadingZeros64(word)
+			r = r.Union(image.Rect(minX, y, maxX+1, y+1))
+		}
+	}
+
+	return r
+}
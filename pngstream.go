@@ -0,0 +1,107 @@
+package pixelmatch
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+)
+
+// pngStreamWriter encodes a non-interlaced 8-bit NRGBA PNG row by row,
+// so the whole image never has to be kept in memory.
+type pngStreamWriter struct {
+	w    *bufio.Writer
+	zw   *zlib.Writer
+	line []byte
+	err  error
+}
+
+func newPNGStreamWriter(w io.Writer, width, height int) (*pngStreamWriter, error) {
+	p := &pngStreamWriter{
+		w:    bufio.NewWriter(w),
+		line: make([]byte, 1+width*4),
+	}
+
+	if _, err := p.w.WriteString("\x89PNG\r\n\x1a\n"); err != nil {
+		return nil, err
+	}
+
+	var ihdr [13]byte
+	binary.BigEndian.PutUint32(ihdr[0:4], uint32(width))
+	binary.BigEndian.PutUint32(ihdr[4:8], uint32(height))
+	ihdr[8] = 8  // bit depth
+	ihdr[9] = 6  // color type: true color with alpha
+	ihdr[10] = 0 // compression method
+	ihdr[11] = 0 // filter method
+	ihdr[12] = 0 // interlace method
+	if err := p.writeChunk("IHDR", ihdr[:]); err != nil {
+		return nil, err
+	}
+
+	p.zw = zlib.NewWriter(idatWriter{p})
+
+	return p, nil
+}
+
+// WriteRow encodes a single row of NRGBA pixels without filtering.
+func (p *pngStreamWriter) WriteRow(pix []byte) error {
+	p.line[0] = 0
+	copy(p.line[1:], pix)
+
+	if _, err := p.zw.Write(p.line); err != nil {
+		return err
+	}
+
+	return p.err
+}
+
+// Close flushes the compressed data and writes the end of the image.
+func (p *pngStreamWriter) Close() error {
+	if err := p.zw.Close(); err != nil {
+		return err
+	}
+	if p.err != nil {
+		return p.err
+	}
+	if err := p.writeChunk("IEND", nil); err != nil {
+		return err
+	}
+
+	return p.w.Flush()
+}
+
+func (p *pngStreamWriter) writeChunk(name string, data []byte) error {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], name)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	var footer [4]byte
+	binary.BigEndian.PutUint32(footer[:], crc.Sum32())
+
+	for _, b := range [][]byte{header[:], data, footer[:]} {
+		if _, err := p.w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// idatWriter wraps every write of the compressor into an IDAT chunk.
+type idatWriter struct {
+	p *pngStreamWriter
+}
+
+func (w idatWriter) Write(b []byte) (int, error) {
+	if err := w.p.writeChunk("IDAT", b); err != nil {
+		w.p.err = err
+		return 0, err
+	}
+
+	return len(b), nil
+}
@@ -0,0 +1,83 @@
+package pixelmatch
+
+import "math"
+
+// IgnoreColors compares the brightness of the pixels only,
+// so changes of hue and saturation are not reported.
+func IgnoreColors() Option {
+	return func(o *Options) {
+		o.ignoreColors = true
+	}
+}
+
+// IgnoreAlpha compares the pixels as if they were fully opaque.
+func IgnoreAlpha() Option {
+	return func(o *Options) {
+		o.ignoreAlpha = true
+	}
+}
+
+// IgnoreLessThan treats pixels whose brightness differs by less than
+// brightnessDelta (0 to 255) as similar, whatever the threshold is.
+func IgnoreLessThan(brightnessDelta float64) Option {
+	return func(o *Options) {
+		o.ignoreLessThan = brightnessDelta
+	}
+}
+
+// WithIgnoreTransparent treats pixels fully transparent in both images as
+// equal whatever their color channels hold, e.g. garbage left in the margins
+// of sprites. The built-in metrics already blend colors by their alpha, so it
+// matters with IgnoreAlpha, WithChannelDiff and custom metrics.
+func WithIgnoreTransparent() Option {
+	return func(o *Options) {
+		o.ignoreTransparent = true
+	}
+}
+
+// hasPresets reports whether any of the color tolerance presets is used,
+// or linear light, which goes the same way.
+func (o *Options) hasPresets() bool {
+	return o.ignoreColors || o.ignoreAlpha || o.ignoreLessThan > 0 || o.linearLight
+}
+
+// presetDelta is the delta of the pixel comparer with the color tolerance presets applied.
+func (o *Options) presetDelta(c1, c2 [4]uint8) float64 {
+	if o.ignoreAlpha {
+		c1[3], c2[3] = 255, 255
+	}
+
+	if o.ignoreColors || o.ignoreLessThan > 0 {
+		y := o.yiqColorDelta(c1, c2, true)
+		if math.Abs(y) < o.ignoreLessThan {
+			return 0
+		}
+
+		if o.ignoreColors {
+			// the brightness part of the YIQ delta, negative if the img2 pixel is darker
+			delta := 0.5053 * y * y
+			if y > 0 {
+				return -delta
+			}
+			return delta
+		}
+	}
+
+	if o.linearLight {
+		switch o.metric.(type) {
+		case yiqMetric, ssimMetric:
+			return linearColorDelta(c1, c2, false)
+		}
+	}
+
+	return o.metric.Delta(c1, c2)
+}
+
+// yiqColorDelta is colorDelta, in linear light with WithLinearLight.
+func (o *Options) yiqColorDelta(c1, c2 [4]uint8, yOnly bool) float64 {
+	if o.linearLight {
+		return linearColorDelta(c1, c2, yOnly)
+	}
+
+	return colorDelta(c1, c2, yOnly)
+}
@@ -0,0 +1,67 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestColorPresets(t *testing.T) {
+	solid := func(c color.NRGBA) *image.NRGBA {
+		img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				img.SetNRGBA(x, y, c)
+			}
+		}
+		return img
+	}
+
+	var (
+		gray      = solid(color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+		grayAlpha = solid(color.NRGBA{R: 128, G: 128, B: 128, A: 100})
+		lighter   = solid(color.NRGBA{R: 140, G: 140, B: 140, A: 255})
+		clearRed  = solid(color.NRGBA{R: 255})
+		clearBlue = solid(color.NRGBA{B: 255})
+	)
+
+	// a color of about the same brightness as gray: Y = 0.299*200 + 0.587*100 + 0.114*50 ~ 124
+	hue := solid(color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+
+	for _, tc := range []struct {
+		name       string
+		img1, img2 *image.NRGBA
+		opts       []Option
+		want       uint64
+	}{
+		{"hue", gray, hue, nil, 16},
+		{"hue ignoring colors", gray, hue, []Option{IgnoreColors()}, 0},
+		{"alpha", gray, grayAlpha, nil, 16},
+		{"alpha ignoring alpha", gray, grayAlpha, []Option{IgnoreAlpha()}, 0},
+		{"brightness", gray, lighter, []Option{WithThreshold(0.01)}, 16},
+		{"brightness below the limit", gray, lighter, []Option{WithThreshold(0.01), IgnoreLessThan(16)}, 0},
+		{"brightness above the limit", gray, lighter, []Option{WithThreshold(0.01), IgnoreLessThan(8)}, 16},
+		{"transparent", clearRed, clearBlue, nil, 0},
+		{"transparent ignoring alpha", clearRed, clearBlue, []Option{IgnoreAlpha()}, 16},
+		{"transparent ignoring transparent", clearRed, clearBlue, []Option{IgnoreAlpha(), WithIgnoreTransparent()}, 0},
+		{"transparent and opaque", clearRed, gray, []Option{IgnoreAlpha(), WithIgnoreTransparent()}, 16},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			count, err := Compare(tc.img1, tc.img2, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tc.want {
+				t.Errorf("Expected %d different pixels, got - %d", tc.want, count)
+			}
+
+			result, err := Diff(tc.img1, tc.img2, nil, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.DiffPixels != tc.want {
+				t.Errorf("Expected Diff to find %d different pixels, got - %d", tc.want, result.DiffPixels)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package pixelmatch
+
+import "sync"
+
+// WithProgress calls fn every time a band of rows is compared with the
+// number of rows done so far and the total number of rows, so frontends
+// can show the progress of large comparisons. Calls never overlap and
+// done grows with every call.
+func WithProgress(fn func(done, total int)) Option {
+	return func(o *Options) {
+		o.progress = fn
+	}
+}
+
+// progress counts compared rows across all bands.
+// A nil *progress reports nothing.
+type progress struct {
+	mu          sync.Mutex
+	fn          func(done, total int)
+	done, total int
+}
+
+func newProgress(options *Options, total int) *progress {
+	if options.progress == nil {
+		return nil
+	}
+
+	return &progress{fn: options.progress, total: total}
+}
+
+// add reports that rows more rows are compared.
+func (p *progress) add(rows int) {
+	if p == nil || rows == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done += rows
+	p.fn(p.done, p.total)
+}
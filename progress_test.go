@@ -0,0 +1,65 @@
+package pixelmatch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestWithProgress(t *testing.T) {
+	img1 := decodeTestImage(t, "./testdata/img1.png")
+	img2 := decodeTestImage(t, "./testdata/img2.png")
+	height := img1.Bounds().Dy()
+
+	var calls, last int
+	check := func(done, total int) {
+		calls++
+		if total != height || done <= last || done > total {
+			t.Errorf("Unexpected progress %d of %d after %d", done, total, last)
+		}
+		last = done
+	}
+
+	if _, err := Diff(img1, img2, nil, WithParallelism(3), WithProgress(check)); err != nil {
+		t.Fatal(err)
+	}
+	if calls < 2 || last != height {
+		t.Errorf("Expected progress per band up to %d rows, got - %d calls up to %d", height, calls, last)
+	}
+
+	calls, last = 0, 0
+	if _, err := Compare(img1, img2, WithProgress(check)); err != nil {
+		t.Fatal(err)
+	}
+	if last != height {
+		t.Errorf("Expected Compare to report %d rows, got - %d", height, last)
+	}
+
+	calls, last = 0, 0
+	a, b := toNRGBA(img1), toNRGBA(img2)
+	s := NewStreamDiffer(a.Bounds().Dx(), height, WithProgress(check))
+	for y := 0; y < height; y += 10 {
+		end := y + 10
+		if end > height {
+			end = height
+		}
+		if err := s.WriteRows(a.Pix[y*a.Stride:end*a.Stride], b.Pix[y*b.Stride:end*b.Stride]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if last != height {
+		t.Errorf("Expected the stream to report %d rows, got - %d", height, last)
+	}
+
+	// rows outside of the region are not compared
+	calls, last = 0, 0
+	height = 10
+	if _, err := Diff(img1, img2, nil, WithRegion(image.Rect(0, 0, 5, 10)), WithProgress(check)); err != nil {
+		t.Fatal(err)
+	}
+	if last != 10 {
+		t.Errorf("Expected progress of the region rows, got - %d", last)
+	}
+}
@@ -0,0 +1,23 @@
+package pixelmatch
+
+import "image"
+
+// WithRegion restricts the comparison and the diff rendering to the part
+// of the images inside r, so a region of interest such as the header of a
+// screenshot can be compared without cropping both images first. Pixels
+// outside r are neither counted nor drawn; the anti-aliasing check still
+// looks at their siblings outside of it.
+func WithRegion(r image.Rectangle) Option {
+	return func(o *Options) {
+		o.region = &r
+	}
+}
+
+// compareRect returns the part of r compared with the options.
+func (o *Options) compareRect(r image.Rectangle) image.Rectangle {
+	if o.region == nil {
+		return r
+	}
+
+	return r.Intersect(*o.region)
+}
@@ -0,0 +1,52 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWithRegion(t *testing.T) {
+	rect := image.Rect(0, 0, 20, 20)
+	img1, img2 := image.NewNRGBA(rect), image.NewNRGBA(rect)
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			img1.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+			img2.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+	img2.SetNRGBA(2, 2, color.NRGBA{A: 255})
+	img2.SetNRGBA(15, 15, color.NRGBA{A: 255})
+
+	header := image.Rect(0, 0, 20, 5)
+	output := image.NewNRGBA(rect)
+	result, err := Diff(img1, img2, output, WithRegion(header), WithDiffMask(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 1 || result.TotalPixels != 100 {
+		t.Errorf("Expected 1 of 100 pixels to differ in the region, got - %d of %d", result.DiffPixels, result.TotalPixels)
+	}
+	if output.NRGBAAt(15, 15) != (color.NRGBA{}) || output.NRGBAAt(10, 10) != (color.NRGBA{}) {
+		t.Error("Expected pixels outside of the region to be left untouched")
+	}
+	if output.NRGBAAt(2, 2) != defaultOptions.diffColor {
+		t.Errorf("Expected the different pixel to be drawn, got - %v", output.NRGBAAt(2, 2))
+	}
+
+	count, err := Compare(img1, img2, WithRegion(header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("Expected Compare to find 1 pixel in the region, got - %d", count)
+	}
+
+	result, err = Diff(img1, img2, nil, WithRegion(image.Rect(100, 100, 120, 120)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 0 || result.TotalPixels != 0 {
+		t.Errorf("Expected nothing compared outside of the images, got - %+v", result)
+	}
+}
@@ -0,0 +1,115 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// RenderMode tells Diff how to compose the output image.
+type RenderMode int
+
+const (
+	// RenderDiff draws the diff alone, output has the bounds of the images.
+	RenderDiff RenderMode = iota
+
+	// RenderSideBySide draws a triptych of the first image, the second image
+	// and the diff next to each other; output is three times as wide
+	// as the images, see RenderMode.Bounds.
+	RenderSideBySide
+
+	// RenderOnionSkin draws the different and anti-aliased pixels over
+	// both images blended half and half; output has the bounds of the images.
+	// The diff is drawn as a mask, so WithDiffMask, WithAlpha and the
+	// background options do not apply.
+	RenderOnionSkin
+)
+
+// onionSkinOpacity is the opacity of the second image drawn over the first one.
+var onionSkinOpacity = color.Alpha{A: 128}
+
+// String returns the name of the mode.
+func (m RenderMode) String() string {
+	switch m {
+	case RenderDiff:
+		return "diff"
+	case RenderSideBySide:
+		return "side-by-side"
+	case RenderOnionSkin:
+		return "onion-skin"
+	default:
+		return "unknown"
+	}
+}
+
+// Bounds returns the bounds of the output of the mode for images of bounds r.
+func (m RenderMode) Bounds(r image.Rectangle) image.Rectangle {
+	if m == RenderSideBySide {
+		r.Max.X += 2 * r.Dx()
+	}
+
+	return r
+}
+
+// WithRenderMode sets how the output image is composed. The images drawn
+// next to or under the diff are the images as compared, e.g. scaled down by
+// WithScale or aligned by WithAutoAlign.
+func WithRenderMode(mode RenderMode) Option {
+	return func(o *Options) {
+		o.renderMode = mode
+	}
+}
+
+// RenderFunc returns the color a different or anti-aliased pixel at x, y
+// is drawn in, given its colors c1 and c2 in the two images.
+type RenderFunc func(x, y int, kind PixelKind, c1, c2 [4]uint8) color.NRGBA
+
+// WithRenderFunc draws the different and anti-aliased pixels in the colors
+// returned by fn instead of the diff and anti-aliasing colors, e.g. with an
+// intensity encoding the size of the change. It is called only for pixels
+// drawn into an output, so not for anti-aliased pixels of a mask. Rows are
+// drawn concurrently, so fn must be safe for concurrent use.
+func WithRenderFunc(fn RenderFunc) Option {
+	return func(o *Options) {
+		o.renderFunc = fn
+	}
+}
+
+// drawPixel draws a different or anti-aliased pixel into the output row
+// at byte offset k unless the row is nil.
+func (c *pixelComparer) drawPixel(out []uint8, k, x, y int, kind PixelKind, p1, p2 uint32, delta float64) {
+	if out == nil {
+		return
+	}
+
+	options := c.options
+	switch {
+	case options.renderFunc != nil:
+		putPixel(out, k, options.renderFunc(x, y, kind, unpackColor(p1), unpackColor(p2)))
+	case kind == PixelAntialiased:
+		putPixel(out, k, options.aaColor)
+	case options.heatmap:
+		putPixel(out, k, options.heatColor(delta))
+	default:
+		putPixel(out, k, options.diffColorFor(delta))
+	}
+}
+
+// render composes the output of the mode from the compared images a and b
+// and the diff drawn for them; a, b and diff have the same bounds.
+func render(mode RenderMode, output, a, b, diff *image.NRGBA) {
+	r := a.Rect
+
+	switch mode {
+	case RenderSideBySide:
+		for i, panel := range []*image.NRGBA{a, b, diff} {
+			dst := r.Add(image.Pt(i*r.Dx(), 0))
+			draw.Draw(output, dst, panel, r.Min, draw.Src)
+		}
+
+	case RenderOnionSkin:
+		draw.Draw(output, r, a, r.Min, draw.Src)
+		draw.DrawMask(output, r, b, r.Min, image.NewUniform(onionSkinOpacity), image.Point{}, draw.Over)
+		draw.Draw(output, r, diff, r.Min, draw.Over)
+	}
+}
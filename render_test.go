@@ -0,0 +1,136 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestWithRenderMode(t *testing.T) {
+	var (
+		red   = color.NRGBA{R: 255, A: 255}
+		white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+		black = color.NRGBA{A: 255}
+	)
+
+	// a white and a black image with an equal red patch
+	a, b := patchedImage(255), patchedImage(255)
+	for y := a.Rect.Min.Y; y < a.Rect.Max.Y; y++ {
+		for x := a.Rect.Min.X; x < a.Rect.Max.X; x++ {
+			if a.NRGBAAt(x, y) == (color.NRGBA{R: 128, G: 128, B: 128, A: 255}) {
+				a.SetNRGBA(x, y, white)
+				b.SetNRGBA(x, y, black)
+			} else {
+				a.SetNRGBA(x, y, red)
+				b.SetNRGBA(x, y, red)
+			}
+		}
+	}
+
+	side, result, err := DiffNew(a, b, WithRenderMode(RenderSideBySide))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := image.Rect(0, 0, 48, 16); side.Rect != want || result.DiffPixels != 16*16-9 {
+		t.Fatalf("Expected a triptych of %v with %d different pixels, got - %v, %d", want, 16*16-9, side.Rect, result.DiffPixels)
+	}
+	for i, want := range []color.NRGBA{white, black, red} {
+		if got := side.NRGBAAt(i*16, 0); got != want {
+			t.Errorf("Expected %v in panel %d, got - %v", want, i, got)
+		}
+	}
+	if got := side.NRGBAAt(2*16+7, 7); got.A != 0 {
+		t.Errorf("Expected the unchanged patch transparent in the diff panel, got - %v", got)
+	}
+
+	onion, _, err := DiffNew(a, b, WithRenderMode(RenderOnionSkin), WithDiffMask(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if onion.Rect != a.Rect {
+		t.Fatalf("Expected the onion skin of %v, got - %v", a.Rect, onion.Rect)
+	}
+	if got := onion.NRGBAAt(0, 0); got != red {
+		t.Errorf("Expected a different pixel drawn in red, got - %v", got)
+	}
+	if got := onion.NRGBAAt(7, 7); got != red {
+		t.Errorf("Expected the unchanged patch as it is, got - %v", got)
+	}
+
+	// blended half and half where nothing differs
+	noDiff, _, err := DiffNew(a, b, WithRenderMode(RenderOnionSkin), WithThreshold(1), WithDiffColor(color.Transparent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := noDiff.NRGBAAt(0, 0); got.R < 126 || got.R > 128 || got.R != got.G || got.A != 255 {
+		t.Errorf("Expected gray, got - %v", got)
+	}
+}
+
+func TestWithRenderModeOutputSize(t *testing.T) {
+	a, b := patchedImage(128), patchedImage(0)
+
+	output := image.NewNRGBA(RenderSideBySide.Bounds(a.Rect))
+	if _, err := Diff(a, b, output, WithRenderMode(RenderSideBySide)); err != nil {
+		t.Fatal(err)
+	}
+	if output.NRGBAAt(16+7, 7) != b.NRGBAAt(7, 7) {
+		t.Errorf("Expected the second image in the middle panel, got - %v", output.NRGBAAt(16+7, 7))
+	}
+
+	if _, err := Diff(a, b, image.NewNRGBA(a.Rect), WithRenderMode(RenderSideBySide)); !errors.Is(err, ErrImageSize) {
+		t.Errorf("Expected ErrImageSize, got - %v", err)
+	}
+	if _, err := Diff(a, b, output); !errors.Is(err, ErrImageSize) {
+		t.Errorf("Expected ErrImageSize, got - %v", err)
+	}
+}
+
+func TestWithRenderFunc(t *testing.T) {
+	img1 := decodeTestImage(t, "./testdata/img1.png")
+	img2 := decodeTestImage(t, "./testdata/img2.png")
+
+	render := func(x, y int, kind PixelKind, c1, c2 [4]uint8) color.NRGBA {
+		return color.NRGBA{R: c1[0], G: c2[0], B: uint8(kind) + 1, A: 255}
+	}
+
+	var diffs, aa []image.Point
+	callback := func(x, y int, delta float64, kind PixelKind) {
+		if kind == PixelDiff {
+			diffs = append(diffs, image.Pt(x, y))
+		} else {
+			aa = append(aa, image.Pt(x, y))
+		}
+	}
+
+	output, result, err := DiffNew(img1, img2, WithRenderFunc(render), WithPixelCallback(callback), WithParallelism(1), WithDiffMask(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) == 0 || len(aa) == 0 || uint64(len(diffs)) != result.DiffPixels {
+		t.Fatalf("Expected different and anti-aliased pixels, got - %d, %d", len(diffs), len(aa))
+	}
+
+	a, b := toNRGBA(img1), toNRGBA(img2)
+	for kind, points := range map[PixelKind][]image.Point{PixelDiff: diffs, PixelAntialiased: aa} {
+		for _, p := range points {
+			want := color.NRGBA{R: a.NRGBAAt(p.X, p.Y).R, G: b.NRGBAAt(p.X, p.Y).R, B: uint8(kind) + 1, A: 255}
+			if got := output.NRGBAAt(p.X, p.Y); got != want {
+				t.Fatalf("Expected %s pixel %v drawn in %v, got - %v", kind, p, want, got)
+			}
+		}
+	}
+
+	// anti-aliased pixels are not drawn into a mask
+	mask, _, err := DiffNew(img1, img2, WithRenderFunc(render))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mask.NRGBAAt(aa[0].X, aa[0].Y); got.A != 0 {
+		t.Errorf("Expected a transparent anti-aliased pixel, got - %v", got)
+	}
+	if got := mask.NRGBAAt(diffs[0].X, diffs[0].Y); got.B != uint8(PixelDiff)+1 {
+		t.Errorf("Expected a rendered different pixel, got - %v", got)
+	}
+}
@@ -0,0 +1,105 @@
+package pixelmatch
+
+import (
+	"image"
+	"time"
+)
+
+// DiffResult describes the outcome of a comparison.
+type DiffResult struct {
+	// number of different pixels
+	DiffPixels uint64 `json:"diffPixels"`
+
+	// number of pixels that differ only because of anti-aliasing;
+	// always zero with WithIncludeAA(true)
+	AAPixels uint64 `json:"aaPixels"`
+
+	// number of compared pixels
+	TotalPixels uint64 `json:"totalPixels"`
+
+	// share of different pixels, from 0 to 100
+	Percent float64 `json:"percent"`
+
+	// bounding box of all different pixels; empty when there are none
+	Bounds image.Rectangle `json:"bounds"`
+
+	// diff statistics per grid cell; nil unless WithGrid is used
+	Grid *DiffGrid `json:"grid,omitempty"`
+
+	// clusters of contiguous different pixels, largest first;
+	// nil unless WithClusters is used
+	Clusters []Cluster `json:"clusters,omitempty"`
+
+	// merged bounding boxes of the different pixels, top to bottom;
+	// nil unless WithBoundingBoxes is used
+	Boxes []image.Rectangle `json:"boxes,omitempty"`
+
+	// number of pixels that differ in every channel;
+	// zero unless WithChannelDiff is used
+	Channels ChannelDiff `json:"channels"`
+
+	// mean structural similarity of the images, from -1 to 1;
+	// computed only with MetricSSIM
+	SSIM float64 `json:"ssim,omitempty"`
+
+	// structural similarity of every window; nil unless MetricSSIM is used
+	SSIMMap *SimilarityMap `json:"ssimMap,omitempty"`
+
+	// mean squared error and PSNR; nil unless WithExtraMetrics is used
+	Metrics *ExtraMetrics `json:"metrics,omitempty"`
+
+	// confidence interval of Percent; nil unless WithSampleRate is used
+	Sample *SampleEstimate `json:"sample,omitempty"`
+
+	// mask of the anti-aliased pixels (see MaskPalette);
+	// nil unless WithAAMask is used
+	AAMask *image.Paletted `json:"-"`
+
+	// translation of the second image against the first one found by
+	// WithAutoAlign: pixel x, y of the first image was compared with pixel
+	// x+Offset.X, y+Offset.Y of the second one
+	Offset image.Point `json:"offset"`
+
+	// how the second image is rotated or mirrored against the first one,
+	// see DetectOrientation; zero unless WithOrientationCheck is used
+	Orientation Orientation `json:"orientation,omitempty"`
+
+	// time spent on the comparison
+	Elapsed time.Duration `json:"elapsed"`
+
+	// sum of the squared channel differences for Metrics
+	squaredError float64
+
+	// different pixels; nil unless DiffMask, WithResultMask or WithIncremental asked the comparison to keep them
+	diff *pixelSet
+
+	// anti-aliased pixels; nil unless WithIncremental asked the comparison to keep them
+	aa *pixelSet
+
+	// settings of the comparison, encoded by MarshalJSON
+	options *Options
+}
+
+// add merges a partial result of a single band into r.
+func (r *DiffResult) add(part DiffResult) {
+	r.DiffPixels += part.DiffPixels
+	r.AAPixels += part.AAPixels
+	r.TotalPixels += part.TotalPixels
+	r.squaredError += part.squaredError
+	r.Bounds = r.Bounds.Union(part.Bounds)
+	r.Channels.add(part.Channels)
+}
+
+// finish fills the derived fields once all bands are merged.
+func (r *DiffResult) finish(start time.Time) {
+	if r.TotalPixels > 0 {
+		r.Percent = float64(r.DiffPixels) * 100 / float64(r.TotalPixels)
+	}
+	r.Elapsed = time.Since(start)
+}
+
+// markDiff grows the bounding box to include the pixel at x, y.
+func (r *DiffResult) markDiff(x, y int) {
+	r.DiffPixels++
+	r.Bounds = r.Bounds.Union(image.Rect(x, y, x+1, y+1))
+}
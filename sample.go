@@ -0,0 +1,92 @@
+package pixelmatch
+
+import (
+	"image"
+	"math"
+)
+
+// WithSampleRate compares only one pixel out of every n, for triaging lots
+// of images where exact counts are overkill. The compared pixels are split
+// into runs of n in row-major order and one pixel at a fixed pseudo-random
+// position of every run is compared, so the same images always give the
+// same result. DiffPixels and TotalPixels count the sampled pixels,
+// Percent estimates the share of different pixels of the whole images and
+// DiffResult.Sample holds its confidence interval. Pixels that are not
+// sampled are left as they are in the diff image. A rate of 1 or less
+// compares every pixel.
+func WithSampleRate(n int) Option {
+	return func(o *Options) {
+		o.sampleRate = n
+	}
+}
+
+// SampleEstimate is the confidence interval of the share of different
+// pixels estimated with WithSampleRate.
+type SampleEstimate struct {
+	// one pixel out of Rate was compared
+	Rate int `json:"rate"`
+
+	// bounds of the 95% confidence interval of Percent, from 0 to 100
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// newSampleEstimate returns the Wilson score interval of diff different
+// pixels out of total sampled ones, which stays within 0 and 100 and does
+// not collapse when no sampled pixel differs.
+func newSampleEstimate(rate int, diff, total uint64) *SampleEstimate {
+	e := &SampleEstimate{Rate: rate, High: 100}
+	if total == 0 {
+		return e
+	}
+
+	const z = 1.959964 // 97.5th percentile of the normal distribution
+
+	n := float64(total)
+	p := float64(diff) / n
+	d := 1 + z*z/n
+	center := (p + z*z/(2*n)) / d
+	half := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / d
+
+	e.Low = math.Max(center-half, 0) * 100
+	e.High = math.Min(center+half, 1) * 100
+
+	return e
+}
+
+// compareLine compares row y like compareRow, only its sampled pixels
+// with WithSampleRate and the tiles found by the coarse pass.
+func (c *pixelComparer) compareLine(a, b, output *image.NRGBA, y, minX, maxX int, part *DiffResult) {
+	if c.coarse != nil {
+		c.compareTiles(a, b, output, y, minX, maxX, part)
+		return
+	}
+
+	n := c.options.sampleRate
+	if n <= 1 {
+		c.compareRow(a, b, output, y, minX, maxX, part)
+		return
+	}
+
+	// positions of the row among the compared pixels in row-major order
+	start := (y-c.region.Min.Y)*c.region.Dx() + minX - c.region.Min.X
+	end := start + maxX - minX
+
+	for run := start / n; run*n < end; run++ {
+		p := run*n + int(sampleOffset(uint64(run))%uint64(n))
+		if p >= start && p < end {
+			x := minX + p - start
+			c.compareRow(a, b, output, y, x, x+1, part)
+		}
+	}
+}
+
+// sampleOffset scrambles the index of a run of pixels (the splitmix64
+// finalizer), so the sampled pixels form no regular pattern.
+func sampleOffset(v uint64) uint64 {
+	v += 0x9e3779b97f4a7c15
+	v = (v ^ v>>30) * 0xbf58476d1ce4e5b9
+	v = (v ^ v>>27) * 0x94d049bb133111eb
+
+	return v ^ v>>31
+}
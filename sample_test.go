@@ -0,0 +1,97 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWithSampleRate(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	img1, img2 := solidImage(300, 200, white), solidImage(300, 200, white)
+
+	// a tenth of the pixels scattered over the image
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < len(img2.Pix)/4/10; i++ {
+		img2.SetNRGBA(rnd.Intn(300), rnd.Intn(200), color.NRGBA{A: 255})
+	}
+
+	exact, err := Diff(img1, img2, nil, WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rate := range []int{7, 64, 1000} {
+		result, err := Diff(img1, img2, nil, WithIncludeAA(true), WithSampleRate(rate))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := uint64(300 * 200 / rate); result.TotalPixels < want || result.TotalPixels > want+1 {
+			t.Errorf("rate %d: expected %d sampled pixels, got - %d", rate, want, result.TotalPixels)
+		}
+		if s := result.Sample; s == nil || s.Rate != rate || exact.Percent < s.Low || exact.Percent > s.High || result.Percent < s.Low || result.Percent > s.High {
+			t.Errorf("rate %d: expected %.2f%% within the interval of %.2f%%, got - %+v", rate, exact.Percent, result.Percent, s)
+		}
+
+		again, _ := Diff(img1, img2, nil, WithIncludeAA(true), WithSampleRate(rate), WithParallelism(3))
+		if again.DiffPixels != result.DiffPixels || again.TotalPixels != result.TotalPixels {
+			t.Errorf("rate %d: expected the same samples in bands, got - %d/%d, %d/%d", rate, again.DiffPixels, again.TotalPixels, result.DiffPixels, result.TotalPixels)
+		}
+	}
+
+	if n, err := Compare(img1, img2, WithIncludeAA(true), WithSampleRate(64)); err != nil || n >= exact.DiffPixels/10 {
+		t.Errorf("Expected Compare to count the sampled pixels, got - %d %v", n, err)
+	}
+
+	stream := NewStreamDiffer(300, 200, WithIncludeAA(true), WithSampleRate(64))
+	if err := stream.WriteRows(img1.Pix, img2.Pix); err != nil {
+		t.Fatal(err)
+	}
+	streamed, err := stream.Close()
+	sampled, _ := Diff(img1, img2, nil, WithIncludeAA(true), WithSampleRate(64))
+	if err != nil || streamed.DiffPixels != sampled.DiffPixels || streamed.TotalPixels != sampled.TotalPixels {
+		t.Errorf("Expected the stream to sample the same pixels, got - %d/%d %v", streamed.DiffPixels, streamed.TotalPixels, err)
+	}
+
+	if result, _ := Diff(img1, img1, nil, WithSampleRate(64)); result.Sample.Low != 0 || result.Sample.High <= 0 || result.Sample.High > 1 {
+		t.Errorf("Expected a narrow interval above zero for identical images, got - %+v", result.Sample)
+	}
+}
+
+func TestNewSampleEstimate(t *testing.T) {
+	e := newSampleEstimate(10, 50, 100)
+	if math.Abs(e.Low-40.38) > 0.01 || math.Abs(e.High-59.62) > 0.01 {
+		t.Errorf("Expected the Wilson interval 40.38-59.62, got - %+v", e)
+	}
+	if e := newSampleEstimate(10, 0, 0); e.Low != 0 || e.High != 100 {
+		t.Errorf("Expected the whole range without samples, got - %+v", e)
+	}
+	if e := newSampleEstimate(10, 20, 20); e.High != 100 || e.Low < 80 {
+		t.Errorf("Expected the interval to end at 100, got - %+v", e)
+	}
+}
+
+func TestCompareLineSamplesEveryRun(t *testing.T) {
+	options := newOptions(WithSampleRate(5))
+	rect := image.Rect(2, 3, 13, 10)
+	img := solidImage(20, 20, color.NRGBA{A: 255})
+	cmp := newPixelComparer(&options, img.Rect)
+	cmp.region = rect
+
+	var sampled []int
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		var part DiffResult
+		cmp.compareLine(img, img, nil, y, rect.Min.X, rect.Max.X, &part)
+		sampled = append(sampled, int(part.TotalPixels))
+	}
+
+	total := 0
+	for _, n := range sampled {
+		total += n
+	}
+	if want := rect.Dx() * rect.Dy() / 5; total != want && total != want+1 {
+		t.Errorf("Expected one pixel out of five, got - %d of %d", total, rect.Dx()*rect.Dy())
+	}
+}
@@ -0,0 +1,206 @@
+package pixelmatch
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+)
+
+// WithScale downscales both images by the factor (0 to 1) with bilinear
+// interpolation before comparing them, trading accuracy for speed when an
+// approximate share of different pixels is enough; factors outside of (0, 1)
+// keep the images as they are. Everything after the scaling works on the
+// smaller images: the output must have their bounds and the pixel counts,
+// bounds and clusters of the result are in their pixels, while regions given
+// by WithRegion, WithIgnoreRegions and WithIgnoreMask are scaled along.
+func WithScale(factor float64) Option {
+	return func(o *Options) {
+		o.scale = factor
+	}
+}
+
+// WithMaxDimension downscales both images like WithScale so that neither
+// side of their union exceeds px pixels; px <= 0 disables it. When both
+// options are set the smaller factor wins.
+func WithMaxDimension(px int) Option {
+	return func(o *Options) {
+		o.maxDimension = px
+	}
+}
+
+// scaleFactor returns the factor images of bounds r are downscaled by,
+// 1 when they are compared as they are.
+func (o *Options) scaleFactor(r image.Rectangle) float64 {
+	s := 1.0
+	if o.scale > 0 && o.scale < 1 {
+		s = o.scale
+	}
+
+	if side := maxInt(r.Dx(), r.Dy()); o.maxDimension > 0 && side > o.maxDimension {
+		s = math.Min(s, float64(o.maxDimension)/float64(side))
+	}
+
+	return s
+}
+
+// scaleImages downscales both images by options.scaleFactor along with the
+// regions of the options and returns them with a function releasing the
+// copies once they are compared. Images needing no scaling are returned as
+// they are.
+func scaleImages(img1, img2 image.Image, options *Options) (a, b image.Image, release func()) {
+	if isEmptyImg(img1) || isEmptyImg(img2) {
+		// left for alignImages to reject
+		return img1, img2, func() {}
+	}
+
+	s := options.scaleFactor(img1.Bounds().Union(img2.Bounds()))
+	if s >= 1 {
+		return img1, img2, func() {}
+	}
+
+	options.scaleRegions(s)
+	options.debug("downscaling images", "factor", s)
+
+	if isHighDepth(img1) || isHighDepth(img2) {
+		a := toNRGBA64(img1, img1.Bounds(), color.NRGBA{})
+		b := toNRGBA64(img2, img2.Bounds(), color.NRGBA{})
+
+		return scaleNRGBA64(a, s, options), scaleNRGBA64(b, s, options), func() {}
+	}
+
+	scaled := [2]*image.NRGBA{}
+	for i, img := range []image.Image{img1, img2} {
+		src := convertNRGBA(img, options.pool)
+		scaled[i] = scaleNRGBA(src, s, options)
+		if !sharesPix(src, img) {
+			options.pool.put(src)
+		}
+	}
+
+	return scaled[0], scaled[1], func() {
+		options.pool.put(scaled[0])
+		options.pool.put(scaled[1])
+	}
+}
+
+// scaleRegions scales the region of interest and the excluded regions
+// of the options by s.
+func (o *Options) scaleRegions(s float64) {
+	if o.region != nil {
+		r := scaleRect(*o.region, s)
+		o.region = &r
+	}
+
+	regions := make([]image.Rectangle, len(o.ignoreRegions))
+	for i, r := range o.ignoreRegions {
+		regions[i] = scaleRect(r, s)
+	}
+	o.ignoreRegions = regions
+
+	if o.ignoreMask != nil {
+		o.ignoreMask = &scaledMask{Image: o.ignoreMask, scale: s, rect: scaleRect(o.ignoreMask.Bounds(), s)}
+	}
+}
+
+// scaleRect returns r scaled by s, rounded outwards so that non-empty
+// rectangles stay non-empty.
+func scaleRect(r image.Rectangle, s float64) image.Rectangle {
+	return image.Rect(
+		int(math.Floor(float64(r.Min.X)*s)),
+		int(math.Floor(float64(r.Min.Y)*s)),
+		int(math.Ceil(float64(r.Max.X)*s)),
+		int(math.Ceil(float64(r.Max.Y)*s)),
+	)
+}
+
+// scaledMask samples the nearest pixel of a mask for the downscaled images.
+type scaledMask struct {
+	image.Image
+	scale float64
+	rect  image.Rectangle
+}
+
+func (m *scaledMask) Bounds() image.Rectangle {
+	return m.rect
+}
+
+func (m *scaledMask) At(x, y int) color.Color {
+	return m.Image.At(int(math.Floor((float64(x)+0.5)/m.scale)), int(math.Floor((float64(y)+0.5)/m.scale)))
+}
+
+func scaleNRGBA(img *image.NRGBA, s float64, options *Options) *image.NRGBA {
+	dst := options.pool.newNRGBA(scaleRect(img.Rect, s))
+	scaleRows(img.Rect, dst.Rect, s, options.workers(), loadNRGBA(img), storeNRGBA(dst))
+
+	return dst
+}
+
+func scaleNRGBA64(img *image.NRGBA64, s float64, options *Options) *image.NRGBA64 {
+	dst := image.NewNRGBA64(scaleRect(img.Rect, s))
+	scaleRows(img.Rect, dst.Rect, s, options.workers(), loadNRGBA64(img), storeNRGBA64(dst))
+
+	return dst
+}
+
+// scaleRows fills the rows of dst with the bilinear interpolation of the
+// rows of src scaled by s, matching pixel centers. load and store work
+// like in blurRows.
+func scaleRows(src, dst image.Rectangle, s float64, workers int, load, store func(y int, row []float32)) {
+	// source columns and weights are the same for every row
+	var (
+		w      = dst.Dx()
+		x0, x1 = make([]int, w), make([]int, w)
+		wx     = make([]float32, w)
+	)
+	for i := range wx {
+		c0, c1, t := sourcePixels(dst.Min.X+i, src.Min.X, src.Max.X, s)
+		x0[i], x1[i], wx[i] = (c0-src.Min.X)*4, (c1-src.Min.X)*4, t
+	}
+
+	runBands(context.Background(), dst, workers, func(band image.Rectangle) {
+		top, bottom := make([]float32, src.Dx()*4), make([]float32, src.Dx()*4)
+		row := make([]float32, w*4)
+
+		for y := band.Min.Y; y < band.Max.Y; y++ {
+			y0, y1, wy := sourcePixels(y, src.Min.Y, src.Max.Y, s)
+			load(y0, top)
+			load(y1, bottom)
+
+			for i := 0; i < w; i++ {
+				for c := 0; c < 4; c++ {
+					t := top[x0[i]+c] + (top[x1[i]+c]-top[x0[i]+c])*wx[i]
+					b := bottom[x0[i]+c] + (bottom[x1[i]+c]-bottom[x0[i]+c])*wx[i]
+					row[i*4+c] = t + (b-t)*wy
+				}
+			}
+
+			store(y, row)
+		}
+	})
+}
+
+// sourcePixels returns the two source pixels within [min, max) around the
+// center of the scaled pixel p and the weight of the second one.
+func sourcePixels(p, min, max int, s float64) (p0, p1 int, t float32) {
+	c := (float64(p)+0.5)/s - 0.5
+	f := math.Floor(c)
+
+	return clampInt(int(f), min, max-1), clampInt(int(f)+1, min, max-1), float32(c - f)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
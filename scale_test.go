@@ -0,0 +1,150 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+func TestScaleFactor(t *testing.T) {
+	r := image.Rect(0, 0, 800, 400)
+
+	tests := []struct {
+		name string
+		opts []Option
+		want float64
+	}{
+		{"none", nil, 1},
+		{"scale", []Option{WithScale(0.25)}, 0.25},
+		{"upscale ignored", []Option{WithScale(2)}, 1},
+		{"max dimension", []Option{WithMaxDimension(200)}, 0.25},
+		{"max dimension larger", []Option{WithMaxDimension(1000)}, 1},
+		{"smaller wins", []Option{WithScale(0.5), WithMaxDimension(200)}, 0.25},
+	}
+
+	for _, tc := range tests {
+		options := newOptions(tc.opts...)
+		if got := options.scaleFactor(r); got != tc.want {
+			t.Errorf("%s: expected %f, got - %f", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestWithScale(t *testing.T) {
+	img1 := decodeTestImage(t, "./testdata/img1.png")
+	img2 := decodeTestImage(t, "./testdata/img2.png")
+
+	full, err := Diff(img1, img2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, result, err := DiffNew(img1, img2, WithScale(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := scaleRect(img1.Bounds(), 0.5)
+	if !output.Rect.Eq(want) {
+		t.Errorf("Expected output bounds %v, got - %v", want, output.Rect)
+	}
+	if result.TotalPixels != uint64(want.Dx()*want.Dy()) {
+		t.Errorf("Expected %d total pixels, got - %d", want.Dx()*want.Dy(), result.TotalPixels)
+	}
+	if result.DiffPixels == 0 || math.Abs(result.Percent-full.Percent) > full.Percent/2 {
+		t.Errorf("Expected about %.2f%% different pixels, got - %.2f%%", full.Percent, result.Percent)
+	}
+
+	count, err := Compare(img1, img2, WithScale(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != result.DiffPixels {
+		t.Errorf("Expected Compare to agree with Diff, got - %d", count)
+	}
+
+	if _, err := Diff(img1, img2, image.NewNRGBA(img1.Bounds()), WithScale(0.5)); !errors.Is(err, ErrImageSize) {
+		t.Errorf("Expected ErrImageSize for a full size output, got - %v", err)
+	}
+}
+
+func TestWithMaxDimensionRegions(t *testing.T) {
+	rect := image.Rect(0, 0, 64, 32)
+	img1, img2 := image.NewNRGBA(rect), image.NewNRGBA(rect)
+	gray := &image.Uniform{C: color.NRGBA{R: 200, G: 200, B: 200, A: 255}}
+	draw.Draw(img1, rect, gray, image.Point{}, draw.Src)
+	draw.Draw(img2, rect, gray, image.Point{}, draw.Src)
+	changed := image.Rect(8, 8, 24, 24)
+	draw.Draw(img2, changed, image.Black, image.Point{}, draw.Src)
+
+	output, result, err := DiffNew(img1, img2, WithMaxDimension(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !output.Rect.Eq(image.Rect(0, 0, 16, 8)) {
+		t.Errorf("Expected 16x8 output, got - %v", output.Rect)
+	}
+	if result.DiffPixels == 0 {
+		t.Error("Expected the changed block to be found")
+	}
+
+	for name, opt := range map[string]Option{
+		"ignore region": WithIgnoreRegions(changed),
+		"ignore mask":   WithIgnoreMask(maskImage(rect, changed)),
+		"region":        WithRegion(image.Rect(32, 0, 64, 32)),
+	} {
+		result, err := Diff(img1, img2, nil, WithMaxDimension(16), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.DiffPixels != 0 {
+			t.Errorf("%s: expected the region to be scaled along, got - %d different pixels", name, result.DiffPixels)
+		}
+	}
+}
+
+func TestWithScaleHighDepth(t *testing.T) {
+	rect := image.Rect(0, 0, 8, 8)
+	img1, img2 := image.NewGray16(rect), image.NewGray16(rect)
+	draw.Draw(img1, rect, &image.Uniform{C: color.Gray16{Y: 0x1000}}, image.Point{}, draw.Src)
+	draw.Draw(img2, rect, &image.Uniform{C: color.Gray16{Y: 0x1080}}, image.Point{}, draw.Src)
+
+	result, err := Diff(img1, img2, nil, WithScale(0.5), WithThreshold(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != 16 {
+		t.Errorf("Expected all 16 downscaled pixels to differ in 16 bits, got - %d", result.DiffPixels)
+	}
+}
+
+func TestScaleNRGBA(t *testing.T) {
+	rect := image.Rect(0, 0, 10, 6)
+	img := image.NewNRGBA(rect)
+	c := color.NRGBA{R: 10, G: 120, B: 240, A: 255}
+	draw.Draw(img, rect, &image.Uniform{C: c}, image.Point{}, draw.Src)
+
+	options := newOptions()
+	scaled := scaleNRGBA(img, 0.3, &options)
+
+	if !scaled.Rect.Eq(image.Rect(0, 0, 3, 2)) {
+		t.Fatalf("Expected 3x2 bounds, got - %v", scaled.Rect)
+	}
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			if got := scaled.NRGBAAt(x, y); got != c {
+				t.Errorf("Expected %v at %d,%d, got - %v", c, x, y, got)
+			}
+		}
+	}
+}
+
+func maskImage(r, set image.Rectangle) *image.Gray {
+	mask := image.NewGray(r)
+	draw.Draw(mask, set, image.White, image.Point{}, draw.Src)
+
+	return mask
+}
@@ -0,0 +1,39 @@
+package pixelmatch
+
+import (
+	"image"
+	"math"
+)
+
+// WithShiftTolerance ignores pixels that moved by at most n pixels in any
+// direction, such as text shifted by font hinting or 1px layout jitter.
+// Before a pixel is flagged, the n-pixel neighborhood of the other image
+// is searched for a color within the threshold, in both directions.
+// Such pixels are drawn and counted as similar.
+func WithShiftTolerance(n int) Option {
+	return func(o *Options) {
+		o.shift = n
+	}
+}
+
+// shifted reports whether the pixel at x, y of each image has a similar
+// color within the shift tolerance in the other one.
+func (c *pixelComparer) shifted(a, b *image.NRGBA, x, y int) bool {
+	return c.hasNear(getColor(a, x, y), b, x, y) && c.hasNear(getColor(b, x, y), a, x, y)
+}
+
+// hasNear reports whether img has a color similar to c around x, y.
+func (c *pixelComparer) hasNear(cc [4]uint8, img *image.NRGBA, x, y int) bool {
+	n := c.options.shift
+	r := image.Rect(x-n, y-n, x+n+1, y+n+1).Intersect(img.Rect)
+
+	for ny := r.Min.Y; ny < r.Max.Y; ny++ {
+		for nx := r.Min.X; nx < r.Max.X; nx++ {
+			if math.Abs(c.delta(cc, getColor(img, nx, ny))) <= c.maxDelta {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,67 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// lineImage draws a black horizontal line at row y over white.
+func lineImage(y int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for py := 0; py < 16; py++ {
+		for px := 0; px < 16; px++ {
+			c := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			if py == y || py == y+1 {
+				c = color.NRGBA{A: 255}
+			}
+			img.SetNRGBA(px, py, c)
+		}
+	}
+
+	return img
+}
+
+func TestWithShiftTolerance(t *testing.T) {
+	img1, img2 := lineImage(5), lineImage(8)
+
+	count, err := Compare(img1, img2, WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count == 0 {
+		t.Fatal("Expected the shifted line to differ without tolerance")
+	}
+
+	for _, tc := range []struct {
+		shift int
+		want  uint64
+	}{
+		{1, count},
+		{2, count / 2}, // rows next to the other line
+		{3, 0},
+	} {
+		count, err := Compare(img1, img2, WithIncludeAA(true), WithShiftTolerance(tc.shift))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != tc.want {
+			t.Errorf("Expected %d different pixels with shift %d, got - %d", tc.want, tc.shift, count)
+		}
+
+		// the streaming differ keeps enough rows for the shift
+		s := NewStreamDiffer(16, 16, WithIncludeAA(true), WithShiftTolerance(tc.shift))
+		for y := 0; y < 16; y++ {
+			if err := s.WriteRows(img1.Pix[y*64:(y+1)*64], img2.Pix[y*64:(y+1)*64]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		result, err := s.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.DiffPixels != tc.want {
+			t.Errorf("Expected the stream to find %d pixels with shift %d, got - %d", tc.want, tc.shift, result.DiffPixels)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package pixelmatch
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Sink stores the artifacts of comparisons, diff images and reports, under
+// slash-separated relative names such as "pages/home.png". DirSink writes
+// them to a directory and MemorySink keeps them in memory; other sinks, e.g.
+// of S3 or GCS buckets, can be plugged into DiffBatch with WithSink, into the
+// baseline package and into anything else that writes artifacts.
+// Sinks must be safe for concurrent use.
+type Sink interface {
+	// WriteDiffImage stores the diff image under name; the extension of
+	// name may select the format, e.g. ".png".
+	WriteDiffImage(name string, img image.Image) error
+
+	// WriteReport stores an encoded report, e.g. JSON, under name.
+	WriteReport(name string, report []byte) error
+}
+
+// DirSink is a Sink writing the artifacts to files under a directory,
+// creating the directories of the names as needed. Diff images are encoded
+// by the extension of the name like DiffFiles does.
+type DirSink struct {
+	dir string
+}
+
+// NewDirSink returns a Sink writing the artifacts under dir.
+func NewDirSink(dir string) *DirSink {
+	return &DirSink{dir: dir}
+}
+
+// Path returns the path of the file storing the artifact with the given name.
+func (s *DirSink) Path(name string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(name))
+}
+
+// WriteDiffImage writes the diff image to the file of name.
+func (s *DirSink) WriteDiffImage(name string, img image.Image) error {
+	p := s.Path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	return EncodeFile(p, img)
+}
+
+// WriteReport writes the report to the file of name.
+func (s *DirSink) WriteReport(name string, report []byte) error {
+	p := s.Path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(p, report, 0o644)
+}
+
+// MemorySink is a Sink keeping the artifacts in memory, e.g. for tests
+// or to serve them without touching the disk.
+type MemorySink struct {
+	mu      sync.Mutex
+	images  map[string]image.Image
+	reports map[string][]byte
+}
+
+// NewMemorySink returns an empty MemorySink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{
+		images:  make(map[string]image.Image),
+		reports: make(map[string][]byte),
+	}
+}
+
+// WriteDiffImage keeps the diff image under name, replacing an earlier one.
+func (s *MemorySink) WriteDiffImage(name string, img image.Image) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.images[name] = img
+	return nil
+}
+
+// WriteReport keeps a copy of the report under name, replacing an earlier one.
+func (s *MemorySink) WriteReport(name string, report []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reports[name] = append([]byte(nil), report...)
+	return nil
+}
+
+// DiffImage returns the diff image stored under name or nil.
+func (s *MemorySink) DiffImage(name string) image.Image {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.images[name]
+}
+
+// Report returns the report stored under name or nil.
+func (s *MemorySink) Report(name string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reports[name]
+}
+
+// Names returns the sorted names of the stored diff images and reports.
+func (s *MemorySink) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.images)+len(s.reports))
+	for name := range s.images {
+		names = append(names, name)
+	}
+	for name := range s.reports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// WithSink makes DiffBatch write the diff image of every pair that does not
+// pass (see DiffResult.Passed) to the sink, named after the pair with the
+// extension replaced by ".png", e.g. "home.png" for "home.jpg". Pairs
+// without an Output get one drawn for the sink. Failing to write the image
+// fails the pair.
+func WithSink(s Sink) Option {
+	return func(o *Options) {
+		o.sink = s
+	}
+}
+
+// diffImageName returns the name of the diff image of the pair with the
+// given name and index in the batch.
+func diffImageName(name string, i int) string {
+	if name == "" {
+		return fmt.Sprintf("%d.png", i)
+	}
+
+	return strings.TrimSuffix(name, path.Ext(name)) + ".png"
+}
@@ -0,0 +1,61 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// failingSink fails every write.
+type failingSink struct{}
+
+func (failingSink) WriteDiffImage(name string, img image.Image) error { return errors.New("full") }
+func (failingSink) WriteReport(name string, report []byte) error      { return errors.New("full") }
+
+func TestWithSink(t *testing.T) {
+	a, b := patchedImage(0), patchedImage(255)
+	pairs := []ImagePair{
+		{Name: "pages/changed.jpg", A: a, B: b},
+		{Name: "same.png", A: a, B: a},
+		{A: a, B: b},
+	}
+
+	sink := NewMemorySink()
+	if err := BatchErr(DiffBatch(pairs, WithSink(sink))); err != nil {
+		t.Fatal(err)
+	}
+	if names := sink.Names(); !reflect.DeepEqual(names, []string{"2.png", "pages/changed.png"}) {
+		t.Errorf("Expected the diff images of the changed pairs, got - %v", names)
+	}
+	if img := sink.DiffImage("pages/changed.png"); img == nil || !img.Bounds().Eq(a.Bounds()) {
+		t.Errorf("Expected a diff image of the pair, got - %v", img)
+	}
+
+	results := DiffBatch(pairs[:1], WithSink(failingSink{}))
+	if results[0].Err == nil || results[0].Result.DiffPixels == 0 {
+		t.Errorf("Expected the failed write to fail the pair, got - %+v", results[0])
+	}
+}
+
+func TestDirSink(t *testing.T) {
+	dir := t.TempDir()
+	sink := NewDirSink(dir)
+
+	if err := sink.WriteDiffImage("a/b/diff.png", patchedImage(255)); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.WriteReport("reports/report.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := DecodeFile(filepath.Join(dir, "a", "b", "diff.png"))
+	if err != nil || !img.Bounds().Eq(image.Rect(0, 0, 16, 16)) {
+		t.Errorf("Expected the diff image in a subdirectory, got - %v %v", img, err)
+	}
+	if data, err := os.ReadFile(sink.Path("reports/report.json")); err != nil || string(data) != "{}" {
+		t.Errorf("Expected the report, got - %q %v", data, err)
+	}
+}
@@ -0,0 +1,259 @@
+// Package source fetches the images to compare from files, HTTP servers and
+// object stores, so retrieval and comparison are a single call:
+//
+//	a := source.Retry(source.Timeout(source.HTTP(nil, baseURL), 10*time.Second), 3, time.Second)
+//	b := source.S3(client, "screenshots", "home.png")
+//	result, output, err := source.DiffSources(ctx, a, b, pixelmatch.WithThreshold(0.05))
+package source
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+// Fetcher fetches an encoded image, e.g. a PNG.
+type Fetcher interface {
+	// Fetch returns the encoded image; the caller closes it.
+	Fetch(ctx context.Context) (io.ReadCloser, error)
+}
+
+// FetcherFunc adapts a function to a Fetcher.
+type FetcherFunc func(ctx context.Context) (io.ReadCloser, error)
+
+// Fetch calls f(ctx).
+func (f FetcherFunc) Fetch(ctx context.Context) (io.ReadCloser, error) {
+	return f(ctx)
+}
+
+// File fetches the image from the file at path.
+func File(path string) Fetcher {
+	return FetcherFunc(func(ctx context.Context) (io.ReadCloser, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return os.Open(path)
+	})
+}
+
+// StatusError reports an HTTP response with a status other than 200 OK.
+// A 404 Not Found matches fs.ErrNotExist with errors.Is.
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("GET %s: %d %s", e.URL, e.Code, http.StatusText(e.Code))
+}
+
+// Unwrap returns fs.ErrNotExist for 404 Not Found and nil otherwise.
+func (e *StatusError) Unwrap() error {
+	if e.Code == http.StatusNotFound {
+		return fs.ErrNotExist
+	}
+
+	return nil
+}
+
+// temporary reports whether a later request may succeed.
+func (e *StatusError) temporary() bool {
+	return e.Code >= 500 || e.Code == http.StatusRequestTimeout || e.Code == http.StatusTooManyRequests
+}
+
+// HTTP fetches the image with a GET request of url using client;
+// a nil client is http.DefaultClient.
+func HTTP(client *http.Client, url string) Fetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return FetcherFunc(func(ctx context.Context) (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, &StatusError{URL: url, Code: resp.StatusCode}
+		}
+
+		return resp.Body, nil
+	})
+}
+
+// ObjectGetter gets objects from an S3-compatible store. It is implemented
+// by a thin adapter of the client of the store, e.g. of the GetObject method
+// of the AWS SDK or minio-go, which keeps their dependencies out of this
+// package. Missing objects should be reported with an error matching
+// fs.ErrNotExist, so they are not retried.
+type ObjectGetter interface {
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+}
+
+// S3 fetches the image from the object with the key in the bucket.
+func S3(client ObjectGetter, bucket, key string) Fetcher {
+	return FetcherFunc(func(ctx context.Context) (io.ReadCloser, error) {
+		return client.GetObject(ctx, bucket, key)
+	})
+}
+
+// Timeout limits every fetch of f, including reading the image, to d.
+func Timeout(f Fetcher, d time.Duration) Fetcher {
+	return FetcherFunc(func(ctx context.Context) (io.ReadCloser, error) {
+		ctx, cancel := context.WithTimeout(ctx, d)
+
+		rc, err := f.Fetch(ctx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		return &cancelCloser{ReadCloser: rc, cancel: cancel}, nil
+	})
+}
+
+// cancelCloser cancels the context of a fetch once its image is closed.
+type cancelCloser struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelCloser) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// Retry fetches the image with f up to attempts times, waiting backoff before
+// the second attempt and twice as long before every further one. Every attempt
+// reads the whole image, so failures while reading it are retried too. Errors
+// that would not go away are not retried: missing files and objects (matching
+// fs.ErrNotExist) and HTTP responses with 4xx statuses other than 408 and 429.
+func Retry(f Fetcher, attempts int, backoff time.Duration) Fetcher {
+	return FetcherFunc(func(ctx context.Context) (io.ReadCloser, error) {
+		var err error
+		for i := 0; i < attempts; i++ {
+			if i > 0 {
+				t := time.NewTimer(backoff << (i - 1))
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return nil, fmt.Errorf("%w, last error: %v", ctx.Err(), err)
+				case <-t.C:
+				}
+			}
+
+			var data []byte
+			if data, err = fetchAll(ctx, f); err == nil {
+				return io.NopCloser(bytes.NewReader(data)), nil
+			}
+			if !retryable(err) {
+				return nil, err
+			}
+		}
+
+		return nil, err
+	})
+}
+
+// fetchAll fetches the image with f and reads it whole.
+func fetchAll(ctx context.Context, f Fetcher) ([]byte, error) {
+	rc, err := f.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+// retryable reports whether fetching again may succeed after err.
+func retryable(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.temporary()
+	}
+
+	return !errors.Is(err, fs.ErrNotExist)
+}
+
+// DiffSources fetches both images concurrently, decodes them and compares
+// them like pixelmatch.DiffReaders. Use Retry and Timeout to retry failed
+// fetches and limit how long they take.
+func DiffSources(ctx context.Context, srcA, srcB Fetcher, opts ...pixelmatch.Option) (pixelmatch.DiffResult, image.Image, error) {
+	var (
+		imgs [2]image.Image
+		errs [2]error
+		wg   sync.WaitGroup
+	)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for i, src := range []Fetcher{srcA, srcB} {
+		wg.Add(1)
+		go func(i int, src Fetcher) {
+			defer wg.Done()
+
+			if imgs[i], errs[i] = fetchImage(ctx, src, opts); errs[i] != nil {
+				// the comparison fails anyway
+				cancel()
+			}
+		}(i, src)
+	}
+	wg.Wait()
+
+	for i, name := range []string{"first", "second"} {
+		// the error of the other image may be just the cancellation
+		if errs[i] != nil && !errors.Is(errs[i], context.Canceled) {
+			return pixelmatch.DiffResult{}, nil, fmt.Errorf("fetch %s image: %w", name, errs[i])
+		}
+	}
+	for i, name := range []string{"first", "second"} {
+		if errs[i] != nil {
+			return pixelmatch.DiffResult{}, nil, fmt.Errorf("fetch %s image: %w", name, errs[i])
+		}
+	}
+
+	output, result, err := pixelmatch.DiffNew(imgs[0], imgs[1], opts...)
+	if err != nil {
+		return result, nil, err
+	}
+
+	return result, output, nil
+}
+
+// fetchImage fetches and decodes the image of src.
+func fetchImage(ctx context.Context, src Fetcher, opts []pixelmatch.Option) (image.Image, error) {
+	rc, err := src.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	img, _, err := pixelmatch.Decode(rc, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	return img, nil
+}
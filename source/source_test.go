@@ -0,0 +1,135 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func encodeTestImage(t *testing.T, changed bool) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+	if changed {
+		img.SetNRGBA(3, 3, color.NRGBA{A: 255})
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// objects is an in-memory ObjectGetter.
+type objects map[string][]byte
+
+func (o objects) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	data, ok := o[bucket+"/"+key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestDiffSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.png")
+	if err := os.WriteFile(path, encodeTestImage(t, false), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first request fails
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(encodeTestImage(t, true))
+	}))
+	defer srv.Close()
+
+	store := objects{"shots/b.png": encodeTestImage(t, true)}
+
+	for _, tc := range []struct {
+		name string
+		a, b Fetcher
+	}{
+		{"file and http", File(path), Retry(HTTP(srv.Client(), srv.URL), 2, time.Millisecond)},
+		{"file and s3", File(path), Timeout(S3(store, "shots", "b.png"), time.Second)},
+	} {
+		result, output, err := DiffSources(context.Background(), tc.a, tc.b)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if result.DiffPixels != 1 || output == nil {
+			t.Errorf("%s: expected 1 different pixel and a diff image, got - %d %v", tc.name, result.DiffPixels, output)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected the failed request to be retried once, got - %d requests", requests)
+	}
+
+	_, _, err := DiffSources(context.Background(), File(path), Retry(S3(store, "shots", "missing.png"), 3, time.Hour))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a missing object to fail without retries, got - %v", err)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var calls int
+	flaky := FetcherFunc(func(ctx context.Context) (io.ReadCloser, error) {
+		calls++
+		return nil, &StatusError{Code: http.StatusBadGateway}
+	})
+
+	if _, err := Retry(flaky, 3, time.Millisecond).Fetch(context.Background()); err == nil || calls != 3 {
+		t.Errorf("Expected 3 failed attempts, got - %d %v", calls, err)
+	}
+
+	calls = 0
+	notFound := &StatusError{Code: http.StatusNotFound}
+	_, err := Retry(FetcherFunc(func(ctx context.Context) (io.ReadCloser, error) {
+		calls++
+		return nil, notFound
+	}), 3, time.Millisecond).Fetch(context.Background())
+	if !errors.Is(err, fs.ErrNotExist) || calls != 1 {
+		t.Errorf("Expected 404 not to be retried, got - %d %v", calls, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Retry(flaky, 3, time.Hour).Fetch(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the backoff to stop with the context, got - %v", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	slow := FetcherFunc(func(ctx context.Context) (io.ReadCloser, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	if _, err := Timeout(slow, time.Millisecond).Fetch(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the fetch to time out, got - %v", err)
+	}
+}
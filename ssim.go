@@ -0,0 +1,111 @@
+package pixelmatch
+
+import (
+	"context"
+	"image"
+)
+
+// MetricSSIM classifies pixels like MetricYIQ and additionally computes
+// the structural similarity index of the images, see DiffResult.SSIM
+// and DiffResult.SSIMMap.
+var MetricSSIM Metric = ssimMetric{}
+
+type ssimMetric struct {
+	yiqMetric
+}
+
+// ssimWindow is the side of the square windows SSIM is computed over.
+const ssimWindow = 8
+
+// stabilizing constants of SSIM for 8-bit values: (0.01*255)^2 and (0.03*255)^2
+const (
+	ssimC1 = 6.5025
+	ssimC2 = 58.5225
+)
+
+// SimilarityMap holds SSIM values of non-overlapping square windows
+// laid out row by row; windows on the right and bottom edges may be smaller.
+type SimilarityMap struct {
+	// side of a window in pixels
+	Window int `json:"window"`
+
+	// number of windows per row and per column
+	Cols int `json:"cols"`
+	Rows int `json:"rows"`
+
+	// SSIM of every window, from -1 to 1
+	Values []float64 `json:"values"`
+}
+
+// At returns SSIM of the window in the given column and row.
+func (m *SimilarityMap) At(col, row int) float64 {
+	return m.Values[row*m.Cols+col]
+}
+
+// ssim computes the mean SSIM of the luma of a and b over r
+// and the similarity of every window.
+func ssim(ctx context.Context, a, b *image.NRGBA, r image.Rectangle) (float64, *SimilarityMap, error) {
+	m := &SimilarityMap{
+		Window: ssimWindow,
+		Cols:   (r.Dx() + ssimWindow - 1) / ssimWindow,
+		Rows:   (r.Dy() + ssimWindow - 1) / ssimWindow,
+	}
+	m.Values = make([]float64, 0, m.Cols*m.Rows)
+
+	total := 0.0
+	for y0 := r.Min.Y; y0 < r.Max.Y; y0 += ssimWindow {
+		if err := ctx.Err(); err != nil {
+			return 0, nil, err
+		}
+
+		for x0 := r.Min.X; x0 < r.Max.X; x0 += ssimWindow {
+			window := image.Rect(x0, y0, x0+ssimWindow, y0+ssimWindow).Intersect(r)
+			value := ssimWindowValue(a, b, window)
+			m.Values = append(m.Values, value)
+			total += value
+		}
+	}
+
+	if len(m.Values) == 0 {
+		// nothing to compare, e.g. a region outside of the images
+		return 1, m, nil
+	}
+
+	return total / float64(len(m.Values)), m, nil
+}
+
+func ssimWindowValue(a, b *image.NRGBA, window image.Rectangle) float64 {
+	var (
+		n                               = float64(window.Dx() * window.Dy())
+		sumA, sumB, sumAA, sumBB, sumAB float64
+	)
+
+	for y := window.Min.Y; y < window.Max.Y; y++ {
+		for x := window.Min.X; x < window.Max.X; x++ {
+			la := luma(getColor(a, x, y))
+			lb := luma(getColor(b, x, y))
+
+			sumA += la
+			sumB += lb
+			sumAA += la * la
+			sumBB += lb * lb
+			sumAB += la * lb
+		}
+	}
+
+	var (
+		meanA = sumA / n
+		meanB = sumB / n
+		varA  = sumAA/n - meanA*meanA
+		varB  = sumBB/n - meanB*meanB
+		cov   = sumAB/n - meanA*meanB
+	)
+
+	return ((2*meanA*meanB + ssimC1) * (2*cov + ssimC2)) /
+		((meanA*meanA + meanB*meanB + ssimC1) * (varA + varB + ssimC2))
+}
+
+// luma returns the brightness of the color blended with white.
+func luma(c [4]uint8) float64 {
+	return rgb2y(blendColor(c))
+}
@@ -0,0 +1,51 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestDiffSSIM(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 20, 12))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 20, 12))
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 20; x++ {
+			c := color.NRGBA{R: uint8(x * 12), G: uint8(y * 20), B: 128, A: 255}
+			img1.SetNRGBA(x, y, c)
+			img2.SetNRGBA(x, y, c)
+		}
+	}
+
+	result, err := Diff(img1, img2, image.NewNRGBA(img1.Bounds()), WithMetric(MetricSSIM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(result.SSIM-1) > 1e-9 {
+		t.Errorf("Expected SSIM 1 for equal images, got - %v", result.SSIM)
+	}
+	if m := result.SSIMMap; m == nil || m.Cols != 3 || m.Rows != 2 || len(m.Values) != 6 {
+		t.Fatalf("Unexpected similarity map %+v", m)
+	}
+
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img2.SetNRGBA(x, y, color.NRGBA{R: uint8((x + y) % 2 * 255), A: 255})
+		}
+	}
+
+	result, err = Diff(img1, img2, image.NewNRGBA(img1.Bounds()), WithMetric(MetricSSIM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SSIMMap.At(0, 0) > 0.5 {
+		t.Errorf("Expected low similarity of the changed window, got - %v", result.SSIMMap.At(0, 0))
+	}
+	if math.Abs(result.SSIMMap.At(1, 0)-1) > 1e-9 {
+		t.Errorf("Expected unchanged window to be similar, got - %v", result.SSIMMap.At(1, 0))
+	}
+	if result.SSIM >= 1 {
+		t.Errorf("Expected SSIM below 1, got - %v", result.SSIM)
+	}
+}
@@ -0,0 +1,210 @@
+package pixelmatch
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"time"
+)
+
+// aaContext is how many rows above and below a pixel
+// the anti-aliasing check looks at.
+const aaContext = 2
+
+// ErrStreamClosed is returned by StreamDiffer after Close.
+var ErrStreamClosed = errors.New("stream differ is closed")
+
+// StreamDiffer compares two images of known size row by row, keeping only
+// the few rows needed for the anti-aliasing check in memory. It is meant for
+// images too large to be decoded completely; create it with NewStreamDiffer,
+// feed the rows with WriteRows and get the result from Close.
+//
+// Options related to whole images (size mismatch, SSIM, parallelism, blur,
+// median filter, edges, text tolerance, scaling, auto-alignment, coarse
+// pass, render modes) are ignored.
+// An observer is told about the comparison by a successful Close.
+type StreamDiffer struct {
+	width, height int
+	options       Options
+	cmp           *pixelComparer
+	budget        *diffBudget
+	progress      *progress
+
+	// rows [lo, hi) of both images; rows before next are already compared
+	a, b         []byte
+	lo, next, hi int
+
+	output *pngStreamWriter
+	row    *image.NRGBA
+
+	result DiffResult
+	start  time.Time
+	err    error
+}
+
+// NewStreamDiffer creates a StreamDiffer for images of the given size.
+func NewStreamDiffer(width, height int, opts ...Option) *StreamDiffer {
+	s := &StreamDiffer{
+		width:   width,
+		height:  height,
+		options: newOptions(opts...),
+		start:   time.Now(),
+	}
+	s.cmp = newPixelComparer(&s.options, image.Rect(0, 0, width, height))
+	s.budget = newDiffBudget(&s.options, 0)
+	s.progress = newProgress(&s.options, height)
+
+	switch {
+	case s.options.err != nil:
+		s.err = s.options.err
+	case width <= 0 || height <= 0:
+		s.err = fmt.Errorf("%w: size %dx%d", ErrEmptyImage, width, height)
+	}
+
+	return s
+}
+
+// SetOutput makes the StreamDiffer encode the diff image as PNG into w
+// while comparing. It must be called before the first WriteRows.
+func (s *StreamDiffer) SetOutput(w io.Writer) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.hi > 0 {
+		return errors.New("pixelmatch: SetOutput called after WriteRows")
+	}
+
+	output, err := newPNGStreamWriter(w, s.width, s.height)
+	if err != nil {
+		return err
+	}
+
+	s.output = output
+	s.row = image.NewNRGBA(image.Rect(0, 0, s.width, 1))
+
+	return nil
+}
+
+// WriteRows adds the next rows of both images as NRGBA pixels
+// (4 bytes per pixel, no padding between rows). Both slices must hold
+// the same whole number of rows.
+func (s *StreamDiffer) WriteRows(a, b []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	stride := s.width * 4
+	if len(a) != len(b) || len(a)%stride != 0 {
+		return fmt.Errorf("%w: rows of %d and %d bytes for width %d", ErrImageSize, len(a), len(b), s.width)
+	}
+
+	rows := len(a) / stride
+	if s.hi+rows > s.height {
+		return fmt.Errorf("%w: got %d rows for height %d", ErrImageSize, s.hi+rows, s.height)
+	}
+
+	s.a = append(s.a, a...)
+	s.b = append(s.b, b...)
+	s.hi += rows
+
+	s.err = s.compareRows()
+
+	return s.err
+}
+
+// Close compares the remaining rows, finishes the PNG output if any
+// and returns the result. It fails when fewer rows than the height were written.
+func (s *StreamDiffer) Close() (DiffResult, error) {
+	if errors.Is(s.err, ErrStreamClosed) {
+		return DiffResult{}, s.err
+	}
+	err := s.err
+	s.err = ErrStreamClosed
+
+	if err != nil && !errors.Is(err, ErrDiffBudgetExceeded) {
+		return DiffResult{}, err
+	}
+	if err == nil && s.hi < s.height {
+		return DiffResult{}, fmt.Errorf("%w: got %d rows for height %d", io.ErrUnexpectedEOF, s.hi, s.height)
+	}
+
+	if s.output != nil && err == nil {
+		if err := s.output.Close(); err != nil {
+			return DiffResult{}, err
+		}
+	}
+
+	s.cmp.finish(&s.result)
+	s.result.finish(s.start)
+
+	// rows are compared on the goroutine writing them
+	options := s.options
+	options.parallelism = 1
+	options.observe(s.start, s.result, err)
+
+	return s.result, err
+}
+
+// compareRows compares every buffered row that has enough rows
+// below it for the anti-aliasing check.
+func (s *StreamDiffer) compareRows() error {
+	lookahead := 0
+	if !s.options.includeAA {
+		lookahead = aaContext
+	}
+	if s.options.shift > lookahead {
+		lookahead = s.options.shift
+	}
+	if s.options.ignoreIsolated && lookahead < 1 {
+		lookahead = 1
+	}
+
+	// report the rows compared by this call however it returns
+	defer func(from int) { s.progress.add(s.next - from) }(s.next)
+
+	stride := s.width * 4
+	for s.next < s.hi && (s.next+lookahead < s.hi || s.hi == s.height) {
+		var (
+			y    = s.next
+			rect = image.Rect(0, s.lo, s.width, s.hi)
+			a    = &image.NRGBA{Pix: s.a, Stride: stride, Rect: rect}
+			b    = &image.NRGBA{Pix: s.b, Stride: stride, Rect: rect}
+			part DiffResult
+		)
+
+		var output *image.NRGBA
+		if s.output != nil {
+			output = s.row
+			output.Rect = image.Rect(0, y, s.width, y+1)
+			for i := range output.Pix {
+				output.Pix[i] = 0
+			}
+		}
+
+		if region := s.cmp.region; y >= region.Min.Y && y < region.Max.Y {
+			s.cmp.compareLine(a, b, output, y, region.Min.X, region.Max.X, &part)
+		}
+		s.result.add(part)
+		s.next++
+
+		if output != nil {
+			if err := s.output.WriteRow(output.Pix); err != nil {
+				return err
+			}
+		}
+
+		if s.budget.spend(part.DiffPixels) {
+			return ErrDiffBudgetExceeded
+		}
+
+		// drop the rows no longer needed by the anti-aliasing check
+		if drop := s.next - lookahead - s.lo; drop > 0 {
+			s.a = append(s.a[:0], s.a[drop*stride:]...)
+			s.b = append(s.b[:0], s.b[drop*stride:]...)
+			s.lo += drop
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func TestStreamDiffer(t *testing.T) {
+	img1 := toNRGBA(decodeTestImage(t, "./testdata/img1.png"))
+	img2 := toNRGBA(decodeTestImage(t, "./testdata/img2.png"))
+	opts := []Option{WithDiffMask(false)}
+
+	want, wantResult, err := DiffNew(img1, img2, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		w, h   = img1.Bounds().Dx(), img1.Bounds().Dy()
+		stride = w * 4
+		buf    bytes.Buffer
+		s      = NewStreamDiffer(w, h, opts...)
+	)
+	if err := s.SetOutput(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for y := 0; y < h; y += 7 {
+		end := y + 7
+		if end > h {
+			end = h
+		}
+		if err := s.WriteRows(img1.Pix[y*stride:end*stride], img2.Pix[y*stride:end*stride]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := s.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiffPixels != wantResult.DiffPixels || result.TotalPixels != wantResult.TotalPixels {
+		t.Errorf("Expected %d/%d, got - %d/%d", wantResult.DiffPixels, wantResult.TotalPixels, result.DiffPixels, result.TotalPixels)
+	}
+	if !result.Bounds.Eq(wantResult.Bounds) {
+		t.Errorf("Expected bounds %v, got - %v", wantResult.Bounds, result.Bounds)
+	}
+
+	got, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(toNRGBA(got).Pix, want.Pix) {
+		t.Error("Streamed diff image differs from Diff output")
+	}
+}
+
+func TestStreamDifferErrors(t *testing.T) {
+	s := NewStreamDiffer(2, 2)
+	if err := s.WriteRows(make([]byte, 8), make([]byte, 4)); !errors.Is(err, ErrImageSize) {
+		t.Errorf("Expected ErrImageSize, got - %v", err)
+	}
+
+	s = NewStreamDiffer(2, 2)
+	if err := s.WriteRows(make([]byte, 8), make([]byte, 8)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Close(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got - %v", err)
+	}
+	if _, err := s.Close(); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("Expected ErrStreamClosed, got - %v", err)
+	}
+
+	if err := NewStreamDiffer(0, 2).WriteRows(nil, nil); !errors.Is(err, ErrEmptyImage) {
+		t.Errorf("Expected ErrEmptyImage, got - %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package pixelmatch
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+)
+
+// SVG returns an SVG overlay of the changes sized to images of the given
+// bounds, so web review tools can layer it over the screenshot instead of
+// another bitmap: every cluster of WithClusters is outlined by a rectangle
+// with a marker at its center of mass and a tooltip of its number of pixels.
+// Without clusters the bounding box of all different pixels is outlined.
+// The outlines are drawn in the diff color of the options.
+func (r DiffResult) SVG(bounds image.Rectangle) []byte {
+	c := defaultOptions.diffColor
+	if r.options != nil {
+		c = r.options.diffColor
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="%d %d %d %d">`+"\n",
+		bounds.Dx(), bounds.Dy(), bounds.Min.X, bounds.Min.Y, bounds.Dx(), bounds.Dy())
+	fmt.Fprintf(&buf, `<g fill="none" stroke="#%02x%02x%02x" stroke-width="2">`+"\n", c.R, c.G, c.B)
+
+	outline := func(rect image.Rectangle, pixels uint64) {
+		plural := "s"
+		if pixels == 1 {
+			plural = ""
+		}
+		fmt.Fprintf(&buf, `<g><title>%d different pixel%s</title><rect x="%d" y="%d" width="%d" height="%d" vector-effect="non-scaling-stroke"/>`,
+			pixels, plural, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy())
+	}
+
+	switch {
+	case len(r.Clusters) > 0:
+		for _, cluster := range r.Clusters {
+			outline(cluster.Bounds, cluster.Pixels)
+			// centroids are of the pixel corners, the marker sits in the middle of the pixel
+			fmt.Fprintf(&buf, `<circle cx="%g" cy="%g" r="2" vector-effect="non-scaling-stroke"/></g>`+"\n",
+				cluster.CentroidX+0.5, cluster.CentroidY+0.5)
+		}
+	case r.DiffPixels > 0:
+		outline(r.Bounds, r.DiffPixels)
+		buf.WriteString("</g>\n")
+	}
+
+	buf.WriteString("</g>\n</svg>\n")
+
+	return buf.Bytes()
+}
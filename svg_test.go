@@ -0,0 +1,81 @@
+package pixelmatch
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDiffResultSVG(t *testing.T) {
+	img1 := image.NewNRGBA(image.Rect(0, 0, 20, 10))
+	img2 := image.NewNRGBA(image.Rect(0, 0, 20, 10))
+	for x := 2; x < 5; x++ {
+		img2.SetNRGBA(x, 3, color.NRGBA{R: 255, A: 255})
+	}
+	img2.SetNRGBA(15, 8, color.NRGBA{R: 255, A: 255})
+
+	type group struct {
+		Title string `xml:"title"`
+		Rect  struct {
+			X      int `xml:"x,attr"`
+			Y      int `xml:"y,attr"`
+			Width  int `xml:"width,attr"`
+			Height int `xml:"height,attr"`
+		} `xml:"rect"`
+		Circle *struct {
+			CX float64 `xml:"cx,attr"`
+			CY float64 `xml:"cy,attr"`
+		} `xml:"circle"`
+	}
+	type svg struct {
+		Width   int    `xml:"width,attr"`
+		Height  int    `xml:"height,attr"`
+		ViewBox string `xml:"viewBox,attr"`
+		Layer   struct {
+			Stroke string  `xml:"stroke,attr"`
+			Groups []group `xml:"g"`
+		} `xml:"g"`
+	}
+	parse := func(data []byte) svg {
+		t.Helper()
+		var v svg
+		if err := xml.Unmarshal(data, &v); err != nil {
+			t.Fatalf("Expected valid SVG, got - %v\n%s", err, data)
+		}
+		return v
+	}
+
+	result, err := Diff(img1, img2, nil, WithClusters(1), WithDiffColor(color.NRGBA{B: 255, A: 255}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := parse(result.SVG(img1.Bounds()))
+	if v.Width != 20 || v.Height != 10 || v.ViewBox != "0 0 20 10" {
+		t.Errorf("Unexpected size %d %d %q", v.Width, v.Height, v.ViewBox)
+	}
+	if v.Layer.Stroke != "#0000ff" {
+		t.Errorf("Expected the diff color, got - %q", v.Layer.Stroke)
+	}
+	if len(v.Layer.Groups) != 2 {
+		t.Fatalf("Expected 2 outlined clusters, got - %+v", v.Layer.Groups)
+	}
+	if g := v.Layer.Groups[0]; g.Title != "3 different pixels" || g.Rect.X != 2 || g.Rect.Y != 3 || g.Rect.Width != 3 || g.Rect.Height != 1 ||
+		g.Circle == nil || g.Circle.CX != 3.5 || g.Circle.CY != 3.5 {
+		t.Errorf("Unexpected outline %+v", g)
+	}
+	if g := v.Layer.Groups[1]; g.Title != "1 different pixel" {
+		t.Errorf("Unexpected title %q", g.Title)
+	}
+
+	result, _ = Diff(img1, img2, nil)
+	v = parse(result.SVG(img1.Bounds()))
+	if len(v.Layer.Groups) != 1 || v.Layer.Groups[0].Title != "4 different pixels" || v.Layer.Groups[0].Rect.Width != 14 || v.Layer.Groups[0].Circle != nil {
+		t.Errorf("Expected the bounds outlined, got - %+v", v.Layer.Groups)
+	}
+
+	result, _ = Diff(img1, img1, nil)
+	if v := parse(result.SVG(img1.Bounds())); len(v.Layer.Groups) != 0 {
+		t.Errorf("Expected no outlines, got - %+v", v.Layer.Groups)
+	}
+}
@@ -0,0 +1,331 @@
+//go:build ignore
+
+// This program generates the fixtures of TestFixtureSnapshots: pairs of
+// images with anti-aliased text and shapes, and their diff images as drawn
+// by this package, whose counts it prints. Run it from the root of the
+// module after a change of the comparison that is meant to alter them:
+//
+//	go run testdata/fixtures/gen.go
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+const dir = "testdata/fixtures"
+
+// cases mirror fixtureCases of fixtures_test.go.
+var cases = []struct {
+	a, b, diff string
+	opts       []pixelmatch.Option
+}{
+	{"1a", "1b", "1diff", []pixelmatch.Option{pixelmatch.WithThreshold(0.05)}},
+	{"1a", "1b", "1diffdefaultthreshold", nil},
+	{"1a", "1b", "1diffmask", []pixelmatch.Option{pixelmatch.WithThreshold(0.05), pixelmatch.WithIncludeAA(false), pixelmatch.WithDiffMask(true)}},
+	{"1a", "1a", "1emptydiffmask", []pixelmatch.Option{pixelmatch.WithThreshold(0), pixelmatch.WithDiffMask(true)}},
+	{"2a", "2b", "2diff", []pixelmatch.Option{
+		pixelmatch.WithThreshold(0.05),
+		pixelmatch.WithAlpha(0.5),
+		pixelmatch.WithAAColor(color.NRGBA{G: 192, A: 255}),
+		pixelmatch.WithDiffColor(color.NRGBA{R: 255, B: 255, A: 255}),
+	}},
+	{"3a", "3b", "3diff", []pixelmatch.Option{pixelmatch.WithThreshold(0.05)}},
+	{"4a", "4b", "4diff", []pixelmatch.Option{pixelmatch.WithThreshold(0.05)}},
+	{"5a", "5b", "5diff", []pixelmatch.Option{pixelmatch.WithThreshold(0.05)}},
+	{"6a", "6b", "6diff", []pixelmatch.Option{pixelmatch.WithThreshold(0.05)}},
+	{"6a", "6a", "6empty", []pixelmatch.Option{pixelmatch.WithThreshold(0)}},
+	{"7a", "7b", "7diff", []pixelmatch.Option{pixelmatch.WithDiffColorAlt(color.NRGBA{G: 255, A: 255})}},
+	{"8a", "5b", "8diff", []pixelmatch.Option{pixelmatch.WithThreshold(0.05)}},
+}
+
+func main() {
+	regular, err := newFace(goregular.TTF, 13)
+	if err != nil {
+		log.Fatal(err)
+	}
+	bold, err := newFace(gobold.TTF, 40)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	images := map[string]*image.NRGBA{
+		"1a": text(regular, false),
+		"1b": text(regular, true),
+		"2a": panel(regular, false),
+		"2b": panel(regular, true),
+		"3a": chart(false),
+		"3b": chart(true),
+		"4a": photo(false),
+		"4b": photo(true),
+		"5a": shapes(0),
+		"5b": shapes(0.3),
+		"6a": icon(false),
+		"6b": icon(true),
+		"7a": blocks(false),
+		"7b": blocks(true),
+		"8a": title(bold),
+	}
+	for name, img := range images {
+		if err := writePNG(name, img); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fmt.Printf("%-24s %8s %8s\n", "case", "diff", "aa")
+	for _, tc := range cases {
+		opts := append([]pixelmatch.Option{pixelmatch.WithDiffMask(false)}, tc.opts...)
+		output, result, err := pixelmatch.DiffNew(images[tc.a], images[tc.b], opts...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := writePNG(tc.diff, output); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%-24s %8d %8d\n", tc.diff, result.DiffPixels, result.AAPixels)
+	}
+}
+
+func newFace(ttf []byte, size float64) (font.Face, error) {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72})
+}
+
+func writePNG(name string, img image.Image) error {
+	f, err := os.Create(filepath.Join(dir, name+".png"))
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func fill(w, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Rect, image.NewUniform(c), image.Point{}, draw.Src)
+
+	return img
+}
+
+func drawText(dst draw.Image, face font.Face, x, y int, c color.Color, s string) {
+	d := font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+// path is a polygon drawn with anti-aliasing.
+type path [][2]float32
+
+func (p path) draw(dst draw.Image, c color.Color) {
+	r := dst.Bounds()
+	z := vector.NewRasterizer(r.Dx(), r.Dy())
+	z.MoveTo(p[0][0], p[0][1])
+	for _, pt := range p[1:] {
+		z.LineTo(pt[0], pt[1])
+	}
+	z.ClosePath()
+	z.Draw(dst, r, image.NewUniform(c), image.Point{})
+}
+
+func circle(cx, cy, radius float32) path {
+	const n = 64
+	p := make(path, n)
+	for i := range p {
+		a := 2 * math.Pi * float64(i) / n
+		p[i] = [2]float32{cx + radius*float32(math.Cos(a)), cy + radius*float32(math.Sin(a))}
+	}
+
+	return p
+}
+
+func rect(x0, y0, x1, y1 float32) path {
+	return path{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+}
+
+// line is a segment of the given width.
+func line(x0, y0, x1, y1, width float32) path {
+	dx, dy := x1-x0, y1-y0
+	l := float32(math.Hypot(float64(dx), float64(dy)))
+	nx, ny := -dy/l*width/2, dx/l*width/2
+
+	return path{{x0 + nx, y0 + ny}, {x1 + nx, y1 + ny}, {x1 - nx, y1 - ny}, {x0 - nx, y0 - ny}}
+}
+
+// text is a paragraph; the second version changes a word and the shade of
+// a line.
+func text(face font.Face, changed bool) *image.NRGBA {
+	img := fill(240, 110, color.White)
+
+	lines := []string{
+		"The quick brown fox jumps over",
+		"the lazy dog while the cat sleeps",
+		"on the warm windowsill, dreaming",
+		"of fish, birds and balls of yarn.",
+		"Nothing moves in the quiet house",
+		"until the mail arrives at noon.",
+	}
+	if changed {
+		lines[1] = "the lazy dog while the cat naps"
+	}
+	for i, s := range lines {
+		c := color.NRGBA{0x33, 0x33, 0x33, 0xff}
+		if changed && i == 4 {
+			c = color.NRGBA{0x3c, 0x3c, 0x50, 0xff}
+		}
+		drawText(img, face, 8, 18+i*16, c, s)
+	}
+
+	return img
+}
+
+// panel is a translucent card with shapes over a transparent background;
+// the second version moves the circle and recolors the triangle.
+func panel(face font.Face, changed bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 150))
+
+	rect(10, 10, 190, 140).draw(img, color.NRGBA{0x20, 0x40, 0x80, 0xa0})
+	drawText(img, face, 20, 32, color.NRGBA{0xff, 0xff, 0xff, 0xff}, "Status")
+
+	cx := float32(60)
+	if changed {
+		cx = 63.5
+	}
+	circle(cx, 85, 25).draw(img, color.NRGBA{0xf0, 0xa0, 0x30, 0xe0})
+
+	tri := color.NRGBA{0x40, 0xd0, 0x60, 0xff}
+	if changed {
+		tri = color.NRGBA{0x40, 0xb0, 0xd0, 0xff}
+	}
+	path{{120, 120}, {170, 120}, {145, 60}}.draw(img, tri)
+
+	return img
+}
+
+// chart is a line chart; the second version moves a data point.
+func chart(changed bool) *image.NRGBA {
+	img := fill(220, 140, color.White)
+
+	axis := color.NRGBA{0x88, 0x88, 0x88, 0xff}
+	rect(20, 10, 21, 121).draw(img, axis)
+	rect(20, 120, 210, 121).draw(img, axis)
+
+	values := []float32{30, 55, 42, 80, 71, 95, 60}
+	if changed {
+		values[4] = 64
+	}
+	series := color.NRGBA{0x1f, 0x77, 0xb4, 0xff}
+	for i := 1; i < len(values); i++ {
+		x0, x1 := 30+float32(i-1)*28, 30+float32(i)*28
+		line(x0, 120-values[i-1], x1, 120-values[i], 2).draw(img, series)
+	}
+	for i, v := range values {
+		circle(30+float32(i)*28, 120-v, 3.5).draw(img, series)
+	}
+
+	return img
+}
+
+// photo is a noisy gradient; the second version is brighter and noisier
+// in its lower half.
+func photo(changed bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 160, 120))
+	rnd := rand.New(rand.NewSource(4))
+
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 160; x++ {
+			noise := rnd.Intn(17) - 8
+			r := float64(60 + x + noise)
+			g := float64(90 + y + noise/2)
+			b := float64(200 - x/2 - y/3)
+			if changed && y >= 60 {
+				r, g, b = r*1.15+float64(noise), g*1.1, b*0.95
+			}
+			img.SetNRGBA(x, y, color.NRGBA{clamp(r), clamp(g), clamp(b), 0xff})
+		}
+	}
+
+	return img
+}
+
+// shapes draws a few shapes offset by a fraction of a pixel, which changes
+// only their anti-aliased edges.
+func shapes(offset float32) *image.NRGBA {
+	img := fill(160, 120, color.White)
+
+	rect(20+offset, 20, 70+offset, 60).draw(img, color.NRGBA{0x30, 0x30, 0x30, 0xff})
+	circle(110, 45+offset, 22).draw(img, color.NRGBA{0xc0, 0x20, 0x20, 0xff})
+	line(20, 100+offset, 140, 85+offset, 3).draw(img, color.NRGBA{0x20, 0x60, 0x20, 0xff})
+
+	return img
+}
+
+// icon is a small floppy disk; the second version adds a dot to its label.
+func icon(changed bool) *image.NRGBA {
+	img := fill(48, 48, color.White)
+
+	body := color.NRGBA{0x2a, 0x4d, 0x7a, 0xff}
+	path{{6, 6}, {36, 6}, {42, 12}, {42, 42}, {6, 42}}.draw(img, body)
+	rect(13, 6, 33, 17).draw(img, color.NRGBA{0xdd, 0xdd, 0xdd, 0xff})
+	rect(27, 8, 31, 15).draw(img, body)
+	rect(11, 24, 37, 40).draw(img, color.White)
+	if changed {
+		circle(24, 32, 2.5).draw(img, body)
+	}
+
+	return img
+}
+
+// blocks are gray blocks; the second version lightens one and darkens
+// another.
+func blocks(changed bool) *image.NRGBA {
+	img := fill(200, 100, color.White)
+
+	shades := []uint8{0x60, 0x90, 0xc0}
+	if changed {
+		shades[0], shades[2] = 0x90, 0x80
+	}
+	for i, s := range shades {
+		x := float32(15 + i*62)
+		rect(x, 20, x+50, 80).draw(img, color.NRGBA{s, s, s, 0xff})
+	}
+
+	return img
+}
+
+// title is a picture unrelated to that of shapes.
+func title(face font.Face) *image.NRGBA {
+	img := fill(160, 120, color.NRGBA{0xf4, 0xf0, 0xe6, 0xff})
+
+	rect(0, 80, 160, 120).draw(img, color.NRGBA{0x22, 0x55, 0x88, 0xff})
+	drawText(img, face, 12, 60, color.NRGBA{0x88, 0x22, 0x22, 0xff}, "pixel")
+
+	return img
+}
+
+func clamp(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, v)))
+}
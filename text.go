@@ -0,0 +1,119 @@
+package pixelmatch
+
+import "image"
+
+const (
+	// textRadius is the radius of the window WithTextTolerance measures the
+	// variance of the brightness in, a 7x7 window covers a few glyph strokes.
+	textRadius = 3
+
+	// textStdDev is the standard deviation of the brightness (0 to 255)
+	// of a window that makes its center text-like; dark text on a light
+	// background is far above it, gradients and photos mostly below.
+	textStdDev = 48
+)
+
+// WithTextTolerance compares the pixels in text-like regions of either image
+// with a looser threshold (0 to 1) than the rest, since font rendering differs
+// in ways the anti-aliasing detector misses, e.g. in hinting and subpixel
+// positioning across platforms. A pixel is text-like when the brightness of
+// its 7x7 neighbourhood varies strongly, as it does in and around glyphs;
+// threshold <= the threshold of the comparison disables it.
+func WithTextTolerance(threshold float64) Option {
+	return func(o *Options) {
+		o.textThreshold = threshold
+	}
+}
+
+// textTolerant reports whether the comparison uses a looser threshold in text-like regions.
+func (o *Options) textTolerant() bool {
+	return o.textThreshold > o.threshold
+}
+
+// textRegions returns the set of the pixels of r that are text-like in a or b.
+func textRegions(a, b *image.NRGBA, r image.Rectangle) *pixelSet {
+	set := newPixelSet(a.Rect)
+	markTextLike(a, r, set)
+	markTextLike(b, r, set)
+
+	return set
+}
+
+// markTextLike adds the pixels of r whose neighbourhood in img has a standard
+// deviation of the brightness of at least textStdDev to set. The window is
+// clipped to the bounds of img and slides over the image: the sums of its
+// columns are updated row by row and the sums of the window column by column.
+func markTextLike(img *image.NRGBA, r image.Rectangle, set *pixelSet) {
+	var (
+		bounds = img.Rect
+		w      = bounds.Dx()
+		luma   = make([]uint32, w*bounds.Dy())
+		colSum = make([]uint32, w)
+		colSq  = make([]uint32, w)
+	)
+
+	for y := 0; y < bounds.Dy(); y++ {
+		row := img.Pix[img.PixOffset(bounds.Min.X, bounds.Min.Y+y):][:w*4]
+		for x := 0; x < w; x++ {
+			var c [4]uint8
+			copy(c[:], row[x*4:x*4+4])
+			luma[y*w+x] = uint32(clampUint8(rgb2y(blendColor(c))))
+		}
+	}
+
+	addRow := func(y int, add bool) {
+		for x, v := range luma[y*w:][:w] {
+			if add {
+				colSum[x], colSq[x] = colSum[x]+v, colSq[x]+v*v
+			} else {
+				colSum[x], colSq[x] = colSum[x]-v, colSq[x]-v*v
+			}
+		}
+	}
+
+	// rows 0..textRadius-1 of the window of the first row; the loop adds the rest
+	for y := 0; y < minInt(textRadius, bounds.Dy()); y++ {
+		addRow(y, true)
+	}
+
+	for y := 0; y < bounds.Dy(); y++ {
+		if next := y + textRadius; next < bounds.Dy() {
+			addRow(next, true)
+		}
+		if prev := y - textRadius - 1; prev >= 0 {
+			addRow(prev, false)
+		}
+
+		absY := bounds.Min.Y + y
+		if absY < r.Min.Y || absY >= r.Max.Y {
+			continue
+		}
+
+		rows := minInt(y+textRadius, bounds.Dy()-1) - maxInt(y-textRadius, 0) + 1
+
+		var sum, sq uint32
+		for x := 0; x < minInt(textRadius, w); x++ {
+			sum, sq = sum+colSum[x], sq+colSq[x]
+		}
+
+		for x := 0; x < w; x++ {
+			if next := x + textRadius; next < w {
+				sum, sq = sum+colSum[next], sq+colSq[next]
+			}
+			if prev := x - textRadius - 1; prev >= 0 {
+				sum, sq = sum-colSum[prev], sq-colSq[prev]
+			}
+
+			absX := bounds.Min.X + x
+			if absX < r.Min.X || absX >= r.Max.X {
+				continue
+			}
+
+			n := float64(rows * (minInt(x+textRadius, w-1) - maxInt(x-textRadius, 0) + 1))
+			mean := float64(sum) / n
+			if float64(sq)/n-mean*mean >= textStdDev*textStdDev {
+				set.set(absX, absY)
+			}
+		}
+	}
+}
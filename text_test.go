@@ -0,0 +1,80 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// textLikeImage draws dense vertical strokes of the given gray, like a word,
+// and a flat 3x3 patch of the patch gray far from them over white.
+func textLikeImage(stroke, patch uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+	draw.Draw(img, img.Rect, image.White, image.Point{}, draw.Src)
+
+	for x := 4; x < 16; x += 2 {
+		draw.Draw(img, image.Rect(x, 4, x+1, 12), image.NewUniform(color.Gray{Y: stroke}), image.Point{}, draw.Src)
+	}
+	draw.Draw(img, image.Rect(30, 20, 33, 23), image.NewUniform(color.Gray{Y: patch}), image.Point{}, draw.Src)
+
+	return img
+}
+
+func TestWithTextTolerance(t *testing.T) {
+	a, b := textLikeImage(0, 255), textLikeImage(100, 155)
+
+	strict, err := Diff(a, b, nil, WithIncludeAA(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strict.DiffPixels != 6*8+9 {
+		t.Fatalf("Expected the strokes and the patch to differ, got - %d", strict.DiffPixels)
+	}
+
+	tolerant, err := Diff(a, b, nil, WithIncludeAA(true), WithTextTolerance(0.5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tolerant.DiffPixels != 9 || tolerant.Bounds != image.Rect(30, 20, 33, 23) {
+		t.Errorf("Expected only the patch to differ, got - %d in %v", tolerant.DiffPixels, tolerant.Bounds)
+	}
+
+	if count, err := Compare(a, b, WithIncludeAA(true), WithTextTolerance(0.5)); err != nil || count != tolerant.DiffPixels {
+		t.Errorf("Expected Compare to count %d pixels, got - %d, %v", tolerant.DiffPixels, count, err)
+	}
+
+	// a tolerance below the threshold changes nothing
+	if result, _ := Diff(a, b, nil, WithIncludeAA(true), WithTextTolerance(0.05)); result.DiffPixels != strict.DiffPixels {
+		t.Errorf("Expected %d pixels with a stricter text threshold, got - %d", strict.DiffPixels, result.DiffPixels)
+	}
+}
+
+func TestTextRegions(t *testing.T) {
+	img := textLikeImage(0, 255)
+	sub := img.SubImage(image.Rect(2, 2, 38, 28)).(*image.NRGBA)
+
+	for _, tc := range []struct {
+		name string
+		img  *image.NRGBA
+	}{
+		{"image", img},
+		{"sub-image", sub},
+	} {
+		set := textRegions(tc.img, tc.img, tc.img.Rect)
+
+		for _, p := range []struct {
+			x, y int
+			want bool
+		}{
+			{9, 8, true},    // between the strokes
+			{5, 4, true},    // a stroke end
+			{30, 25, false}, // flat background
+			{20, 8, false},  // beyond the window of the last stroke
+		} {
+			if got := set.has(p.x, p.y); got != p.want {
+				t.Errorf("%s: expected text-like %v at (%d,%d), got - %v", tc.name, p.want, p.x, p.y, got)
+			}
+		}
+	}
+}
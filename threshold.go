@@ -0,0 +1,40 @@
+package pixelmatch
+
+// WithFailureThreshold lets a comparison pass with up to pixels different
+// pixels, see DiffResult.Passed; like failureThreshold of jest-image-snapshot
+// with the "pixel" failureThresholdType.
+func WithFailureThreshold(pixels uint64) Option {
+	return func(o *Options) {
+		o.failureThreshold = float64(pixels)
+		o.failurePercent = false
+	}
+}
+
+// WithFailureThresholdPercent lets a comparison pass with up to percent
+// (0 to 100) of the pixels different, see DiffResult.Passed; like the
+// "percent" failureThresholdType of jest-image-snapshot, where 0.01 is 1 here.
+func WithFailureThresholdPercent(percent float64) Option {
+	return func(o *Options) {
+		o.failureThreshold = percent
+		o.failurePercent = true
+	}
+}
+
+// Pass reports whether at most maxPercent (0 to 100) of the pixels differ.
+func (r DiffResult) Pass(maxPercent float64) bool {
+	return r.Percent <= maxPercent
+}
+
+// Passed reports whether the result is within the failure threshold of the
+// comparison set by WithFailureThreshold or WithFailureThresholdPercent;
+// without one only a result with no different pixels passes.
+func (r DiffResult) Passed() bool {
+	if r.options == nil {
+		return r.DiffPixels == 0
+	}
+	if r.options.failurePercent {
+		return r.Pass(r.options.failureThreshold)
+	}
+
+	return float64(r.DiffPixels) <= r.options.failureThreshold
+}
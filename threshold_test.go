@@ -0,0 +1,50 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestPassed(t *testing.T) {
+	a, b := image.NewNRGBA(image.Rect(0, 0, 10, 10)), image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	b.SetNRGBA(2, 2, color.NRGBA{R: 255, A: 255})
+	b.SetNRGBA(7, 7, color.NRGBA{R: 255, A: 255})
+
+	tests := []struct {
+		name string
+		opts []Option
+		want bool
+	}{
+		{"no threshold", nil, false},
+		{"pixels below", []Option{WithFailureThreshold(1)}, false},
+		{"pixels at", []Option{WithFailureThreshold(2)}, true},
+		{"percent below", []Option{WithFailureThresholdPercent(1.5)}, false},
+		{"percent at", []Option{WithFailureThresholdPercent(2)}, true},
+		{"last one wins", []Option{WithFailureThresholdPercent(5), WithFailureThreshold(1)}, false},
+	}
+
+	for _, tc := range tests {
+		result, err := Diff(a, b, nil, append(tc.opts, WithIncludeAA(true))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := result.Passed(); got != tc.want {
+			t.Errorf("%s: expected passed %v with %d pixels (%.0f%%), got - %v", tc.name, tc.want, result.DiffPixels, result.Percent, got)
+		}
+	}
+
+	if result, _ := Diff(a, a, nil); !result.Passed() {
+		t.Error("Expected equal images to pass without a threshold")
+	}
+	if (DiffResult{DiffPixels: 1}).Passed() {
+		t.Error("Expected a result without options to pass only with no different pixels")
+	}
+}
+
+func TestPass(t *testing.T) {
+	result := DiffResult{DiffPixels: 5, TotalPixels: 200, Percent: 2.5}
+	if !result.Pass(2.5) || result.Pass(2) {
+		t.Errorf("Expected 2.5%% to pass at 2.5 and fail at 2")
+	}
+}
@@ -0,0 +1,248 @@
+package pixelmatch
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"hash"
+	"image"
+	"sync"
+	"sync/atomic"
+)
+
+// cacheTile is the size of the tiles whose results WithTileCache keeps.
+const cacheTile = 64
+
+// TileKey identifies the comparison of a tile: a SHA-256 hash of the
+// pixels of both images in the tile and around it, as far as the
+// anti-aliasing and other checks look, of its ignored pixels and of the
+// options affecting the outcome.
+type TileKey [sha256.Size]byte
+
+// TileResult is the outcome of comparing a tile of the images.
+type TileResult struct {
+	DiffPixels, AAPixels, TotalPixels uint64
+
+	// bounding box of the different pixels relative to the top left
+	// corner of the tile
+	Bounds image.Rectangle
+
+	// zero unless WithChannelDiff is used
+	Channels ChannelDiff
+}
+
+// TileCache keeps the results of comparing tiles of images, so repeated
+// comparisons of mostly unchanged images skip the tiles seen before.
+// MemoryTileCache keeps them in memory; other caches, e.g. shared by the
+// machines of a CI farm, can be plugged in with WithTileCache. Tile caches
+// must be safe for concurrent use.
+type TileCache interface {
+	// GetTile returns the result stored under key, if any.
+	GetTile(key TileKey) (TileResult, bool)
+
+	// PutTile stores the result under key.
+	PutTile(key TileKey, r TileResult)
+}
+
+// WithTileCache makes Diff split the images into 64x64 tiles and look up
+// the result of every tile in c before comparing its pixels, storing the
+// results of the tiles it compares. Custom metrics are told apart by their
+// names in the keys. It applies when no diff image is drawn and no option
+// needs the single pixels (DiffMask, clusters, grid, pixel callback, extra
+// metrics, text tolerance, sampling, images of more than 8 bits per
+// channel); the coarse pass is skipped with it.
+func WithTileCache(c TileCache) Option {
+	return func(o *Options) {
+		o.tileCache = c
+	}
+}
+
+// MemoryTileCache is a TileCache keeping the most recently used results
+// in memory.
+type MemoryTileCache struct {
+	mu    sync.Mutex
+	max   int
+	order *list.List // of *tileEntry, most recently used first
+	tiles map[TileKey]*list.Element
+}
+
+type tileEntry struct {
+	key    TileKey
+	result TileResult
+}
+
+// NewMemoryTileCache returns an empty MemoryTileCache keeping up to
+// maxTiles results, about 100 bytes each.
+func NewMemoryTileCache(maxTiles int) *MemoryTileCache {
+	return &MemoryTileCache{
+		max:   maxTiles,
+		order: list.New(),
+		tiles: make(map[TileKey]*list.Element),
+	}
+}
+
+// GetTile returns the result stored under key, if any, and marks it as
+// the most recently used one.
+func (c *MemoryTileCache) GetTile(key TileKey) (TileResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.tiles[key]
+	if !ok {
+		return TileResult{}, false
+	}
+	c.order.MoveToFront(e)
+
+	return e.Value.(*tileEntry).result, true
+}
+
+// PutTile stores the result under key, evicting the least recently used
+// result when the cache is full.
+func (c *MemoryTileCache) PutTile(key TileKey, r TileResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.tiles[key]; ok {
+		e.Value.(*tileEntry).result = r
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.tiles[key] = c.order.PushFront(&tileEntry{key: key, result: r})
+	for c.order.Len() > c.max {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.tiles, last.Value.(*tileEntry).key)
+	}
+}
+
+// Len returns the number of results in the cache.
+func (c *MemoryTileCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// cacheTiles reports whether the tile cache applies to the comparison.
+func (c *pixelComparer) cacheTiles(output *image.NRGBA) bool {
+	o := c.options
+
+	return o.tileCache != nil && output == nil && c.diff == nil && c.aa == nil && c.grid == nil && c.text == nil && c.a64 == nil &&
+		o.pixelFunc == nil && !o.extraMetrics && o.sampleRate <= 1
+}
+
+// compareCached compares the region tile by tile, taking the results of
+// the tiles from the tile cache where it has them.
+func (c *pixelComparer) compareCached(ctx context.Context, a, b *image.NRGBA, budget *diffBudget, stop func(), prog *progress) DiffResult {
+	var (
+		result DiffResult
+		mu     sync.Mutex
+		hits   uint64
+		r      = c.region
+		tiles  = image.Rect(0, 0, (r.Dx()+cacheTile-1)/cacheTile, (r.Dy()+cacheTile-1)/cacheTile)
+	)
+
+	// the settings affecting the outcome, as encoded with the results
+	settings, _ := json.Marshal(*c.options)
+
+	runBands(ctx, tiles, c.options.workers(), func(band image.Rectangle) {
+		var (
+			part DiffResult
+			h    = sha256.New()
+			rows int
+			ty   int
+		)
+		for ty = band.Min.Y; ty < band.Max.Y && ctx.Err() == nil; ty++ {
+			var tile image.Rectangle
+			for tx := 0; tx < tiles.Dx(); tx++ {
+				tile = image.Rect(tx*cacheTile, ty*cacheTile, (tx+1)*cacheTile, (ty+1)*cacheTile).Add(r.Min).Intersect(r)
+				key := c.tileKey(h, settings, a, b, tile)
+
+				t, ok := c.options.tileCache.GetTile(key)
+				if ok {
+					atomic.AddUint64(&hits, 1)
+				} else {
+					var p DiffResult
+					for y := tile.Min.Y; y < tile.Max.Y; y++ {
+						c.compareRow(a, b, nil, y, tile.Min.X, tile.Max.X, &p)
+					}
+					t = TileResult{
+						DiffPixels:  p.DiffPixels,
+						AAPixels:    p.AAPixels,
+						TotalPixels: p.TotalPixels,
+						Bounds:      p.Bounds.Sub(tile.Min),
+						Channels:    p.Channels,
+					}
+					c.options.tileCache.PutTile(key, t)
+				}
+
+				part.add(DiffResult{
+					DiffPixels:  t.DiffPixels,
+					AAPixels:    t.AAPixels,
+					TotalPixels: t.TotalPixels,
+					Bounds:      t.Bounds.Add(tile.Min),
+					Channels:    t.Channels,
+				})
+				if budget.spend(t.DiffPixels) {
+					stop()
+				}
+			}
+			rows += tile.Dy()
+		}
+
+		mu.Lock()
+		result.add(part)
+		if ty == band.Max.Y {
+			c.options.reportBand(image.Rect(r.Min.X, r.Min.Y+band.Min.Y*cacheTile, r.Max.X, r.Min.Y+band.Min.Y*cacheTile+rows), part)
+		}
+		mu.Unlock()
+
+		prog.add(rows)
+	})
+
+	c.options.debug("compared tiles", "cached", hits, "total", tiles.Dx()*tiles.Dy())
+
+	return result
+}
+
+// tileKey returns the key of the comparison of the tile with the settings.
+func (c *pixelComparer) tileKey(h hash.Hash, settings []byte, a, b *image.NRGBA, tile image.Rectangle) TileKey {
+	// the pixels the checks of the tile look at
+	around := tile.Inset(-c.options.neighbourhood()).Intersect(c.bounds).Intersect(a.Rect)
+
+	h.Reset()
+	h.Write(settings)
+
+	var buf []byte
+	for _, v := range []int{tile.Dx(), tile.Dy(), tile.Min.X - around.Min.X, tile.Min.Y - around.Min.Y, around.Dx(), around.Dy()} {
+		buf = binary.AppendVarint(buf, int64(v))
+	}
+	h.Write(buf)
+
+	for _, img := range []*image.NRGBA{a, b} {
+		for y := around.Min.Y; y < around.Max.Y; y++ {
+			i := img.PixOffset(around.Min.X, y)
+			h.Write(img.Pix[i : i+around.Dx()*4])
+		}
+	}
+
+	if c.ignore != nil {
+		buf = buf[:0]
+		for y := around.Min.Y; y < around.Max.Y; y++ {
+			for x := around.Min.X; x < around.Max.X; x++ {
+				if c.ignore.ignored(x, y) {
+					buf = binary.AppendUvarint(buf, uint64((y-around.Min.Y)*around.Dx()+x-around.Min.X))
+				}
+			}
+		}
+		h.Write(buf)
+	}
+
+	var key TileKey
+	h.Sum(key[:0])
+
+	return key
+}
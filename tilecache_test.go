@@ -0,0 +1,108 @@
+package pixelmatch
+
+import (
+	"image"
+	"image/color"
+	"sync/atomic"
+	"testing"
+)
+
+// countingTileCache counts the lookups of a MemoryTileCache.
+type countingTileCache struct {
+	*MemoryTileCache
+	gets, hits int64
+}
+
+func (c *countingTileCache) GetTile(key TileKey) (TileResult, bool) {
+	r, ok := c.MemoryTileCache.GetTile(key)
+	atomic.AddInt64(&c.gets, 1)
+	if ok {
+		atomic.AddInt64(&c.hits, 1)
+	}
+
+	return r, ok
+}
+
+func TestWithTileCache(t *testing.T) {
+	img1 := toNRGBA(decodeTestImage(t, "testdata/img1.png"))
+	img2 := toNRGBA(decodeTestImage(t, "testdata/img2.png"))
+
+	want, err := Diff(img1, img2, nil, WithChannelDiff(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := &countingTileCache{MemoryTileCache: NewMemoryTileCache(1 << 20)}
+	for run := 0; run < 2; run++ {
+		cache.gets, cache.hits = 0, 0
+		got, err := Diff(img1, img2, nil, WithChannelDiff(true), WithTileCache(cache))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.DiffPixels != want.DiffPixels || got.AAPixels != want.AAPixels || got.TotalPixels != want.TotalPixels || got.Bounds != want.Bounds || got.Channels != want.Channels {
+			t.Errorf("run %d: expected %+v, got - %+v", run, want, got)
+		}
+	}
+
+	// tiles of the same content share their results
+	tiles := int64((img1.Rect.Dx() + cacheTile - 1) / cacheTile * ((img1.Rect.Dy() + cacheTile - 1) / cacheTile))
+	if cache.gets != tiles || cache.hits != tiles || cache.Len() >= int(tiles) {
+		t.Errorf("Expected the second run to hit all %d tiles, got - %d of %d lookups", tiles, cache.hits, cache.gets)
+	}
+
+	// a changed pixel invalidates the tiles whose checks look at it
+	changed := image.NewNRGBA(img2.Rect)
+	copy(changed.Pix, img2.Pix)
+	changed.SetNRGBA(cacheTile*3, cacheTile*2, color.NRGBA{R: 1, A: 255})
+
+	cache.gets, cache.hits = 0, 0
+	if _, err := Diff(img1, changed, nil, WithChannelDiff(true), WithTileCache(cache)); err != nil {
+		t.Fatal(err)
+	}
+	if misses := cache.gets - cache.hits; misses != 4 {
+		t.Errorf("Expected the 4 tiles around the pixel to be compared again, got - %d", misses)
+	}
+
+	// other settings do not share the results
+	other := &countingTileCache{MemoryTileCache: NewMemoryTileCache(1 << 20)}
+	cache.gets, cache.hits = 0, 0
+	for _, c := range []TileCache{cache, other} {
+		if _, err := Diff(img1, img2, nil, WithChannelDiff(true), WithTileCache(c), WithThreshold(0.3)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cache.hits-other.hits != 0 {
+		t.Errorf("Expected no hits of the results with another threshold, got - %d", cache.hits-other.hits)
+	}
+
+	// the cache does not apply when a diff image is drawn
+	cache.gets = 0
+	if _, _, err := DiffNew(img1, img2, WithTileCache(cache)); err != nil || cache.gets != 0 {
+		t.Errorf("Expected the cache to be skipped, got - %d lookups %v", cache.gets, err)
+	}
+}
+
+func TestMemoryTileCache(t *testing.T) {
+	c := NewMemoryTileCache(2)
+	keys := []TileKey{{1}, {2}, {3}}
+
+	c.PutTile(keys[0], TileResult{DiffPixels: 1})
+	c.PutTile(keys[1], TileResult{DiffPixels: 2})
+	if r, ok := c.GetTile(keys[0]); !ok || r.DiffPixels != 1 {
+		t.Fatalf("Expected the first result, got - %+v %v", r, ok)
+	}
+
+	// the second key is the least recently used one now
+	c.PutTile(keys[2], TileResult{DiffPixels: 3})
+	if _, ok := c.GetTile(keys[1]); ok || c.Len() != 2 {
+		t.Errorf("Expected the second result to be evicted, got - %d results", c.Len())
+	}
+	if _, ok := c.GetTile(keys[0]); !ok {
+		t.Error("Expected the first result to stay")
+	}
+
+	c.PutTile(keys[2], TileResult{DiffPixels: 4})
+	if r, _ := c.GetTile(keys[2]); r.DiffPixels != 4 || c.Len() != 2 {
+		t.Errorf("Expected the third result to be replaced, got - %+v", r)
+	}
+}
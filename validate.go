@@ -0,0 +1,99 @@
+package pixelmatch
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrInvalidOptions is returned for options that cannot be applied,
+// see Options.Validate.
+var ErrInvalidOptions = errors.New("invalid options")
+
+// Validate normalizes the options and reports those that cannot be
+// applied. The threshold and the alpha are clamped to [0, 1]; a NaN of
+// them or of another float option, a nil color and a negative size,
+// count or distance make it return an error wrapping ErrInvalidOptions
+// that names all of them. The values the options document as disabling
+// them stay valid, e.g. a negative blur, scale, maximum dimension,
+// auto-align shift or sample rate. The comparisons validate their options
+// before comparing anything and fail with this error, so out-of-range
+// values do not silently produce nonsense diffs; New validates the options
+// of a Differ once, see Differ.Err.
+func (o *Options) Validate() error {
+	problems := append([]string(nil), o.nilColors...)
+
+	for _, v := range []struct {
+		name  string
+		value *float64
+	}{
+		{"threshold", &o.threshold},
+		{"alpha", &o.alpha},
+	} {
+		switch {
+		case math.IsNaN(*v.value):
+			problems = append(problems, v.name+" is NaN")
+		case *v.value < 0 || *v.value > 1:
+			o.debug("clamping option to [0, 1]", "option", v.name, "value", *v.value)
+			*v.value = math.Max(0, math.Min(1, *v.value))
+		}
+	}
+
+	for _, v := range []struct {
+		name  string
+		value float64
+	}{
+		{"text threshold", o.textThreshold},
+		{"ignore less than", o.ignoreLessThan},
+		{"failure threshold", o.failureThreshold},
+	} {
+		switch {
+		case math.IsNaN(v.value):
+			problems = append(problems, v.name+" is NaN")
+		case v.value < 0:
+			problems = append(problems, fmt.Sprintf("%s %v is negative", v.name, v.value))
+		}
+	}
+
+	// values that disable these options, negative ones included, are valid
+	for _, v := range []struct {
+		name  string
+		value float64
+	}{
+		{"blur", o.blur},
+		{"scale", o.scale},
+	} {
+		if math.IsNaN(v.value) {
+			problems = append(problems, v.name+" is NaN")
+		}
+	}
+
+	for _, v := range []struct {
+		name  string
+		value int
+	}{
+		{"grid columns", o.gridCols},
+		{"grid rows", o.gridRows},
+		{"shift tolerance", o.shift},
+		{"mask erosion", o.maskErode},
+		{"mask dilation", o.maskDilate},
+		{"bounding box padding", o.boxPadding},
+	} {
+		if v.value < 0 {
+			problems = append(problems, fmt.Sprintf("%s %d is negative", v.name, v.value))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidOptions, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// nilColor records that the option of the given name was passed a nil
+// color, which Validate reports.
+func (o *Options) nilColor(option string) {
+	o.nilColors = append(o.nilColors[:len(o.nilColors):len(o.nilColors)], option+" color is nil")
+}
@@ -0,0 +1,61 @@
+package pixelmatch
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	options := newOptions(WithThreshold(1.5), WithAlpha(-0.2))
+	if options.err != nil {
+		t.Fatalf("Expected clamped options to be valid, got - %v", options.err)
+	}
+	if options.threshold != 1 || options.alpha != 0 {
+		t.Errorf("Expected threshold 1 and alpha 0, got - %v %v", options.threshold, options.alpha)
+	}
+
+	options = newOptions(WithThreshold(math.NaN()), WithDiffColor(nil), WithIgnoreColor(nil), WithGrid(-1, 2), WithBlur(math.NaN()))
+	err := options.Validate()
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Fatalf("Expected ErrInvalidOptions, got - %v", err)
+	}
+	for _, want := range []string{"threshold is NaN", "diff color is nil", "ignore color is nil", "grid columns -1 is negative", "blur is NaN"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %q", want, err)
+		}
+	}
+	if options.diffColor != defaultOptions.diffColor || options.ignoreColor != nil {
+		t.Errorf("Expected nil colors to keep the defaults, got - %v %v", options.diffColor, options.ignoreColor)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	img.SetNRGBA(1, 1, color.NRGBA{R: 255, A: 255})
+	blank := image.NewNRGBA(img.Rect)
+
+	d := New(WithAAColor(nil))
+	if !errors.Is(d.Err(), ErrInvalidOptions) {
+		t.Errorf("Expected the Differ to report ErrInvalidOptions, got - %v", d.Err())
+	}
+	if _, err := d.Diff(img, blank, nil); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Expected Differ.Diff to fail with ErrInvalidOptions, got - %v", err)
+	}
+	if _, err := Compare(img, blank, WithMaskErode(-2)); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Expected Compare to fail with ErrInvalidOptions, got - %v", err)
+	}
+	if err := NewStreamDiffer(4, 4, WithMaskDilate(-1)).WriteRows(img.Pix, blank.Pix); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Expected the stream to fail with ErrInvalidOptions, got - %v", err)
+	}
+	// the values documented to disable an option
+	for _, opt := range []Option{WithScale(-1), WithScale(2), WithMaxDimension(-1), WithAutoAlign(-1), WithBlur(-1), WithSampleRate(-2)} {
+		if err := newOptions(opt).err; err != nil {
+			t.Errorf("Expected an option disabled by its value to be valid, got - %v", err)
+		}
+	}
+	if New().Err() != nil {
+		t.Errorf("Expected the defaults to be valid, got - %v", New().Err())
+	}
+}
@@ -0,0 +1,136 @@
+package video
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// RawFormat is the pixel format of a raw video stream.
+type RawFormat int
+
+const (
+	// RawRGB24 stores every pixel in 3 bytes, R, G and B,
+	// like ffmpeg -f rawvideo -pix_fmt rgb24.
+	RawRGB24 RawFormat = iota
+
+	// RawRGBA stores every pixel in 4 bytes, R, G, B and non-premultiplied A,
+	// like ffmpeg -f rawvideo -pix_fmt rgba.
+	RawRGBA
+)
+
+// String returns the ffmpeg name of the format.
+func (f RawFormat) String() string {
+	switch f {
+	case RawRGB24:
+		return "rgb24"
+	case RawRGBA:
+		return "rgba"
+	default:
+		return "unknown"
+	}
+}
+
+// pixelSize returns the number of bytes of a pixel.
+func (f RawFormat) pixelSize() int {
+	if f == RawRGB24 {
+		return 3
+	}
+
+	return 4
+}
+
+// RawReader reads the frames of a raw video stream, which has no header,
+// so the frame size and pixel format are given by the caller. Frames are
+// *image.NRGBA.
+type RawReader struct {
+	r      io.Reader
+	format RawFormat
+	frame  *image.NRGBA
+	buf    []byte
+}
+
+// NewRawReader returns a reader of frames of the given size and format.
+func NewRawReader(r io.Reader, width, height int, format RawFormat) *RawReader {
+	frame := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	raw := &RawReader{r: r, format: format, frame: frame}
+	if format == RawRGB24 {
+		raw.buf = make([]byte, 3*width*height)
+	}
+
+	return raw
+}
+
+// ReadFrame returns the next frame or io.EOF after the last one.
+// The frame is overwritten by the next call.
+func (r *RawReader) ReadFrame() (image.Image, error) {
+	if r.format == RawRGBA {
+		return r.read(r.frame.Pix)
+	}
+
+	if _, err := r.read(r.buf); err != nil {
+		return nil, err
+	}
+	for i, j := 0, 0; i < len(r.buf); i, j = i+3, j+4 {
+		r.frame.Pix[j], r.frame.Pix[j+1], r.frame.Pix[j+2], r.frame.Pix[j+3] = r.buf[i], r.buf[i+1], r.buf[i+2], 255
+	}
+
+	return r.frame, nil
+}
+
+// read fills buf with the next frame.
+func (r *RawReader) read(buf []byte) (image.Image, error) {
+	n, err := io.ReadFull(r.r, buf)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("truncated %s frame of %d bytes: %w", r.format, n, err)
+	}
+
+	return r.frame, nil
+}
+
+// RawWriter writes frames as a raw video stream.
+type RawWriter struct {
+	w      io.Writer
+	format RawFormat
+	frame  *image.NRGBA
+	buf    []byte
+}
+
+// NewRawWriter returns a writer of frames in the given format.
+func NewRawWriter(w io.Writer, format RawFormat) *RawWriter {
+	return &RawWriter{w: w, format: format}
+}
+
+// WriteFrame writes img as the next frame.
+func (w *RawWriter) WriteFrame(img image.Image) error {
+	r := img.Bounds()
+
+	frame, ok := img.(*image.NRGBA)
+	if !ok || frame.Stride != 4*r.Dx() {
+		if w.frame == nil || !w.frame.Rect.Eq(r) {
+			w.frame = image.NewNRGBA(r)
+		}
+		draw.Draw(w.frame, r, img, r.Min, draw.Src)
+		frame = w.frame
+	}
+
+	if w.format == RawRGBA {
+		_, err := w.w.Write(frame.Pix[:4*r.Dx()*r.Dy()])
+		return err
+	}
+
+	if size := 3 * r.Dx() * r.Dy(); len(w.buf) != size {
+		w.buf = make([]byte, size)
+	}
+	for i, j := 0, 0; i < len(w.buf); i, j = i+3, j+4 {
+		w.buf[i], w.buf[i+1], w.buf[i+2] = frame.Pix[j], frame.Pix[j+1], frame.Pix[j+2]
+	}
+	_, err := w.w.Write(w.buf)
+
+	return err
+}
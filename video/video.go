@@ -0,0 +1,183 @@
+// Package video compares two videos frame by frame, e.g. the output of an
+// encoder against a reference, reading YUV4MPEG2 or raw RGB streams such as
+// those piped out of ffmpeg:
+//
+//	ffmpeg -i reference.mp4 -f yuv4mpegpipe reference.y4m
+//	ffmpeg -i encoded.mp4 -f yuv4mpegpipe encoded.y4m
+//
+//	a, err := video.NewY4MReader(fa)
+//	b, err := video.NewY4MReader(fb)
+//	result, err := video.Compare(ctx, a, b, pixelmatch.New(pixelmatch.WithThreshold(0.05)), nil)
+package video
+
+import (
+	"context"
+	"errors"
+	"image"
+	"io"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+// FrameReader reads the frames of a video one by one.
+type FrameReader interface {
+	// ReadFrame returns the next frame or io.EOF after the last one.
+	// The frame may be overwritten by the next call.
+	ReadFrame() (image.Image, error)
+}
+
+// FrameReaderFunc adapts an iterator function to a FrameReader.
+type FrameReaderFunc func() (image.Image, error)
+
+// ReadFrame calls f().
+func (f FrameReaderFunc) ReadFrame() (image.Image, error) {
+	return f()
+}
+
+// Frames returns a FrameReader of the given frames.
+func Frames(frames ...image.Image) FrameReader {
+	return FrameReaderFunc(func() (image.Image, error) {
+		if len(frames) == 0 {
+			return nil, io.EOF
+		}
+
+		frame := frames[0]
+		frames = frames[1:]
+
+		return frame, nil
+	})
+}
+
+// FrameWriter writes the frames of a video one by one.
+type FrameWriter interface {
+	WriteFrame(img image.Image) error
+}
+
+// Result describes the outcome of comparing two videos.
+type Result struct {
+	// results of the frames compared pairwise, in order
+	Frames []pixelmatch.DiffResult `json:"frames"`
+
+	// number of frames of both videos; the extra frames
+	// of the longer video are read but not compared
+	FramesA int `json:"framesA"`
+	FramesB int `json:"framesB"`
+
+	// number of compared frames with different pixels
+	DiffFrames int `json:"diffFrames"`
+
+	// sums of the different and compared pixels of all compared frames
+	DiffPixels  uint64 `json:"diffPixels"`
+	TotalPixels uint64 `json:"totalPixels"`
+
+	// share of different pixels of all compared frames, from 0 to 100
+	Percent float64 `json:"percent"`
+
+	// index of the frame with the largest share of different pixels
+	// and that share; -1 and 0 when no frame differs
+	WorstFrame   int     `json:"worstFrame"`
+	WorstPercent float64 `json:"worstPercent"`
+}
+
+// Equal reports whether both videos have the same number of frames
+// and no frame has different pixels.
+func (r Result) Equal() bool {
+	return r.FramesA == r.FramesB && r.DiffFrames == 0
+}
+
+// Compare reads the frames of a and b in lockstep and compares frame N of a
+// with frame N of b with d, which holds the options and reuses its buffers
+// from frame to frame. Unless out is nil the diff of every compared frame is
+// written to it, e.g. a Y4MWriter to watch the differences in a player.
+// Comparing stops at the first error of reading, comparing or writing.
+func Compare(ctx context.Context, a, b FrameReader, d *pixelmatch.Differ, out FrameWriter) (Result, error) {
+	result := Result{WorstFrame: -1}
+
+	var output *image.NRGBA
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		frameA, errA := readFrame(a, &result.FramesA)
+		frameB, errB := readFrame(b, &result.FramesB)
+		if err := firstError(errA, errB); err != nil {
+			return result, err
+		}
+		if errA == io.EOF || errB == io.EOF {
+			break
+		}
+
+		if out != nil && (output == nil || !output.Rect.Eq(frameA.Bounds())) {
+			output = image.NewNRGBA(frameA.Bounds())
+		}
+
+		frame, err := d.DiffContext(ctx, frameA, frameB, output)
+		if err != nil && !errors.Is(err, pixelmatch.ErrDiffBudgetExceeded) {
+			return result, err
+		}
+		result.add(frame)
+
+		if out != nil {
+			if err := out.WriteFrame(output); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	// count the extra frames of the longer video
+	for _, r := range []struct {
+		reader FrameReader
+		count  *int
+	}{{a, &result.FramesA}, {b, &result.FramesB}} {
+		for {
+			_, err := readFrame(r.reader, r.count)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// readFrame reads the next frame of r and counts it.
+func readFrame(r FrameReader, count *int) (image.Image, error) {
+	frame, err := r.ReadFrame()
+	if err == nil {
+		*count++
+	}
+
+	return frame, err
+}
+
+// firstError returns the first of the errors other than io.EOF.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil && err != io.EOF {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// add adds the result of the next compared frame.
+func (r *Result) add(frame pixelmatch.DiffResult) {
+	if frame.DiffPixels > 0 {
+		r.DiffFrames++
+		if frame.Percent > r.WorstPercent {
+			r.WorstFrame, r.WorstPercent = len(r.Frames), frame.Percent
+		}
+	}
+
+	r.Frames = append(r.Frames, frame)
+	r.DiffPixels += frame.DiffPixels
+	r.TotalPixels += frame.TotalPixels
+	if r.TotalPixels > 0 {
+		r.Percent = float64(r.DiffPixels) * 100 / float64(r.TotalPixels)
+	}
+}
@@ -0,0 +1,155 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+
+	"github.com/inotnako/pixelmatch-go"
+)
+
+// testFrame returns a white frame with a black square of the given size.
+func testFrame(square int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 12))
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 16; x++ {
+			c := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			if x >= 4 && x < 4+square && y >= 4 && y < 4+square {
+				c = color.NRGBA{A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+// countingWriter counts the written frames.
+type countingWriter struct {
+	frames int
+}
+
+func (w *countingWriter) WriteFrame(img image.Image) error {
+	w.frames++
+	return nil
+}
+
+func TestCompare(t *testing.T) {
+	var (
+		a   = Frames(testFrame(2), testFrame(2), testFrame(2))
+		b   = Frames(testFrame(2), testFrame(3), testFrame(4), testFrame(4))
+		out = &countingWriter{}
+	)
+
+	result, err := Compare(context.Background(), a, b, pixelmatch.New(pixelmatch.WithIncludeAA(true)), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.FramesA != 3 || result.FramesB != 4 || len(result.Frames) != 3 || out.frames != 3 {
+		t.Fatalf("Expected 3 of 3 and 4 frames compared and written, got - %+v (%d written)", result, out.frames)
+	}
+	if result.DiffFrames != 2 || result.DiffPixels != 5+12 || result.TotalPixels != 3*16*12 {
+		t.Errorf("Expected 17 different pixels in 2 frames, got - %+v", result)
+	}
+	if result.WorstFrame != 2 || result.Equal() {
+		t.Errorf("Expected the last compared frame to be the worst, got - %d", result.WorstFrame)
+	}
+
+	same, err := Compare(context.Background(), Frames(testFrame(2)), Frames(testFrame(2)), pixelmatch.New(), nil)
+	if err != nil || !same.Equal() || same.WorstFrame != -1 {
+		t.Errorf("Expected equal videos, got - %+v %v", same, err)
+	}
+
+	broken := FrameReaderFunc(func() (image.Image, error) { return nil, io.ErrUnexpectedEOF })
+	if _, err := Compare(context.Background(), Frames(testFrame(2)), broken, pixelmatch.New(), nil); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected the read error, got - %v", err)
+	}
+}
+
+func TestY4M(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewY4MWriter(&buf, 30000, 1001)
+	for _, square := range []int{2, 3} {
+		if err := w.WriteFrame(testFrame(square)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.WriteFrame(image.NewNRGBA(image.Rect(0, 0, 2, 2))); !errors.Is(err, ErrY4M) {
+		t.Errorf("Expected a frame of another size to be rejected, got - %v", err)
+	}
+
+	r, err := NewY4MReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if num, den := r.FrameRate(); num != 30000 || den != 1001 || r.Bounds() != testFrame(0).Rect {
+		t.Errorf("Unexpected header %d/%d %v", num, den, r.Bounds())
+	}
+
+	result, err := Compare(context.Background(), Frames(testFrame(2), testFrame(2)), r, pixelmatch.New(pixelmatch.WithIncludeAA(true)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FramesB != 2 || result.Frames[0].DiffPixels != 0 || result.Frames[1].DiffPixels != 5 {
+		t.Errorf("Expected the frames to survive the round trip, got - %+v", result)
+	}
+
+	// 4:2:0 frame of 2x2 pixels: 4 luma samples and one of each chroma
+	r, err = NewY4MReader(bytes.NewReader([]byte("YUV4MPEG2 W2 H2 F25:1 C420jpeg\nFRAME\n\x10\x20\x30\x40\x80\x80")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame, err := r.ReadFrame()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gray := color.GrayModel.Convert(frame.At(1, 1)).(color.Gray); gray.Y != 0x40 {
+		t.Errorf("Expected the luma of the last sample, got - %v", gray)
+	}
+	if _, err := r.ReadFrame(); err != io.EOF {
+		t.Errorf("Expected io.EOF after the last frame, got - %v", err)
+	}
+
+	for _, stream := range []string{
+		"",
+		"MPEG W2 H2\n",
+		"YUV4MPEG2 W2 H2 C420p10\n",
+		"YUV4MPEG2 W0 H2\n",
+		"YUV4MPEG2 W2 H2\nFRAME\n\x10",
+	} {
+		r, err := NewY4MReader(bytes.NewReader([]byte(stream)))
+		if err == nil {
+			_, err = r.ReadFrame()
+		}
+		if !errors.Is(err, ErrY4M) && !(stream == "" && err == io.EOF) {
+			t.Errorf("%q: expected ErrY4M, got - %v", stream, err)
+		}
+	}
+}
+
+func TestRaw(t *testing.T) {
+	for _, format := range []RawFormat{RawRGB24, RawRGBA} {
+		var buf bytes.Buffer
+		w := NewRawWriter(&buf, format)
+		for _, frame := range []image.Image{testFrame(2), testFrame(3).SubImage(image.Rect(0, 0, 16, 12))} {
+			if err := w.WriteFrame(frame); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if buf.Len() != 2*16*12*format.pixelSize() {
+			t.Fatalf("%s: expected 2 frames, got - %d bytes", format, buf.Len())
+		}
+
+		buf.WriteByte(0)
+		r := NewRawReader(&buf, 16, 12, format)
+		result, err := Compare(context.Background(), r, Frames(testFrame(2), testFrame(2)), pixelmatch.New(pixelmatch.WithIncludeAA(true)), nil)
+		if err == nil || result.DiffPixels != 5 {
+			t.Errorf("%s: expected 5 different pixels and a truncated frame, got - %+v %v", format, result, err)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package video
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrY4M is returned for malformed or unsupported YUV4MPEG2 streams.
+var ErrY4M = errors.New("invalid YUV4MPEG2 stream")
+
+const (
+	y4mMagic      = "YUV4MPEG2"
+	y4mFrameMagic = "FRAME"
+)
+
+// Y4MReader reads the frames of a YUV4MPEG2 stream with 8-bit 4:2:0, 4:2:2,
+// 4:4:4 or monochrome samples, as written by ffmpeg -f yuv4mpegpipe. Frames
+// are *image.YCbCr (*image.Gray for monochrome streams) and converted to RGB
+// like the image package does, so both compared streams should use the same
+// color range.
+type Y4MReader struct {
+	r      *bufio.Reader
+	width  int
+	height int
+
+	// frame rate as a fraction, 0/0 when the header has none
+	rateNum, rateDen int
+
+	ycbcr *image.YCbCr
+	gray  *image.Gray
+}
+
+// NewY4MReader reads the stream header from r.
+func NewY4MReader(r io.Reader) (*Y4MReader, error) {
+	y := &Y4MReader{r: bufio.NewReader(r)}
+
+	header, err := y.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	params := strings.Fields(header)
+	if len(params) == 0 || params[0] != y4mMagic {
+		return nil, fmt.Errorf("%w: missing %s signature", ErrY4M, y4mMagic)
+	}
+
+	colorspace := "420jpeg"
+	for _, p := range params[1:] {
+		value := p[1:]
+		switch p[0] {
+		case 'W':
+			y.width, err = strconv.Atoi(value)
+		case 'H':
+			y.height, err = strconv.Atoi(value)
+		case 'F':
+			_, err = fmt.Sscanf(value, "%d:%d", &y.rateNum, &y.rateDen)
+		case 'C':
+			colorspace = value
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: parameter %s: %v", ErrY4M, p, err)
+		}
+	}
+
+	if y.width <= 0 || y.height <= 0 {
+		return nil, fmt.Errorf("%w: frame size %dx%d", ErrY4M, y.width, y.height)
+	}
+
+	rect := image.Rect(0, 0, y.width, y.height)
+	switch colorspace {
+	case "420jpeg", "420paldv", "420mpeg2", "420":
+		y.ycbcr = image.NewYCbCr(rect, image.YCbCrSubsampleRatio420)
+	case "422":
+		y.ycbcr = image.NewYCbCr(rect, image.YCbCrSubsampleRatio422)
+	case "444":
+		y.ycbcr = image.NewYCbCr(rect, image.YCbCrSubsampleRatio444)
+	case "mono":
+		y.gray = image.NewGray(rect)
+	default:
+		return nil, fmt.Errorf("%w: unsupported colorspace %s", ErrY4M, colorspace)
+	}
+
+	return y, nil
+}
+
+// Bounds returns the bounds of the frames.
+func (y *Y4MReader) Bounds() image.Rectangle {
+	return image.Rect(0, 0, y.width, y.height)
+}
+
+// FrameRate returns the frame rate of the stream as a fraction,
+// 0/0 when the header does not tell.
+func (y *Y4MReader) FrameRate() (num, den int) {
+	return y.rateNum, y.rateDen
+}
+
+// ReadFrame returns the next frame or io.EOF after the last one.
+// The frame is overwritten by the next call.
+func (y *Y4MReader) ReadFrame() (image.Image, error) {
+	header, err := y.readLine()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(header, y4mFrameMagic) {
+		return nil, fmt.Errorf("%w: missing %s signature", ErrY4M, y4mFrameMagic)
+	}
+
+	planes := [][]uint8{nil}
+	if y.gray != nil {
+		planes[0] = y.gray.Pix
+	} else {
+		planes = [][]uint8{y.ycbcr.Y, y.ycbcr.Cb, y.ycbcr.Cr}
+	}
+
+	for _, plane := range planes {
+		if _, err := io.ReadFull(y.r, plane); err != nil {
+			return nil, fmt.Errorf("%w: truncated frame: %v", ErrY4M, err)
+		}
+	}
+
+	if y.gray != nil {
+		return y.gray, nil
+	}
+
+	return y.ycbcr, nil
+}
+
+// readLine reads a header line without the newline; io.EOF means there is
+// no more data.
+func (y *Y4MReader) readLine() (string, error) {
+	line, err := y.r.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", io.EOF
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: truncated header: %v", ErrY4M, err)
+	}
+
+	return strings.TrimSuffix(line, "\n"), nil
+}
+
+// Y4MWriter writes frames as a YUV4MPEG2 stream with 4:4:4 samples, which
+// players and ffmpeg read; the alpha channel of the frames is dropped. The
+// stream header is written with the first frame, all frames must have its
+// bounds.
+type Y4MWriter struct {
+	w                io.Writer
+	rateNum, rateDen int
+
+	bounds image.Rectangle
+	buf    []byte
+}
+
+// NewY4MWriter returns a writer of a stream with the given frame rate
+// fraction, e.g. 25/1 or Y4MReader.FrameRate of the compared stream;
+// a zero fraction writes 25/1.
+func NewY4MWriter(w io.Writer, rateNum, rateDen int) *Y4MWriter {
+	if rateNum <= 0 || rateDen <= 0 {
+		rateNum, rateDen = 25, 1
+	}
+
+	return &Y4MWriter{w: w, rateNum: rateNum, rateDen: rateDen}
+}
+
+// WriteFrame writes img as the next frame.
+func (y *Y4MWriter) WriteFrame(img image.Image) error {
+	r := img.Bounds()
+	if y.buf == nil {
+		y.bounds = r
+		y.buf = make([]byte, 3*r.Dx()*r.Dy())
+
+		header := fmt.Sprintf("%s W%d H%d F%d:%d Ip A1:1 C444\n", y4mMagic, r.Dx(), r.Dy(), y.rateNum, y.rateDen)
+		if _, err := io.WriteString(y.w, header); err != nil {
+			return err
+		}
+	}
+	if r.Dx() != y.bounds.Dx() || r.Dy() != y.bounds.Dy() {
+		return fmt.Errorf("%w: frame size %v differs from %v", ErrY4M, r.Size(), y.bounds.Size())
+	}
+
+	var (
+		n          = r.Dx() * r.Dy()
+		yp, cb, cr = y.buf[:n], y.buf[n : 2*n], y.buf[2*n:]
+		i          int
+	)
+	nrgba, _ := img.(*image.NRGBA)
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			var c color.NRGBA
+			if nrgba != nil {
+				c = nrgba.NRGBAAt(px, py)
+			} else {
+				c = color.NRGBAModel.Convert(img.At(px, py)).(color.NRGBA)
+			}
+			yp[i], cb[i], cr[i] = color.RGBToYCbCr(c.R, c.G, c.B)
+			i++
+		}
+	}
+
+	if _, err := io.WriteString(y.w, y4mFrameMagic+"\n"); err != nil {
+		return err
+	}
+	_, err := y.w.Write(y.buf)
+
+	return err
+}